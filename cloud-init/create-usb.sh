@@ -5,11 +5,13 @@
 # Creates a bootable USB drive with cloud-init configuration for NoCloud
 # datasource. Works on both macOS and Linux.
 #
-# Usage: ./create-usb.sh [device]
+# Usage: ./create-usb.sh [--spec ucli.yaml] [device]
 #
-# The USB drive will contain:
+# The seed image is built by 'ucli seed build' from a spec file and written
+# to the whole device. The USB drive will contain:
 #   - meta-data: Instance metadata
-#   - user-data: Cloud-init configuration (from cloud-init.yaml)
+#   - user-data: Cloud-init configuration generated from the spec
+#   - network-config: DHCP on every ethernet interface
 #
 # For bare-metal installation:
 #   1. Boot from Ubuntu Server ISO
@@ -24,9 +26,8 @@ set -o pipefail
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
 
 # Files
-CLOUD_INIT_YAML="${SCRIPT_DIR}/cloud-init.yaml"
-META_DATA_FILE=""
-USER_DATA_FILE=""
+SPEC_FILE="ucli.yaml"
+SEED_IMAGE=""
 
 # Colors
 RED='\033[0;31m'
@@ -128,6 +129,34 @@ confirm_device() {
     fi
 }
 
+#==============================================================================
+# Seed Image
+#==============================================================================
+
+find_ucli() {
+    if [[ -n "${UCLI:-}" ]]; then
+        echo "${UCLI}"
+    elif command -v ucli &>/dev/null; then
+        command -v ucli
+    elif [[ -x "${SCRIPT_DIR}/../bin/ucli" ]]; then
+        echo "${SCRIPT_DIR}/../bin/ucli"
+    else
+        log_error "ucli not found. Build it with 'make build-cli' or set UCLI"
+        exit 1
+    fi
+}
+
+build_seed_image() {
+    local ucli
+    ucli=$(find_ucli)
+
+    SEED_IMAGE="$(mktemp -d)/seed.img"
+    trap 'rm -rf "$(dirname "${SEED_IMAGE}")"' EXIT
+
+    log_info "Building seed image from ${SPEC_FILE}..."
+    "${ucli}" seed build --spec "${SPEC_FILE}" --format fat --out "${SEED_IMAGE}"
+}
+
 #==============================================================================
 # USB Creation
 #==============================================================================
@@ -138,22 +167,11 @@ create_usb_macos() {
     log_info "Unmounting device..."
     diskutil unmountDisk "${device}" || true
 
-    log_info "Formatting as FAT32 with label 'cidata'..."
-    diskutil eraseDisk FAT32 cidata MBRFormat "${device}"
+    # The raw device is much faster to write than the buffered one
+    log_info "Writing seed image to ${device}..."
+    sudo dd if="${SEED_IMAGE}" of="${device/\/dev\/disk//dev/rdisk}" bs=1m
 
-    # Get the partition
-    local partition="${device}s1"
-
-    log_info "Mounting partition..."
-    diskutil mount "${partition}"
-
-    local mount_point="/Volumes/cidata"
-
-    log_info "Copying cloud-init files..."
-    create_cloud_init_files "${mount_point}"
-
-    log_info "Unmounting..."
-    diskutil unmount "${mount_point}"
+    diskutil eject "${device}" || true
 
     log_success "USB created successfully!"
 }
@@ -165,84 +183,19 @@ create_usb_linux() {
     log_info "Unmounting device..."
     umount "${device}"* 2>/dev/null || true
 
-    log_info "Creating partition table..."
-    sudo parted -s "${device}" mklabel msdos
-    sudo parted -s "${device}" mkpart primary fat32 1MiB 100%
-
-    # Get partition name
-    local partition="${device}1"
-    if [[ "${device}" == *"nvme"* ]]; then
-        partition="${device}p1"
-    fi
-
-    log_info "Formatting as FAT32 with label 'cidata'..."
-    sudo mkfs.vfat -n cidata "${partition}"
-
-    log_info "Mounting partition..."
-    local mount_point="/tmp/cidata-$$"
-    mkdir -p "${mount_point}"
-    sudo mount "${partition}" "${mount_point}"
-
-    log_info "Copying cloud-init files..."
-    create_cloud_init_files "${mount_point}"
-
-    log_info "Unmounting..."
-    sudo umount "${mount_point}"
-    rmdir "${mount_point}"
+    log_info "Writing seed image to ${device}..."
+    sudo dd if="${SEED_IMAGE}" of="${device}" bs=4M conv=fsync
 
     log_success "USB created successfully!"
 }
 
-create_cloud_init_files() {
-    local mount_point="$1"
-
-    # Create meta-data
-    log_info "Creating meta-data..."
-    # Extract hostname from cloud-init.yaml or use default
-    local instance_id
-    instance_id="iid-local-$(date +%Y%m%d%H%M%S)"
-
-    local hostname
-    if [[ -f "${CLOUD_INIT_YAML}" ]]; then
-        hostname=$(grep -E "^hostname:" "${CLOUD_INIT_YAML}" | awk '{print $2}' || echo "ubuntu-server")
-    else
-        hostname="ubuntu-server"
-    fi
-
-    cat > "${mount_point}/meta-data" << EOF
-instance-id: ${instance_id}
-local-hostname: ${hostname}
-EOF
-
-    # Copy user-data (cloud-init.yaml)
-    log_info "Copying user-data..."
-    if [[ -f "${CLOUD_INIT_YAML}" ]]; then
-        cp "${CLOUD_INIT_YAML}" "${mount_point}/user-data"
-    else
-        log_error "cloud-init.yaml not found. Run ./generate.sh first."
-        exit 1
-    fi
-
-    # Create empty network-config (use DHCP)
-    log_info "Creating network-config..."
-    cat > "${mount_point}/network-config" << EOF
-version: 2
-ethernets:
-  id0:
-    match:
-      driver: "*"
-    dhcp4: true
-EOF
-
-    log_success "Cloud-init files created"
-}
-
 #==============================================================================
 # Main
 #==============================================================================
 
 main() {
-    local device="${1:-}"
+    local device=""
+    local dry_run=false
 
     echo ""
     echo "════════════════════════════════════════════"
@@ -250,39 +203,57 @@ main() {
     echo "════════════════════════════════════════════"
     echo ""
 
-    # Check for cloud-init.yaml
-    if [[ ! -f "${CLOUD_INIT_YAML}" ]]; then
-        log_error "cloud-init.yaml not found"
-        log_info "Run ./generate.sh first to create it"
-        exit 1
-    fi
-
     # Parse arguments
-    for arg in "$@"; do
-        case "${arg}" in
+    while [[ $# -gt 0 ]]; do
+        case "$1" in
             --help|-h)
-                echo "Usage: $0 [device]"
+                echo "Usage: $0 [--spec ucli.yaml] [device]"
                 echo ""
                 echo "Creates a bootable USB with cloud-init configuration."
                 echo ""
                 echo "Arguments:"
                 echo "  device    Target device (e.g., /dev/disk2 on macOS, /dev/sdb on Linux)"
                 echo ""
+                echo "Options:"
+                echo "  --spec FILE    ucli spec to generate the configuration from (default: ucli.yaml)"
+                echo "  --dry-run, -n  Build the seed image without writing it"
+                echo ""
                 echo "Examples:"
-                echo "  $0 /dev/disk2      # macOS"
-                echo "  $0 /dev/sdb        # Linux"
+                echo "  $0 /dev/disk2                    # macOS"
+                echo "  $0 --spec box.yaml /dev/sdb      # Linux"
                 exit 0
                 ;;
+            --spec)
+                SPEC_FILE="${2:-}"
+                shift
+                ;;
             --dry-run|-n)
-                log_info "Dry-run mode - would create USB with:"
-                echo "  meta-data: instance metadata"
-                echo "  user-data: $(wc -l < "${CLOUD_INIT_YAML}") lines from cloud-init.yaml"
-                echo "  network-config: DHCP configuration"
-                exit 0
+                dry_run=true
+                ;;
+            *)
+                device="$1"
                 ;;
         esac
+        shift
     done
 
+    # Check for the spec
+    if [[ ! -f "${SPEC_FILE}" ]]; then
+        log_error "Spec not found: ${SPEC_FILE}"
+        log_info "See the ucli.yaml spec in the README, or pass --spec"
+        exit 1
+    fi
+
+    build_seed_image
+
+    if [[ "${dry_run}" == "true" ]]; then
+        log_info "Dry-run mode - would write $(wc -c < "${SEED_IMAGE}" | tr -d ' ') bytes with:"
+        echo "  meta-data: instance metadata"
+        echo "  user-data: cloud-init configuration from ${SPEC_FILE}"
+        echo "  network-config: DHCP configuration"
+        exit 0
+    fi
+
     local platform
     platform=$(detect_platform)
     log_info "Platform: ${platform}"
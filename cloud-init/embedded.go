@@ -5,6 +5,7 @@ import _ "embed"
 
 // Template contains the cloud-init.yaml template with variable placeholders.
 // Variables like ${USERNAME}, ${HOSTNAME}, etc. are substituted at generation time.
+// It is the source of the cloud-config built by generator.NewCloudConfig.
 //
 //go:embed cloud-init.template.yaml
 var Template string
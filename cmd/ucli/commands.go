@@ -4,10 +4,23 @@ import "github.com/spf13/cobra"
 
 // newPackagesCmd creates the packages subcommand
 func newPackagesCmd() *cobra.Command {
-	return &cobra.Command{
+	var f packagesFlags
+
+	cmd := &cobra.Command{
 		Use:   "packages",
 		Short: "List available packages",
-		Long:  `List all available packages that can be installed via cloud-init.`,
-		RunE:  runPackages,
+		Long: `List all available packages that can be installed via cloud-init, with their dependencies and conflicts.
+
+With --validate, report installer scripts that are missing metadata
+(DISPLAY_NAME, CATEGORY, DEFAULT, description) or could not be parsed, and
+exit non-zero if there are any. --dir checks a directory of scripts instead
+of the ones built into ucli.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runPackages(cmd, f)
+		},
 	}
+
+	cmd.Flags().BoolVar(&f.validate, "validate", false, "report scripts with missing or invalid metadata")
+	cmd.Flags().StringVar(&f.dir, "dir", "", "read installer scripts from this directory instead of the embedded ones")
+	return cmd
 }
@@ -0,0 +1,625 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/configonly"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/multipass"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/terragrunt"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/journal"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/packages"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/spec"
+)
+
+// createFlags holds the flag values for the create command.
+type createFlags struct {
+	specPath    string
+	projectPath string
+	resume      string
+
+	target        string
+	name          string
+	cpus          int
+	memoryMB      int
+	diskGB        int
+	ubuntuVersion string
+	image         string
+	libvirtURI    string
+	storagePool   string
+	network       string
+	autostart     bool
+	apply         bool
+	autoApprove   bool
+	keepOnFailure bool
+	outputDir     string
+	cloudInit     bool
+
+	count     int
+	names     []string
+	parallel  int
+	onFailure string
+
+	username     string
+	hostname     string
+	displayName  string
+	gitName      string
+	gitEmail     string
+	sshKeys      []string
+	sshKeyFiles  []string
+	githubUser   string
+	githubPAT    string
+	tailscaleKey string
+	packages     []string
+}
+
+func newCreateCmd() *cobra.Command {
+	f := &createFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a VM or config non-interactively",
+		Long: `Create a VM or generate config files without the interactive wizard.
+
+Options come from a spec file (--spec), from flags, or both. Flags that are
+set explicitly override values from the spec file. Progress is printed to
+stdout and the command exits non-zero if the deployment fails.
+
+Targets:
+  terragrunt   Generate Terragrunt config for a libvirt VM under tf/<name>/,
+               and with --apply run terragrunt init, plan and apply
+  multipass    Launch a local Multipass VM
+  config       Write config.env, secrets.env and summary.md only
+
+Examples:
+  ucli create --spec ucli.yaml
+  ucli create --spec ucli.yaml --target terragrunt --apply --auto-approve
+  ucli create --target multipass --name dev --username me --hostname dev \
+    --ssh-key-file ~/.ssh/id_ed25519.pub
+  ucli create --target config --output-dir ./out --cloud-init \
+    --username me --hostname box --packages lazygit,fzf
+
+A failed deployment that kept its resources (--keep-on-failure) can be
+continued with --resume, which skips the stages it completed:
+  ucli create --resume dev-vm
+
+--count or --names (or a fleet section in the spec) creates several
+identical machines, a few at a time. --on-failure decides what happens
+to the others when one fails: continue, abort (stop starting new ones and
+cancel those running) or cleanup (abort, then remove those that succeeded):
+  ucli create --spec ucli.yaml --name dev --count 5 --parallel 2
+  ucli create --spec ucli.yaml --names web:web.lan,db --on-failure cleanup`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCreate(cmd, f)
+		},
+	}
+
+	flags := cmd.Flags()
+	addSpecFlags(flags, f)
+
+	flags.StringVarP(&f.target, "target", "t", "", "deployment target: terragrunt, multipass or config")
+	flags.StringVarP(&f.name, "name", "n", "", "VM name (auto-generated if empty)")
+	flags.IntVar(&f.cpus, "cpus", 0, "number of vCPUs")
+	flags.IntVar(&f.memoryMB, "memory", 0, "memory in MB")
+	flags.IntVar(&f.diskGB, "disk", 0, "disk size in GB")
+	flags.StringVar(&f.ubuntuVersion, "ubuntu-version", "", "Ubuntu version for multipass (e.g. 24.04)")
+	flags.StringVar(&f.image, "image", "", "Ubuntu cloud image path for terragrunt")
+	flags.StringVar(&f.libvirtURI, "libvirt-uri", "", "libvirt connection URI for terragrunt")
+	flags.StringVar(&f.storagePool, "storage-pool", "", "libvirt storage pool for terragrunt")
+	flags.StringVar(&f.network, "network", "", "libvirt network for terragrunt")
+	flags.BoolVar(&f.autostart, "autostart", false, "start the VM on host boot (terragrunt)")
+	flags.BoolVar(&f.apply, "apply", false, "run terragrunt init, plan and apply after generating (terragrunt)")
+	flags.BoolVar(&f.autoApprove, "auto-approve", false, "apply without asking to confirm the plan (terragrunt)")
+	flags.BoolVar(&f.keepOnFailure, "keep-on-failure", false, "keep resources for debugging on failure")
+	flags.StringVarP(&f.outputDir, "output-dir", "o", "", "output directory for the config target")
+	flags.BoolVar(&f.cloudInit, "cloud-init", false, "also write cloud-init/cloud-init.yaml (config target)")
+
+	flags.StringVar(&f.resume, "resume", "", "resume the failed deployment with this journal ID or VM name (see ucli logs)")
+
+	flags.IntVar(&f.count, "count", 0, "create this many machines named <name>-1 to <name>-<count>")
+	flags.StringSliceVar(&f.names, "names", nil, "create these machines, as name or name:hostname (comma-separated)")
+	flags.IntVar(&f.parallel, "parallel", 0, fmt.Sprintf("machines deployed at once with --count or --names (default %d)", fleet.DefaultParallel))
+	flags.StringVar(&f.onFailure, "on-failure", "", "when a machine of a fleet fails: continue (default), abort or cleanup")
+
+	addUserFlags(flags, f)
+
+	cmd.MarkFlagsMutuallyExclusive("resume", "spec")
+	cmd.MarkFlagsMutuallyExclusive("resume", "target")
+	cmd.MarkFlagsMutuallyExclusive("resume", "count")
+	cmd.MarkFlagsMutuallyExclusive("resume", "names")
+	cmd.MarkFlagsMutuallyExclusive("count", "names")
+
+	return cmd
+}
+
+// addSpecFlags registers the spec file and project flags.
+func addSpecFlags(flags *pflag.FlagSet, f *createFlags) {
+	flags.StringVarP(&f.specPath, "spec", "f", "", "path to a ucli.yaml spec file")
+	flags.StringVar(&f.projectPath, "project", "", "project path (default: from ucli init)")
+}
+
+// addUserFlags registers the flags for the machine user and packages,
+// which every command that generates cloud-init shares.
+func addUserFlags(flags *pflag.FlagSet, f *createFlags) {
+	flags.StringVar(&f.username, "username", "", "username on the machine")
+	flags.StringVar(&f.hostname, "hostname", "", "machine hostname")
+	flags.StringVar(&f.displayName, "display-name", "", "display name for the machine user")
+	flags.StringVar(&f.gitName, "git-name", "", "git commit name")
+	flags.StringVar(&f.gitEmail, "git-email", "", "git commit email")
+	flags.StringArrayVar(&f.sshKeys, "ssh-key", nil, "SSH public key (repeatable)")
+	flags.StringArrayVar(&f.sshKeyFiles, "ssh-key-file", nil, "file containing an SSH public key (repeatable)")
+	flags.StringVar(&f.githubUser, "github-user", "", "GitHub username for importing SSH keys")
+	flags.StringVar(&f.githubPAT, "github-pat", "", "GitHub personal access token")
+	flags.StringVar(&f.tailscaleKey, "tailscale-key", "", "Tailscale auth key")
+	flags.StringSliceVar(&f.packages, "packages", nil, "comma-separated packages to enable (default: all)")
+}
+
+// runCreate resolves the spec, runs the matching deployer and reports the result.
+func runCreate(cmd *cobra.Command, f *createFlags) error {
+	if f.resume != "" {
+		return runResume(cmd, f)
+	}
+
+	s, err := f.resolveSpec(cmd)
+	if err != nil {
+		return err
+	}
+	if s.Fleet != nil {
+		return runFleet(cmd, f, s)
+	}
+
+	deployer, opts, err := f.newDeployment(s)
+	if err != nil {
+		return err
+	}
+	if deployer.Target() == deploy.TargetTerragrunt {
+		opts.Terragrunt.Approve = promptApproval(cmd.InOrStdin(), cmd.OutOrStdout())
+	}
+
+	return runDeployment(cmd, deployer, opts)
+}
+
+// newDeployment returns the deployer and options for the machine s
+// describes. Terragrunt approval is left to the caller.
+func (f *createFlags) newDeployment(s *spec.Spec) (deploy.Deployer, *deploy.DeployOptions, error) {
+	target, err := s.DeploymentTarget()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, registry, err := f.fullConfig(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := &deploy.DeployOptions{Config: cfg}
+
+	var deployer deploy.Deployer
+	switch target {
+	case deploy.TargetMultipass:
+		if opts.ProjectRoot, err = resolveProjectDir(f.projectPath); err != nil {
+			return nil, nil, err
+		}
+		opts.Multipass = s.MultipassOptions()
+		deployer = multipass.New()
+	case deploy.TargetTerragrunt:
+		if opts.ProjectRoot, err = resolveProjectDir(f.projectPath); err != nil {
+			return nil, nil, err
+		}
+		opts.Terragrunt = s.TerragruntOptions()
+		deployer = terragrunt.New(opts.ProjectRoot)
+	case deploy.TargetConfigOnly:
+		outputDir, generateYAML := ".", false
+		if s.Output != nil {
+			if s.Output.Dir != "" {
+				outputDir = s.Output.Dir
+			}
+			generateYAML = s.Output.CloudInit
+		}
+		opts.ProjectRoot = outputDir
+		deployer = configonly.New(outputDir, generateYAML, registry)
+	}
+
+	return deployer, opts, nil
+}
+
+// runResume continues the failed deployment recorded in the journal named
+// by --resume, with the options it ran with. Only --auto-approve and
+// --keep-on-failure can be changed.
+func runResume(cmd *cobra.Command, f *createFlags) error {
+	dir, err := journal.Dir()
+	if err != nil {
+		return fmt.Errorf("failed to get journal directory: %w", err)
+	}
+	s, err := journal.Find(dir, f.resume)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := journal.Checkpoint(s)
+	if err != nil {
+		return err
+	}
+
+	opts := checkpoint.Options()
+	changed := cmd.Flags().Changed
+
+	var deployer deploy.Deployer
+	switch checkpoint.Target {
+	case deploy.TargetMultipass:
+		setBool(changed("keep-on-failure"), &opts.Multipass.KeepOnFailure, f.keepOnFailure)
+		deployer = multipass.New()
+	case deploy.TargetTerragrunt:
+		setBool(changed("auto-approve"), &opts.Terragrunt.AutoApprove, f.autoApprove)
+		setBool(changed("keep-on-failure"), &opts.Terragrunt.KeepOnFailure, f.keepOnFailure)
+		opts.Terragrunt.Approve = promptApproval(cmd.InOrStdin(), cmd.OutOrStdout())
+		deployer = terragrunt.New(opts.ProjectRoot)
+	default:
+		return fmt.Errorf("%s deployments cannot be resumed", checkpoint.Target)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Resuming %s\n", s.ID)
+	return runDeployment(cmd, deployer, opts)
+}
+
+// runDeployment runs deployer, or resumes opts.Checkpoint with it, cleans
+// up on failure and records the deployment in a journal.
+func runDeployment(cmd *cobra.Command, deployer deploy.Deployer, opts *deploy.DeployOptions) error {
+	target := deployer.Target()
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Deploying to %s\n\n", deployer.Name())
+
+	ctx, stop := interruptContext(cmd.Context())
+	defer stop()
+
+	progress := printProgress(out)
+	j, err := journal.Start(target, opts.VMName(target))
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: not recording this deployment: %v\n", err)
+	} else {
+		progress = j.Progress(progress)
+	}
+
+	var result *deploy.DeployResult
+	if opts.Checkpoint != nil {
+		result, err = deploy.Resume(ctx, deployer, opts, progress)
+	} else {
+		result, err = deployer.Deploy(ctx, opts, progress)
+	}
+	if result == nil {
+		result = &deploy.DeployResult{Success: false, Target: target, Error: err}
+	}
+	if !result.Success {
+		if ctx.Err() != nil {
+			progress(deploy.NewErrorEvent("Deployment cancelled"))
+		}
+		if err := deploy.RunCleanup(ctx, deployer, opts, progress); err != nil {
+			fmt.Fprintf(out, "Warning: %v\n", err)
+		}
+		if !opts.KeepOnFailure(target) {
+			result.Checkpoint = nil // Nothing left to resume
+		}
+	}
+	printResult(out, result)
+
+	if !result.Success {
+		if result.Error == nil {
+			result.Error = err
+		}
+		if result.Error == nil {
+			result.Error = errors.New("unknown error")
+		}
+	}
+	if j != nil && j.Finish(result) == nil {
+		fmt.Fprintf(out, "\nLogs: ucli logs %s\n", j.ID)
+		if !result.Success && result.Checkpoint.Resumable() {
+			fmt.Fprintf(out, "Resume: ucli create --resume %s\n", j.ID)
+		}
+	}
+
+	if !result.Success {
+		return fmt.Errorf("deployment failed: %w", result.Error)
+	}
+	return nil
+}
+
+// interruptContext returns a context cancelled by the first Ctrl-C, so the
+// deployment is cancelled and cleaned up; a second one quits.
+func interruptContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// fullConfig validates the user section and packages of s and builds the
+// config used to generate cloud-init.
+func (f *createFlags) fullConfig(s *spec.Spec) (*config.FullConfig, *packages.Registry, error) {
+	if s.User.Username == "" {
+		return nil, nil, fmt.Errorf("username is required (--username or user.username in the spec)")
+	}
+	if s.User.Hostname == "" {
+		return nil, nil, fmt.Errorf("hostname is required (--hostname or user.hostname in the spec)")
+	}
+
+	registry, err := discoverPackages(f.projectPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range s.Packages {
+		if registry.Get(name) == nil {
+			return nil, nil, fmt.Errorf("unknown package %q (run 'ucli packages' to list packages)", name)
+		}
+	}
+
+	if len(s.Packages) == 0 {
+		s.Packages = registry.Defaults()
+	}
+
+	// Enable the dependencies of the selected packages too
+	res, err := registry.Resolve(s.Packages)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve packages: %w", err)
+	}
+	if len(res.Conflicts) > 0 {
+		msgs := make([]string, len(res.Conflicts))
+		for i, c := range res.Conflicts {
+			msgs[i] = c.String()
+		}
+		return nil, nil, fmt.Errorf("conflicting packages: %s", strings.Join(msgs, "; "))
+	}
+	s.Packages = res.Packages
+
+	cfg := s.ToFullConfig(registry.Names())
+	if cfg.PackageScripts, err = registry.ExtraScripts(s.Packages); err != nil {
+		return nil, nil, fmt.Errorf("failed to load package scripts: %w", err)
+	}
+	return cfg, registry, nil
+}
+
+// resolveSpec loads the spec file (if any) and applies explicitly set flags on top.
+func (f *createFlags) resolveSpec(cmd *cobra.Command) (*spec.Spec, error) {
+	s := &spec.Spec{}
+	if f.specPath != "" {
+		loaded, err := spec.Load(f.specPath)
+		if err != nil {
+			return nil, err
+		}
+		s = loaded
+	}
+
+	changed := cmd.Flags().Changed
+
+	if changed("target") {
+		s.Target = f.target
+	}
+	if changed("name") {
+		s.Name = f.name
+	}
+	if changed("username") {
+		s.User.Username = f.username
+	}
+	if changed("hostname") {
+		s.User.Hostname = f.hostname
+	}
+	if changed("display-name") {
+		s.User.DisplayName = f.displayName
+	}
+	if changed("git-name") {
+		s.Git.Name = f.gitName
+	}
+	if changed("git-email") {
+		s.Git.Email = f.gitEmail
+	}
+	if changed("github-user") {
+		s.GitHub.User = f.githubUser
+	}
+	if changed("github-pat") {
+		s.GitHub.PAT = f.githubPAT
+	}
+	if changed("tailscale-key") {
+		s.Tailscale.AuthKey = f.tailscaleKey
+	}
+	if changed("packages") {
+		s.Packages = f.packages
+	}
+	if err := f.applyFleetFlags(changed, s); err != nil {
+		return nil, err
+	}
+
+	// SSH keys from flags are appended to keys from the spec
+	s.SSHKeys = append(s.SSHKeys, f.sshKeys...)
+	for _, path := range f.sshKeyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSH key file: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				s.SSHKeys = append(s.SSHKeys, line)
+			}
+		}
+	}
+
+	// Target-specific flags apply to the block for the resolved target
+	switch deploy.DeploymentTarget(s.Target) {
+	case deploy.TargetMultipass:
+		if s.Multipass == nil {
+			s.Multipass = &spec.MultipassSpec{}
+		}
+		mp := s.Multipass
+		setInt(changed("cpus"), &mp.CPUs, f.cpus)
+		setInt(changed("memory"), &mp.MemoryMB, f.memoryMB)
+		setInt(changed("disk"), &mp.DiskGB, f.diskGB)
+		setString(changed("ubuntu-version"), &mp.UbuntuVersion, f.ubuntuVersion)
+		setBool(changed("keep-on-failure"), &mp.KeepOnFailure, f.keepOnFailure)
+	case deploy.TargetTerragrunt:
+		if s.Terragrunt == nil {
+			s.Terragrunt = &spec.TerragruntSpec{}
+		}
+		tg := s.Terragrunt
+		setInt(changed("cpus"), &tg.CPUs, f.cpus)
+		setInt(changed("memory"), &tg.MemoryMB, f.memoryMB)
+		setInt(changed("disk"), &tg.DiskGB, f.diskGB)
+		setString(changed("image"), &tg.UbuntuImage, f.image)
+		setString(changed("libvirt-uri"), &tg.LibvirtURI, f.libvirtURI)
+		setString(changed("storage-pool"), &tg.StoragePool, f.storagePool)
+		setString(changed("network"), &tg.NetworkName, f.network)
+		setBool(changed("autostart"), &tg.Autostart, f.autostart)
+		setBool(changed("apply"), &tg.Apply, f.apply)
+		setBool(changed("auto-approve"), &tg.AutoApprove, f.autoApprove)
+		setBool(changed("keep-on-failure"), &tg.KeepOnFailure, f.keepOnFailure)
+	case deploy.TargetConfigOnly:
+		if s.Output == nil {
+			s.Output = &spec.OutputSpec{}
+		}
+		setString(changed("output-dir"), &s.Output.Dir, f.outputDir)
+		setBool(changed("cloud-init"), &s.Output.CloudInit, f.cloudInit)
+	}
+
+	return s, nil
+}
+
+func setInt(changed bool, dst *int, v int) {
+	if changed {
+		*dst = v
+	}
+}
+
+func setString(changed bool, dst *string, v string) {
+	if changed {
+		*dst = v
+	}
+}
+
+func setBool(changed bool, dst *bool, v bool) {
+	if changed {
+		*dst = v
+	}
+}
+
+// resolveProjectDir returns the project path from the flag or the global config.
+func resolveProjectDir(flagPath string) (string, error) {
+	if flagPath != "" {
+		cfg := globalconfig.NewConfig()
+		if err := cfg.SetProjectPath(flagPath); err != nil {
+			return "", err
+		}
+		return cfg.ProjectPath, nil
+	}
+
+	cfg, err := globalconfig.Load()
+	if err != nil {
+		if errors.Is(err, globalconfig.ErrNotInitialized) {
+			return "", fmt.Errorf("ucli not initialized. Run 'ucli init <path>' or pass --project")
+		}
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	projectDir, err := cfg.ProjectDir()
+	if err != nil {
+		return "", fmt.Errorf("invalid project path: %w", err)
+	}
+	return projectDir, nil
+}
+
+// promptApproval returns an approval callback that asks on w and reads y/N from r.
+func promptApproval(r io.Reader, w io.Writer) deploy.ApprovalFunc {
+	reader := bufio.NewReader(r)
+	return func(_ context.Context, summary string) (bool, error) {
+		fmt.Fprintf(w, "\n%s\n", summary)
+		return askYesNo(reader, w, "Apply these changes?")
+	}
+}
+
+// askYesNo writes question to w and reads a y/N answer from reader.
+func askYesNo(reader *bufio.Reader, w io.Writer, question string) (bool, error) {
+	fmt.Fprintf(w, "%s [y/N]: ", question)
+	answer, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	fmt.Fprintln(w)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// printProgress returns a progress callback that writes events as plain lines.
+// Consecutive events with the same stage and message (streamed command
+// output) only print their detail.
+func printProgress(w io.Writer) deploy.ProgressCallback {
+	var last deploy.ProgressEvent
+	return func(e deploy.ProgressEvent) {
+		if !e.IsError && e.Detail != "" && e.Stage == last.Stage && e.Message == last.Message {
+			fmt.Fprintf(w, "       %s\n", e.Detail)
+			return
+		}
+		last = e
+
+		prefix := fmt.Sprintf("[%3d%%]", e.Percent)
+		if e.Percent < 0 {
+			prefix = "[ -- ]"
+		}
+		if e.IsError {
+			prefix = "[FAIL]"
+		}
+
+		fmt.Fprintf(w, "%s %s: %s\n", prefix, e.Stage.DisplayName(), e.Message)
+		if e.Command != "" {
+			fmt.Fprintf(w, "       $ %s\n", e.Command)
+		}
+		if e.Detail != "" {
+			for _, line := range strings.Split(strings.TrimRight(e.Detail, "\n"), "\n") {
+				fmt.Fprintf(w, "       %s\n", line)
+			}
+		}
+	}
+}
+
+// printResult writes the deployment outcome and outputs.
+func printResult(w io.Writer, result *deploy.DeployResult) {
+	fmt.Fprintln(w)
+	if result.Success {
+		fmt.Fprintln(w, "Deployment successful")
+	} else {
+		fmt.Fprintln(w, "Deployment failed")
+	}
+
+	if len(result.Outputs) > 0 {
+		keys := make([]string, 0, len(result.Outputs))
+		for k := range result.Outputs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintln(w, "\nOutputs:")
+		for _, k := range keys {
+			fmt.Fprintf(w, "  %s: %s\n", k, result.Outputs[k])
+		}
+	}
+
+	for _, log := range result.Logs {
+		fmt.Fprintf(w, "  %s\n", log)
+	}
+
+	if result.Duration > 0 {
+		fmt.Fprintf(w, "\nDuration: %s\n", result.Duration.Round(1e6))
+	}
+}
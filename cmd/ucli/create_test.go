@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/journal"
+)
+
+func TestCreateCmd_ConfigTarget(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	outDir := t.TempDir()
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"create",
+		"--target", "config",
+		"--output-dir", outDir,
+		"--cloud-init",
+		"--username", "tester",
+		"--hostname", "testbox",
+		"--ssh-key", "ssh-ed25519 AAAAC3 test@example",
+		"--packages", "lazygit,fzf",
+	})
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+
+	err := rootCmd.Execute()
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "Deployment successful")
+	assert.FileExists(t, filepath.Join(outDir, "config.env"))
+	assert.FileExists(t, filepath.Join(outDir, "cloud-init", "cloud-init.yaml"))
+
+	data, err := os.ReadFile(filepath.Join(outDir, "config.env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "tester")
+}
+
+func TestCreateCmd_PackageDependencies(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	outDir := t.TempDir()
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"create", "--target", "config", "--output-dir", outDir, "--cloud-init",
+		"--username", "u", "--hostname", "h", "--packages", "lazydocker",
+	})
+	rootCmd.SetOut(&bytes.Buffer{})
+	require.NoError(t, rootCmd.Execute())
+
+	data, err := os.ReadFile(filepath.Join(outDir, "cloud-init", "cloud-init.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "PACKAGE_LAZYDOCKER_ENABLED=false")
+	assert.NotContains(t, string(data), "PACKAGE_HOMEBREW_ENABLED=false")
+	assert.NotContains(t, string(data), "PACKAGE_DOCKER_ENABLED=false")
+	assert.Contains(t, string(data), "PACKAGE_LAZYGIT_ENABLED=false")
+}
+
+func TestCreateCmd_PackageConflicts(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	overlay := filepath.Join(configHome, "ucli", "packages")
+	require.NoError(t, os.MkdirAll(overlay, 0755))
+	script := "#!/bin/bash\nPACKAGE_NAME=\"podman\"\nCONFLICTS=\"docker\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(overlay, "podman.sh"), []byte(script), 0755))
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"create", "--target", "config", "--output-dir", t.TempDir(),
+		"--username", "u", "--hostname", "h", "--packages", "podman,lazydocker",
+	})
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "conflicting packages: ")
+	assert.Contains(t, err.Error(), "podman")
+}
+
+func TestCreateCmd_SpecWithFlagOverrides(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	outDir := filepath.Join(dir, "out")
+	specPath := filepath.Join(dir, "ucli.yaml")
+
+	spec := `version: 1
+target: config
+user:
+  username: fromspec
+  hostname: spechost
+output:
+  dir: ` + outDir + `
+`
+	require.NoError(t, os.WriteFile(specPath, []byte(spec), 0644))
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{"create", "--spec", specPath, "--username", "fromflag"})
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+
+	err := rootCmd.Execute()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(outDir, "config.env"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "fromflag")
+	assert.Contains(t, string(data), "spechost")
+	assert.NotContains(t, string(data), "fromspec")
+}
+
+func TestCreateCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "missing target",
+			args:    []string{"create", "--username", "u", "--hostname", "h"},
+			wantErr: "target is required",
+		},
+		{
+			name:    "unknown target",
+			args:    []string{"create", "--target", "aws", "--username", "u", "--hostname", "h"},
+			wantErr: "unknown target",
+		},
+		{
+			name:    "missing username",
+			args:    []string{"create", "--target", "config", "--hostname", "h"},
+			wantErr: "username is required",
+		},
+		{
+			name:    "unknown package",
+			args:    []string{"create", "--target", "config", "--username", "u", "--hostname", "h", "--packages", "nope"},
+			wantErr: "unknown package",
+		},
+		{
+			name:    "missing spec file",
+			args:    []string{"create", "--spec", "/nonexistent/ucli.yaml"},
+			wantErr: "failed to open spec file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootCmd := newRootCmd()
+			rootCmd.SetArgs(tt.args)
+			rootCmd.SetOut(&bytes.Buffer{})
+			rootCmd.SetErr(&bytes.Buffer{})
+
+			err := rootCmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestCreateCmd_Resume(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// A terragrunt deployment that failed after writing its config
+	machineDir := filepath.Join(t.TempDir(), "tf", "web")
+	require.NoError(t, os.MkdirAll(machineDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(machineDir, "terragrunt.hcl"), nil, 0644))
+
+	checkpoint := deploy.NewCheckpoint(deploy.TargetTerragrunt, &deploy.DeployOptions{
+		Terragrunt: deploy.TerragruntOptions{VMName: "web", KeepOnFailure: true},
+	})
+	checkpoint.Complete(deploy.StagePreparing, map[string]string{"vm_name": "web", "config_dir": machineDir})
+
+	dir, err := journal.Dir()
+	require.NoError(t, err)
+	j, err := journal.Create(dir, deploy.TargetTerragrunt, "web")
+	require.NoError(t, err)
+	require.NoError(t, j.Finish(&deploy.DeployResult{Error: errors.New("interrupted"), Checkpoint: checkpoint}))
+
+	out, err := runLogsCmd(t)
+	require.NoError(t, err)
+	assert.Contains(t, out, "failed (resumable)")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{"create", "--resume", "web"})
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	require.NoError(t, rootCmd.Execute())
+	assert.Contains(t, buf.String(), "Resuming "+j.ID)
+	assert.Contains(t, buf.String(), "Reusing config directory")
+	assert.Contains(t, buf.String(), "Deployment successful")
+
+	// The newest deployment for web now succeeded
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{"create", "--resume", "web"})
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+	assert.ErrorContains(t, rootCmd.Execute(), "nothing to resume")
+
+	// Options come from the checkpoint, not a spec
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{"create", "--resume", "web", "--spec", "ucli.yaml"})
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+	assert.ErrorContains(t, rootCmd.Execute(), "none of the others can be")
+}
+
+func TestPromptApproval(t *testing.T) {
+	var out bytes.Buffer
+	approve := promptApproval(strings.NewReader("y\nno\n"), &out)
+
+	ok, err := approve(context.Background(), "Plan: 1 to add, 0 to change, 0 to destroy.")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, out.String(), "Plan: 1 to add")
+	assert.Contains(t, out.String(), "Apply these changes? [y/N]")
+
+	ok, err = approve(context.Background(), "")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// EOF declines
+	ok, err = approve(context.Background(), "")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestPrintProgress_StreamedOutput(t *testing.T) {
+	var out bytes.Buffer
+	progress := printProgress(&out)
+
+	progress(deploy.NewProgressEventWithCommand(deploy.StageApplying, "Applying changes...", "terragrunt apply", 70))
+	progress(deploy.NewProgressEventWithDetail(deploy.StageApplying, "Applying changes...", "libvirt_volume.disk: Creating...", 70))
+	progress(deploy.NewProgressEventWithDetail(deploy.StageApplying, "Applying changes...", "Apply complete!", 70))
+
+	assert.Equal(t, 1, strings.Count(out.String(), "Applying changes..."))
+	assert.Contains(t, out.String(), "       libvirt_volume.disk: Creating...\n       Apply complete!\n")
+}
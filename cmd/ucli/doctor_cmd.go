@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/doctor"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+)
+
+// doctorFlags holds the flag values for the doctor command.
+type doctorFlags struct {
+	groups  []string
+	pool    string
+	network string
+	output  string
+	fix     bool
+	yes     bool
+}
+
+// doctorReport is the JSON form of the doctor results.
+type doctorReport struct {
+	Groups  []doctor.CheckGroup `json:"groups"`
+	Summary doctor.Summary      `json:"summary"`
+	Healthy bool                `json:"healthy"`
+}
+
+func newDoctorCmd() *cobra.Command {
+	f := &doctorFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that the tools ucli depends on are installed",
+		Long: `Check the tools ucli depends on, the same checks as the Doctor tab.
+
+Exits non-zero if a check in a required group is missing or failed, so
+scripts can gate on a healthy host. The Terraform/libvirt, Multipass and
+ISO/USB groups are required; groups named with --group are always required.
+
+The Terraform/libvirt group checks the OpenTofu and Terragrunt versions, the
+libvirt provider cache, /dev/kvm access, group membership, the
+qemu:///system connection and that the storage pool and network VMs use
+exist and are active.
+
+With --fix, the fix command for each missing tool runs after you confirm it,
+or without asking with --yes. Checks run again afterwards.
+
+Examples:
+  ucli doctor
+  ucli doctor --group terraform --group iso
+  ucli doctor --group terraform --pool vms --network lab
+  ucli doctor --output json
+  ucli doctor --group iso --fix --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			checker := doctor.NewChecker()
+			checker.SetStoragePool(f.pool)
+			checker.SetNetwork(f.network)
+			if path := defaultImagePath(); path != "" {
+				checker.SetImagePath(path)
+			}
+			return runDoctor(cmd, f, checker, doctor.NewFixer())
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&f.groups, "group", nil, "check only these groups: terraform, multipass, iso, terminal")
+	flags.StringVar(&f.pool, "pool", doctor.DefaultStoragePool, "libvirt storage pool VMs use")
+	flags.StringVar(&f.network, "network", doctor.DefaultNetwork, "libvirt network VMs use")
+	flags.StringVar(&f.output, "output", outputText, "output format: text or json")
+	flags.BoolVar(&f.fix, "fix", false, "run the fix commands for missing tools")
+	flags.BoolVarP(&f.yes, "yes", "y", false, "run fixes without asking to confirm")
+
+	return cmd
+}
+
+// runDoctor runs the checks, applies fixes if asked and reports the results.
+func runDoctor(cmd *cobra.Command, f *doctorFlags, checker *doctor.Checker, fixer *doctor.Fixer) error {
+	if f.output != outputText && f.output != outputJSON {
+		return fmt.Errorf("unsupported output format %q (want text or json)", f.output)
+	}
+
+	groups, err := checkGroups(checker, f.groups)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if f.fix {
+		// Keep stdout parseable when writing JSON
+		log := out
+		if f.output == outputJSON {
+			log = cmd.ErrOrStderr()
+		}
+		ran, err := runFixes(bufio.NewReader(cmd.InOrStdin()), log, fixer, groups, f.yes)
+		if err != nil {
+			return err
+		}
+		if ran > 0 {
+			if groups, err = checkGroups(checker, f.groups); err != nil {
+				return err
+			}
+		}
+	}
+
+	var required []doctor.CheckGroup
+	for _, g := range groups {
+		if g.Required {
+			required = append(required, g)
+		}
+	}
+	healthy := !checker.HasIssues(required)
+
+	summary := checker.GetSummary(groups)
+	if f.output == outputJSON {
+		if err := writeJSON(out, doctorReport{Groups: groups, Summary: summary, Healthy: healthy}); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(out, groups, summary)
+	}
+
+	if !healthy {
+		s := checker.GetSummary(required)
+		return fmt.Errorf("%d required check(s) missing or failed", s.Missing+s.Errors)
+	}
+	return nil
+}
+
+// checkGroups runs the checks for the named groups, or for every group on
+// this platform when none are named. Named groups are marked required.
+func checkGroups(checker *doctor.Checker, ids []string) ([]doctor.CheckGroup, error) {
+	if len(ids) == 0 {
+		return checker.CheckAll(), nil
+	}
+
+	groups := make([]doctor.CheckGroup, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := doctor.GetGroupDefinition(id); !ok {
+			return nil, fmt.Errorf("unknown group %q (want %s)", id, strings.Join(doctor.GetAllGroupIDs(), ", "))
+		}
+		group := checker.CheckGroup(id)
+		group.Required = true
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// runFixes runs the fix command for each missing or failed check, asking
+// first unless yes is set. It returns how many fixes ran.
+func runFixes(reader *bufio.Reader, w io.Writer, fixer *doctor.Fixer, groups []doctor.CheckGroup, yes bool) (int, error) {
+	ran := 0
+	for _, g := range groups {
+		for _, check := range g.Checks {
+			if !check.HasIssue() || check.FixCommand == nil {
+				continue
+			}
+
+			fix := check.FixCommand
+			fmt.Fprintf(w, "%s is %s. %s:\n  %s\n", check.Name, check.Status, fix.Description, fix.Command)
+			if !yes {
+				ok, err := askYesNo(reader, w, "Run it?")
+				if err != nil {
+					return ran, err
+				}
+				if !ok {
+					continue
+				}
+			}
+
+			ran++
+			if err := fixer.RunFix(fix); err != nil {
+				fmt.Fprintf(w, "✗ %s: %v\n\n", check.Name, err)
+				continue
+			}
+			fmt.Fprintf(w, "✓ %s fixed\n\n", check.Name)
+		}
+	}
+	return ran, nil
+}
+
+// printDoctorReport writes the check results grouped as in the Doctor tab.
+func printDoctorReport(w io.Writer, groups []doctor.CheckGroup, summary doctor.Summary) {
+	for _, g := range groups {
+		name := g.Name
+		if !g.Required {
+			name += " (optional)"
+		}
+		fmt.Fprintln(w, name)
+
+		for _, check := range g.Checks {
+			fmt.Fprintf(w, "  %s %-20s %s\n", statusIcon(check.Status), check.Name, check.Message)
+			if check.HasIssue() && check.FixCommand != nil {
+				fmt.Fprintf(w, "      fix: %s\n", check.FixCommand.Command)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%d checks: %d ok, %d missing, %d warnings, %d errors\n",
+		summary.Total, summary.OK, summary.Missing, summary.Warnings, summary.Errors)
+}
+
+// statusIcon returns the Doctor tab's icon for a check status.
+func statusIcon(s doctor.CheckStatus) string {
+	switch s {
+	case doctor.StatusOK:
+		return "✓"
+	case doctor.StatusMissing:
+		return "✗"
+	case doctor.StatusWarning:
+		return "⚠"
+	default:
+		return "!"
+	}
+}
+
+// defaultImagePath returns the path of the default registered cloud image,
+// or "" to check the checker's default path.
+func defaultImagePath() string {
+	store, err := settings.NewStore()
+	if err != nil {
+		return ""
+	}
+	s, err := store.Load()
+	if err != nil {
+		return ""
+	}
+	if img := s.DefaultCloudImage(); img != nil {
+		return img.Path
+	}
+	return ""
+}
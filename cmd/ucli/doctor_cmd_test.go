@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/doctor"
+)
+
+// fakeHost is a doctor.CommandExecutor where every tool is installed except
+// the missing ones. Fix commands install the tools they name.
+type fakeHost struct {
+	missing map[string]bool
+	fixes   []string
+}
+
+func (h *fakeHost) LookPath(file string) (string, error) {
+	if h.missing[file] {
+		return "", errors.New("not found")
+	}
+	return "/usr/bin/" + file, nil
+}
+
+func (h *fakeHost) Run(name string, args ...string) (string, error) {
+	switch strings.Join(append([]string{name}, args...), " ") {
+	case "id -nG":
+		return "me libvirt kvm", nil
+	case "virsh -c qemu:///system pool-info default":
+		return "Name: default\nState: running\nAutostart: yes\n", nil
+	case "virsh -c qemu:///system net-info default":
+		return "Name: default\nActive: yes\nAutostart: yes\n", nil
+	}
+	return "active 1.0.0", nil
+}
+
+func (h *fakeHost) CombinedOutput(name string, args ...string) ([]byte, error) {
+	cmd := args[len(args)-1]
+	h.fixes = append(h.fixes, cmd)
+	for tool := range h.missing {
+		if strings.Contains(cmd, tool) {
+			delete(h.missing, tool)
+		}
+	}
+	return nil, nil
+}
+
+func (h *fakeHost) FileExists(string) bool { return true }
+
+func runDoctorCmd(t *testing.T, host *fakeHost, stdin string, args ...string) (string, error) {
+	t.Helper()
+	f := &doctorFlags{}
+	cmd := &cobra.Command{
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDoctor(cmd, f, doctor.NewCheckerWithExecutor(host), doctor.NewFixerWithExecutor(host))
+		},
+	}
+	cmd.Flags().StringSliceVar(&f.groups, "group", nil, "")
+	cmd.Flags().StringVar(&f.output, "output", outputText, "")
+	cmd.Flags().BoolVar(&f.fix, "fix", false, "")
+	cmd.Flags().BoolVar(&f.yes, "yes", false, "")
+	cmd.SetArgs(args)
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func TestDoctorCmd(t *testing.T) {
+	t.Setenv("TERM_PROGRAM", "")
+	t.Setenv("TERM", "xterm")
+
+	// Ghostty is optional, so its absence does not fail
+	out, err := runDoctorCmd(t, &fakeHost{missing: map[string]bool{"ghostty": true}}, "")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Multipass\n  ✓ Multipass")
+	assert.Contains(t, out, "Terminal (optional)\n  ✗ Ghostty")
+	assert.Contains(t, out, "1 missing")
+
+	// A missing tool in a required group fails with its fix listed
+	out, err = runDoctorCmd(t, &fakeHost{missing: map[string]bool{"xorriso": true}}, "", "--group", "iso")
+	assert.EqualError(t, err, "1 required check(s) missing or failed")
+	assert.Contains(t, out, "ISO/USB\n  ✗ xorriso")
+	assert.Contains(t, out, "      fix: ")
+	assert.NotContains(t, out, "Multipass")
+
+	// Named groups are required
+	_, err = runDoctorCmd(t, &fakeHost{missing: map[string]bool{"ghostty": true}}, "", "--group", "terminal,iso")
+	assert.EqualError(t, err, "1 required check(s) missing or failed")
+
+	_, err = runDoctorCmd(t, &fakeHost{}, "", "--group", "docker")
+	assert.ErrorContains(t, err, `unknown group "docker" (want terraform, multipass, iso, terminal)`)
+
+	_, err = runDoctorCmd(t, &fakeHost{}, "", "--output", "yaml")
+	assert.ErrorContains(t, err, `unsupported output format "yaml"`)
+}
+
+func TestDoctorCmd_JSON(t *testing.T) {
+	out, err := runDoctorCmd(t, &fakeHost{missing: map[string]bool{"multipass": true}}, "", "--group", "multipass,iso", "--output", "json")
+	assert.Error(t, err)
+
+	var report doctorReport
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.False(t, report.Healthy)
+	assert.Equal(t, doctor.Summary{Total: 2, OK: 1, Missing: 1}, report.Summary)
+	require.Len(t, report.Groups, 2)
+	assert.True(t, report.Groups[0].Required)
+	assert.Contains(t, out, `"status": "missing"`)
+	assert.Contains(t, out, `"status": "ok"`)
+}
+
+func TestDoctorCmd_Fix(t *testing.T) {
+	// Declining leaves the tool missing
+	host := &fakeHost{missing: map[string]bool{"xorriso": true}}
+	out, err := runDoctorCmd(t, host, "n\n", "--group", "iso", "--fix")
+	assert.Error(t, err)
+	assert.Contains(t, out, "xorriso is missing.")
+	assert.Contains(t, out, "Run it? [y/N]:")
+	assert.Empty(t, host.fixes)
+
+	// Confirming runs the fix and checks again
+	out, err = runDoctorCmd(t, host, "y\n", "--group", "iso", "--fix")
+	require.NoError(t, err)
+	assert.Contains(t, out, "✓ xorriso fixed")
+	assert.Contains(t, out, "ISO/USB\n  ✓ xorriso")
+	require.Len(t, host.fixes, 1)
+	assert.Contains(t, host.fixes[0], "install")
+
+	// --yes skips the prompt
+	host = &fakeHost{missing: map[string]bool{"xorriso": true}}
+	out, err = runDoctorCmd(t, host, "", "--group", "iso", "--fix", "--yes")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "Run it?")
+	assert.Len(t, host.fixes, 1)
+}
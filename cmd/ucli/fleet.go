@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/multipass"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/spec"
+)
+
+// applyFleetFlags applies the explicitly set fleet flags to the spec's
+// fleet section. --count and --names replace each other's spec values.
+func (f *createFlags) applyFleetFlags(changed func(string) bool, s *spec.Spec) error {
+	if !changed("count") && !changed("names") && !changed("parallel") && !changed("on-failure") {
+		return nil
+	}
+	if s.Fleet == nil {
+		s.Fleet = &spec.FleetSpec{}
+	}
+
+	if changed("count") {
+		s.Fleet.Count = f.count
+		s.Fleet.Machines = nil
+	}
+	if changed("names") {
+		s.Fleet.Count = 0
+		s.Fleet.Machines = nil
+		for _, name := range f.names {
+			name, hostname, _ := strings.Cut(strings.TrimSpace(name), ":")
+			s.Fleet.Machines = append(s.Fleet.Machines, spec.MachineSpec{Name: name, Hostname: hostname})
+		}
+	}
+	setInt(changed("parallel"), &s.Fleet.Parallel, f.parallel)
+	setString(changed("on-failure"), &s.Fleet.OnFailure, f.onFailure)
+
+	if s.Fleet.Count == 0 && len(s.Fleet.Machines) == 0 {
+		return fmt.Errorf("--parallel and --on-failure need --count or --names")
+	}
+	return nil
+}
+
+// runFleet deploys every machine of the spec's fleet, prints their progress
+// prefixed with the machine name and a table of the results.
+func runFleet(cmd *cobra.Command, f *createFlags, s *spec.Spec) error {
+	specs, err := s.Expand()
+	if err != nil {
+		return err
+	}
+	fleetOpts, err := s.FleetOptions()
+	if err != nil {
+		return err
+	}
+	fleetOpts.Journal = true
+
+	// Machines write progress and ask for approval concurrently
+	out := &lockedWriter{w: cmd.OutOrStdout()}
+	approve := fleetApproval(cmd.InOrStdin(), out)
+
+	machines := make([]fleet.Machine, 0, len(specs))
+	for _, ms := range specs {
+		deployer, opts, err := f.newDeployment(ms)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ms.Name, err)
+		}
+		switch deployer.Target() {
+		case deploy.TargetMultipass:
+			opts.CloudInitPath = multipass.CloudInitPath(opts.ProjectRoot, ms.Name)
+		case deploy.TargetTerragrunt:
+			opts.Terragrunt.Approve = approve(ms.Name)
+		}
+		machines = append(machines, fleet.Machine{Name: ms.Name, Deployer: deployer, Options: opts})
+	}
+	// Terragrunt machines share the module's base volume in the pool
+	fleetOpts.SerialFirst = machines[0].Deployer.Target() == deploy.TargetTerragrunt
+
+	fmt.Fprintf(out, "Deploying %d machines to %s (%d at a time, on failure: %s)\n\n",
+		len(machines), machines[0].Deployer.Name(), min(fleetOpts.Parallel, len(machines)), fleetOpts.Policy)
+
+	ctx, stop := interruptContext(cmd.Context())
+	defer stop()
+
+	results := fleet.Run(ctx, machines, fleetOpts, fleetProgress(out, specs))
+	printFleetResults(out, results)
+
+	if err := results.Err(); err != nil {
+		return fmt.Errorf("fleet deployment failed: %w", err)
+	}
+	return nil
+}
+
+// fleetProgress returns a progress callback that prints each machine's
+// events as printProgress does, prefixed with the machine name.
+func fleetProgress(w io.Writer, specs []*spec.Spec) fleet.ProgressCallback {
+	width := 0
+	for _, s := range specs {
+		width = max(width, len(s.Name))
+	}
+
+	printers := make(map[string]deploy.ProgressCallback)
+	return func(e fleet.Event) {
+		p, ok := printers[e.Machine]
+		if !ok {
+			p = printProgress(&prefixWriter{w: w, prefix: fmt.Sprintf("%-*s | ", width, e.Machine)})
+			printers[e.Machine] = p
+		}
+		p(e.ProgressEvent)
+	}
+}
+
+// fleetApproval returns a function that creates approval callbacks for the
+// machines of a fleet. They ask one at a time and share one reader of r.
+func fleetApproval(r io.Reader, w io.Writer) func(name string) deploy.ApprovalFunc {
+	reader := bufio.NewReader(r)
+	var mu sync.Mutex
+	return func(name string) deploy.ApprovalFunc {
+		return func(_ context.Context, summary string) (bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Fprintf(w, "\n%s:\n%s\n", name, summary)
+			return askYesNo(reader, w, fmt.Sprintf("Apply these changes to %s?", name))
+		}
+	}
+}
+
+// printFleetResults writes a table of the machines' outcomes, then the
+// errors of those that failed.
+func printFleetResults(w io.Writer, results fleet.Results) {
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tADDRESS\tDURATION\tJOURNAL")
+	for _, r := range results {
+		address, duration, id := r.Address(), "-", r.JournalID
+		if address == "" {
+			address = "-"
+		}
+		if r.Result != nil && r.Result.Duration > 0 {
+			duration = r.Result.Duration.Round(1e6).String()
+		}
+		if id == "" {
+			id = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Name, r.Status, address, duration, id)
+	}
+	_ = tw.Flush()
+
+	var failed []fleet.Result
+	for _, r := range results {
+		if r.Error() != "" && r.Status != fleet.StatusSucceeded {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintln(w, "\nErrors:")
+		for _, r := range failed {
+			fmt.Fprintf(w, "  %s: %s\n", r.Name, r.Error())
+			if r.JournalID != "" && r.Result.Checkpoint.Resumable() {
+				fmt.Fprintf(w, "    Resume: ucli create --resume %s\n", r.JournalID)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d machines: %s\n", len(results), results.Summary())
+}
+
+// prefixWriter writes each line to w with prefix in front.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if len(line) > 0 {
+			buf.WriteString(p.prefix)
+			buf.Write(line)
+		}
+	}
+	if _, err := p.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// lockedWriter serializes writes to w.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(b)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTerragruntProject creates a project directory with the terragrunt
+// module the terragrunt target needs.
+func newTerragruntProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "terragrunt", "modules", "libvirt-vm"), 0755))
+	return dir
+}
+
+func TestCreateCmd_Fleet(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	project := newTerragruntProject(t)
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"create", "--target", "terragrunt", "--project", project,
+		"--name", "web", "--count", "3", "--parallel", "2",
+		"--username", "u", "--hostname", "web", "--packages", "lazygit",
+	})
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	require.NoError(t, rootCmd.Execute())
+
+	out := buf.String()
+	assert.Contains(t, out, "Deploying 3 machines to Terragrunt")
+	assert.Contains(t, out, "web-2 | [")
+	assert.Regexp(t, `NAME\s+STATUS\s+ADDRESS\s+DURATION\s+JOURNAL`, out)
+	assert.Regexp(t, `web-3\s+succeeded`, out)
+	assert.Contains(t, out, "3 machines: 3 succeeded")
+
+	for _, name := range []string{"web-1", "web-2", "web-3"} {
+		data, err := os.ReadFile(filepath.Join(project, "tf", name, "cloud-init.yaml"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "hostname: "+name)
+	}
+
+	out, err := runLogsCmd(t)
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(out, "succeeded"))
+}
+
+func TestCreateCmd_FleetFailure(t *testing.T) {
+	tests := []struct {
+		policy string
+		status []string
+		left   []string
+	}{
+		{"continue", []string{`db\s+succeeded`, `web\s+failed`, `cache\s+succeeded`}, []string{"db", "cache"}},
+		{"abort", []string{`db\s+succeeded`, `web\s+failed`, `cache\s+skipped`}, []string{"db"}},
+		{"cleanup", []string{`db\s+removed`, `web\s+failed`, `cache\s+skipped`}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			project := newTerragruntProject(t)
+
+			// web already has a config, so its deployment fails
+			existing := filepath.Join(project, "tf", "web")
+			require.NoError(t, os.MkdirAll(existing, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(existing, "terragrunt.hcl"), nil, 0644))
+
+			rootCmd := newRootCmd()
+			rootCmd.SetArgs([]string{
+				"create", "--target", "terragrunt", "--project", project,
+				"--names", "db,web:web.lan,cache", "--parallel", "1", "--on-failure", tt.policy,
+				"--username", "u", "--packages", "lazygit",
+			})
+			var buf bytes.Buffer
+			rootCmd.SetOut(&buf)
+			rootCmd.SetErr(&bytes.Buffer{})
+
+			err := rootCmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "fleet deployment failed")
+
+			out := buf.String()
+			for _, status := range tt.status {
+				assert.Regexp(t, status, out)
+			}
+			assert.Contains(t, out, "web: config 'web' already exists")
+			assert.FileExists(t, filepath.Join(existing, "terragrunt.hcl"))
+
+			for _, name := range []string{"db", "cache"} {
+				_, err := os.Stat(filepath.Join(project, "tf", name))
+				if slices.Contains(tt.left, name) {
+					assert.NoError(t, err, name)
+				} else {
+					assert.True(t, os.IsNotExist(err), name)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateCmd_FleetErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"config target", []string{"--target", "config", "--name", "a", "--count", "2"}, "not supported for the config target"},
+		{"count without name", []string{"--target", "terragrunt", "--count", "2"}, "name is required with count"},
+		{"parallel alone", []string{"--target", "terragrunt", "--parallel", "2"}, "need --count or --names"},
+		{"policy", []string{"--target", "terragrunt", "--names", "a,b", "--on-failure", "retry"}, "unknown failure policy"},
+		{"count and names", []string{"--target", "terragrunt", "--names", "a", "--count", "2"}, "none of the others can be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootCmd := newRootCmd()
+			rootCmd.SetArgs(append([]string{"create", "--username", "u", "--hostname", "h"}, tt.args...))
+			rootCmd.SetOut(&bytes.Buffer{})
+			rootCmd.SetErr(&bytes.Buffer{})
+
+			err := rootCmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestFleetApproval(t *testing.T) {
+	var out bytes.Buffer
+	approve := fleetApproval(strings.NewReader("y\nn\n"), &out)
+
+	ok, err := approve("web")(context.Background(), "Plan: 1 to add")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, out.String(), "Apply these changes to web? [y/N]")
+
+	// Both machines read answers from the same input
+	ok, err = approve("db")(context.Background(), "Plan: 1 to add")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{w: &out, prefix: "web | "}
+
+	_, err := w.Write([]byte("one\ntwo\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "web | one\nweb | two\n", out.String())
+}
@@ -0,0 +1,591 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/images"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+)
+
+// Output formats for commands that support --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// imagesFlags holds the flags shared by the images subcommands.
+type imagesFlags struct {
+	output string
+}
+
+func newImagesCmd() *cobra.Command {
+	f := &imagesFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "images",
+		Short: "Manage Ubuntu cloud images",
+		Long: `Manage the Ubuntu cloud images used for new VMs.
+
+Images are downloaded from cloud-images.ubuntu.com and verified against the
+release's SHA256SUMS. Its signature is checked with gpgv against the Ubuntu
+cloud image key built into ucli; when that is not possible, pull says so and
+images are listed as verified by checksum only. Use --output json for
+machine-readable output.
+
+Examples:
+  ucli images list
+  ucli images pull 24.04 --arch arm64
+  ucli images add ./noble-server-cloudimg-amd64.img
+  ucli images set-default ubuntu-24.04-amd64
+  ucli images gc --dry-run`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if f.output != outputText && f.output != outputJSON {
+				return fmt.Errorf("unsupported output format %q (want text or json)", f.output)
+			}
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&f.output, "output", outputText, "output format: text or json")
+
+	cmd.AddCommand(
+		newImagesListCmd(f),
+		newImagesPullCmd(f),
+		newImagesAddCmd(f),
+		newImagesVerifyCmd(f),
+		newImagesRmCmd(f),
+		newImagesSetDefaultCmd(f),
+		newImagesUsageCmd(f),
+		newImagesGCCmd(f),
+	)
+	return cmd
+}
+
+// imageJSON is the JSON form of a registered image.
+type imageJSON struct {
+	settings.CloudImage
+	Default bool `json:"default"`
+}
+
+func newImagesListCmd(f *imagesFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List registered cloud images",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := settings.NewStore()
+			if err != nil {
+				return err
+			}
+			s, err := store.Load()
+			if err != nil {
+				return err
+			}
+
+			var defaultID string
+			if def := s.DefaultCloudImage(); def != nil {
+				defaultID = def.ID
+			}
+
+			out := cmd.OutOrStdout()
+			if f.output == outputJSON {
+				list := make([]imageJSON, 0, len(s.CloudImages))
+				for _, img := range s.CloudImages {
+					list = append(list, imageJSON{CloudImage: img, Default: img.ID == defaultID})
+				}
+				return writeJSON(out, list)
+			}
+
+			if len(s.CloudImages) == 0 {
+				fmt.Fprintln(out, "No cloud images registered. Run 'ucli images pull <version>' to download one.")
+				return nil
+			}
+
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tNAME\tSIZE\tVERIFIED\tPATH")
+			for _, img := range s.CloudImages {
+				id := img.ID
+				if id == defaultID {
+					id += " *"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", id, img.Name, formatBytes(img.Size), verification(&img), img.Path)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintln(out, "\n* default image")
+			return nil
+		},
+	}
+}
+
+func newImagesPullCmd(f *imagesFlags) *cobra.Command {
+	var arch, serial string
+
+	cmd := &cobra.Command{
+		Use:   "pull <version>",
+		Short: "Download and verify a cloud image",
+		Long: `Download an Ubuntu cloud image by version or codename (e.g. 24.04 or noble),
+verify it and register it.
+
+Releases and builds are read from the cloud-images.ubuntu.com simplestreams
+index (cached for a day). Use --serial to pin a specific build.
+
+An interrupted pull keeps the partial download. Running the same pull again,
+or starting the TUI, continues it from where it stopped.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := settings.NewStore()
+			if err != nil {
+				return err
+			}
+
+			registry, err := images.LoadRegistry(cmd.Context(), images.DefaultStreamsClient())
+			if err != nil {
+				if serial != "" {
+					return err
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+			}
+			rel := registry.FindRelease(args[0])
+			if rel == nil {
+				return fmt.Errorf("unknown release %q", args[0])
+			}
+
+			info := registry.GetCloudImageInfo(rel.Version, arch)
+			if serial != "" {
+				info = registry.GetCloudImageBuild(rel.Version, arch, serial)
+			}
+			if info == nil {
+				if serial != "" {
+					return fmt.Errorf("no %s build %s for %s", arch, serial, rel.Name)
+				}
+				return fmt.Errorf("no %s image for %s", arch, rel.Name)
+			}
+
+			checksums := images.DefaultChecksumSource()
+			downloader := images.NewDownloaderWithRegistry(store, registry, checksums)
+
+			var onProgress images.ProgressCallback
+			if f.output == outputText {
+				label := arch
+				if info.Serial != "" {
+					label += ", " + info.Serial
+				}
+				verb := "Pulling"
+				if partial := downloader.PartialDownload(*info); partial != nil {
+					verb = "Resuming"
+					label += ", " + formatBytes(partial.Downloaded) + " already downloaded"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s (%s)\n", verb, rel.Name, label)
+				bar := newProgressBar(cmd.ErrOrStderr())
+				defer bar.finish()
+				onProgress = bar.update
+			}
+
+			warnUnsigned(cmd.ErrOrStderr(), checksums)
+
+			// Stop cleanly on Ctrl-C so the partial download can be resumed
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			img, err := downloader.DownloadCloudImageInfo(ctx, *info, onProgress)
+			if err != nil {
+				if ctx.Err() != nil {
+					return fmt.Errorf("pull interrupted; run it again to resume the download")
+				}
+				return err
+			}
+			return printImage(cmd, f, img, "Pulled")
+		},
+	}
+
+	cmd.Flags().StringVar(&arch, "arch", images.GetDefaultArch(), "image architecture (amd64 or arm64)")
+	cmd.Flags().StringVar(&serial, "serial", "", "build serial to pull, e.g. 20240423 (default: latest)")
+	return cmd
+}
+
+func newImagesAddCmd(f *imagesFlags) *cobra.Command {
+	var release, arch string
+
+	cmd := &cobra.Command{
+		Use:   "add <path>",
+		Short: "Register an existing cloud image file",
+		Long: `Register an image file that is already on disk.
+
+The release and architecture are detected from standard file names such as
+noble-server-cloudimg-amd64.img; use --release and --arch otherwise.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := settings.NewStore()
+			if err != nil {
+				return err
+			}
+			manager := images.NewManager(store)
+
+			version, imageArch, _ := manager.Registry().ParseImageFilename(filepath.Base(args[0]))
+			if release != "" {
+				version = release
+				if rel := manager.Registry().FindRelease(release); rel != nil {
+					version = rel.Version
+				}
+			}
+			if arch != "" {
+				imageArch = arch
+			}
+			if version == "" || imageArch == "" {
+				return fmt.Errorf("cannot detect the release of %s; pass --release and --arch", filepath.Base(args[0]))
+			}
+
+			img, err := manager.AddExistingImage(args[0], version, imageArch)
+			if err != nil {
+				return err
+			}
+			return printImage(cmd, f, img, "Added")
+		},
+	}
+
+	cmd.Flags().StringVar(&release, "release", "", "Ubuntu version or codename (default: detect from file name)")
+	cmd.Flags().StringVar(&arch, "arch", "", "image architecture (default: detect from file name)")
+	return cmd
+}
+
+func newImagesVerifyCmd(f *imagesFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify <id>",
+		Short: "Verify an image's checksum",
+		Long: `Verify an image against its recorded checksum, or against the release's
+SHA256SUMS if it was downloaded from a known URL.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := settings.NewStore()
+			if err != nil {
+				return err
+			}
+
+			verified, err := images.NewManager(store).VerifyImage(args[0])
+			if err != nil {
+				return err
+			}
+
+			if f.output == outputJSON {
+				if err := writeJSON(cmd.OutOrStdout(), map[string]any{"id": args[0], "verified": verified}); err != nil {
+					return err
+				}
+			} else if verified {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: checksum ok\n", args[0])
+			}
+
+			if !verified {
+				return fmt.Errorf("%s: checksum mismatch", args[0])
+			}
+			return nil
+		},
+	}
+}
+
+func newImagesRmCmd(f *imagesFlags) *cobra.Command {
+	var deleteFile bool
+
+	cmd := &cobra.Command{
+		Use:   "rm <id>",
+		Short: "Unregister a cloud image",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := settings.NewStore()
+			if err != nil {
+				return err
+			}
+
+			if err := images.NewManager(store).RemoveImage(args[0], deleteFile); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if f.output == outputJSON {
+				return writeJSON(out, map[string]any{"id": args[0], "removed": true, "file_deleted": deleteFile})
+			}
+			if deleteFile {
+				fmt.Fprintf(out, "Removed %s and deleted its file\n", args[0])
+			} else {
+				fmt.Fprintf(out, "Removed %s (file kept on disk)\n", args[0])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&deleteFile, "delete-file", false, "also delete the image file")
+	return cmd
+}
+
+func newImagesSetDefaultCmd(f *imagesFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-default <id>",
+		Short: "Set the image preselected for new VMs",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := settings.NewStore()
+			if err != nil {
+				return err
+			}
+
+			if err := images.NewManager(store).SetDefaultImage(args[0]); err != nil {
+				return err
+			}
+
+			if f.output == outputJSON {
+				return writeJSON(cmd.OutOrStdout(), map[string]any{"id": args[0], "default": true})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Default image set to %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newImagesUsageCmd(f *imagesFlags) *cobra.Command {
+	var projectPath string
+
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Show disk usage and what uses each image",
+		Long: `Show the size of each registered image and the machines (tf/*/terragrunt.hcl)
+and saved VM configs that use it, plus orphaned partial downloads.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := settings.NewStore()
+			if err != nil {
+				return err
+			}
+
+			report, err := images.NewManager(store).Usage(imagesProjectDir(projectPath))
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if f.output == outputJSON {
+				return writeJSON(out, report)
+			}
+
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tSIZE\tUSED BY")
+			for _, u := range report.Images {
+				size := formatBytes(u.Size)
+				if u.Missing {
+					size = "missing"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", u.Image.ID, size, formatReferences(u.References))
+			}
+			for _, t := range report.Orphans {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", t.Path, formatBytes(t.Size), "- (orphaned download)")
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "\nTotal: %s\n", formatBytes(report.TotalSize()))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&projectPath, "project", "", "project directory (default: from ucli init)")
+	return cmd
+}
+
+func newImagesGCCmd(f *imagesFlags) *cobra.Command {
+	var projectPath string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Delete unused images and orphaned downloads",
+		Long: `Delete registered images that no machine, saved VM config or default setting
+refers to, and partial downloads that will not be resumed.
+
+Use --dry-run to see what would be deleted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := settings.NewStore()
+			if err != nil {
+				return err
+			}
+
+			// Without the project, machine references can't be checked
+			projectDir, err := resolveProjectDir(projectPath)
+			if err != nil {
+				return err
+			}
+
+			result, err := images.NewManager(store).GC(projectDir, dryRun)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if f.output == outputJSON {
+				return writeJSON(out, result)
+			}
+
+			verb := "Deleted"
+			if dryRun {
+				verb = "Would delete"
+			}
+			for _, img := range result.Images {
+				fmt.Fprintf(out, "%s %s (%s)\n", verb, img.ID, img.Path)
+			}
+			for _, t := range result.Orphans {
+				fmt.Fprintf(out, "%s %s\n", verb, t.Path)
+			}
+			if len(result.Images) == 0 && len(result.Orphans) == 0 {
+				fmt.Fprintln(out, "Nothing to clean up")
+				return nil
+			}
+
+			if dryRun {
+				fmt.Fprintf(out, "\n%s would be freed\n", formatBytes(result.Freed))
+			} else {
+				fmt.Fprintf(out, "\nFreed %s\n", formatBytes(result.Freed))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&projectPath, "project", "", "project directory (default: from ucli init)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be deleted without deleting")
+	return cmd
+}
+
+// imagesProjectDir returns the project whose machines are checked for image
+// references, or "" when no project is configured.
+func imagesProjectDir(flagPath string) string {
+	projectDir, err := resolveProjectDir(flagPath)
+	if err != nil {
+		return ""
+	}
+	return projectDir
+}
+
+// formatReferences summarizes what uses an image.
+func formatReferences(refs []images.Reference) string {
+	if len(refs) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		parts = append(parts, fmt.Sprintf("%s %s", ref.Kind, ref.Name))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printImage prints a single image after pull or add.
+func printImage(cmd *cobra.Command, f *imagesFlags, img *settings.CloudImage, verb string) error {
+	out := cmd.OutOrStdout()
+	if f.output == outputJSON {
+		return writeJSON(out, img)
+	}
+
+	fmt.Fprintf(out, "%s %s\n", verb, img.ID)
+	fmt.Fprintf(out, "  Path:     %s\n", img.Path)
+	fmt.Fprintf(out, "  Size:     %s\n", formatBytes(img.Size))
+	if img.SHA256 != "" {
+		fmt.Fprintf(out, "  SHA256:   %s\n", img.SHA256)
+	}
+	fmt.Fprintf(out, "  Verified: %s\n", verification(img))
+	return nil
+}
+
+// warnUnsigned warns when checksums cannot verify SHA256SUMS signatures.
+func warnUnsigned(w io.Writer, checksums *images.ChecksumSource) {
+	if reason := checksums.Unsigned(); reason != "" {
+		fmt.Fprintf(w, "Warning: SHA256SUMS signature will not be checked: %s\n", reason)
+	}
+}
+
+// verification describes how an image was verified: against a signed
+// SHA256SUMS, by checksum only, or not at all.
+func verification(img *settings.CloudImage) string {
+	switch {
+	case img.Verified && img.Signed:
+		return "signed"
+	case img.Verified:
+		return "checksum only"
+	default:
+		return "no"
+	}
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// formatBytes formats a size in bytes using binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// progressBar draws a single-line download progress bar.
+type progressBar struct {
+	w       io.Writer
+	last    time.Time
+	percent int
+	drawn   bool
+}
+
+const progressBarWidth = 30
+
+func newProgressBar(w io.Writer) *progressBar {
+	return &progressBar{w: w, percent: -1}
+}
+
+// update redraws the bar when the percentage changes, or periodically when
+// the total size is unknown.
+func (b *progressBar) update(downloaded, total int64) {
+	if total <= 0 {
+		if time.Since(b.last) < 250*time.Millisecond {
+			return
+		}
+		b.last = time.Now()
+		fmt.Fprintf(b.w, "\r%s downloaded", formatBytes(downloaded))
+		b.drawn = true
+		return
+	}
+
+	percent := int(downloaded * 100 / total)
+	if percent == b.percent {
+		return
+	}
+	b.percent = percent
+
+	filled := progressBarWidth * percent / 100
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+	fmt.Fprintf(b.w, "\r[%s] %3d%% %s / %s", bar, percent, formatBytes(downloaded), formatBytes(total))
+	b.drawn = true
+}
+
+// finish ends the progress line.
+func (b *progressBar) finish() {
+	if b.drawn {
+		fmt.Fprintln(b.w)
+	}
+}
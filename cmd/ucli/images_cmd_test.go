@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/images"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+)
+
+func runImagesCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs(append([]string{"images"}, args...))
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&bytes.Buffer{})
+	err := rootCmd.Execute()
+	return buf.String(), err
+}
+
+func TestImagesCmd(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	out, err := runImagesCmd(t, "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "No cloud images registered")
+
+	// Release and arch are detected from the file name
+	dir := t.TempDir()
+	noble := filepath.Join(dir, "noble-server-cloudimg-amd64.img")
+	require.NoError(t, os.WriteFile(noble, []byte("noble"), 0644))
+	out, err = runImagesCmd(t, "add", noble)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Added ubuntu-24.04-amd64")
+
+	custom := filepath.Join(dir, "custom.img")
+	require.NoError(t, os.WriteFile(custom, []byte("custom"), 0644))
+	_, err = runImagesCmd(t, "add", custom)
+	assert.ErrorContains(t, err, "pass --release and --arch")
+
+	out, err = runImagesCmd(t, "add", custom, "--release", "jammy", "--arch", "arm64", "--output", "json")
+	require.NoError(t, err)
+	var added map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &added))
+	assert.Equal(t, "ubuntu-22.04-arm64", added["id"])
+
+	out, err = runImagesCmd(t, "set-default", "ubuntu-22.04-arm64")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Default image set to ubuntu-22.04-arm64")
+
+	out, err = runImagesCmd(t, "list", "--output", "json")
+	require.NoError(t, err)
+	var list []imageJSON
+	require.NoError(t, json.Unmarshal([]byte(out), &list))
+	require.Len(t, list, 2)
+	assert.False(t, list[0].Default)
+	assert.True(t, list[1].Default)
+
+	out, err = runImagesCmd(t, "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "ubuntu-22.04-arm64 *")
+
+	// Local images without a checksum can't be verified
+	_, err = runImagesCmd(t, "verify", "ubuntu-24.04-amd64")
+	assert.ErrorContains(t, err, "no checksum available")
+
+	out, err = runImagesCmd(t, "rm", "ubuntu-22.04-arm64", "--delete-file")
+	require.NoError(t, err)
+	assert.Contains(t, out, "deleted its file")
+	assert.NoFileExists(t, custom)
+
+	_, err = runImagesCmd(t, "rm", "ubuntu-22.04-arm64")
+	assert.ErrorContains(t, err, "image not found")
+
+	_, err = runImagesCmd(t, "list", "--output", "yaml")
+	assert.ErrorContains(t, err, `unsupported output format "yaml"`)
+}
+
+func TestImagesCmd_UsageAndGC(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	project := t.TempDir()
+	cfg := globalconfig.NewConfig()
+	cfg.ProjectPath = project
+	require.NoError(t, cfg.Save())
+
+	dir := t.TempDir()
+	noble := filepath.Join(dir, "noble-server-cloudimg-amd64.img")
+	jammy := filepath.Join(dir, "jammy-server-cloudimg-amd64.img")
+	require.NoError(t, os.WriteFile(noble, []byte("noble"), 0644))
+	require.NoError(t, os.WriteFile(jammy, []byte("jammy"), 0644))
+	for _, path := range []string{noble, jammy} {
+		_, err := runImagesCmd(t, "add", path)
+		require.NoError(t, err)
+	}
+
+	vmDir := filepath.Join(project, "tf", "dev")
+	require.NoError(t, os.MkdirAll(vmDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(vmDir, "terragrunt.hcl"),
+		[]byte("inputs = {\n  ubuntu_image_path = \""+noble+"\"\n}\n"), 0644))
+
+	out, err := runImagesCmd(t, "usage", "--project", project)
+	require.NoError(t, err)
+	assert.Contains(t, out, "machine dev")
+	assert.Contains(t, out, "Total: 10 B")
+
+	out, err = runImagesCmd(t, "gc", "--project", project, "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Would delete ubuntu-22.04-amd64")
+	assert.FileExists(t, jammy)
+
+	out, err = runImagesCmd(t, "gc")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Deleted ubuntu-22.04-amd64")
+	assert.NoFileExists(t, jammy)
+	assert.FileExists(t, noble)
+
+	out, err = runImagesCmd(t, "gc", "--project", project)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Nothing to clean up")
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "2.0 GiB", formatBytes(2<<30))
+}
+
+func TestProgressBar(t *testing.T) {
+	var buf bytes.Buffer
+	bar := newProgressBar(&buf)
+	bar.update(0, 2048)
+	bar.update(10, 2048) // same percentage, not redrawn
+	bar.update(1024, 2048)
+	bar.finish()
+
+	assert.Equal(t, "\r[------------------------------]   0% 0 B / 2.0 KiB"+
+		"\r[###############---------------]  50% 1.0 KiB / 2.0 KiB\n", buf.String())
+}
+
+func TestVerification(t *testing.T) {
+	assert.Equal(t, "no", verification(&settings.CloudImage{}))
+	assert.Equal(t, "checksum only", verification(&settings.CloudImage{Verified: true}))
+	assert.Equal(t, "signed", verification(&settings.CloudImage{Verified: true, Signed: true}))
+	assert.Equal(t, "no", verification(&settings.CloudImage{Signed: true}))
+}
+
+func TestWarnUnsigned(t *testing.T) {
+	var buf bytes.Buffer
+	warnUnsigned(&buf, images.NewChecksumSource(nil))
+	assert.Equal(t, "Warning: SHA256SUMS signature will not be checked: no Ubuntu signing key is available\n", buf.String())
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/iso"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/spec"
+)
+
+// isoFlags holds the flag values for the iso build command.
+type isoFlags struct {
+	createFlags
+
+	source  string
+	out     string
+	storage string
+	disk    string
+}
+
+func newISOCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "iso",
+		Short: "Build autoinstall ISOs for bare-metal installs",
+		Long: `Build Ubuntu autoinstall ISOs for bare-metal installs.
+
+Register an Ubuntu live-server ISO, then build a copy of it that installs
+unattended with the generated configuration. The result is a hybrid image
+that boots from a CD-ROM or a USB stick written with dd.
+
+Building requires xorriso.
+
+Examples:
+  ucli iso add ~/Downloads/ubuntu-24.04.1-live-server-amd64.iso
+  ucli iso list
+  ucli iso build --spec ucli.yaml --out metal.iso`,
+	}
+
+	cmd.AddCommand(newISOListCmd(), newISOAddCmd(), newISOBuildCmd())
+	return cmd
+}
+
+func newISOListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List registered ISOs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if len(cfg.ISOs) == 0 {
+				fmt.Fprintln(out, "No ISOs registered. Run 'ucli iso add <path>' to add one.")
+				return nil
+			}
+
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tNAME\tPATH")
+			for _, i := range cfg.ISOs {
+				id := i.ID
+				if id == cfg.Preferences.DefaultISO {
+					id += " *"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", id, i.Name, i.Path)
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func newISOAddCmd() *cobra.Command {
+	var setDefault bool
+
+	cmd := &cobra.Command{
+		Use:   "add <path>",
+		Short: "Register an Ubuntu live-server ISO",
+		Long: `Register an Ubuntu live-server ISO that is already on disk.
+
+The ID is the file name without .iso. Registering a file with the same ID
+again replaces the entry.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entry, err := iso.NewISO(args[0])
+			if err != nil {
+				return err
+			}
+
+			cfg, err := loadGlobalConfig()
+			if err != nil {
+				return err
+			}
+			cfg.AddISO(entry)
+			if setDefault || len(cfg.ISOs) == 1 {
+				cfg.Preferences.DefaultISO = entry.ID
+			}
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %s (%s)\n", entry.ID, entry.Path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&setDefault, "default", false, "use this ISO when --source is not given")
+	return cmd
+}
+
+func newISOBuildCmd() *cobra.Command {
+	f := &isoFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Build an autoinstall ISO from a spec file or flags",
+		Long: `Generate an autoinstall user-data from a spec file or flags and write a
+copy of the source ISO that installs Ubuntu with it.
+
+The installer boot entries are patched with 'autoinstall ds=nocloud', so the
+install starts without prompts. It erases the target disk: the largest disk
+unless --disk or the spec's install.storage.match selects another. Encryption,
+disk match rules, a password hash and late-commands are set in the spec's
+install section.
+
+--source takes a registered ISO ID or a path, and defaults to the default
+registered ISO.
+
+Examples:
+  ucli iso build --spec ucli.yaml --out metal.iso
+  ucli iso build --source ubuntu-24.04.1-live-server-amd64 \
+    --username me --hostname metal --ssh-key-file ~/.ssh/id_ed25519.pub --out metal.iso`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runISOBuild(cmd, f)
+		},
+	}
+
+	flags := cmd.Flags()
+	addSpecFlags(flags, &f.createFlags)
+	flags.StringVar(&f.source, "source", "", "registered ISO ID or path to a live-server ISO (default: the default ISO)")
+	flags.StringVarP(&f.out, "out", "o", "", "output ISO path (required)")
+	flags.StringVar(&f.storage, "storage", "", "storage layout: lvm or direct (default: lvm)")
+	flags.StringVar(&f.disk, "disk", "", "install disk path, e.g. /dev/nvme0n1 (default: largest disk)")
+	addUserFlags(flags, &f.createFlags)
+	_ = cmd.MarkFlagRequired("out")
+
+	return cmd
+}
+
+// runISOBuild generates the configuration and builds the ISO.
+func runISOBuild(cmd *cobra.Command, f *isoFlags) error {
+	source, err := resolveISOSource(f.source)
+	if err != nil {
+		return err
+	}
+
+	s, err := f.resolveSpec(cmd)
+	if err != nil {
+		return err
+	}
+
+	changed := cmd.Flags().Changed
+	if changed("storage") || changed("disk") {
+		if s.Install == nil {
+			s.Install = &spec.InstallSpec{}
+		}
+		setString(changed("storage"), &s.Install.Storage.Layout, f.storage)
+		setString(changed("disk"), &s.Install.Storage.Match.Path, f.disk)
+	}
+
+	cfg, _, err := f.fullConfig(s)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	opts := &iso.Options{
+		SourceISO:  source,
+		OutputPath: f.out,
+		Config:     cfg,
+	}
+	result, err := iso.New().Build(context.Background(), opts, printProgress(out))
+	if err != nil {
+		return err
+	}
+
+	for _, log := range result.Logs {
+		fmt.Fprintln(out, log)
+	}
+	fmt.Fprintf(out, "\nWrote %s (%s) in %s\n", result.OutputPath, formatBytes(result.Size), result.Duration.Round(100*time.Millisecond))
+	return nil
+}
+
+// resolveISOSource returns the source ISO path for a registered ID or a
+// path, falling back to the default registered ISO.
+func resolveISOSource(source string) (string, error) {
+	if source != "" {
+		if _, err := os.Stat(source); err == nil {
+			return source, nil
+		}
+	}
+
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return "", err
+	}
+
+	id := source
+	if id == "" {
+		id = cfg.Preferences.DefaultISO
+	}
+	if id == "" && len(cfg.ISOs) == 1 {
+		id = cfg.ISOs[0].ID
+	}
+	if id == "" {
+		return "", fmt.Errorf("no source ISO: pass --source or register one with 'ucli iso add <path>'")
+	}
+
+	entry := cfg.FindISO(id)
+	if entry == nil {
+		return "", fmt.Errorf("ISO %q is not registered and is not a file (run 'ucli iso list')", id)
+	}
+	return entry.Path, nil
+}
+
+// loadGlobalConfig loads the global config, which holds the registered ISOs.
+func loadGlobalConfig() (*globalconfig.Config, error) {
+	cfg, err := globalconfig.Load()
+	if err != nil {
+		if errors.Is(err, globalconfig.ErrNotInitialized) {
+			return nil, fmt.Errorf("ucli not initialized. Run 'ucli init <path>' first")
+		}
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+)
+
+// fakeXorriso answers grub.cfg extraction with a live-server boot menu,
+// copies the mapped user-data to $FAKE_USER_DATA and writes the output ISO.
+const fakeXorriso = `#!/bin/sh
+case " $* " in
+*" -extract /boot/grub/grub.cfg "*)
+	for last; do :; done
+	printf 'menuentry "Install" {\n\tlinux\t/casper/vmlinuz  ---\n}\n' > "$last"
+	;;
+*" -extract "*)
+	echo "xorriso : FAILURE : Cannot find path" >&2
+	exit 5
+	;;
+*)
+	while [ $# -gt 0 ]; do
+		case "$1" in
+		-outdev) out=$2 ;;
+		-map) [ "$3" = /nocloud ] && cp "$2/user-data" "$FAKE_USER_DATA" ;;
+		esac
+		shift
+	done
+	echo "xorriso : UPDATE : 100.00% done"
+	echo iso > "$out"
+	;;
+esac
+`
+
+// setupISOConfig points the global config at a temporary project.
+func setupISOConfig(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := globalconfig.NewConfig()
+	cfg.ProjectPath = t.TempDir()
+	require.NoError(t, cfg.Save())
+}
+
+func runISOCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs(append([]string{"iso"}, args...))
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&bytes.Buffer{})
+	err := rootCmd.Execute()
+	return buf.String(), err
+}
+
+func TestISOCmd_AddAndList(t *testing.T) {
+	setupISOConfig(t)
+	src := filepath.Join(t.TempDir(), "ubuntu-24.04.1-live-server-amd64.iso")
+	require.NoError(t, os.WriteFile(src, []byte("iso"), 0644))
+
+	out, err := runISOCmd(t, "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "No ISOs registered")
+
+	out, err = runISOCmd(t, "add", src)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Added ubuntu-24.04.1-live-server-amd64")
+
+	out, err = runISOCmd(t, "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "ubuntu-24.04.1-live-server-amd64 *")
+	assert.Contains(t, out, "Ubuntu 24.04.1 Live Server (amd64)")
+
+	_, err = runISOCmd(t, "add", filepath.Join(t.TempDir(), "missing.iso"))
+	assert.ErrorContains(t, err, "ISO not found")
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	_, err = runISOCmd(t, "add", src)
+	assert.ErrorContains(t, err, "ucli not initialized")
+}
+
+func TestISOCmd_Build(t *testing.T) {
+	setupISOConfig(t)
+	dir := t.TempDir()
+
+	bin := filepath.Join(dir, "bin")
+	require.NoError(t, os.MkdirAll(bin, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "xorriso"), []byte(fakeXorriso), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	userData := filepath.Join(dir, "user-data")
+	t.Setenv("FAKE_USER_DATA", userData)
+
+	src := filepath.Join(dir, "ubuntu-24.04.1-live-server-amd64.iso")
+	require.NoError(t, os.WriteFile(src, []byte("iso"), 0644))
+	_, err := runISOCmd(t, "add", src)
+	require.NoError(t, err)
+
+	isoPath := filepath.Join(dir, "metal.iso")
+	out, err := runISOCmd(t, "build",
+		"--out", isoPath,
+		"--username", "tester",
+		"--hostname", "metal",
+		"--ssh-key", "ssh-ed25519 AAAAC3 test@example",
+		"--storage", "direct",
+		"--disk", "/dev/nvme0n1",
+	)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Patching GRUB boot entries")
+	assert.Contains(t, out, "       xorriso : UPDATE : 100.00% done")
+	assert.Contains(t, out, "Wrote "+isoPath)
+	assert.FileExists(t, isoPath)
+
+	data, err := os.ReadFile(userData)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "#cloud-config\nautoinstall:\n")
+	assert.Contains(t, string(data), "- name: tester")
+	assert.Contains(t, string(data), "      name: direct\n      match:\n        path: /dev/nvme0n1\n")
+}
+
+func TestISOCmd_BuildErrors(t *testing.T) {
+	setupISOConfig(t)
+	out := filepath.Join(t.TempDir(), "metal.iso")
+
+	_, err := runISOCmd(t, "build", "--username", "tester", "--hostname", "metal")
+	assert.ErrorContains(t, err, `required flag(s) "out" not set`)
+
+	_, err = runISOCmd(t, "build", "--out", out, "--username", "tester", "--hostname", "metal")
+	assert.ErrorContains(t, err, "no source ISO")
+
+	_, err = runISOCmd(t, "build", "--source", "missing", "--out", out, "--username", "tester", "--hostname", "metal")
+	assert.ErrorContains(t, err, `ISO "missing" is not registered`)
+
+	src := filepath.Join(t.TempDir(), "ubuntu-24.04.1-live-server-amd64.iso")
+	require.NoError(t, os.WriteFile(src, []byte("iso"), 0644))
+	_, err = runISOCmd(t, "build", "--source", src, "--out", out, "--username", "tester", "--hostname", "metal", "--storage", "zfs")
+	assert.ErrorContains(t, err, `unknown storage layout "zfs"`)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/journal"
+)
+
+// logsFlags holds the flag values for the logs command.
+type logsFlags struct {
+	follow bool
+	json   bool
+}
+
+func newLogsCmd() *cobra.Command {
+	f := &logsFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "logs [vm]",
+		Short: "Show the recorded progress and result of deployments",
+		Long: `Show deployments recorded by ucli create and the Create tab.
+
+Every deployment's progress events, commands, their output and the final
+result are written to a journal under ~/.config/ucli/state/deployments.
+The last 100 deployments from the past 30 days are kept.
+
+Without arguments the recorded deployments are listed. With a VM name or
+journal ID, the newest matching deployment is replayed. --follow tails it
+until it finishes; without a name it follows the newest deployment.
+
+Examples:
+  ucli logs
+  ucli logs dev-vm
+  ucli logs --follow
+  ucli logs dev-vm --json | jq 'select(.is_error)'`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := journal.Dir()
+			if err != nil {
+				return fmt.Errorf("failed to get journal directory: %w", err)
+			}
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return runLogs(cmd, f, dir, name)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&f.follow, "follow", "f", false, "wait for new output until the deployment finishes")
+	cmd.Flags().BoolVar(&f.json, "json", false, "print journal records as JSON lines")
+
+	return cmd
+}
+
+// runLogs lists the journals in dir, or replays the one matching name.
+func runLogs(cmd *cobra.Command, f *logsFlags, dir, name string) error {
+	out := cmd.OutOrStdout()
+	if name == "" && !f.follow {
+		return listJournals(out, dir, f.json)
+	}
+
+	s, err := journal.Find(dir, name)
+	if err != nil {
+		return err
+	}
+
+	write := printRecord(out)
+	if f.json {
+		write = writeRecord(out)
+	}
+
+	if !f.follow {
+		records, err := journal.Read(s.Path)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			write(r)
+		}
+		if !s.Finished && !f.json {
+			fmt.Fprintln(out, "\nDeployment has not finished; use --follow to wait for it.")
+		}
+		return nil
+	}
+
+	// Ctrl-C stops following
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	if err := journal.Follow(ctx, s.Path, write); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// listJournals writes a table or JSON list of the journals in dir.
+func listJournals(out io.Writer, dir string, asJSON bool) error {
+	list, err := journal.List(dir)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		if list == nil {
+			list = []journal.Summary{}
+		}
+		return writeJSON(out, list)
+	}
+
+	if len(list) == 0 {
+		fmt.Fprintln(out, "No deployments recorded yet.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tVM\tTARGET\tSTARTED\tSTATUS")
+	for _, s := range list {
+		vm := s.VM
+		if vm == "" {
+			vm = "-"
+		}
+		status := s.Status()
+		if s.Resumable {
+			status += " (resumable)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.ID, vm, s.Target, s.Started.Format("2006-01-02 15:04:05"), status)
+	}
+	return tw.Flush()
+}
+
+// printRecord returns a function that writes records as ucli create
+// printed them.
+func printRecord(out io.Writer) func(journal.Record) {
+	progress := printProgress(out)
+	return func(r journal.Record) {
+		switch r.Kind {
+		case journal.KindStart:
+			fmt.Fprintf(out, "Deployment to %s started %s\n\n", r.Target.DisplayName(), r.Time.Format("2006-01-02 15:04:05"))
+		case journal.KindEvent:
+			progress(r.ProgressEvent())
+		case journal.KindResult:
+			printResult(out, r.DeployResult())
+		}
+	}
+}
+
+// writeRecord returns a function that writes records as JSON lines.
+func writeRecord(out io.Writer) func(journal.Record) {
+	enc := json.NewEncoder(out)
+	return func(r journal.Record) {
+		_ = enc.Encode(r)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/journal"
+)
+
+func runLogsCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs(append([]string{"logs"}, args...))
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&bytes.Buffer{})
+	err := rootCmd.Execute()
+	return buf.String(), err
+}
+
+func TestLogsCmd_AfterCreate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	out, err := runLogsCmd(t)
+	require.NoError(t, err)
+	assert.Contains(t, out, "No deployments recorded yet.")
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{
+		"create", "--target", "config", "--output-dir", t.TempDir(),
+		"--username", "tester", "--hostname", "testbox",
+	})
+	var createOut bytes.Buffer
+	rootCmd.SetOut(&createOut)
+	require.NoError(t, rootCmd.Execute())
+	assert.Contains(t, createOut.String(), "Logs: ucli logs ")
+
+	out, err = runLogsCmd(t)
+	require.NoError(t, err)
+	assert.Contains(t, out, "ID")
+	assert.Contains(t, out, "config")
+	assert.Contains(t, out, "succeeded")
+
+	// The newest deployment replays as ucli create printed it
+	out, err = runLogsCmd(t, "--follow")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Deployment to Config Only started")
+	assert.Contains(t, out, "Deployment successful")
+}
+
+func TestLogsCmd_ReplayByVM(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir, err := journal.Dir()
+	require.NoError(t, err)
+
+	j, err := journal.Create(dir, deploy.TargetMultipass, "dev")
+	require.NoError(t, err)
+	progress := j.Progress(deploy.NoOpProgress)
+	progress(deploy.NewProgressEventWithCommand(deploy.StageLaunching, "Launching VM 'dev'...", "multipass launch --name dev", 35))
+	progress(deploy.NewErrorEvent("launch timed out"))
+	require.NoError(t, j.Finish(&deploy.DeployResult{Error: errors.New("failed to launch VM")}))
+
+	// Another deployment still running
+	running, err := journal.Create(dir, deploy.TargetTerragrunt, "web")
+	require.NoError(t, err)
+	defer running.Close()
+
+	out, err := runLogsCmd(t, "dev")
+	require.NoError(t, err)
+	assert.Contains(t, out, "[ 35%] Launching: Launching VM 'dev'...")
+	assert.Contains(t, out, "$ multipass launch --name dev")
+	assert.Contains(t, out, "[FAIL] Error: launch timed out")
+	assert.Contains(t, out, "Deployment failed")
+
+	out, err = runLogsCmd(t, "web")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Deployment has not finished")
+
+	out, err = runLogsCmd(t, "dev", "--json")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 4)
+	var last journal.Record
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &last))
+	assert.Equal(t, journal.KindResult, last.Kind)
+	assert.Equal(t, "failed to launch VM", last.Error)
+
+	out, err = runLogsCmd(t, "--json")
+	require.NoError(t, err)
+	var list []journal.Summary
+	require.NoError(t, json.Unmarshal([]byte(out), &list))
+	require.Len(t, list, 2)
+	assert.Equal(t, "web", list[0].VM)
+
+	_, err = runLogsCmd(t, "nope")
+	assert.EqualError(t, err, `no deployment found for "nope"`)
+}
@@ -4,6 +4,8 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -12,8 +14,11 @@ import (
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/app"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/create"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/doctor"
+	isoview "github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/iso"
 	settingsview "github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/settings"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/vms"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/images"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
 )
 
@@ -43,6 +48,13 @@ It supports:
   - Interactive package selection from available installers
   - Direct generation of cloud-init.yaml (no config files needed)
   - Generation of Terragrunt/OpenTofu configs for libvirt VMs
+  - Headless creation from flags or a ucli.yaml spec (ucli create)
+  - Validation of cloud-init user-data (ucli validate)
+  - Cloud image downloads and verification (ucli images)
+  - NoCloud seed images for VMs and bare metal (ucli seed)
+  - Autoinstall ISOs for bare-metal installs (ucli iso)
+  - Host dependency checks for scripts and CI (ucli doctor)
+  - Recorded output of past deployments (ucli logs)
 
 Run without arguments to launch the full-screen TUI.`,
 		Version: version,
@@ -51,7 +63,15 @@ Run without arguments to launch the full-screen TUI.`,
 
 	rootCmd.AddCommand(
 		newInitCmd(),
+		newCreateCmd(),
 		newPackagesCmd(),
+		newSpecCmd(),
+		newValidateCmd(),
+		newImagesCmd(),
+		newSeedCmd(),
+		newISOCmd(),
+		newDoctorCmd(),
+		newLogsCmd(),
 	)
 
 	return rootCmd
@@ -74,18 +94,28 @@ func runTUI(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("invalid project path: %w", err)
 	}
 
+	// Warnings from background work would draw over the TUI
+	defer logToFile()()
+
 	// Create settings store for cloud images
+	var downloader *images.Downloader
 	store, err := settings.NewStore()
 	if err != nil {
 		// Non-fatal - create will work without cloud images
 		store = nil
+	} else {
+		// Continue image downloads that were interrupted when ucli last exited
+		downloader = images.NewDownloader(store)
+		_, _ = downloader.ResumePersistedDownloads()
 	}
 
 	// Create the application with tabs
 	model := app.New(projectDir).WithTabs(
+		vms.New(projectDir),
 		create.New(projectDir, store),
+		isoview.New(projectDir, store),
 		doctor.New(),
-		settingsview.New(),
+		settingsview.New(downloader),
 	)
 
 	// Run the TUI
@@ -96,3 +126,27 @@ func runTUI(_ *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+// logToFile sends the standard logger to the log file in the state
+// directory, or discards it if the file can't be opened. It returns a
+// function that closes the file and restores logging to stderr.
+func logToFile() func() {
+	path, err := globalconfig.GetLogPath()
+	if err == nil {
+		err = globalconfig.EnsureConfigDir()
+	}
+
+	var f *os.File
+	if err == nil {
+		f, err = tea.LogToFile(path, "ucli")
+	}
+	if err != nil {
+		log.SetOutput(io.Discard)
+		return func() { log.SetOutput(os.Stderr) }
+	}
+
+	return func() {
+		log.SetOutput(os.Stderr)
+		f.Close()
+	}
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"bytes"
+	"log"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,8 +32,8 @@ func TestRootCmdHelp(t *testing.T) {
 	output := buf.String()
 	assert.Contains(t, output, "ucli")
 	assert.Contains(t, output, "packages")
-	// create and build commands have been removed
-	assert.NotContains(t, output, "create")
+	assert.Contains(t, output, "create")
+	// build command has been removed
 	assert.NotContains(t, output, "build")
 }
 
@@ -52,8 +55,53 @@ func TestPackagesCmd(t *testing.T) {
 	rootCmd := newRootCmd()
 	rootCmd.SetArgs([]string{"packages"})
 
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+
 	err := rootCmd.Execute()
 	assert.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "Dependencies:")
+	assert.Contains(t, output, "lazydocker -> homebrew, docker")
+}
+
+func TestPackagesCmd_Validate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gitui.sh"), []byte(`#!/bin/bash
+# gitui Installer
+# Terminal UI for git
+PACKAGE_NAME="gitui"
+DEFAULT="false"
+`), 0644))
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{"packages", "--validate", "--dir", dir})
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&bytes.Buffer{})
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 problem(s) in 1 package script(s)")
+	assert.Contains(t, buf.String(), "gitui.sh: missing DISPLAY_NAME")
+	assert.Contains(t, buf.String(), "gitui.sh: missing CATEGORY")
+
+	// Listing still works and marks opt-in packages
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{"packages", "--dir", dir})
+	buf.Reset()
+	rootCmd.SetOut(&buf)
+	require.NoError(t, rootCmd.Execute())
+	assert.Contains(t, buf.String(), "Other:\n  - gitui: Terminal UI for git (opt-in)")
+	assert.Contains(t, buf.String(), "2 script issue(s) found")
+
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{"packages", "--validate"})
+	buf.Reset()
+	rootCmd.SetOut(&buf)
+	require.NoError(t, rootCmd.Execute())
+	assert.Contains(t, buf.String(), "packages ok")
 }
 
 func TestSubcommandHelp(t *testing.T) {
@@ -67,6 +115,11 @@ func TestSubcommandHelp(t *testing.T) {
 			args:    []string{"packages", "--help"},
 			expects: []string{"packages", "cloud-init"},
 		},
+		{
+			name:    "create help",
+			args:    []string{"create", "--help"},
+			expects: []string{"--spec", "--target", "multipass", "terragrunt"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -87,3 +140,16 @@ func TestSubcommandHelp(t *testing.T) {
 		})
 	}
 }
+
+func TestLogToFile(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	closeLog := logToFile()
+	log.Printf("Warning: failed to save download state for x: disk full")
+	closeLog()
+
+	data, err := os.ReadFile(filepath.Join(configHome, "ucli", "state", "ucli.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Warning: failed to save download state for x: disk full")
+}
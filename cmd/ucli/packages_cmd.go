@@ -2,32 +2,131 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/packages"
 )
 
-// runPackages lists all available packages from embedded scripts.
-func runPackages(_ *cobra.Command, _ []string) error {
-	registry, err := packages.DiscoverEmbedded()
+// packagesFlags holds the flags for the packages command.
+type packagesFlags struct {
+	validate bool
+	dir      string
+}
+
+// runPackages lists all available packages from embedded scripts and
+// overlay directories.
+func runPackages(cmd *cobra.Command, f packagesFlags) error {
+	var registry *packages.Registry
+	var err error
+	if f.dir != "" {
+		registry, err = packages.DiscoverFromFS(os.DirFS(f.dir))
+		if err != nil {
+			return fmt.Errorf("failed to discover packages: %w", err)
+		}
+	} else if registry, err = discoverPackages(""); err != nil {
+		return err
+	}
+
+	if f.validate {
+		return validatePackages(cmd, registry)
+	}
+
+	printPackages(cmd, registry)
+	if n := len(registry.Issues); n > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d script issue(s) found; run 'ucli packages --validate' for details\n", n)
+	}
+	return registry.CheckDependencies()
+}
+
+// discoverPackages loads the embedded packages merged with the user overlay
+// directory and, when a project is configured, its packages.d directory.
+func discoverPackages(projectPath string) (*packages.Registry, error) {
+	projectDir, err := resolveProjectDir(projectPath)
 	if err != nil {
-		return fmt.Errorf("failed to discover packages: %w", err)
+		projectDir = "" // Overlays don't require an initialized project
 	}
 
-	fmt.Printf("Found %d packages:\n\n", len(registry.Packages))
+	registry, err := packages.DiscoverWithOverlays(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover packages: %w", err)
+	}
+	return registry, nil
+}
+
+// validatePackages reports script metadata issues and dependency errors.
+func validatePackages(cmd *cobra.Command, registry *packages.Registry) error {
+	out := cmd.OutOrStdout()
+
+	problems := len(registry.Issues)
+	for _, issue := range registry.Issues {
+		fmt.Fprintln(out, issue)
+	}
+	if err := registry.CheckDependencies(); err != nil {
+		fmt.Fprintln(out, err)
+		problems++
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("%d problem(s) in %d package script(s)", problems, len(registry.Packages))
+	}
+	fmt.Fprintf(out, "%d packages ok\n", len(registry.Packages))
+	return nil
+}
+
+// printPackages prints packages by category followed by the dependency graph.
+func printPackages(cmd *cobra.Command, registry *packages.Registry) {
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintf(out, "Found %d packages:\n\n", len(registry.Packages))
 
 	for _, category := range registry.Categories() {
-		fmt.Printf("%s:\n", category)
+		fmt.Fprintf(out, "%s:\n", category)
 		for _, pkg := range registry.ByCategory[category] {
 			desc := pkg.Description
 			if desc == "" {
 				desc = "(no description)"
 			}
-			fmt.Printf("  - %s: %s\n", pkg.Name, desc)
+			if !pkg.Default {
+				desc += " (opt-in)"
+			}
+			if pkg.Source != packages.SourceEmbedded {
+				desc += fmt.Sprintf(" [%s]", provenance(pkg))
+			}
+			fmt.Fprintf(out, "  - %s: %s\n", pkg.Name, desc)
 		}
-		fmt.Println()
+		fmt.Fprintln(out)
 	}
 
-	return nil
+	var deps, conflicts []string
+	for _, pkg := range registry.Packages {
+		if len(pkg.Depends) > 0 {
+			deps = append(deps, fmt.Sprintf("  %s -> %s", pkg.Name, strings.Join(pkg.Depends, ", ")))
+		}
+		if len(pkg.Conflicts) > 0 {
+			conflicts = append(conflicts, fmt.Sprintf("  %s conflicts with %s", pkg.Name, strings.Join(pkg.Conflicts, ", ")))
+		}
+	}
+
+	if len(deps) > 0 {
+		fmt.Fprintln(out, "Dependencies:")
+		fmt.Fprintln(out, strings.Join(deps, "\n"))
+		fmt.Fprintln(out)
+	}
+	if len(conflicts) > 0 {
+		fmt.Fprintln(out, "Conflicts:")
+		fmt.Fprintln(out, strings.Join(conflicts, "\n"))
+		fmt.Fprintln(out)
+	}
+}
+
+// provenance describes where an overlay package came from, such as
+// "user, overrides embedded".
+func provenance(pkg packages.Package) string {
+	if pkg.Overrides == nil {
+		return string(pkg.Source)
+	}
+	return fmt.Sprintf("%s, overrides %s", pkg.Source, pkg.Overrides.Source)
 }
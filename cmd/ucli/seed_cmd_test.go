@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runSeedCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs(append([]string{"seed", "build"}, args...))
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&bytes.Buffer{})
+	err := rootCmd.Execute()
+	return buf.String(), err
+}
+
+func TestSeedBuildCmd(t *testing.T) {
+	dir := t.TempDir()
+	networkPath := filepath.Join(dir, "network.yaml")
+	require.NoError(t, os.WriteFile(networkPath, []byte("version: 2\n"), 0644))
+
+	isoPath := filepath.Join(dir, "seed.iso")
+	out, err := runSeedCmd(t,
+		"--out", isoPath,
+		"--username", "tester",
+		"--hostname", "testbox",
+		"--ssh-key", "ssh-ed25519 AAAAC3 test@example",
+		"--packages", "lazygit",
+		"--instance-id", "iid-test",
+		"--network-config", networkPath,
+	)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Wrote iso seed image")
+
+	data, err := os.ReadFile(isoPath)
+	require.NoError(t, err)
+	assert.Equal(t, "CD001", string(data[16*2048+1:16*2048+6]))
+	assert.Contains(t, string(data), "#cloud-config")
+	assert.Contains(t, string(data), "instance-id: iid-test\nlocal-hostname: testbox\n")
+	assert.Contains(t, string(data), "version: 2\n")
+
+	// The format follows the extension unless --format is set
+	fatPath := filepath.Join(dir, "seed.bin")
+	out, err = runSeedCmd(t, "--out", fatPath, "--username", "tester", "--hostname", "testbox")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Wrote fat seed image")
+
+	data, err = os.ReadFile(fatPath)
+	require.NoError(t, err)
+	assert.Equal(t, "FAT12   ", string(data[54:62]))
+	assert.Contains(t, string(data), "dhcp4: true")
+}
+
+func TestSeedBuildCmd_Errors(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "seed.iso")
+
+	_, err := runSeedCmd(t, "--username", "tester", "--hostname", "testbox")
+	assert.ErrorContains(t, err, `required flag(s) "out" not set`)
+
+	_, err = runSeedCmd(t, "--out", out, "--hostname", "testbox")
+	assert.ErrorContains(t, err, "username is required")
+
+	_, err = runSeedCmd(t, "--out", out, "--username", "tester", "--hostname", "testbox", "--format", "qcow2")
+	assert.ErrorContains(t, err, `unsupported seed format "qcow2"`)
+}
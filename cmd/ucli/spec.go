@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/spec"
+)
+
+func newSpecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "spec",
+		Short: "Work with ucli.yaml machine spec files",
+	}
+
+	cmd.AddCommand(newSpecExportCmd(), newSpecCheckCmd())
+	return cmd
+}
+
+func newSpecExportCmd() *cobra.Command {
+	var outPath string
+
+	cmd := &cobra.Command{
+		Use:   "export <saved-config>",
+		Short: "Export a saved VM configuration as a spec file",
+		Long: `Export a VM configuration saved from the Create wizard as a ucli.yaml spec.
+
+The configuration is looked up by ID or name. The spec is written to stdout
+unless --output is given.
+
+Examples:
+  ucli spec export dev-box
+  ucli spec export dev-box -o ucli.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSpecExport(cmd, args[0], outPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outPath, "output", "o", "", "write the spec to a file instead of stdout")
+	return cmd
+}
+
+func runSpecExport(cmd *cobra.Command, ref, outPath string) error {
+	store, err := settings.NewStore()
+	if err != nil {
+		return err
+	}
+	s, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	cfg := s.FindVMConfig(ref)
+	if cfg == nil {
+		for i := range s.VMConfigs {
+			if s.VMConfigs[i].Name == ref {
+				cfg = &s.VMConfigs[i]
+				break
+			}
+		}
+	}
+	if cfg == nil {
+		return fmt.Errorf("saved configuration %q not found", ref)
+	}
+
+	sp := spec.FromVMConfig(cfg)
+	if outPath != "" {
+		if err := sp.Save(outPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", outPath)
+		return nil
+	}
+
+	data, err := sp.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+func newSpecCheckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check [file]",
+		Short: "Check a spec file for errors",
+		Long:  `Parse a spec file (default: ` + spec.DefaultFileName + `) and report schema errors such as unknown fields.`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := spec.DefaultFileName
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			sp, err := spec.Load(path)
+			if err != nil {
+				return err
+			}
+			if _, err := sp.DeploymentTarget(); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if _, err := sp.Expand(); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: ok (version %d, target %s)\n", path, sp.Version, sp.Target)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecCheckCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("version: 1\ntarget: multipass\n"), 0644))
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("version: 1\ntarget: multipass\ncpus: 4\n"), 0644))
+
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs([]string{"spec", "check", valid})
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	require.NoError(t, rootCmd.Execute())
+	assert.Contains(t, buf.String(), "ok (version 1, target multipass)")
+
+	rootCmd = newRootCmd()
+	rootCmd.SetArgs([]string{"spec", "check", invalid})
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field cpus not found")
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/validation"
+)
+
+func newValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <file>",
+		Short: "Validate a cloud-init user-data file",
+		Long: `Check a cloud-init user-data file (such as a generated cloud-init.yaml)
+against the cloud-config schema.
+
+Reports a missing #cloud-config header, unknown top-level keys, malformed
+users, write_files and runcmd entries, and unsubstituted ${...} placeholders.
+Exits non-zero if any errors are found; warnings alone do not fail.
+
+Examples:
+  ucli validate cloud-init/cloud-init.yaml
+  ucli validate tf/dev-box/cloud-init.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+			result := &validation.Result{Issues: validation.ValidateCloudInit(path)}
+
+			out := cmd.OutOrStdout()
+			for _, issue := range result.Issues {
+				fmt.Fprintf(out, "%s: %s\n", issue.Severity, issue)
+			}
+
+			if result.HasErrors() {
+				return fmt.Errorf("%s: %d error(s), %d warning(s)", path, result.ErrorCount(), result.WarningCount())
+			}
+			if n := result.WarningCount(); n > 0 {
+				fmt.Fprintf(out, "%s: ok with %d warning(s)\n", path, n)
+				return nil
+			}
+			fmt.Fprintf(out, "%s: ok\n", path)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("#cloud-config\nhostname: test\n"), 0644))
+
+	warn := filepath.Join(dir, "warn.yaml")
+	require.NoError(t, os.WriteFile(warn, []byte("#cloud-config\nhostnam: test\n"), 0644))
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("#cloud-config\nusers: ${USERNAME}\n"), 0644))
+
+	run := func(path string) (string, error) {
+		rootCmd := newRootCmd()
+		rootCmd.SetArgs([]string{"validate", path})
+		var buf bytes.Buffer
+		rootCmd.SetOut(&buf)
+		rootCmd.SetErr(&bytes.Buffer{})
+		err := rootCmd.Execute()
+		return buf.String(), err
+	}
+
+	out, err := run(valid)
+	require.NoError(t, err)
+	assert.Equal(t, valid+": ok\n", out)
+
+	out, err = run(warn)
+	require.NoError(t, err)
+	assert.Contains(t, out, `warning: `+warn+`:2: hostnam: unknown top-level key "hostnam"`)
+	assert.Contains(t, out, "ok with 1 warning(s)")
+
+	out, err = run(invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 error(s), 0 warning(s)")
+	assert.Contains(t, out, "error: "+invalid+":2: users: must be a list")
+	assert.Contains(t, out, "unsubstituted placeholder ${USERNAME}")
+}
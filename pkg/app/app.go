@@ -89,8 +89,15 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		hasFocusedInput = m.tabs[m.activeTab].HasFocusedInput()
 	}
 
-	// Always allow Ctrl+C to quit
+	// Ctrl+C quits, unless the active tab has work it can cancel first
 	if msg.Type == tea.KeyCtrlC {
+		if len(m.tabs) > 0 && m.activeTab < len(m.tabs) {
+			if c, ok := m.tabs[m.activeTab].(interface{ CanCancel() bool }); ok && c.CanCancel() {
+				var cmd tea.Cmd
+				m.tabs[m.activeTab], cmd = m.tabs[m.activeTab].Update(msg)
+				return m, cmd
+			}
+		}
 		m.quitting = true
 		return m, tea.Quit
 	}
@@ -84,6 +84,39 @@ func TestModel_Update_QuitKey(t *testing.T) {
 	assert.NotNil(t, cmd)
 }
 
+// cancelTab is a tab with work that Ctrl+C cancels.
+type cancelTab struct {
+	mockTab
+	busy      bool
+	cancelled bool
+}
+
+func (t *cancelTab) CanCancel() bool { return t.busy }
+
+func (t *cancelTab) Update(msg tea.Msg) (Tab, tea.Cmd) {
+	if k, ok := msg.(tea.KeyMsg); ok && k.Type == tea.KeyCtrlC {
+		t.cancelled = true
+		t.busy = false
+	}
+	return t, nil
+}
+
+func TestModel_Update_CtrlCCancelsTab(t *testing.T) {
+	tab := &cancelTab{mockTab: *newMockTab(TabCreate, "Create", "2", ""), busy: true}
+	m := New("/test").WithTabs(tab)
+
+	// The first Ctrl+C goes to the tab
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	model := updated.(Model)
+	assert.True(t, tab.cancelled)
+	assert.False(t, model.quitting)
+
+	// Once there is nothing to cancel it quits
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	assert.True(t, updated.(Model).quitting)
+	assert.NotNil(t, cmd)
+}
+
 func TestModel_Update_TabSwitching(t *testing.T) {
 	tab1 := newMockTab(TabVMs, "VMs", "1", "VMs content")
 	tab2 := newMockTab(TabCreate, "Create", "2", "Create content")
@@ -12,6 +12,7 @@ const (
 	TabCreate
 	TabDoctor
 	TabConfig
+	TabISO
 )
 
 // Tab is the interface that all tabs must implement.
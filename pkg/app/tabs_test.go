@@ -11,6 +11,7 @@ func TestTabID(t *testing.T) {
 	assert.Equal(t, TabID(1), TabCreate)
 	assert.Equal(t, TabID(2), TabDoctor)
 	assert.Equal(t, TabID(3), TabConfig)
+	assert.Equal(t, TabID(4), TabISO)
 }
 
 func TestNewBaseTab(t *testing.T) {
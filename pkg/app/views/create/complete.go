@@ -22,6 +22,10 @@ func (m *Model) initCompletePhase() {
 // handleCompletePhase handles input for the Complete phase
 func (m *Model) handleCompletePhase(msg tea.KeyMsg) (app.Tab, tea.Cmd) {
 	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("r"))) && m.CanRetry():
+		// Resume the failed deployment
+		return m, m.retryDeploy()
+
 	case key.Matches(msg, key.NewBinding(key.WithKeys("enter", "r"))):
 		// Reset and start over
 		m.wizard.Reset()
@@ -55,7 +59,9 @@ func (m *Model) viewCompletePhase() string {
 
 	result := state.result
 
-	if result.Success {
+	if state.fleet != nil && state.fleet.results != nil {
+		b.WriteString(m.viewFleetResults(state.fleet))
+	} else if result.Success {
 		b.WriteString(successStyle.Render("  Deployment Successful"))
 		b.WriteString("\n\n")
 
@@ -109,7 +115,13 @@ func (m *Model) viewCompletePhase() string {
 	b.WriteString("\n\n")
 
 	// Actions
-	b.WriteString(dimStyle.Render("Press [Enter] or [r] to start over"))
+	if m.CanRetry() {
+		b.WriteString(dimStyle.Render("Press [r] to retry from the last completed stage"))
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render("Press [Enter] to start over"))
+	} else {
+		b.WriteString(dimStyle.Render("Press [Enter] or [r] to start over"))
+	}
 	b.WriteString("\n")
 	b.WriteString(dimStyle.Render("Press [Tab] to switch tabs"))
 	b.WriteString("\n")
@@ -203,6 +215,27 @@ func (m *Model) viewNextSteps() string {
 		if vmName == "" {
 			vmName = "<vm-name>"
 		}
+
+		// Applied: the VM exists, show how to reach it
+		if m.wizard.Data.TerragruntOpts.Apply {
+			if state := m.getDeployState(); state != nil && state.result != nil {
+				if ssh := state.result.Outputs["ssh_command"]; ssh != "" {
+					b.WriteString(labelStyle.Render("  SSH into the VM:"))
+					b.WriteString("\n")
+					b.WriteString("  ")
+					b.WriteString(cmdStyle.Render(ssh))
+					b.WriteString("\n\n")
+				}
+			}
+
+			b.WriteString(labelStyle.Render("  Start, stop or destroy it from the VMs tab, or:"))
+			b.WriteString("\n")
+			b.WriteString("  ")
+			b.WriteString(cmdStyle.Render(fmt.Sprintf("cd tf/%s && terragrunt destroy", vmName)))
+			b.WriteString("\n\n")
+			break
+		}
+
 		b.WriteString(labelStyle.Render("  Navigate to generated config:"))
 		b.WriteString("\n")
 		b.WriteString("  ")
@@ -3,8 +3,6 @@ package create
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/progress"
@@ -12,12 +10,14 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/app"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/create/wizard"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/configonly"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/multipass"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/terragrunt"
-	"github.com/jaspreet-dot-casa/cloud-init/pkg/generator"
-	"github.com/jaspreet-dot-casa/cloud-init/pkg/packages"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/journal"
 )
 
 // Ensure app.Tab is used
@@ -32,6 +32,20 @@ type deployState struct {
 	progressChan chan deploy.ProgressEvent
 	result       *deploy.DeployResult
 	done         bool
+
+	// Plan approval for Terragrunt apply mode
+	approveChan chan bool
+	confirming  bool
+
+	// Esc or Ctrl+C cancels the deployment, then cleanup runs
+	cancel     context.CancelFunc
+	cancelling bool
+
+	// resume is the checkpoint of the failed deployment being retried
+	resume *deploy.Checkpoint
+
+	// fleet is set when several machines are deployed at once
+	fleet *fleetState
 }
 
 // getDeployState returns the deploy state with proper type assertion.
@@ -64,11 +78,15 @@ func (m *Model) initDeployPhase() {
 		progressBar:  p,
 		events:       make([]deploy.ProgressEvent, 0),
 		progressChan: make(chan deploy.ProgressEvent, 100),
+		approveChan:  make(chan bool, 1),
 	}
 	m.wizard.DeployState = state
 
 	// Create the appropriate deployer based on target
 	state.deployer = m.createDeployer()
+	if m.isFleet() {
+		state.fleet = m.newFleetState()
+	}
 }
 
 // createDeployer creates the appropriate deployer for the selected target
@@ -79,20 +97,14 @@ func (m *Model) createDeployer() deploy.Deployer {
 	case deploy.TargetTerragrunt:
 		return terragrunt.New(m.projectDir)
 	case deploy.TargetConfigOnly:
-		// For config-only, we'll use a simple generator
-		return &configOnlyDeployer{
-			projectDir:   m.projectDir,
-			outputDir:    m.wizard.Data.GenerateOpts.OutputDir,
-			generateYAML: m.wizard.Data.GenerateOpts.GenerateCloudInit,
-			registry:     m.wizard.Registry,
-		}
+		return configonly.New(
+			m.wizard.Data.GenerateOpts.OutputDir,
+			m.wizard.Data.GenerateOpts.GenerateCloudInit,
+			m.wizard.Registry,
+		)
 	default:
 		// Fallback to config-only deployer for unknown targets
-		return &configOnlyDeployer{
-			projectDir: m.projectDir,
-			outputDir:  ".",
-			registry:   m.wizard.Registry,
-		}
+		return configonly.New(".", false, m.wizard.Registry)
 	}
 }
 
@@ -102,15 +114,27 @@ func (m *Model) startDeploy() tea.Cmd {
 	if state == nil {
 		return nil
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	state.cancel = cancel
+
+	if state.fleet != nil {
+		return tea.Batch(
+			state.spinner.Tick,
+			m.runFleet(ctx),
+			m.waitForFleetProgress(),
+		)
+	}
 	return tea.Batch(
 		state.spinner.Tick,
-		m.runDeployment(),
+		m.runDeployment(ctx),
 		m.waitForDeployProgress(),
 	)
 }
 
-// runDeployment runs the deployment in the background
-func (m *Model) runDeployment() tea.Cmd {
+// runDeployment runs the deployment in the background. If it fails or ctx
+// is cancelled, the deployer's cleanup runs before completion is reported.
+func (m *Model) runDeployment(ctx context.Context) tea.Cmd {
 	return func() tea.Msg {
 		state := m.getDeployState()
 		if state == nil || state.deployer == nil {
@@ -121,16 +145,48 @@ func (m *Model) runDeployment() tea.Cmd {
 		}
 
 		// Build deploy options from wizard data
-		opts := m.buildDeployOptions()
+		opts, err := m.buildDeployOptions()
+		if err != nil {
+			return deployCompleteMsg{result: &deploy.DeployResult{
+				Success: false,
+				Target:  m.wizard.Data.Target,
+				Error:   err,
+			}}
+		}
+
+		// Block at StageConfirming until the user answers the prompt
+		if opts.Terragrunt.Apply && !opts.Terragrunt.AutoApprove {
+			opts.Terragrunt.Approve = func(ctx context.Context, _ string) (bool, error) {
+				select {
+				case ok := <-state.approveChan:
+					return ok, nil
+				case <-ctx.Done():
+					return false, ctx.Err()
+				}
+			}
+		}
 
 		// Progress callback that sends to channel
-		progressCallback := func(e deploy.ProgressEvent) {
+		var progressCallback deploy.ProgressCallback = func(e deploy.ProgressEvent) {
 			state.progressChan <- e
 		}
 
-		// Run deployment
-		ctx := context.Background()
-		result, err := state.deployer.Deploy(ctx, opts, progressCallback)
+		// Record the deployment for ucli logs; it still runs if the
+		// journal cannot be created
+		target := state.deployer.Target()
+		j, err := journal.Start(target, opts.VMName(target))
+		if err == nil {
+			progressCallback = j.Progress(progressCallback)
+		}
+
+		// Run deployment, or continue the failed one being retried
+		var result *deploy.DeployResult
+		if state.resume != nil {
+			opts.Checkpoint = state.resume
+			result, err = deploy.Resume(ctx, state.deployer, opts, progressCallback)
+		} else {
+			result, err = state.deployer.Deploy(ctx, opts, progressCallback)
+		}
 
 		// Handle error if result is nil
 		if err != nil && result == nil {
@@ -140,6 +196,21 @@ func (m *Model) runDeployment() tea.Cmd {
 			}
 		}
 
+		if !result.Success {
+			if ctx.Err() != nil {
+				progressCallback(deploy.NewErrorEvent("Deployment cancelled"))
+			}
+			if err := deploy.RunCleanup(ctx, state.deployer, opts, progressCallback); err != nil {
+				result.Logs = append(result.Logs, fmt.Sprintf("Warning: %v", err))
+			}
+			if !opts.KeepOnFailure(target) {
+				result.Checkpoint = nil // Nothing left to resume
+			}
+		}
+		if j != nil {
+			_ = j.Finish(result)
+		}
+
 		// Signal completion
 		close(state.progressChan)
 
@@ -164,7 +235,7 @@ func (m *Model) waitForDeployProgress() tea.Cmd {
 }
 
 // buildDeployOptions builds the deploy options from wizard data
-func (m *Model) buildDeployOptions() *deploy.DeployOptions {
+func (m *Model) buildDeployOptions() (*deploy.DeployOptions, error) {
 	data := &m.wizard.Data
 
 	// Collect all SSH keys (both from GitHub and locally selected)
@@ -172,19 +243,18 @@ func (m *Model) buildDeployOptions() *deploy.DeployOptions {
 	sshKeys = append(sshKeys, data.GitHubSSHKeys...)
 	sshKeys = append(sshKeys, data.SSHKeys...)
 
-	// Build the config
-	cfg := &config.FullConfig{
-		Username:         data.Username,
-		Hostname:         data.Hostname,
-		FullName:         data.GitName,
-		Email:            data.GitEmail,
-		MachineName:      data.DisplayName,
-		SSHPublicKeys:    sshKeys,
-		EnabledPackages:  data.Packages,
-		TailscaleAuthKey: data.TailscaleKey,
-		GithubUser:       data.GitHubUser,
-		GithubPAT:        data.GitHubPAT,
-	}
+	// Build the config, keeping the git, tailscale and docker defaults
+	cfg := config.NewFullConfig()
+	cfg.Username = data.Username
+	cfg.Hostname = data.Hostname
+	cfg.FullName = data.GitName
+	cfg.Email = data.GitEmail
+	cfg.MachineName = data.DisplayName
+	cfg.SSHPublicKeys = sshKeys
+	cfg.EnabledPackages = data.Packages
+	cfg.TailscaleAuthKey = data.TailscaleKey
+	cfg.GithubUser = data.GitHubUser
+	cfg.GithubPAT = data.GitHubPAT
 
 	// Calculate disabled packages
 	if m.wizard.Registry != nil {
@@ -198,6 +268,12 @@ func (m *Model) buildDeployOptions() *deploy.DeployOptions {
 				cfg.DisabledPackages = append(cfg.DisabledPackages, pkg)
 			}
 		}
+
+		scripts, err := m.wizard.Registry.ExtraScripts(data.Packages)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load package scripts: %w", err)
+		}
+		cfg.PackageScripts = scripts
 	}
 
 	opts := &deploy.DeployOptions{
@@ -214,7 +290,7 @@ func (m *Model) buildDeployOptions() *deploy.DeployOptions {
 		opts.Terragrunt = data.TerragruntOpts
 	}
 
-	return opts
+	return opts, nil
 }
 
 // handleDeployPhase handles input for the Deploy phase
@@ -226,7 +302,36 @@ func (m *Model) handleDeployPhase(msg tea.Msg) (app.Tab, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if state.fleet != nil && len(state.fleet.confirming) > 0 {
+			switch msg.String() {
+			case "y", "Y":
+				state.fleet.answerFleetApproval(true)
+			case "n", "N", "esc":
+				state.fleet.answerFleetApproval(false)
+			case "ctrl+c":
+				state.fleet.confirming = nil
+				m.cancelDeploy()
+			}
+			return m, nil
+		}
+		if state.confirming {
+			switch msg.String() {
+			case "y", "Y":
+				state.confirming = false
+				state.approveChan <- true
+			case "n", "N", "esc":
+				state.confirming = false
+				state.approveChan <- false
+			case "ctrl+c":
+				state.confirming = false
+				m.cancelDeploy()
+			}
+			return m, nil
+		}
+
 		switch msg.String() {
+		case "esc", "ctrl+c":
+			m.cancelDeploy()
 		case "enter":
 			if state.done {
 				// Move to complete phase
@@ -234,6 +339,8 @@ func (m *Model) handleDeployPhase(msg tea.Msg) (app.Tab, tea.Cmd) {
 				m.initPhase(m.wizard.Phase)
 				return m, nil
 			}
+		case "r":
+			return m, m.retryDeploy()
 		}
 
 	case spinner.TickMsg:
@@ -249,7 +356,17 @@ func (m *Model) handleDeployPhase(msg tea.Msg) (app.Tab, tea.Cmd) {
 		return m, cmd
 
 	case deployProgressMsg:
-		state.events = append(state.events, deploy.ProgressEvent(msg))
+		event := deploy.ProgressEvent(msg)
+		if n := len(state.events); n > 0 && isStreamedLine(state.events[n-1], event) {
+			// Streamed command output replaces the previous line
+			event.Command = state.events[n-1].Command
+			state.events[n-1] = event
+		} else {
+			state.events = append(state.events, event)
+		}
+		if event.Stage == deploy.StageConfirming {
+			state.confirming = true
+		}
 		// Continue listening for more progress events
 		return m, tea.Batch(
 			m.waitForDeployProgress(),
@@ -259,12 +376,80 @@ func (m *Model) handleDeployPhase(msg tea.Msg) (app.Tab, tea.Cmd) {
 	case deployCompleteMsg:
 		state.done = true
 		state.result = msg.result
+		if state.cancel != nil {
+			state.cancel()
+		}
+		return m, nil
+
+	case fleetProgressMsg:
+		if state.fleet != nil {
+			return m, m.handleFleetProgress(state, fleet.Event(msg))
+		}
+
+	case fleetCompleteMsg:
+		state.done = true
+		if state.fleet != nil {
+			state.result = state.fleet.complete(state.deployer.Target(), msg.results)
+		}
+		if state.cancel != nil {
+			state.cancel()
+		}
 		return m, nil
 	}
 
 	return m, nil
 }
 
+// cancelDeploy cancels a running deployment. Cleanup runs once the
+// deployer returns.
+func (m *Model) cancelDeploy() {
+	state := m.getDeployState()
+	if state == nil || state.done || state.cancelling || state.cancel == nil {
+		return
+	}
+	state.cancelling = true
+	state.cancel()
+}
+
+// CanCancel returns true while a deployment is running and has not been
+// cancelled yet, so Ctrl+C cancels it instead of quitting.
+func (m *Model) CanCancel() bool {
+	if m.wizard.Phase != wizard.PhaseDeploy {
+		return false
+	}
+	state := m.getDeployState()
+	return state != nil && !state.done && !state.cancelling && state.cancel != nil
+}
+
+// CanRetry returns true when the deployment failed after completing stages
+// whose resources were kept, so it can be resumed.
+func (m *Model) CanRetry() bool {
+	state := m.getDeployState()
+	return state != nil && state.done && state.result != nil &&
+		!state.result.Success && state.result.Checkpoint.Resumable()
+}
+
+// retryDeploy resumes a failed deployment from its checkpoint, skipping
+// the stages it completed.
+func (m *Model) retryDeploy() tea.Cmd {
+	if !m.CanRetry() {
+		return nil
+	}
+	checkpoint := m.getDeployState().result.Checkpoint
+
+	m.wizard.Phase = wizard.PhaseDeploy
+	m.initPhase(m.wizard.Phase)
+	m.getDeployState().resume = checkpoint
+	return m.startDeploy()
+}
+
+// isStreamedLine reports whether next is another output line for the same
+// step as prev, so it can be shown in place instead of appended.
+func isStreamedLine(prev, next deploy.ProgressEvent) bool {
+	return !next.IsError && next.Detail != "" &&
+		prev.Stage == next.Stage && prev.Message == next.Message
+}
+
 // viewDeployPhase renders the Deploy phase
 func (m *Model) viewDeployPhase() string {
 	state := m.getDeployState()
@@ -274,6 +459,12 @@ func (m *Model) viewDeployPhase() string {
 
 	var b strings.Builder
 
+	if state.fleet != nil {
+		b.WriteString(m.viewFleetDeploy(state))
+		b.WriteString(m.viewDeployFooter(state))
+		return b.String()
+	}
+
 	// Header
 	deployerName := "Unknown"
 	if state.deployer != nil {
@@ -338,233 +529,48 @@ func (m *Model) viewDeployPhase() string {
 		}
 	}
 
-	// Spinner if still deploying
-	if !state.done && len(state.events) > 0 {
+	// Approval prompt or spinner if still deploying
+	if state.confirming {
+		b.WriteString("\n")
+		b.WriteString(warningStyle.Render("  Apply these changes? "))
+		b.WriteString(dimStyle.Render("[y] apply  [n] cancel"))
+		b.WriteString("\n")
+	} else if !state.done && len(state.events) > 0 {
 		b.WriteString("\n")
 		b.WriteString("  ")
 		b.WriteString(state.spinner.View())
-		b.WriteString(" Working...")
+		if state.cancelling {
+			b.WriteString(" Cancelling...")
+		} else {
+			b.WriteString(" Working...")
+		}
 		b.WriteString("\n")
 	}
 
-	// Footer
+	b.WriteString(m.viewDeployFooter(state))
+
+	return b.String()
+}
+
+// viewDeployFooter renders the hint below the deployment progress
+func (m *Model) viewDeployFooter(state *deployState) string {
+	var b strings.Builder
+
 	b.WriteString("\n")
 	if state.done {
 		if state.result != nil && state.result.Success {
 			b.WriteString(dimStyle.Render("Press Enter to view results"))
+		} else if m.CanRetry() {
+			b.WriteString(dimStyle.Render("Press r to retry from the last completed stage, Enter to continue"))
 		} else {
 			b.WriteString(dimStyle.Render("Press Enter to continue"))
 		}
+	} else if state.cancelling {
+		b.WriteString(dimStyle.Render("Cancelling deployment, press Ctrl+C to quit without cleanup"))
 	} else {
-		b.WriteString(dimStyle.Render("Deployment in progress..."))
+		b.WriteString(dimStyle.Render("Deployment in progress, press Esc to cancel"))
 	}
 	b.WriteString("\n")
 
 	return b.String()
 }
-
-// configOnlyDeployer is a simple deployer for config-only generation
-type configOnlyDeployer struct {
-	projectDir   string
-	outputDir    string
-	generateYAML bool
-	registry     *packages.Registry
-}
-
-func (d *configOnlyDeployer) Name() string {
-	return "Config Generator"
-}
-
-func (d *configOnlyDeployer) Target() deploy.DeploymentTarget {
-	return deploy.TargetConfigOnly
-}
-
-func (d *configOnlyDeployer) Validate(opts *deploy.DeployOptions) error {
-	if d.registry == nil {
-		return fmt.Errorf("package registry not available - cannot generate summary")
-	}
-	return nil
-}
-
-func (d *configOnlyDeployer) Deploy(ctx context.Context, opts *deploy.DeployOptions, progress deploy.ProgressCallback) (*deploy.DeployResult, error) {
-	cfg := opts.Config
-	outputDir := d.outputDir
-	if outputDir == "" {
-		outputDir = "."
-	}
-
-	// Ensure output directory exists
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Helper to safely call progress callback
-	reportProgress := func(event deploy.ProgressEvent) {
-		if progress != nil {
-			progress(event)
-		}
-	}
-
-	reportProgress(deploy.ProgressEvent{
-		Stage:   deploy.StageConfig,
-		Message: "Generating configuration files...",
-		Percent: 10,
-	})
-
-	// Generate config.env
-	reportProgress(deploy.ProgressEvent{
-		Stage:   deploy.StageConfig,
-		Message: "Writing config.env...",
-		Percent: 25,
-	})
-
-	configEnvPath := filepath.Join(outputDir, "config.env")
-	if err := d.writeConfigEnv(configEnvPath, cfg); err != nil {
-		return nil, fmt.Errorf("failed to write config.env: %w", err)
-	}
-
-	// Generate summary.md
-	reportProgress(deploy.ProgressEvent{
-		Stage:   deploy.StageConfig,
-		Message: "Writing summary.md...",
-		Percent: 40,
-	})
-
-	summaryPath := filepath.Join(outputDir, "summary.md")
-	if err := generator.GenerateSummary(cfg, d.registry, summaryPath); err != nil {
-		return nil, fmt.Errorf("failed to write summary.md: %w", err)
-	}
-
-	// Generate secrets.env
-	reportProgress(deploy.ProgressEvent{
-		Stage:   deploy.StageConfig,
-		Message: "Writing cloud-init/secrets.env...",
-		Percent: 55,
-	})
-
-	secretsDir := filepath.Join(outputDir, "cloud-init")
-	if err := os.MkdirAll(secretsDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create cloud-init directory: %w", err)
-	}
-
-	secretsEnvPath := filepath.Join(secretsDir, "secrets.env")
-	if err := d.writeSecretsEnv(secretsEnvPath, cfg); err != nil {
-		return nil, fmt.Errorf("failed to write secrets.env: %w", err)
-	}
-
-	// Generate cloud-init.yaml if requested
-	if d.generateYAML {
-		reportProgress(deploy.ProgressEvent{
-			Stage:   deploy.StageConfig,
-			Message: "Writing cloud-init/cloud-init.yaml...",
-			Percent: 75,
-		})
-
-		outputPath := filepath.Join(secretsDir, "cloud-init.yaml")
-
-		if err := generator.Generate(cfg, outputPath); err != nil {
-			return nil, fmt.Errorf("failed to generate cloud-init.yaml: %w", err)
-		}
-	}
-
-	reportProgress(deploy.ProgressEvent{
-		Stage:   deploy.StageComplete,
-		Message: "Configuration files generated successfully",
-		Percent: 100,
-	})
-
-	outputs := map[string]string{
-		"config.env":  configEnvPath,
-		"secrets.env": secretsEnvPath,
-		"summary.md":  summaryPath,
-	}
-	if d.generateYAML {
-		outputs["cloud-init.yaml"] = filepath.Join(secretsDir, "cloud-init.yaml")
-	}
-
-	return &deploy.DeployResult{
-		Success: true,
-		Outputs: outputs,
-	}, nil
-}
-
-// writeConfigEnv writes the config.env file
-func (d *configOnlyDeployer) writeConfigEnv(path string, cfg *config.FullConfig) error {
-	var b strings.Builder
-
-	b.WriteString("# Generated by ucli - Configuration File\n")
-	b.WriteString("# This file contains non-sensitive configuration\n\n")
-
-	// User configuration
-	b.WriteString("# User Configuration\n")
-	b.WriteString(fmt.Sprintf("USERNAME=%q\n", cfg.Username))
-	b.WriteString(fmt.Sprintf("HOSTNAME=%q\n", cfg.Hostname))
-	b.WriteString(fmt.Sprintf("USER_NAME=%q\n", cfg.FullName))
-	b.WriteString(fmt.Sprintf("USER_EMAIL=%q\n", cfg.Email))
-	b.WriteString(fmt.Sprintf("MACHINE_USER_NAME=%q\n", cfg.MachineName))
-	b.WriteString("\n")
-
-	// Git configuration
-	b.WriteString("# Git Configuration\n")
-	b.WriteString(fmt.Sprintf("GIT_DEFAULT_BRANCH=%q\n", "main"))
-	b.WriteString(fmt.Sprintf("GIT_PUSH_AUTO_SETUP_REMOTE=%t\n", true))
-	b.WriteString(fmt.Sprintf("GIT_PULL_REBASE=%t\n", true))
-	b.WriteString("\n")
-
-	// Package configuration
-	b.WriteString("# Package Configuration\n")
-	for _, pkg := range cfg.EnabledPackages {
-		envVar := "PACKAGE_" + strings.ToUpper(strings.ReplaceAll(pkg, "-", "_")) + "_ENABLED"
-		b.WriteString(fmt.Sprintf("export %s=true\n", envVar))
-	}
-	for _, pkg := range cfg.DisabledPackages {
-		envVar := "PACKAGE_" + strings.ToUpper(strings.ReplaceAll(pkg, "-", "_")) + "_ENABLED"
-		b.WriteString(fmt.Sprintf("export %s=false\n", envVar))
-	}
-
-	return os.WriteFile(path, []byte(b.String()), 0644)
-}
-
-// writeSecretsEnv writes the secrets.env file
-func (d *configOnlyDeployer) writeSecretsEnv(path string, cfg *config.FullConfig) error {
-	var b strings.Builder
-
-	b.WriteString("# Generated by ucli - Secrets File\n")
-	b.WriteString("# This file contains sensitive configuration - DO NOT COMMIT\n\n")
-
-	// SSH keys
-	b.WriteString("# SSH Keys\n")
-	for i, key := range cfg.SSHPublicKeys {
-		b.WriteString(fmt.Sprintf("SSH_PUBLIC_KEY_%d=%q\n", i+1, key))
-	}
-	if len(cfg.SSHPublicKeys) > 0 {
-		b.WriteString(fmt.Sprintf("SSH_PUBLIC_KEY=%q\n", cfg.SSHPublicKeys[0]))
-	}
-	b.WriteString("\n")
-
-	// Tailscale
-	if cfg.TailscaleAuthKey != "" {
-		b.WriteString("# Tailscale\n")
-		b.WriteString(fmt.Sprintf("TAILSCALE_AUTH_KEY=%q\n", cfg.TailscaleAuthKey))
-		b.WriteString("\n")
-	}
-
-	// GitHub
-	if cfg.GithubUser != "" || cfg.GithubPAT != "" {
-		b.WriteString("# GitHub\n")
-		if cfg.GithubUser != "" {
-			b.WriteString(fmt.Sprintf("GITHUB_USER=%q\n", cfg.GithubUser))
-		}
-		if cfg.GithubPAT != "" {
-			b.WriteString(fmt.Sprintf("GITHUB_PAT=%q\n", cfg.GithubPAT))
-		}
-		b.WriteString("\n")
-	}
-
-	return os.WriteFile(path, []byte(b.String()), 0600) // More restrictive permissions for secrets
-}
-
-func (d *configOnlyDeployer) Cleanup(ctx context.Context, opts *deploy.DeployOptions) error {
-	// Nothing to cleanup for config-only generation
-	return nil
-}
@@ -0,0 +1,377 @@
+package create
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/create/wizard"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/multipass"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/terragrunt"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
+)
+
+// fleetState holds the progress of a fleet deployment, one row per machine
+type fleetState struct {
+	machines []*fleetMachine
+	events   chan fleet.Event
+	results  fleet.Results
+
+	// Machines waiting at StageConfirming, answered in order
+	confirming []string
+	approvals  map[string]chan bool
+}
+
+// fleetMachine is one row of the fleet view
+type fleetMachine struct {
+	name     string
+	hostname string
+	last     deploy.ProgressEvent
+	started  bool
+	result   *fleet.Result
+}
+
+// status returns the machine's status for display.
+func (fm *fleetMachine) status() string {
+	switch {
+	case fm.result != nil:
+		return string(fm.result.Status)
+	case fm.started:
+		return "running"
+	default:
+		return string(fleet.StatusPending)
+	}
+}
+
+// saveFleetOptions saves the machine count and failure policy fields
+func (m *Model) saveFleetOptions() {
+	m.wizard.Data.Fleet = wizard.FleetOptions{
+		Count:  GetMachineCountValue(m.wizard.SelectIdxs["machines"]),
+		Policy: GetFailurePolicyValue(m.wizard.SelectIdxs["on_failure"]),
+	}
+}
+
+// isFleet reports whether the wizard creates more than one machine
+func (m *Model) isFleet() bool {
+	return m.wizard.Data.Fleet.Count > 1 && m.wizard.Data.Target != deploy.TargetConfigOnly
+}
+
+// fleetNames returns the names and hostnames of the fleet's machines, after
+// the VM name and hostname from the wizard
+func (m *Model) fleetNames() (names, hostnames []string) {
+	data := &m.wizard.Data
+
+	base := data.MultipassOpts.VMName
+	if data.Target == deploy.TargetTerragrunt {
+		base = data.TerragruntOpts.VMName
+	}
+	hostname := data.Hostname
+	if hostname == "" {
+		hostname = base
+	}
+
+	return fleet.Names(base, data.Fleet.Count), fleet.Names(hostname, data.Fleet.Count)
+}
+
+// newFleetState creates a row for each machine of the fleet
+func (m *Model) newFleetState() *fleetState {
+	names, hostnames := m.fleetNames()
+	fs := &fleetState{
+		events:    make(chan fleet.Event, 100),
+		approvals: make(map[string]chan bool),
+	}
+	for i, name := range names {
+		fs.machines = append(fs.machines, &fleetMachine{name: name, hostname: hostnames[i]})
+		fs.approvals[name] = make(chan bool, 1)
+	}
+	return fs
+}
+
+// runFleet deploys every machine of the fleet in the background, reporting
+// events on the fleet's channel
+func (m *Model) runFleet(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		state := m.getDeployState()
+		fs := state.fleet
+
+		base, err := m.buildDeployOptions()
+		if err != nil {
+			close(fs.events)
+			return deployCompleteMsg{result: &deploy.DeployResult{
+				Success: false,
+				Target:  m.wizard.Data.Target,
+				Error:   err,
+			}}
+		}
+
+		machines := make([]fleet.Machine, 0, len(fs.machines))
+		for _, fm := range fs.machines {
+			cfg := *base.Config
+			cfg.Hostname = fm.hostname
+			opts := *base
+			opts.Config = &cfg
+
+			var deployer deploy.Deployer
+			switch m.wizard.Data.Target {
+			case deploy.TargetMultipass:
+				opts.Multipass.VMName = fm.name
+				opts.CloudInitPath = multipass.CloudInitPath(opts.ProjectRoot, fm.name)
+				deployer = multipass.New()
+			case deploy.TargetTerragrunt:
+				opts.Terragrunt.VMName = fm.name
+				if opts.Terragrunt.Apply && !opts.Terragrunt.AutoApprove {
+					approve := fs.approvals[fm.name]
+					opts.Terragrunt.Approve = func(ctx context.Context, _ string) (bool, error) {
+						select {
+						case ok := <-approve:
+							return ok, nil
+						case <-ctx.Done():
+							return false, ctx.Err()
+						}
+					}
+				}
+				deployer = terragrunt.New(m.projectDir)
+			}
+			machines = append(machines, fleet.Machine{Name: fm.name, Deployer: deployer, Options: &opts})
+		}
+
+		fleetOpts := fleet.Options{
+			Parallel: fleet.DefaultParallel,
+			Policy:   m.wizard.Data.Fleet.Policy,
+			Journal:  true,
+		}
+		results := fleet.Run(ctx, machines, fleetOpts, func(e fleet.Event) {
+			fs.events <- e
+		})
+
+		// Signal completion
+		close(fs.events)
+
+		return fleetCompleteMsg{results: results}
+	}
+}
+
+// waitForFleetProgress waits for the next event from any machine
+func (m *Model) waitForFleetProgress() tea.Cmd {
+	return func() tea.Msg {
+		state := m.getDeployState()
+		if state == nil || state.fleet == nil {
+			return nil
+		}
+
+		event, ok := <-state.fleet.events
+		if !ok {
+			return nil // Channel closed
+		}
+		return fleetProgressMsg(event)
+	}
+}
+
+// handleFleetProgress records an event on its machine's row
+func (m *Model) handleFleetProgress(state *deployState, e fleet.Event) tea.Cmd {
+	fs := state.fleet
+	for _, fm := range fs.machines {
+		if fm.name != e.Machine {
+			continue
+		}
+		fm.started = true
+		if e.Percent < 0 {
+			e.Percent = fm.last.Percent // Cleanup events keep the last percentage
+		}
+		fm.last = e.ProgressEvent
+	}
+	if e.Stage == deploy.StageConfirming {
+		fs.confirming = append(fs.confirming, e.Machine)
+	}
+
+	return tea.Batch(
+		m.waitForFleetProgress(),
+		state.progressBar.SetPercent(float64(fs.percent())/100.0),
+	)
+}
+
+// answerFleetApproval answers the plan approval of the first machine
+// waiting for one
+func (fs *fleetState) answerFleetApproval(ok bool) {
+	if len(fs.confirming) == 0 {
+		return
+	}
+	name := fs.confirming[0]
+	fs.confirming = fs.confirming[1:]
+	fs.approvals[name] <- ok
+}
+
+// complete records the results of the fleet on each row and returns an
+// aggregate result for the Complete phase
+func (fs *fleetState) complete(target deploy.DeploymentTarget, results fleet.Results) *deploy.DeployResult {
+	fs.results = results
+	fs.confirming = nil
+
+	result := &deploy.DeployResult{
+		Success: results.Success(),
+		Target:  target,
+		Error:   results.Err(),
+	}
+	for i := range results {
+		fs.machines[i].result = &results[i]
+		if r := results[i].Result; r != nil {
+			result.Duration = max(result.Duration, r.Duration)
+		}
+	}
+	return result
+}
+
+// percent returns the average progress of the machines
+func (fs *fleetState) percent() int {
+	if len(fs.machines) == 0 {
+		return 0
+	}
+	total := 0
+	for _, fm := range fs.machines {
+		p := fm.last.Percent
+		if fm.result != nil {
+			p = 100 // Finished, whatever the outcome
+		}
+		total += min(max(p, 0), 100)
+	}
+	return total / len(fs.machines)
+}
+
+// viewFleetDeploy renders one progress row per machine of the fleet
+func (m *Model) viewFleetDeploy(state *deployState) string {
+	var b strings.Builder
+	fs := state.fleet
+
+	deployerName := "Unknown"
+	if state.deployer != nil {
+		deployerName = state.deployer.Name()
+	}
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Deploying %d machines to %s", len(fs.machines), deployerName)))
+	b.WriteString("\n\n")
+
+	percent := fs.percent()
+	b.WriteString(progressBarStyle.Render(state.progressBar.ViewAs(float64(percent) / 100.0)))
+	b.WriteString(fmt.Sprintf(" %d%%", percent))
+	b.WriteString("\n\n")
+
+	width := 0
+	for _, fm := range fs.machines {
+		width = max(width, len(fm.name))
+	}
+
+	for _, fm := range fs.machines {
+		status := fm.status()
+
+		icon := "  "
+		msgStyle := dimStyle
+		switch {
+		case fm.last.IsError || status == string(fleet.StatusFailed) || status == string(fleet.StatusCancelled):
+			icon = errorStyle.Render("  ")
+			msgStyle = errorStyle
+		case status == string(fleet.StatusSucceeded):
+			icon = successStyle.Render("  ")
+			msgStyle = successStyle
+		case status == "running":
+			icon = activeStyle.Render("  ")
+		}
+
+		message := fm.last.Message
+		if message == "" {
+			message = "Waiting to start"
+		}
+
+		b.WriteString(icon)
+		b.WriteString(labelStyle.Render(fmt.Sprintf("%-*s ", width, fm.name)))
+		b.WriteString(msgStyle.Render(fmt.Sprintf("%-10s %3d%%  %s", status, max(fm.last.Percent, 0), message)))
+		b.WriteString("\n")
+	}
+
+	if len(fs.confirming) > 0 {
+		b.WriteString("\n")
+		b.WriteString(warningStyle.Render(fmt.Sprintf("  Apply these changes to %s? ", fs.confirming[0])))
+		b.WriteString(dimStyle.Render("[y] apply  [n] cancel"))
+		b.WriteString("\n")
+	} else if !state.done {
+		b.WriteString("\n")
+		b.WriteString("  ")
+		b.WriteString(state.spinner.View())
+		if state.cancelling {
+			b.WriteString(" Cancelling...")
+		} else {
+			b.WriteString(" Working...")
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// viewFleetResults renders the aggregate result table of a fleet
+func (m *Model) viewFleetResults(fs *fleetState) string {
+	var b strings.Builder
+
+	if fs.results.Success() {
+		b.WriteString(successStyle.Render(fmt.Sprintf("  %d Machines Deployed", len(fs.results))))
+	} else {
+		b.WriteString(errorStyle.Render("  Fleet Deployment Failed"))
+	}
+	b.WriteString("\n\n")
+
+	width := len("NAME")
+	for _, r := range fs.results {
+		width = max(width, len(r.Name))
+	}
+	row := fmt.Sprintf("  %%-%ds  %%-10s %%-16s %%-10s %%s", width)
+
+	b.WriteString(labelStyle.Render(fmt.Sprintf(row, "NAME", "STATUS", "ADDRESS", "DURATION", "JOURNAL")))
+	b.WriteString("\n")
+	for _, r := range fs.results {
+		address, duration, id := r.Address(), "-", r.JournalID
+		if address == "" {
+			address = "-"
+		}
+		if r.Result != nil && r.Result.Duration > 0 {
+			duration = r.Result.Duration.Round(1e6).String()
+		}
+		if id == "" {
+			id = "-"
+		}
+
+		style := valueStyle
+		switch r.Status {
+		case fleet.StatusSucceeded:
+			style = successStyle
+		case fleet.StatusFailed, fleet.StatusCancelled:
+			style = errorStyle
+		case fleet.StatusSkipped, fleet.StatusRemoved:
+			style = dimStyle
+		}
+		b.WriteString(style.Render(fmt.Sprintf(row, r.Name, r.Status, address, duration, id)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	first := true
+	for _, r := range fs.results {
+		if r.Status == fleet.StatusSucceeded || r.Error() == "" {
+			continue
+		}
+		if first {
+			b.WriteString(errorStyle.Render("Errors:"))
+			b.WriteString("\n")
+			first = false
+		}
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  %s: %s", r.Name, r.Error())))
+		b.WriteString("\n")
+	}
+	if !first {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(dimStyle.Render(fmt.Sprintf("%d machines: %s", len(fs.results), fs.results.Summary())))
+	b.WriteString("\n\n")
+
+	return b.String()
+}
@@ -3,6 +3,7 @@ package create
 import (
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/create/wizard"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/packages"
 )
 
@@ -25,6 +26,14 @@ type deployCompleteMsg struct {
 	result *deploy.DeployResult
 }
 
+// fleetProgressMsg wraps a progress event from one machine of a fleet.
+type fleetProgressMsg fleet.Event
+
+// fleetCompleteMsg is sent when every machine of a fleet has finished.
+type fleetCompleteMsg struct {
+	results fleet.Results
+}
+
 // targetValidatedMsg is sent when target validation completes.
 type targetValidatedMsg struct {
 	err error
@@ -47,17 +47,17 @@ type Model struct {
 // New creates a new Create VM model
 func New(projectDir string, store *settings.Store) *Model {
 	m := &Model{
-		BaseTab:       app.NewBaseTab(app.TabCreate, "Create", "1"),
+		BaseTab:       app.NewBaseTab(app.TabCreate, "Create", "2"),
 		projectDir:    projectDir,
 		store:         store,
 		wizard:        wizard.NewState(),
 		phaseRegistry: phases.NewRegistry(),
 	}
 
-	// Load cloud images from settings
+	// Load cloud images from settings, default first
 	if store != nil {
 		if s, err := store.Load(); err == nil {
-			m.cloudImages = s.CloudImages
+			m.cloudImages = s.CloudImagesDefaultFirst()
 		}
 	}
 
@@ -81,11 +81,11 @@ func (m *Model) Init() tea.Cmd {
 	return nil
 }
 
-// loadPackages loads the package registry from embedded scripts
+// loadPackages loads the package registry from embedded scripts and overlays
 func (m *Model) loadPackages() tea.Cmd {
 	m.loadingPackages = true
 	return func() tea.Msg {
-		registry, err := packages.DiscoverEmbedded()
+		registry, err := packages.DiscoverWithOverlays(m.projectDir)
 		return packagesLoadedMsg{registry: registry, err: err}
 	}
 }
@@ -103,8 +103,8 @@ func (m *Model) Update(msg tea.Msg) (app.Tab, tea.Cmd) {
 			m.message = "Error loading packages: " + msg.err.Error()
 		} else {
 			m.wizard.Registry = msg.registry
-			// Initialize all packages as selected by default
-			for _, name := range msg.registry.Names() {
+			// Preselect packages marked DEFAULT="true"
+			for _, name := range msg.registry.Defaults() {
 				m.wizard.PackageSelected[name] = true
 			}
 		}
@@ -152,7 +152,7 @@ func (m *Model) Update(msg tea.Msg) (app.Tab, tea.Cmd) {
 		m.initPhase(m.wizard.Phase)
 		return m, nil
 
-	case deployProgressMsg, deployCompleteMsg, spinner.TickMsg, progress.FrameMsg:
+	case deployProgressMsg, deployCompleteMsg, fleetProgressMsg, fleetCompleteMsg, spinner.TickMsg, progress.FrameMsg:
 		// Route deploy messages to the deploy phase handler
 		if m.wizard.Phase == wizard.PhaseDeploy {
 			return m.handleDeployPhase(msg)
@@ -427,8 +427,17 @@ func (m *Model) KeyBindings() []string {
 	case wizard.PhaseReview:
 		return []string{"[Enter] deploy", "[Esc] back"}
 	case wizard.PhaseDeploy:
+		if m.CanCancel() {
+			return []string{"Deploying...", "[Esc] cancel"}
+		}
+		if m.CanRetry() {
+			return []string{"[r] retry", "[Enter] continue"}
+		}
 		return []string{"Deploying..."}
 	case wizard.PhaseComplete:
+		if m.CanRetry() {
+			return []string{"[r] retry", "[Enter] new", "[1] view VMs"}
+		}
 		return []string{"[Enter] new", "[1] view VMs"}
 	default:
 		bindings := []string{"[↑/↓] navigate", "[Enter] continue"}
@@ -456,6 +465,11 @@ func (m *Model) SelectedTarget() deploy.DeploymentTarget {
 
 // HasFocusedInput returns true if a text input is currently focused
 func (m *Model) HasFocusedInput() bool {
+	// The plan approval prompt takes all keys
+	if state := m.getDeployState(); state != nil && state.confirming {
+		return true
+	}
+
 	// Check if we're in a phase with text inputs
 	switch m.wizard.Phase {
 	case wizard.PhaseTargetOptions, wizard.PhaseSSH, wizard.PhaseGit, wizard.PhaseHost, wizard.PhaseOptional:
@@ -1,6 +1,8 @@
 package create
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -8,7 +10,9 @@ import (
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/create/phases"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/app/views/create/wizard"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -16,7 +20,7 @@ func TestNew(t *testing.T) {
 
 	assert.Equal(t, app.TabCreate, m.ID())
 	assert.Equal(t, "Create", m.Name())
-	assert.Equal(t, "1", m.ShortKey())
+	assert.Equal(t, "2", m.ShortKey())
 	assert.Equal(t, "/test/project", m.ProjectDir())
 	assert.Equal(t, wizard.PhaseTarget, m.wizard.Phase)
 }
@@ -260,3 +264,203 @@ func TestPhase_String(t *testing.T) {
 		})
 	}
 }
+
+func TestModel_DeployPhase_StreamedOutput(t *testing.T) {
+	m := New("/test/project", nil)
+	m.wizard.Phase = wizard.PhaseDeploy
+	m.wizard.Data.Target = deploy.TargetTerragrunt
+	m.initDeployPhase()
+	state := m.getDeployState()
+	require.NotNil(t, state)
+
+	m.handleDeployPhase(deployProgressMsg(deploy.NewProgressEventWithCommand(deploy.StageApplying, "Applying changes...", "terragrunt apply", 70)))
+	m.handleDeployPhase(deployProgressMsg(deploy.NewProgressEventWithDetail(deploy.StageApplying, "Applying changes...", "Creating...", 70)))
+	m.handleDeployPhase(deployProgressMsg(deploy.NewProgressEventWithDetail(deploy.StageApplying, "Applying changes...", "Apply complete!", 70)))
+
+	require.Len(t, state.events, 1, "output lines replace each other")
+	assert.Equal(t, "Apply complete!", state.events[0].Detail)
+	assert.Equal(t, "terragrunt apply", state.events[0].Command)
+}
+
+func TestModel_DeployPhase_Approval(t *testing.T) {
+	m := New("/test/project", nil)
+	m.wizard.Phase = wizard.PhaseDeploy
+	m.wizard.Data.Target = deploy.TargetTerragrunt
+	m.initDeployPhase()
+	state := m.getDeployState()
+	require.NotNil(t, state)
+
+	m.handleDeployPhase(deployProgressMsg(deploy.NewProgressEventWithDetail(
+		deploy.StageConfirming, "Waiting for approval...", "Plan: 3 to add, 0 to change, 0 to destroy.", 60)))
+	assert.True(t, state.confirming)
+	assert.True(t, m.HasFocusedInput(), "prompt captures keys")
+	assert.Contains(t, m.viewDeployPhase(), "Apply these changes?")
+
+	// Unrelated keys are ignored while confirming
+	m.handleDeployPhase(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, state.confirming)
+
+	m.handleDeployPhase(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	assert.False(t, state.confirming)
+	assert.True(t, <-state.approveChan)
+}
+
+func TestModel_DeployPhase_Cancel(t *testing.T) {
+	m := New("/test/project", nil)
+	m.wizard.Phase = wizard.PhaseDeploy
+	m.wizard.Data.Target = deploy.TargetMultipass
+	m.initDeployPhase()
+	state := m.getDeployState()
+	require.NotNil(t, state)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	state.cancel = cancel
+	m.handleDeployPhase(deployProgressMsg(deploy.NewProgressEvent(deploy.StageLaunching, "Launching VM...", 35)))
+	assert.True(t, m.CanCancel())
+	assert.Contains(t, m.KeyBindings(), "[Esc] cancel")
+
+	m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.True(t, state.cancelling)
+	assert.False(t, m.CanCancel(), "a second Ctrl+C quits")
+	assert.Equal(t, wizard.PhaseDeploy, m.wizard.Phase)
+	assert.Contains(t, m.viewDeployPhase(), "Cancelling...")
+
+	m.handleDeployPhase(deployCompleteMsg{result: &deploy.DeployResult{Error: context.Canceled}})
+	assert.True(t, state.done)
+	assert.False(t, m.CanCancel())
+}
+
+func TestModel_DeployPhase_CancelWhileConfirming(t *testing.T) {
+	m := New("/test/project", nil)
+	m.wizard.Phase = wizard.PhaseDeploy
+	m.wizard.Data.Target = deploy.TargetTerragrunt
+	m.initDeployPhase()
+	state := m.getDeployState()
+	require.NotNil(t, state)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	state.cancel = cancel
+	m.handleDeployPhase(deployProgressMsg(deploy.NewProgressEventWithDetail(
+		deploy.StageConfirming, "Waiting for approval...", "Plan: 3 to add, 0 to change, 0 to destroy.", 60)))
+
+	m.handleDeployPhase(tea.KeyMsg{Type: tea.KeyCtrlC})
+	assert.False(t, state.confirming)
+	assert.True(t, state.cancelling)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestModel_DeployPhase_Retry(t *testing.T) {
+	m := New("/test/project", nil)
+	m.wizard.Phase = wizard.PhaseDeploy
+	m.wizard.Data.Target = deploy.TargetTerragrunt
+	m.initDeployPhase()
+	failed := m.getDeployState()
+
+	checkpoint := deploy.NewCheckpoint(deploy.TargetTerragrunt, &deploy.DeployOptions{})
+	checkpoint.Complete(deploy.StagePreparing, map[string]string{"config_dir": "/test/project/tf/web"})
+	m.handleDeployPhase(deployCompleteMsg{result: &deploy.DeployResult{
+		Error:      errors.New("terragrunt plan failed"),
+		Checkpoint: checkpoint,
+	}})
+	assert.True(t, m.CanRetry())
+	assert.Contains(t, m.KeyBindings(), "[r] retry")
+	assert.Contains(t, m.viewDeployPhase(), "Press r to retry")
+
+	// The failure screen offers the same retry
+	m.handleDeployPhase(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, wizard.PhaseComplete, m.wizard.Phase)
+	assert.Contains(t, m.viewCompletePhase(), "[r] to retry from the last completed stage")
+
+	_, cmd := m.handleCompletePhase(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	require.NotNil(t, cmd)
+	assert.Equal(t, wizard.PhaseDeploy, m.wizard.Phase)
+	state := m.getDeployState()
+	require.NotNil(t, state)
+	assert.NotSame(t, failed, state)
+	assert.Same(t, checkpoint, state.resume)
+	assert.True(t, m.CanCancel())
+	state.cancel()
+}
+
+func TestModel_CompletePhase_RetryWithoutCheckpoint(t *testing.T) {
+	m := New("/test/project", nil)
+	m.wizard.Phase = wizard.PhaseDeploy
+	m.wizard.Data.Target = deploy.TargetMultipass
+	m.initDeployPhase()
+
+	// Cleaned up deployments have nothing to resume, so r starts over
+	m.handleDeployPhase(deployCompleteMsg{result: &deploy.DeployResult{Error: errors.New("launch failed")}})
+	assert.False(t, m.CanRetry())
+	m.handleDeployPhase(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, m.viewCompletePhase(), "Press [Enter] or [r] to start over")
+
+	m.handleCompletePhase(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	assert.NotEqual(t, wizard.PhaseDeploy, m.wizard.Phase)
+	assert.NotEqual(t, wizard.PhaseComplete, m.wizard.Phase)
+}
+
+func TestModel_TargetOptions_Fleet(t *testing.T) {
+	m := New("/test/project", nil)
+	m.wizard.Data.Target = deploy.TargetTerragrunt
+	m.initTerragruntPhase()
+	assert.Contains(t, m.viewTerragruntPhase(), "On failure")
+
+	m.wizard.FocusedField = terragruntFieldMachines
+	m.cycleTerragruntOption(1)
+	m.cycleTerragruntOption(1)
+	m.wizard.FocusedField = terragruntFieldOnFailure
+	m.cycleTerragruntOption(-1)
+	m.saveTerragruntOptions()
+
+	assert.Equal(t, wizard.FleetOptions{Count: 3, Policy: fleet.PolicyCleanup}, m.wizard.Data.Fleet)
+	assert.True(t, m.isFleet())
+}
+
+func TestModel_DeployPhase_Fleet(t *testing.T) {
+	m := New("/test/project", nil)
+	m.wizard.Phase = wizard.PhaseDeploy
+	m.wizard.Data.Target = deploy.TargetTerragrunt
+	m.wizard.Data.TerragruntOpts.VMName = "web"
+	m.wizard.Data.Hostname = "box"
+	m.wizard.Data.Fleet = wizard.FleetOptions{Count: 3, Policy: fleet.PolicyAbort}
+	m.initDeployPhase()
+
+	state := m.getDeployState()
+	require.NotNil(t, state.fleet)
+	require.Len(t, state.fleet.machines, 3)
+	assert.Equal(t, "web-3", state.fleet.machines[2].name)
+	assert.Equal(t, "box-3", state.fleet.machines[2].hostname)
+
+	m.handleDeployPhase(fleetProgressMsg{Machine: "web-1", ProgressEvent: deploy.NewProgressEvent(deploy.StageConfirming, "Waiting for approval...", 60)})
+	m.handleDeployPhase(fleetProgressMsg{Machine: "web-2", ProgressEvent: deploy.NewProgressEvent(deploy.StagePreparing, "Creating config directory...", 20)})
+	view := m.viewDeployPhase()
+	assert.Contains(t, view, "Deploying 3 machines")
+	assert.Contains(t, view, "Creating config directory...")
+	assert.Contains(t, view, "pending")
+	assert.Contains(t, view, "Apply these changes to web-1?")
+	assert.Equal(t, 26, state.fleet.percent())
+
+	// Approvals are answered in the order machines asked
+	m.handleDeployPhase(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	assert.True(t, <-state.fleet.approvals["web-1"])
+	assert.Empty(t, state.fleet.confirming)
+
+	m.handleDeployPhase(fleetCompleteMsg{results: fleet.Results{
+		{Name: "web-1", Status: fleet.StatusSucceeded, Result: &deploy.DeployResult{Success: true, Outputs: map[string]string{"vm_ip": "10.0.0.5"}}},
+		{Name: "web-2", Status: fleet.StatusFailed, Result: &deploy.DeployResult{Error: errors.New("plan failed")}},
+		{Name: "web-3", Status: fleet.StatusSkipped},
+	}})
+	assert.True(t, state.done)
+	assert.False(t, state.result.Success)
+	assert.False(t, m.CanRetry())
+	assert.Equal(t, 100, state.fleet.percent())
+
+	m.handleDeployPhase(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, wizard.PhaseComplete, m.wizard.Phase)
+	view = m.viewCompletePhase()
+	assert.Contains(t, view, "Fleet Deployment Failed")
+	assert.Contains(t, view, "10.0.0.5")
+	assert.Contains(t, view, "web-2: plan failed")
+	assert.Contains(t, view, "3 machines: 1 succeeded, 1 failed, 1 skipped")
+}
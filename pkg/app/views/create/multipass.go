@@ -23,6 +23,8 @@ const (
 	multipassFieldMemory
 	multipassFieldDisk
 	multipassFieldKeepOnFailure
+	multipassFieldMachines
+	multipassFieldOnFailure
 	multipassFieldCount
 )
 
@@ -67,6 +69,8 @@ func (m *Model) initMultipassPhase() {
 	m.wizard.SelectIdxs["memory"] = 1  // 4 GB
 	m.wizard.SelectIdxs["disk"] = 1    // 20 GB
 	m.wizard.CheckStates["keep_on_failure"] = false
+	m.wizard.SelectIdxs["machines"] = 0   // 1 machine
+	m.wizard.SelectIdxs["on_failure"] = 0 // Continue
 }
 
 // handleMultipassPhase handles input for the Multipass options phase
@@ -138,6 +142,10 @@ func (m *Model) cycleMultipassOption(delta int) {
 		m.wizard.CycleSelect("memory", len(MemoryOptions), delta)
 	case multipassFieldDisk:
 		m.wizard.CycleSelect("disk", len(DiskOptions), delta)
+	case multipassFieldMachines:
+		m.wizard.CycleSelect("machines", len(MachineCountOptions), delta)
+	case multipassFieldOnFailure:
+		m.wizard.CycleSelect("on_failure", len(FailurePolicyOptions), delta)
 	}
 }
 
@@ -156,6 +164,7 @@ func (m *Model) saveMultipassOptions() {
 		DiskGB:        GetDiskValue(m.wizard.SelectIdxs["disk"]),
 		KeepOnFailure: m.wizard.CheckStates["keep_on_failure"],
 	}
+	m.saveFleetOptions()
 }
 
 // viewMultipassPhase renders the Multipass options phase
@@ -183,6 +192,10 @@ func (m *Model) viewMultipassPhase() string {
 	// Keep on failure checkbox
 	b.WriteString(wizard.RenderCheckbox(m.wizard, "Keep VM on failure", "keep_on_failure", multipassFieldKeepOnFailure))
 
+	// Fleet
+	b.WriteString(wizard.RenderSelectField(m.wizard, "Machines", "machines", multipassFieldMachines, GetMachineCountLabels()))
+	b.WriteString(wizard.RenderSelectField(m.wizard, "On failure", "on_failure", multipassFieldOnFailure, GetFailurePolicyLabels()))
+
 	return b.String()
 }
 
@@ -1,6 +1,8 @@
 // Package create provides shared option definitions for the wizard.
 package create
 
+import "github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
+
 // SelectOption represents a single option in a select field.
 type SelectOption[T any] struct {
 	Label string
@@ -28,6 +30,22 @@ var DiskOptions = []SelectOption[int]{
 	{Label: "40 GB", Value: 40},
 }
 
+// MachineCountOptions defines how many identical machines can be created.
+var MachineCountOptions = []SelectOption[int]{
+	{Label: "1", Value: 1},
+	{Label: "2", Value: 2},
+	{Label: "3", Value: 3},
+	{Label: "5", Value: 5},
+	{Label: "10", Value: 10},
+}
+
+// FailurePolicyOptions defines what happens to the other machines when one fails.
+var FailurePolicyOptions = []SelectOption[fleet.Policy]{
+	{Label: "Continue with the others", Value: fleet.PolicyContinue},
+	{Label: "Abort the rest", Value: fleet.PolicyAbort},
+	{Label: "Abort and remove all", Value: fleet.PolicyCleanup},
+}
+
 // GetCPULabels returns labels for CPU options.
 func GetCPULabels() []string {
 	return getLabels(CPUOptions)
@@ -43,6 +61,16 @@ func GetDiskLabels() []string {
 	return getLabels(DiskOptions)
 }
 
+// GetMachineCountLabels returns labels for machine count options.
+func GetMachineCountLabels() []string {
+	return getLabels(MachineCountOptions)
+}
+
+// GetFailurePolicyLabels returns labels for failure policy options.
+func GetFailurePolicyLabels() []string {
+	return getLabels(FailurePolicyOptions)
+}
+
 // getLabels extracts labels from a slice of SelectOptions.
 func getLabels[T any](options []SelectOption[T]) []string {
 	labels := make([]string, len(options))
@@ -75,3 +103,29 @@ func GetDiskValue(idx int) int {
 	}
 	return DiskOptions[idx].Value
 }
+
+// GetMachineCountValue returns the machine count at the given index.
+func GetMachineCountValue(idx int) int {
+	if idx < 0 || idx >= len(MachineCountOptions) {
+		return MachineCountOptions[0].Value // Default to one machine
+	}
+	return MachineCountOptions[idx].Value
+}
+
+// GetFailurePolicyLabel returns the label of policy.
+func GetFailurePolicyLabel(policy fleet.Policy) string {
+	for _, opt := range FailurePolicyOptions {
+		if opt.Value == policy {
+			return opt.Label
+		}
+	}
+	return FailurePolicyOptions[0].Label
+}
+
+// GetFailurePolicyValue returns the failure policy at the given index.
+func GetFailurePolicyValue(idx int) fleet.Policy {
+	if idx < 0 || idx >= len(FailurePolicyOptions) {
+		return FailurePolicyOptions[0].Value // Default to continue
+	}
+	return FailurePolicyOptions[idx].Value
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
 )
 
 func TestCPUOptions(t *testing.T) {
@@ -114,3 +116,18 @@ func TestGetMultipassImageValue(t *testing.T) {
 	assert.Equal(t, "24.04", GetMultipassImageValue(-1))
 	assert.Equal(t, "24.04", GetMultipassImageValue(99))
 }
+
+func TestMachineCountOptions(t *testing.T) {
+	assert.Equal(t, []string{"1", "2", "3", "5", "10"}, GetMachineCountLabels())
+	assert.Equal(t, 5, GetMachineCountValue(3))
+	assert.Equal(t, 1, GetMachineCountValue(-1))
+	assert.Equal(t, 1, GetMachineCountValue(99))
+}
+
+func TestFailurePolicyOptions(t *testing.T) {
+	assert.Len(t, GetFailurePolicyLabels(), 3)
+	assert.Equal(t, fleet.PolicyCleanup, GetFailurePolicyValue(2))
+	assert.Equal(t, fleet.PolicyContinue, GetFailurePolicyValue(99))
+	assert.Equal(t, "Abort the rest", GetFailurePolicyLabel(fleet.PolicyAbort))
+	assert.Equal(t, "Continue with the others", GetFailurePolicyLabel(""))
+}
@@ -43,9 +43,9 @@ func (p *PackagesPhase) Init(ctx *wizard.PhaseContext) {
 		ctx.Wizard.PackageSelected = make(map[string]bool)
 	}
 
-	// Initialize package selection to all selected by default
+	// Preselect packages marked DEFAULT="true"
 	if ctx.Wizard.Registry != nil && len(ctx.Wizard.PackageSelected) == 0 {
-		for _, name := range ctx.Wizard.Registry.Names() {
+		for _, name := range ctx.Wizard.Registry.Defaults() {
 			ctx.Wizard.PackageSelected[name] = true
 		}
 	}
@@ -139,7 +139,15 @@ func (p *PackagesPhase) Update(ctx *wizard.PhaseContext, msg tea.KeyMsg) (advanc
 		// Toggle package selection
 		if ctx.Wizard.FocusedField < len(pkgs) {
 			pkgName := pkgs[ctx.Wizard.FocusedField]
-			ctx.Wizard.PackageSelected[pkgName] = !ctx.Wizard.PackageSelected[pkgName]
+			if ctx.Wizard.PackageSelected[pkgName] {
+				p.deselect(ctx, pkgName)
+			} else {
+				ctx.Wizard.PackageSelected[pkgName] = true
+				if added := p.selectDependencies(ctx); len(added) > 0 {
+					p.setMessage(ctx, fmt.Sprintf("Also selected required packages: %s", strings.Join(added, ", ")))
+				}
+				p.warnConflicts(ctx)
+			}
 		}
 		return false, nil
 
@@ -148,6 +156,7 @@ func (p *PackagesPhase) Update(ctx *wizard.PhaseContext, msg tea.KeyMsg) (advanc
 		for _, pkg := range pkgs {
 			ctx.Wizard.PackageSelected[pkg] = true
 		}
+		p.warnConflicts(ctx)
 		return false, nil
 
 	case key.Matches(msg, key.NewBinding(key.WithKeys("n"))):
@@ -173,6 +182,79 @@ func (p *PackagesPhase) Update(ctx *wizard.PhaseContext, msg tea.KeyMsg) (advanc
 	return false, nil
 }
 
+// selectedNames returns the selected package names in sorted order.
+func selectedNames(ctx *wizard.PhaseContext) []string {
+	var names []string
+	for name, selected := range ctx.Wizard.PackageSelected {
+		if selected {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectDependencies selects the dependencies of every selected package and
+// returns the ones that were added.
+func (p *PackagesPhase) selectDependencies(ctx *wizard.PhaseContext) []string {
+	if ctx.Wizard.Registry == nil {
+		return nil
+	}
+
+	res, err := ctx.Wizard.Registry.Resolve(selectedNames(ctx))
+	if err != nil {
+		p.setMessage(ctx, fmt.Sprintf("Warning: %v", err))
+		return nil
+	}
+
+	for _, name := range res.Added {
+		ctx.Wizard.PackageSelected[name] = true
+	}
+	return res.Added
+}
+
+// deselect deselects name along with any selected packages that depend on it.
+func (p *PackagesPhase) deselect(ctx *wizard.PhaseContext, name string) {
+	ctx.Wizard.PackageSelected[name] = false
+	if ctx.Wizard.Registry == nil {
+		return
+	}
+
+	var removed []string
+	for _, dependent := range ctx.Wizard.Registry.Dependents(name) {
+		if ctx.Wizard.PackageSelected[dependent] {
+			ctx.Wizard.PackageSelected[dependent] = false
+			removed = append(removed, dependent)
+		}
+	}
+	if len(removed) > 0 {
+		p.setMessage(ctx, fmt.Sprintf("Also deselected packages that need %s: %s", name, strings.Join(removed, ", ")))
+	}
+}
+
+// warnConflicts reports conflicting packages in the selection.
+func (p *PackagesPhase) warnConflicts(ctx *wizard.PhaseContext) {
+	if ctx.Wizard.Registry == nil {
+		return
+	}
+
+	conflicts := ctx.Wizard.Registry.Conflicts(selectedNames(ctx))
+	if len(conflicts) == 0 {
+		return
+	}
+	msgs := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		msgs[i] = c.String()
+	}
+	p.setMessage(ctx, "Warning: "+strings.Join(msgs, "; "))
+}
+
+func (p *PackagesPhase) setMessage(ctx *wizard.PhaseContext, msg string) {
+	if ctx.Message != nil {
+		*ctx.Message = msg
+	}
+}
+
 // updatePresetPicker handles input when showing the preset picker.
 func (p *PackagesPhase) updatePresetPicker(ctx *wizard.PhaseContext, msg tea.KeyMsg) (advance bool, cmd tea.Cmd) {
 	switch {
@@ -206,6 +288,10 @@ func (p *PackagesPhase) updatePresetPicker(ctx *wizard.PhaseContext, msg tea.Key
 					*ctx.Message = fmt.Sprintf("Applied preset: %s (%d packages)", preset.Name, result.Applied)
 				}
 			}
+			if added := p.selectDependencies(ctx); len(added) > 0 && ctx.Message != nil {
+				*ctx.Message += fmt.Sprintf(", plus required: %s", strings.Join(added, ", "))
+			}
+			p.warnConflicts(ctx)
 		}
 		return false, nil
 	}
@@ -224,7 +310,7 @@ func (p *PackagesPhase) View(ctx *wizard.PhaseContext) string {
 	b.WriteString(wizard.TitleStyle.Render("Package Selection"))
 	b.WriteString("\n\n")
 
-	b.WriteString(wizard.DimStyle.Render("Select packages to install. All are selected by default."))
+	b.WriteString(wizard.DimStyle.Render("Select packages to install. Defaults are preselected."))
 	b.WriteString("\n")
 	b.WriteString(wizard.DimStyle.Render("[Space] toggle  [a] all  [n] none  [p] preset"))
 	b.WriteString("\n\n")
@@ -251,10 +337,13 @@ func (p *PackagesPhase) View(ctx *wizard.PhaseContext) string {
 			checkbox = "[✓]"
 		}
 
-		// Get package description
+		// Get package description and dependencies
 		desc := ""
 		if pkg := ctx.Wizard.Registry.Get(pkgName); pkg != nil {
 			desc = pkg.Description
+			if len(pkg.Depends) > 0 {
+				desc += " (needs " + strings.Join(pkg.Depends, ", ") + ")"
+			}
 		}
 
 		b.WriteString(cursor)
@@ -285,6 +374,11 @@ func (p *PackagesPhase) View(ctx *wizard.PhaseContext) string {
 	b.WriteString(wizard.ValueStyle.Render(strconv.Itoa(selectedCount) + "/" + strconv.Itoa(len(pkgs)) + " packages selected"))
 	b.WriteString("\n")
 
+	for _, c := range ctx.Wizard.Registry.Conflicts(selectedNames(ctx)) {
+		b.WriteString(wizard.WarningStyle.Render("⚠ " + c.String()))
+		b.WriteString("\n")
+	}
+
 	return b.String()
 }
 
@@ -16,14 +16,14 @@ func createTestContextWithPackages() *wizard.PhaseContext {
 	// Create a real registry with test packages
 	registry := &packages.Registry{
 		Packages: []packages.Package{
-			{Name: "docker", Description: "Container runtime"},
-			{Name: "git", Description: "Version control"},
-			{Name: "neovim", Description: "Text editor"},
+			{Name: "docker", Description: "Container runtime", Default: true},
+			{Name: "git", Description: "Version control", Default: true},
+			{Name: "neovim", Description: "Text editor", Default: true},
 		},
 		ByName: map[string]packages.Package{
-			"docker": {Name: "docker", Description: "Container runtime"},
-			"git":    {Name: "git", Description: "Version control"},
-			"neovim": {Name: "neovim", Description: "Text editor"},
+			"docker": {Name: "docker", Description: "Container runtime", Default: true},
+			"git":    {Name: "git", Description: "Version control", Default: true},
+			"neovim": {Name: "neovim", Description: "Text editor", Default: true},
 		},
 	}
 	ctx.Wizard.Registry = registry
@@ -49,6 +49,16 @@ func TestPackagesPhase_Init(t *testing.T) {
 	assert.True(t, ctx.Wizard.PackageSelected["neovim"])
 }
 
+func TestPackagesPhase_Init_Defaults(t *testing.T) {
+	p := NewPackagesPhase()
+	ctx := createTestContextWithDependencies()
+
+	p.Init(ctx)
+
+	assert.True(t, ctx.Wizard.PackageSelected["docker"])
+	assert.False(t, ctx.Wizard.PackageSelected["podman"], "DEFAULT=\"false\" packages start deselected")
+}
+
 func TestPackagesPhase_Init_NoPackages(t *testing.T) {
 	p := NewPackagesPhase()
 	ctx := newTestContext() // No registry
@@ -284,6 +294,67 @@ func TestPackagesPhase_Save_IsSorted(t *testing.T) {
 	}
 }
 
+// createTestContextWithDependencies creates a test context whose packages
+// depend on homebrew, with docker and podman conflicting.
+func createTestContextWithDependencies() *wizard.PhaseContext {
+	ctx := newTestContext()
+	msg := ""
+	ctx.Message = &msg
+
+	registry := packages.NewRegistry()
+	registry.Add(packages.Package{Name: "bat", Default: true, Depends: []string{"homebrew"}})
+	registry.Add(packages.Package{Name: "docker", Default: true, Conflicts: []string{"podman"}})
+	registry.Add(packages.Package{Name: "homebrew", Default: true})
+	registry.Add(packages.Package{Name: "lazydocker", Default: true, Depends: []string{"homebrew", "docker"}})
+	registry.Add(packages.Package{Name: "podman"})
+	ctx.Wizard.Registry = registry
+
+	return ctx
+}
+
+func TestPackagesPhase_Toggle_SelectsDependencies(t *testing.T) {
+	p := NewPackagesPhase()
+	ctx := createTestContextWithDependencies()
+	p.Init(ctx)
+	p.Update(ctx, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+
+	ctx.Wizard.FocusedField = 3 // lazydocker
+	p.Update(ctx, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{' '}})
+
+	assert.True(t, ctx.Wizard.PackageSelected["lazydocker"])
+	assert.True(t, ctx.Wizard.PackageSelected["homebrew"])
+	assert.True(t, ctx.Wizard.PackageSelected["docker"])
+	assert.False(t, ctx.Wizard.PackageSelected["bat"])
+	assert.Equal(t, "Also selected required packages: homebrew, docker", *ctx.Message)
+}
+
+func TestPackagesPhase_Toggle_DeselectsDependents(t *testing.T) {
+	p := NewPackagesPhase()
+	ctx := createTestContextWithDependencies()
+	p.Init(ctx)
+
+	ctx.Wizard.FocusedField = 2 // homebrew
+	p.Update(ctx, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{' '}})
+
+	assert.False(t, ctx.Wizard.PackageSelected["homebrew"])
+	assert.False(t, ctx.Wizard.PackageSelected["bat"])
+	assert.False(t, ctx.Wizard.PackageSelected["lazydocker"])
+	assert.True(t, ctx.Wizard.PackageSelected["docker"])
+	assert.Equal(t, "Also deselected packages that need homebrew: bat, lazydocker", *ctx.Message)
+}
+
+func TestPackagesPhase_Conflicts(t *testing.T) {
+	p := NewPackagesPhase()
+	ctx := createTestContextWithDependencies()
+	p.Init(ctx)
+
+	p.Update(ctx, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+
+	assert.Equal(t, "Warning: docker conflicts with podman", *ctx.Message)
+	assert.Contains(t, p.View(ctx), "docker conflicts with podman")
+	assert.Contains(t, p.View(ctx), "(needs homebrew, docker)")
+}
+
 func TestPackagesPhase_ImplementsPhaseHandler(t *testing.T) {
 	var _ wizard.PhaseHandler = (*PackagesPhase)(nil)
 }
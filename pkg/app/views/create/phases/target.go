@@ -45,7 +45,7 @@ var Targets = []TargetItem{
 	{
 		Target:      deploy.TargetTerragrunt,
 		Name:        "Terragrunt/libvirt",
-		Description: "Generate Terragrunt config for libvirt VM (run manually)",
+		Description: "Generate Terragrunt config for libvirt VM and optionally apply it",
 		Icon:        "🖥️ ",
 	},
 	{
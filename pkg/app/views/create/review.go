@@ -363,6 +363,13 @@ func (m *Model) viewTargetSpecificReview() string {
 		b.WriteString("\n")
 		b.WriteString(labelStyle.Render("Libvirt URI: "))
 		b.WriteString(valueStyle.Render(opts.LibvirtURI))
+		b.WriteString("\n")
+		b.WriteString(labelStyle.Render("Mode: "))
+		if opts.Apply {
+			b.WriteString(valueStyle.Render(terragruntModeLabels[1]))
+		} else {
+			b.WriteString(valueStyle.Render(terragruntModeLabels[0]))
+		}
 		b.WriteString("\n\n")
 
 	case deploy.TargetConfigOnly:
@@ -379,5 +386,15 @@ func (m *Model) viewTargetSpecificReview() string {
 		b.WriteString("\n\n")
 	}
 
+	if m.isFleet() {
+		names, _ := m.fleetNames()
+		b.WriteString(labelStyle.Render("Machines: "))
+		b.WriteString(valueStyle.Render(fmt.Sprintf("%d (%s to %s)", len(names), names[0], names[len(names)-1])))
+		b.WriteString("\n")
+		b.WriteString(labelStyle.Render("On failure: "))
+		b.WriteString(valueStyle.Render(GetFailurePolicyLabel(m.wizard.Data.Fleet.Policy)))
+		b.WriteString("\n\n")
+	}
+
 	return b.String()
 }
@@ -24,9 +24,17 @@ const (
 	terragruntFieldDisk
 	terragruntFieldImagePath
 	terragruntFieldLibvirtURI
+	terragruntFieldMode
+	terragruntFieldKeepOnFailure
+	terragruntFieldMachines
+	terragruntFieldOnFailure
 	terragruntFieldCount
 )
 
+// terragruntModeLabels are the choices for the mode field.
+// Index 1 runs terragrunt init/plan/apply after generating.
+var terragruntModeLabels = []string{"Generate config only", "Generate and apply"}
+
 // Default paths
 const (
 	defaultLibvirtURI  = "qemu:///system"
@@ -48,7 +56,7 @@ func (m *Model) initTerragruntPhase() {
 	imagePath := textinput.New()
 	imagePath.CharLimit = 256
 	if len(m.cloudImages) > 0 {
-		// Use the first cloud image from settings
+		// Use the default cloud image from settings (sorted first)
 		imagePath.Placeholder = m.cloudImages[0].Path
 		imagePath.SetValue(m.cloudImages[0].Path)
 	} else {
@@ -67,6 +75,10 @@ func (m *Model) initTerragruntPhase() {
 	m.wizard.SelectIdxs["cpu"] = 1    // 2 CPUs
 	m.wizard.SelectIdxs["memory"] = 1 // 4 GB
 	m.wizard.SelectIdxs["disk"] = 1   // 20 GB
+	m.wizard.SelectIdxs["tg_mode"] = 0
+	m.wizard.CheckStates["keep_on_failure"] = false
+	m.wizard.SelectIdxs["machines"] = 0   // 1 machine
+	m.wizard.SelectIdxs["on_failure"] = 0 // Continue
 }
 
 // handleTerragruntPhase handles input for the Terragrunt options phase
@@ -96,6 +108,11 @@ func (m *Model) handleTerragruntPhase(msg tea.KeyMsg) (app.Tab, tea.Cmd) {
 		m.cycleTerragruntOption(1)
 		return m, nil
 
+	case key.Matches(msg, key.NewBinding(key.WithKeys(" "))) && m.wizard.FocusedField == terragruntFieldKeepOnFailure:
+		// Toggle checkbox
+		m.wizard.CheckStates["keep_on_failure"] = !m.wizard.CheckStates["keep_on_failure"]
+		return m, nil
+
 	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
 		// Validate and advance
 		m.saveTerragruntOptions()
@@ -122,6 +139,12 @@ func (m *Model) cycleTerragruntOption(delta int) {
 		m.wizard.CycleSelect("memory", len(MemoryOptions), delta)
 	case terragruntFieldDisk:
 		m.wizard.CycleSelect("disk", len(DiskOptions), delta)
+	case terragruntFieldMode:
+		m.wizard.CycleSelect("tg_mode", len(terragruntModeLabels), delta)
+	case terragruntFieldMachines:
+		m.wizard.CycleSelect("machines", len(MachineCountOptions), delta)
+	case terragruntFieldOnFailure:
+		m.wizard.CycleSelect("on_failure", len(FailurePolicyOptions), delta)
 	}
 }
 
@@ -148,15 +171,18 @@ func (m *Model) saveTerragruntOptions() {
 	}
 
 	m.wizard.Data.TerragruntOpts = deploy.TerragruntOptions{
-		VMName:      vmName,
-		CPUs:        GetCPUValue(m.wizard.SelectIdxs["cpu"]),
-		MemoryMB:    GetMemoryValue(m.wizard.SelectIdxs["memory"]),
-		DiskGB:      GetDiskValue(m.wizard.SelectIdxs["disk"]),
-		UbuntuImage: imagePath,
-		LibvirtURI:  libvirtURI,
-		StoragePool: defaultStoragePool,
-		NetworkName: defaultNetwork,
+		VMName:        vmName,
+		CPUs:          GetCPUValue(m.wizard.SelectIdxs["cpu"]),
+		MemoryMB:      GetMemoryValue(m.wizard.SelectIdxs["memory"]),
+		DiskGB:        GetDiskValue(m.wizard.SelectIdxs["disk"]),
+		UbuntuImage:   imagePath,
+		LibvirtURI:    libvirtURI,
+		StoragePool:   defaultStoragePool,
+		NetworkName:   defaultNetwork,
+		Apply:         m.wizard.SelectIdxs["tg_mode"] == 1,
+		KeepOnFailure: m.wizard.CheckStates["keep_on_failure"],
 	}
+	m.saveFleetOptions()
 }
 
 // viewTerragruntPhase renders the Terragrunt options phase
@@ -193,6 +219,16 @@ func (m *Model) viewTerragruntPhase() string {
 	// Libvirt URI
 	b.WriteString(wizard.RenderTextField(m.wizard, "Libvirt URI", "libvirt_uri", terragruntFieldLibvirtURI))
 
+	// Apply mode
+	b.WriteString(wizard.RenderSelectField(m.wizard, "Mode", "tg_mode", terragruntFieldMode, terragruntModeLabels))
+
+	// Keep on failure checkbox
+	b.WriteString(wizard.RenderCheckbox(m.wizard, "Keep VM on failure", "keep_on_failure", terragruntFieldKeepOnFailure))
+
+	// Fleet
+	b.WriteString(wizard.RenderSelectField(m.wizard, "Machines", "machines", terragruntFieldMachines, GetMachineCountLabels()))
+	b.WriteString(wizard.RenderSelectField(m.wizard, "On failure", "on_failure", terragruntFieldOnFailure, GetFailurePolicyLabels()))
+
 	return b.String()
 }
 
@@ -4,6 +4,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/packages"
 )
 
@@ -16,6 +17,7 @@ type WizardData struct {
 	MultipassOpts  deploy.MultipassOptions
 	TerragruntOpts deploy.TerragruntOptions
 	GenerateOpts   GenerateOptions
+	Fleet          FleetOptions
 
 	// SSH configuration
 	SSHKeys       []string
@@ -49,6 +51,13 @@ type GenerateOptions struct {
 	OutputDir         string
 }
 
+// FleetOptions holds how many identical machines to create. With a count
+// above one, machines are named <vm-name>-1 to <vm-name>-<count>.
+type FleetOptions struct {
+	Count  int
+	Policy fleet.Policy
+}
+
 // State holds the current state of the wizard
 type State struct {
 	// Current phase
@@ -0,0 +1,501 @@
+// Package iso provides the autoinstall ISO builder view for the TUI application.
+package iso
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/app"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/iso"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/packages"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/spec"
+)
+
+// Message types for async operations.
+type (
+	// dataLoadedMsg carries the registered ISOs and saved configs.
+	dataLoadedMsg struct {
+		isos      []globalconfig.ISO
+		configs   []settings.VMConfig
+		imagesDir string
+	}
+
+	// dataErrorMsg indicates an error loading ISOs or configs.
+	dataErrorMsg struct {
+		err error
+	}
+
+	// buildProgressMsg wraps a deploy.ProgressEvent for Bubble Tea.
+	buildProgressMsg deploy.ProgressEvent
+
+	// buildCompleteMsg is sent when the build finishes.
+	buildCompleteMsg struct {
+		result *iso.Result
+		err    error
+	}
+)
+
+// Pane is the list that has the selection focus.
+type Pane int
+
+const (
+	PaneISOs Pane = iota
+	PaneConfigs
+)
+
+// maxEvents is how many progress events are shown while building.
+const maxEvents = 6
+
+// Styles
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("229"))
+	paneStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	activeStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	selectedStyle = lipgloss.NewStyle().Background(lipgloss.Color("237"))
+	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	successStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+// Model is the ISO builder view model.
+type Model struct {
+	app.BaseTab
+
+	projectDir string
+	store      *settings.Store
+	builder    *iso.Builder
+
+	isos      []globalconfig.ISO
+	configs   []settings.VMConfig
+	imagesDir string
+
+	spinner spinner.Model
+	loading bool
+	err     error
+	message string
+	pane    Pane
+	cursors map[Pane]int
+
+	// Build state
+	building     bool
+	progressBar  progress.Model
+	progressChan chan deploy.ProgressEvent
+	events       []deploy.ProgressEvent
+	percent      int
+	result       *iso.Result
+	buildErr     error
+}
+
+// New creates a new ISO builder model.
+func New(projectDir string, store *settings.Store) *Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	return &Model{
+		BaseTab:    app.NewBaseTab(app.TabISO, "ISO", "3"),
+		projectDir: projectDir,
+		store:      store,
+		builder:    iso.New(),
+		spinner:    s,
+		loading:    true,
+		cursors:    make(map[Pane]int),
+		progressBar: progress.New(
+			progress.WithDefaultGradient(),
+			progress.WithWidth(50),
+			progress.WithoutPercentage(),
+		),
+	}
+}
+
+// Init initializes the ISO view.
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(
+		m.spinner.Tick,
+		m.loadData,
+	)
+}
+
+// loadData loads registered ISOs and saved VM configs.
+func (m *Model) loadData() tea.Msg {
+	cfg, err := globalconfig.Load()
+	if err != nil {
+		return dataErrorMsg{err: err}
+	}
+
+	var configs []settings.VMConfig
+	if m.store != nil {
+		s, err := m.store.Load()
+		if err != nil {
+			return dataErrorMsg{err: err}
+		}
+		configs = s.VMConfigs
+	}
+
+	imagesDir := cfg.ImagesDir
+	if imagesDir == "" {
+		imagesDir = globalconfig.DefaultImagesDir()
+	}
+
+	return dataLoadedMsg{isos: cfg.ISOs, configs: configs, imagesDir: imagesDir}
+}
+
+// Update handles messages.
+func (m *Model) Update(msg tea.Msg) (app.Tab, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+
+	case spinner.TickMsg:
+		if m.loading || m.building {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+
+	case dataLoadedMsg:
+		m.loading = false
+		m.err = nil
+		m.isos = msg.isos
+		m.configs = msg.configs
+		m.imagesDir = msg.imagesDir
+		m.clampCursors()
+
+	case dataErrorMsg:
+		m.loading = false
+		m.err = msg.err
+
+	case buildProgressMsg:
+		event := deploy.ProgressEvent(msg)
+		m.events = append(m.events, event)
+		if len(m.events) > maxEvents {
+			m.events = m.events[len(m.events)-maxEvents:]
+		}
+		if event.Percent >= 0 {
+			m.percent = event.Percent
+		}
+		return m, m.waitForProgress()
+
+	case buildCompleteMsg:
+		m.building = false
+		m.result = msg.result
+		m.buildErr = msg.err
+		if msg.err == nil {
+			m.percent = 100
+		}
+	}
+
+	return m, nil
+}
+
+// handleKeyMsg handles keyboard input.
+func (m *Model) handleKeyMsg(msg tea.KeyMsg) (app.Tab, tea.Cmd) {
+	if m.building {
+		return m, nil // Ignore input while building
+	}
+
+	if m.result != nil || m.buildErr != nil {
+		switch msg.String() {
+		case "esc", "enter":
+			m.resetBuild()
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	case "left", "h":
+		m.pane = PaneISOs
+	case "right", "l":
+		m.pane = PaneConfigs
+	case "enter", "b":
+		return m, m.startBuild()
+	case "r":
+		m.loading = true
+		m.message = ""
+		return m, tea.Batch(m.spinner.Tick, m.loadData)
+	}
+	return m, nil
+}
+
+// moveCursor moves the selection in the focused pane.
+func (m *Model) moveCursor(delta int) {
+	n := len(m.isos)
+	if m.pane == PaneConfigs {
+		n = len(m.configs)
+	}
+	pos := m.cursors[m.pane] + delta
+	if pos >= 0 && pos < n {
+		m.cursors[m.pane] = pos
+	}
+}
+
+// clampCursors keeps the cursors within the lists after a reload.
+func (m *Model) clampCursors() {
+	for pane, n := range map[Pane]int{PaneISOs: len(m.isos), PaneConfigs: len(m.configs)} {
+		if m.cursors[pane] >= n {
+			m.cursors[pane] = max(n-1, 0)
+		}
+	}
+}
+
+// selected returns the selected ISO and config, or nil if a list is empty.
+func (m *Model) selected() (*globalconfig.ISO, *settings.VMConfig) {
+	var src *globalconfig.ISO
+	var cfg *settings.VMConfig
+	if len(m.isos) > 0 {
+		src = &m.isos[m.cursors[PaneISOs]]
+	}
+	if len(m.configs) > 0 {
+		cfg = &m.configs[m.cursors[PaneConfigs]]
+	}
+	return src, cfg
+}
+
+// outputPath returns where the ISO for cfg is written.
+func (m *Model) outputPath(cfg *settings.VMConfig) string {
+	return filepath.Join(m.imagesDir, cfg.Name+"-autoinstall.iso")
+}
+
+// startBuild starts building an ISO from the selected ISO and config.
+func (m *Model) startBuild() tea.Cmd {
+	src, vmCfg := m.selected()
+	if src == nil {
+		m.message = "No ISOs registered: add one with 'ucli iso add <path>'"
+		return nil
+	}
+	if vmCfg == nil {
+		m.message = "No saved configs: save one from the Create tab first"
+		return nil
+	}
+
+	fullCfg, err := m.fullConfig(vmCfg)
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+		return nil
+	}
+
+	m.building = true
+	m.message = ""
+	m.events = nil
+	m.percent = 0
+	m.progressChan = make(chan deploy.ProgressEvent, 100)
+
+	opts := &iso.Options{
+		SourceISO:  src.Path,
+		OutputPath: m.outputPath(vmCfg),
+		Config:     fullCfg,
+	}
+	return tea.Batch(m.spinner.Tick, m.runBuild(opts, m.progressChan), m.waitForProgress())
+}
+
+// fullConfig builds the configuration for a saved VM config.
+func (m *Model) fullConfig(vmCfg *settings.VMConfig) (*config.FullConfig, error) {
+	registry, err := packages.DiscoverWithOverlays(m.projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover packages: %w", err)
+	}
+
+	s := spec.FromVMConfig(vmCfg)
+	if len(s.Packages) == 0 {
+		s.Packages = registry.Defaults()
+	}
+
+	cfg := s.ToFullConfig(registry.Names())
+	if cfg.PackageScripts, err = registry.ExtraScripts(s.Packages); err != nil {
+		return nil, fmt.Errorf("failed to load package scripts: %w", err)
+	}
+	return cfg, nil
+}
+
+// runBuild runs the build in the background.
+func (m *Model) runBuild(opts *iso.Options, events chan deploy.ProgressEvent) tea.Cmd {
+	return func() tea.Msg {
+		result, err := m.builder.Build(context.Background(), opts, func(e deploy.ProgressEvent) {
+			events <- e
+		})
+		close(events)
+		return buildCompleteMsg{result: result, err: err}
+	}
+}
+
+// waitForProgress waits for the next progress event.
+func (m *Model) waitForProgress() tea.Cmd {
+	events := m.progressChan
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil // Channel closed
+		}
+		return buildProgressMsg(event)
+	}
+}
+
+// resetBuild returns to the selection lists after a build.
+func (m *Model) resetBuild() {
+	m.result = nil
+	m.buildErr = nil
+	m.events = nil
+	m.percent = 0
+}
+
+// View renders the ISO view.
+func (m *Model) View() string {
+	if m.Width() == 0 {
+		return "Loading..."
+	}
+
+	header := titleStyle.Render("Autoinstall ISO Builder")
+	if m.loading || m.building {
+		header += " " + m.spinner.View()
+	}
+
+	var content string
+	switch {
+	case m.loading && len(m.isos) == 0 && len(m.configs) == 0:
+		content = fmt.Sprintf("\n  %s Loading ISOs and saved configs...\n", m.spinner.View())
+	case m.err != nil:
+		content = fmt.Sprintf("\n  Error: %v\n\n  Press 'r' to retry.\n", m.err)
+	case m.building || m.result != nil || m.buildErr != nil:
+		content = m.renderBuild()
+	default:
+		content = m.renderLists()
+	}
+
+	var status string
+	if m.message != "" {
+		status = dimStyle.Render("\n  " + m.message)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, content, status)
+}
+
+// renderLists renders the ISO and config lists side by side.
+func (m *Model) renderLists() string {
+	isoLines := make([]string, 0, len(m.isos))
+	for _, i := range m.isos {
+		name := i.Name
+		if name == "" {
+			name = i.ID
+		}
+		isoLines = append(isoLines, name)
+	}
+	configLines := make([]string, 0, len(m.configs))
+	for _, c := range m.configs {
+		configLines = append(configLines, fmt.Sprintf("%s %s", c.Name, dimStyle.Render(c.Data.Username+"@"+c.Data.Hostname)))
+	}
+
+	width := max(m.Width()/2-2, 20)
+	left := m.renderPane(PaneISOs, "Source ISO", isoLines, "No ISOs registered (ucli iso add <path>)", width)
+	right := m.renderPane(PaneConfigs, "Configuration", configLines, "No saved configs (save one from Create)", width)
+
+	out := "\n" + lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+	if _, cfg := m.selected(); cfg != nil && len(m.isos) > 0 {
+		out += "\n\n  " + dimStyle.Render("Output: "+m.outputPath(cfg))
+	}
+	return out
+}
+
+// renderPane renders one selectable list.
+func (m *Model) renderPane(pane Pane, title string, lines []string, empty string, width int) string {
+	style := paneStyle
+	if m.pane == pane {
+		style = activeStyle
+	}
+
+	out := []string{"  " + style.Render(title)}
+	if len(lines) == 0 {
+		out = append(out, "    "+dimStyle.Render(empty))
+	}
+	for i, line := range lines {
+		cursor := "  "
+		if i == m.cursors[pane] {
+			cursor = "▸ "
+		}
+		line = "  " + cursor + line
+		if i == m.cursors[pane] && m.pane == pane {
+			line = selectedStyle.Render(line)
+		}
+		out = append(out, line)
+	}
+
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(out, "\n"))
+}
+
+// renderBuild renders build progress or the result.
+func (m *Model) renderBuild() string {
+	var b strings.Builder
+	b.WriteString("\n  " + m.progressBar.ViewAs(float64(m.percent)/100) + fmt.Sprintf(" %d%%\n\n", m.percent))
+
+	for _, e := range m.events {
+		line := fmt.Sprintf("  %-22s %s", e.Stage.DisplayName(), e.Message)
+		if e.Detail != "" {
+			line += " " + dimStyle.Render(e.Detail)
+		}
+		if e.IsError {
+			line = errorStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	switch {
+	case m.buildErr != nil:
+		b.WriteString("\n  " + errorStyle.Render(fmt.Sprintf("Build failed: %v", m.buildErr)) + "\n")
+	case m.result != nil:
+		b.WriteString("\n  " + successStyle.Render(fmt.Sprintf("✓ Wrote %s (%.1f MB)", m.result.OutputPath, float64(m.result.Size)/(1024*1024))) + "\n")
+		for _, log := range m.result.Logs {
+			b.WriteString("  " + dimStyle.Render(log) + "\n")
+		}
+		b.WriteString("\n  " + dimStyle.Render("Write it to a USB stick with: sudo dd if="+m.result.OutputPath+" of=/dev/sdX bs=4M status=progress") + "\n")
+	}
+
+	return b.String()
+}
+
+// Focus sets focus on this tab.
+func (m *Model) Focus() tea.Cmd {
+	m.BaseTab.Focus()
+	if m.building {
+		return nil
+	}
+	return m.loadData
+}
+
+// Blur removes focus from this tab.
+func (m *Model) Blur() {
+	m.BaseTab.Blur()
+}
+
+// SetSize sets the tab dimensions.
+func (m *Model) SetSize(width, height int) {
+	m.BaseTab.SetSize(width, height)
+}
+
+// KeyBindings returns the key bindings for this tab.
+func (m *Model) KeyBindings() []string {
+	switch {
+	case m.building:
+		return []string{"building..."}
+	case m.result != nil || m.buildErr != nil:
+		return []string{"[Enter/Esc] back"}
+	}
+	return []string{
+		"[↑/↓] navigate",
+		"[←/→] switch list",
+		"[Enter] build",
+		"[r] refresh",
+	}
+}
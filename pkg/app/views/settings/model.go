@@ -3,6 +3,7 @@ package settings
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/app"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/images"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/utils"
 )
@@ -26,8 +28,26 @@ type (
 	}
 
 	settingsSavedMsg struct{}
+
+	downloadsLoadedMsg struct {
+		downloads []settings.Download
+	}
+
+	// downloadActionMsg reports the result of pausing or resuming a download.
+	downloadActionMsg struct {
+		message string
+		err     error
+	}
+
+	// downloadsTickMsg triggers a periodic refresh of download progress.
+	downloadsTickMsg struct {
+		gen int
+	}
 )
 
+// downloadsRefreshInterval is how often download progress is refreshed.
+const downloadsRefreshInterval = time.Second
+
 // Section represents a section of the config view.
 type Section int
 
@@ -35,6 +55,7 @@ const (
 	SectionSavedConfigs Section = iota
 	SectionPackagePresets
 	SectionAppSettings
+	SectionDownloads
 	sectionCount
 )
 
@@ -82,6 +103,10 @@ type Model struct {
 	store    *settings.Store
 	settings *settings.Settings
 
+	downloader *images.Downloader // Nil without a settings store
+	downloads  []settings.Download
+	tickGen    int // Invalidates refresh ticks from previous focus sessions
+
 	spinner     spinner.Model
 	loading     bool
 	err         error
@@ -98,8 +123,9 @@ type Model struct {
 	editingPreset string // ID of preset being edited
 }
 
-// New creates a new config manager model.
-func New() *Model {
+// New creates a new config manager model. Downloads started by downloader
+// are listed and can be paused and resumed.
+func New(downloader *images.Downloader) *Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
@@ -111,8 +137,9 @@ func New() *Model {
 	}
 
 	return &Model{
-		BaseTab:     app.NewBaseTab(app.TabConfig, "Config", "4"),
+		BaseTab:     app.NewBaseTab(app.TabConfig, "Config", "5"),
 		store:       store,
+		downloader:  downloader,
 		spinner:     s,
 		loading:     true,
 		message:     message,
@@ -126,6 +153,7 @@ func (m *Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
 		m.loadSettings,
+		m.loadDownloads,
 	)
 }
 
@@ -143,6 +171,27 @@ func (m *Model) loadSettings() tea.Msg {
 	return settingsLoadedMsg{settings: s}
 }
 
+// loadDownloads loads the persisted image downloads.
+func (m *Model) loadDownloads() tea.Msg {
+	if m.downloader == nil {
+		return downloadsLoadedMsg{}
+	}
+
+	downloads, err := m.downloader.Downloads()
+	if err != nil {
+		return downloadActionMsg{err: err}
+	}
+	return downloadsLoadedMsg{downloads: downloads}
+}
+
+// scheduleRefresh returns a command that triggers the next download refresh.
+func (m *Model) scheduleRefresh() tea.Cmd {
+	gen := m.tickGen
+	return tea.Tick(downloadsRefreshInterval, func(time.Time) tea.Msg {
+		return downloadsTickMsg{gen: gen}
+	})
+}
+
 // Update handles messages.
 func (m *Model) Update(msg tea.Msg) (app.Tab, tea.Cmd) {
 	var cmd tea.Cmd
@@ -174,6 +223,28 @@ func (m *Model) Update(msg tea.Msg) (app.Tab, tea.Cmd) {
 	case settingsSavedMsg:
 		m.message = "Settings saved"
 		return m, nil
+
+	case downloadsLoadedMsg:
+		m.downloads = msg.downloads
+		// Adjust cursor if downloads were removed
+		if m.itemCursors[SectionDownloads] >= len(m.downloads) && len(m.downloads) > 0 {
+			m.itemCursors[SectionDownloads] = len(m.downloads) - 1
+		}
+		return m, nil
+
+	case downloadActionMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error: %v", msg.err)
+		} else {
+			m.message = msg.message
+		}
+		return m, m.loadDownloads
+
+	case downloadsTickMsg:
+		if msg.gen != m.tickGen || !m.IsFocused() {
+			return m, nil
+		}
+		return m, tea.Batch(m.scheduleRefresh(), m.loadDownloads)
 	}
 
 	return m, nil
@@ -198,9 +269,11 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (app.Tab, tea.Cmd) {
 		return m.handleEdit()
 	case "x", "delete", "backspace":
 		return m.handleDelete()
+	case "p":
+		return m.toggleDownload()
 	case "r":
 		m.loading = true
-		return m, m.loadSettings
+		return m, tea.Batch(m.loadSettings, m.loadDownloads)
 	}
 	return m, nil
 }
@@ -279,10 +352,39 @@ func (m *Model) getItemCount() int {
 		return len(m.getAllPresets()) + 1 // +1 for "Create new..."
 	case SectionAppSettings:
 		return 3 // TerraformDir, DefaultTarget, AutoApprove
+	case SectionDownloads:
+		return len(m.downloads)
 	}
 	return 0
 }
 
+// toggleDownload pauses the selected download if it is running, and
+// resumes it otherwise.
+func (m *Model) toggleDownload() (app.Tab, tea.Cmd) {
+	cursor := m.itemCursors[SectionDownloads]
+	if m.section != SectionDownloads || m.downloader == nil || cursor >= len(m.downloads) {
+		return m, nil
+	}
+
+	dl := m.downloads[cursor]
+	name := filepath.Base(dl.DestPath)
+	downloader := m.downloader
+
+	switch {
+	case downloader.IsDownloadActive(dl.ID):
+		return m, func() tea.Msg {
+			return downloadActionMsg{message: fmt.Sprintf("Paused %s", name), err: downloader.PauseDownload(dl.ID)}
+		}
+	case dl.Status == settings.StatusComplete:
+		m.message = fmt.Sprintf("%s is already downloaded", name)
+		return m, nil
+	default:
+		return m, func() tea.Msg {
+			return downloadActionMsg{message: fmt.Sprintf("Resumed %s", name), err: downloader.ResumeDownload(dl.ID)}
+		}
+	}
+}
+
 // handleEnter handles the Enter key for the current selection.
 func (m *Model) handleEnter() (app.Tab, tea.Cmd) {
 	if m.settings == nil {
@@ -665,6 +767,11 @@ func (m *Model) renderSections() string {
 	// Section 3: App Settings
 	b.WriteString(m.renderSectionHeader("App Settings", SectionAppSettings))
 	b.WriteString(m.renderAppSettings())
+	b.WriteString("\n")
+
+	// Section 4: Image Downloads
+	b.WriteString(m.renderSectionHeader("Image Downloads", SectionDownloads))
+	b.WriteString(m.renderDownloads())
 
 	return b.String()
 }
@@ -842,6 +949,47 @@ func (m *Model) renderAppSettings() string {
 	return b.String()
 }
 
+// renderDownloads renders the image downloads section.
+func (m *Model) renderDownloads() string {
+	var b strings.Builder
+
+	if len(m.downloads) == 0 {
+		b.WriteString(dimStyle.Render("    No image downloads"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	cursor := m.itemCursors[SectionDownloads]
+	isActive := m.section == SectionDownloads
+
+	for i, dl := range m.downloads {
+		prefix := "    "
+		style := itemStyle
+		if isActive && i == cursor {
+			prefix = "  ▸ "
+			style = selectedItemStyle
+		}
+
+		progress := fmt.Sprintf("%d MiB", dl.Downloaded>>20)
+		if dl.TotalBytes > 0 {
+			progress = fmt.Sprintf("%d%% of %d MiB", dl.Downloaded*100/dl.TotalBytes, dl.TotalBytes>>20)
+		}
+
+		b.WriteString(prefix)
+		b.WriteString(style.Render(filepath.Base(dl.DestPath)))
+		b.WriteString(dimStyle.Render(fmt.Sprintf(" (%s, %s)", dl.Status, progress)))
+		b.WriteString("\n")
+
+		if dl.Error != "" {
+			b.WriteString("      ")
+			b.WriteString(errorStyle.Render(dl.Error))
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
 // renderDialog renders the dialog overlay.
 func (m *Model) renderDialog() string {
 	var b strings.Builder
@@ -887,9 +1035,12 @@ func (m *Model) renderDialog() string {
 // Focus is called when the tab becomes active.
 func (m *Model) Focus() tea.Cmd {
 	m.BaseTab.Focus()
+	m.tickGen++
 	return tea.Batch(
 		m.spinner.Tick,
 		m.loadSettings,
+		m.loadDownloads,
+		m.scheduleRefresh(),
 	)
 }
 
@@ -909,6 +1060,14 @@ func (m *Model) KeyBindings() []string {
 			"[Tab] next field",
 		}
 	}
+	if m.section == SectionDownloads {
+		return []string{
+			"[↑/↓] navigate",
+			"[h/l] section",
+			"[p] pause/resume",
+			"[r] refresh",
+		}
+	}
 	return []string{
 		"[↑/↓] navigate",
 		"[h/l] section",
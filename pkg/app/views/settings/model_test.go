@@ -0,0 +1,65 @@
+package settings
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/images"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+)
+
+// update sends msg to m and runs the resulting command, feeding its message
+// back in, until the model settles.
+func update(m *Model, msg tea.Msg) {
+	for msg != nil {
+		_, cmd := m.Update(msg)
+		if cmd == nil {
+			return
+		}
+		msg = cmd()
+	}
+}
+
+func TestModel_PauseAndResumeDownload(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// The server never finishes, so the download stays active until paused
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	store := settings.NewStoreWithDir(t.TempDir())
+	downloader := images.NewDownloader(store)
+	destPath := filepath.Join(t.TempDir(), "noble.img")
+	require.NoError(t, downloader.StartBackgroundDownload("noble", server.URL, destPath))
+	t.Cleanup(func() { _ = downloader.CancelDownload("noble") })
+
+	m := New(downloader)
+	m.SetSize(80, 40)
+	update(m, m.loadSettings())
+	update(m, m.loadDownloads())
+	for m.section != SectionDownloads {
+		update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	}
+	assert.Contains(t, m.View(), "noble.img (downloading")
+
+	update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	assert.False(t, downloader.IsDownloadActive("noble"))
+	assert.Equal(t, "Paused noble.img", m.message)
+	assert.Contains(t, m.View(), "noble.img (paused")
+
+	update(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	assert.True(t, downloader.IsDownloadActive("noble"))
+	assert.Equal(t, "Resumed noble.img", m.message)
+	assert.Contains(t, m.KeyBindings(), "[p] pause/resume")
+}
@@ -0,0 +1,481 @@
+// Package vms provides the VM list and lifecycle view for the TUI application.
+package vms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/app"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/multipass"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/terragrunt"
+)
+
+// refreshInterval is how often the list is refreshed while the tab is focused.
+const refreshInterval = 10 * time.Second
+
+// Message types for async operations.
+type (
+	// machinesLoadedMsg carries the result of listing all backends.
+	machinesLoadedMsg struct {
+		machines []deploy.Machine
+		errs     []error
+	}
+
+	// actionDoneMsg indicates a lifecycle action has finished.
+	actionDoneMsg struct {
+		action string
+		name   string
+		err    error
+	}
+
+	// refreshTickMsg triggers a periodic refresh.
+	refreshTickMsg struct {
+		gen int
+	}
+
+	// execDoneMsg indicates an interactive console/SSH session has ended.
+	execDoneMsg struct {
+		err error
+	}
+)
+
+// Model is the VMs view model.
+type Model struct {
+	app.BaseTab
+
+	backends []deploy.Lifecycle
+	machines []deploy.Machine
+	errs     []error // Non-fatal backend errors from the last refresh
+
+	spinner spinner.Model
+	loading bool
+	busy    string // Action in progress, e.g. "Stopping dev-vm..."
+	message string
+	cursor  int
+
+	confirmDestroy bool
+	tickGen        int // Invalidates refresh ticks from previous focus sessions
+}
+
+// New creates a new VMs model with the Terragrunt and Multipass backends.
+func New(projectDir string) *Model {
+	return NewWithBackends(
+		terragrunt.NewLifecycle(projectDir),
+		multipass.NewLifecycle(),
+	)
+}
+
+// NewWithBackends creates a new VMs model with the given lifecycle backends.
+func NewWithBackends(backends ...deploy.Lifecycle) *Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = app.SpinnerStyle
+
+	return &Model{
+		BaseTab:  app.NewBaseTab(app.TabVMs, "VMs", "1"),
+		backends: backends,
+		spinner:  s,
+		loading:  true,
+	}
+}
+
+// Init initializes the VMs view.
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.loadMachines())
+}
+
+// Update handles messages.
+func (m *Model) Update(msg tea.Msg) (app.Tab, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.confirmDestroy {
+			return m.handleConfirmKey(msg)
+		}
+		return m.handleKeyMsg(msg)
+
+	case spinner.TickMsg:
+		if !m.loading && m.busy == "" {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case machinesLoadedMsg:
+		m.loading = false
+		m.machines = msg.machines
+		m.errs = msg.errs
+		if m.cursor >= len(m.machines) {
+			m.cursor = max(len(m.machines)-1, 0)
+		}
+
+	case actionDoneMsg:
+		m.busy = ""
+		if msg.err != nil {
+			m.message = fmt.Sprintf("%s %s failed: %v", msg.action, msg.name, msg.err)
+		} else {
+			m.message = fmt.Sprintf("%s %s: done", msg.action, msg.name)
+		}
+		m.loading = true
+		return m, tea.Batch(m.spinner.Tick, m.loadMachines())
+
+	case refreshTickMsg:
+		if msg.gen != m.tickGen || !m.IsFocused() {
+			return m, nil
+		}
+		cmds := []tea.Cmd{m.scheduleRefresh()}
+		if m.busy == "" && !m.loading {
+			cmds = append(cmds, m.loadMachines())
+		}
+		return m, tea.Batch(cmds...)
+
+	case execDoneMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Session ended with error: %v", msg.err)
+		}
+		return m, m.loadMachines()
+	}
+
+	return m, nil
+}
+
+// handleKeyMsg handles keyboard input for the list.
+func (m *Model) handleKeyMsg(msg tea.KeyMsg) (app.Tab, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.machines)-1 {
+			m.cursor++
+		}
+	case "r":
+		m.loading = true
+		m.message = ""
+		return m, tea.Batch(m.spinner.Tick, m.loadMachines())
+	case "s":
+		return m.startAction("Start", "Starting", func(ctx context.Context, l deploy.Lifecycle, name string) error {
+			return l.Start(ctx, name)
+		})
+	case "S":
+		return m.startAction("Stop", "Stopping", func(ctx context.Context, l deploy.Lifecycle, name string) error {
+			return l.Stop(ctx, name)
+		})
+	case "d":
+		if m.selected() != nil && m.busy == "" {
+			m.confirmDestroy = true
+			m.message = ""
+		}
+	case "c":
+		return m.openSession("console", func(mc *deploy.Machine) []string { return mc.ConsoleCommand })
+	case "x":
+		return m.openSession("SSH", func(mc *deploy.Machine) []string { return mc.SSHCommand })
+	}
+	return m, nil
+}
+
+// handleConfirmKey handles the destroy confirmation prompt.
+func (m *Model) handleConfirmKey(msg tea.KeyMsg) (app.Tab, tea.Cmd) {
+	m.confirmDestroy = false
+	if msg.String() != "y" && msg.String() != "Y" {
+		m.message = "Destroy cancelled"
+		return m, nil
+	}
+	return m.startAction("Destroy", "Destroying", func(ctx context.Context, l deploy.Lifecycle, name string) error {
+		return l.Destroy(ctx, name)
+	})
+}
+
+// startAction runs a lifecycle action on the selected machine.
+func (m *Model) startAction(action, progress string, fn func(context.Context, deploy.Lifecycle, string) error) (app.Tab, tea.Cmd) {
+	if m.busy != "" {
+		return m, nil
+	}
+	mc := m.selected()
+	if mc == nil {
+		return m, nil
+	}
+	backend := m.backendFor(mc.Target)
+	if backend == nil {
+		m.message = fmt.Sprintf("No backend for %s", mc.Target.DisplayName())
+		return m, nil
+	}
+
+	name := mc.Name
+	m.busy = fmt.Sprintf("%s %s...", progress, name)
+	m.message = ""
+
+	return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+		err := fn(context.Background(), backend, name)
+		return actionDoneMsg{action: action, name: name, err: err}
+	})
+}
+
+// openSession suspends the TUI and runs an interactive command for the selected machine.
+func (m *Model) openSession(kind string, command func(*deploy.Machine) []string) (app.Tab, tea.Cmd) {
+	mc := m.selected()
+	if mc == nil {
+		return m, nil
+	}
+
+	args := command(mc)
+	if len(args) == 0 {
+		m.message = fmt.Sprintf("No %s available for %s (is it running?)", kind, mc.Name)
+		return m, nil
+	}
+
+	m.message = fmt.Sprintf("$ %s", strings.Join(args, " "))
+	c := exec.Command(args[0], args[1:]...)
+	return m, tea.ExecProcess(c, func(err error) tea.Msg {
+		return execDoneMsg{err: err}
+	})
+}
+
+// selected returns the machine under the cursor.
+func (m *Model) selected() *deploy.Machine {
+	if m.cursor < 0 || m.cursor >= len(m.machines) {
+		return nil
+	}
+	return &m.machines[m.cursor]
+}
+
+// backendFor returns the backend managing the given target.
+func (m *Model) backendFor(target deploy.DeploymentTarget) deploy.Lifecycle {
+	for _, b := range m.backends {
+		if b.Target() == target {
+			return b
+		}
+	}
+	return nil
+}
+
+// loadMachines returns a command that lists machines from all backends.
+func (m *Model) loadMachines() tea.Cmd {
+	backends := m.backends
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var msg machinesLoadedMsg
+		for _, b := range backends {
+			machines, err := b.List(ctx)
+			if err != nil {
+				if !errors.Is(err, deploy.ErrBackendUnavailable) {
+					msg.errs = append(msg.errs, fmt.Errorf("%s: %w", b.Target().DisplayName(), err))
+				}
+				continue
+			}
+			msg.machines = append(msg.machines, machines...)
+		}
+		return msg
+	}
+}
+
+// scheduleRefresh returns a command that triggers the next periodic refresh.
+func (m *Model) scheduleRefresh() tea.Cmd {
+	gen := m.tickGen
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg {
+		return refreshTickMsg{gen: gen}
+	})
+}
+
+// View renders the VMs view.
+func (m *Model) View() string {
+	if m.Width() == 0 {
+		return "Loading..."
+	}
+
+	var content string
+	switch {
+	case m.loading && len(m.machines) == 0:
+		content = fmt.Sprintf("\n  %s Looking for VMs...\n", m.spinner.View())
+	case len(m.machines) == 0:
+		content = "\n  No VMs found.\n\n" +
+			app.DimStyle.Render("  Create one from the Create tab (press 2) or with 'ucli create'.")
+	default:
+		content = m.renderTable() + "\n" + m.renderDetails()
+	}
+
+	parts := []string{m.renderHeader(), content}
+	if status := m.renderStatus(); status != "" {
+		parts = append(parts, status)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// renderHeader renders the view title and summary.
+func (m *Model) renderHeader() string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("229")).Render("Virtual Machines")
+	if m.loading || m.busy != "" {
+		title += " " + m.spinner.View()
+	}
+
+	running := 0
+	for _, mc := range m.machines {
+		if mc.State == deploy.StateRunning {
+			running++
+		}
+	}
+	summary := app.DimStyle.Render(fmt.Sprintf("%d running / %d total", running, len(m.machines)))
+
+	gap := m.Width() - lipgloss.Width(title) - lipgloss.Width(summary) - 2
+	if gap < 1 {
+		gap = 1
+	}
+	return title + strings.Repeat(" ", gap) + summary
+}
+
+// renderTable renders the machine list.
+func (m *Model) renderTable() string {
+	header := fmt.Sprintf("    %-24s %-12s %-12s %-16s %s", "NAME", "TARGET", "STATE", "IP", "RESOURCES")
+	lines := []string{"", app.TableHeaderStyle.Render(header)}
+
+	for i, mc := range m.machines {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "▸ "
+		}
+
+		ip := mc.IP
+		if ip == "" {
+			ip = "-"
+		}
+
+		state := app.RenderStatus(fmt.Sprintf("%-12s", mc.State))
+		line := fmt.Sprintf("  %s%-24s %-12s %s %-16s %s",
+			cursor, truncate(mc.Name, 24), mc.Target, state, ip, resources(mc))
+		if i == m.cursor {
+			line = app.SelectedRowStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderDetails renders details for the selected machine.
+func (m *Model) renderDetails() string {
+	mc := m.selected()
+	if mc == nil {
+		return ""
+	}
+
+	var lines []string
+	if mc.Image != "" {
+		lines = append(lines, "  Image:   "+mc.Image)
+	}
+	if mc.Dir != "" {
+		lines = append(lines, "  Config:  "+mc.Dir)
+	}
+	if len(mc.ConsoleCommand) > 0 {
+		lines = append(lines, "  Console: "+strings.Join(mc.ConsoleCommand, " "))
+	}
+	if len(mc.SSHCommand) > 0 {
+		lines = append(lines, "  SSH:     "+strings.Join(mc.SSHCommand, " "))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n" + app.DimStyle.Render(strings.Join(lines, "\n"))
+}
+
+// renderStatus renders the confirmation prompt, progress and messages.
+func (m *Model) renderStatus() string {
+	var lines []string
+
+	if m.confirmDestroy {
+		if mc := m.selected(); mc != nil {
+			lines = append(lines, app.WarningStyle.Render(fmt.Sprintf(
+				"  Destroy %s? This deletes the VM and its disk. [y/N]", mc.Name)))
+		}
+	}
+	if m.busy != "" {
+		lines = append(lines, fmt.Sprintf("  %s %s", m.spinner.View(), m.busy))
+	}
+	if m.message != "" {
+		lines = append(lines, app.DimStyle.Render("  "+m.message))
+	}
+	for _, err := range m.errs {
+		lines = append(lines, app.ErrorStyle.Render(fmt.Sprintf("  %v", err)))
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+// resources formats a machine's CPU, memory and disk.
+func resources(mc deploy.Machine) string {
+	var parts []string
+	if mc.CPUs > 0 {
+		parts = append(parts, fmt.Sprintf("%d vCPU", mc.CPUs))
+	}
+	if mc.MemoryMB > 0 {
+		parts = append(parts, fmt.Sprintf("%d MB", mc.MemoryMB))
+	}
+	if mc.DiskGB > 0 {
+		parts = append(parts, fmt.Sprintf("%d GB", mc.DiskGB))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// truncate shortens s to n characters.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "…"
+}
+
+// Focus sets focus on this tab and starts periodic refresh.
+func (m *Model) Focus() tea.Cmd {
+	m.BaseTab.Focus()
+	m.tickGen++
+	return tea.Batch(m.spinner.Tick, m.loadMachines(), m.scheduleRefresh())
+}
+
+// Blur removes focus from this tab.
+func (m *Model) Blur() {
+	m.BaseTab.Blur()
+	m.confirmDestroy = false
+}
+
+// SetSize sets the tab dimensions.
+func (m *Model) SetSize(width, height int) {
+	m.BaseTab.SetSize(width, height)
+}
+
+// KeyBindings returns the key bindings for this tab.
+func (m *Model) KeyBindings() []string {
+	if m.confirmDestroy {
+		return []string{"[y] destroy", "[any] cancel"}
+	}
+	return []string{
+		"[↑/↓] navigate",
+		"[s] start",
+		"[S] stop",
+		"[d] destroy",
+		"[c] console",
+		"[x] ssh",
+		"[r] refresh",
+	}
+}
+
+// HasFocusedInput returns true while the destroy confirmation is shown.
+func (m *Model) HasFocusedInput() bool {
+	return m.confirmDestroy
+}
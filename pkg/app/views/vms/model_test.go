@@ -0,0 +1,275 @@
+package vms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/app"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+)
+
+// fakeLifecycle is an in-memory deploy.Lifecycle for tests.
+type fakeLifecycle struct {
+	target   deploy.DeploymentTarget
+	machines []deploy.Machine
+	listErr  error
+	actErr   error
+	calls    []string
+}
+
+func (f *fakeLifecycle) Target() deploy.DeploymentTarget { return f.target }
+
+func (f *fakeLifecycle) List(_ context.Context) ([]deploy.Machine, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	return f.machines, nil
+}
+
+func (f *fakeLifecycle) Status(ctx context.Context, name string) (deploy.MachineState, error) {
+	m, err := f.Info(ctx, name)
+	if err != nil {
+		return deploy.StateUnknown, err
+	}
+	return m.State, nil
+}
+
+func (f *fakeLifecycle) Start(_ context.Context, name string) error {
+	f.calls = append(f.calls, "start "+name)
+	return f.actErr
+}
+
+func (f *fakeLifecycle) Stop(_ context.Context, name string) error {
+	f.calls = append(f.calls, "stop "+name)
+	return f.actErr
+}
+
+func (f *fakeLifecycle) Destroy(_ context.Context, name string) error {
+	f.calls = append(f.calls, "destroy "+name)
+	return f.actErr
+}
+
+func (f *fakeLifecycle) Info(_ context.Context, name string) (*deploy.Machine, error) {
+	for i := range f.machines {
+		if f.machines[i].Name == name {
+			return &f.machines[i], nil
+		}
+	}
+	return nil, fmt.Errorf("not found")
+}
+
+func newTestModel() (*Model, *fakeLifecycle, *fakeLifecycle) {
+	tg := &fakeLifecycle{
+		target: deploy.TargetTerragrunt,
+		machines: []deploy.Machine{
+			{Name: "web", Target: deploy.TargetTerragrunt, State: deploy.StateRunning, IP: "10.0.0.5",
+				ConsoleCommand: []string{"virsh", "console", "web"}, SSHCommand: []string{"ssh", "me@10.0.0.5"}},
+			{Name: "db", Target: deploy.TargetTerragrunt, State: deploy.StateStopped},
+		},
+	}
+	mp := &fakeLifecycle{
+		target: deploy.TargetMultipass,
+		machines: []deploy.Machine{
+			{Name: "dev", Target: deploy.TargetMultipass, State: deploy.StateRunning},
+		},
+	}
+
+	m := NewWithBackends(tg, mp)
+	m.SetSize(120, 40)
+	return m, tg, mp
+}
+
+// load runs the list command synchronously and applies the result.
+func load(t *testing.T, m *Model) {
+	t.Helper()
+	msg := m.loadMachines()()
+	m.Update(msg)
+}
+
+// runCmd executes a command and returns its message, skipping batches' spinner ticks.
+func runAction(t *testing.T, cmd tea.Cmd) actionDoneMsg {
+	t.Helper()
+	require.NotNil(t, cmd)
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c == nil {
+				continue
+			}
+			if done, ok := c().(actionDoneMsg); ok {
+				return done
+			}
+		}
+	}
+	done, ok := msg.(actionDoneMsg)
+	require.True(t, ok, "expected actionDoneMsg, got %T", msg)
+	return done
+}
+
+func TestNew(t *testing.T) {
+	m := New("/test/project")
+
+	assert.Equal(t, app.TabVMs, m.ID())
+	assert.Equal(t, "VMs", m.Name())
+	assert.Equal(t, "1", m.ShortKey())
+	assert.Len(t, m.backends, 2)
+}
+
+func TestModel_LoadMachines(t *testing.T) {
+	m, _, _ := newTestModel()
+
+	load(t, m)
+
+	assert.False(t, m.loading)
+	require.Len(t, m.machines, 3)
+	assert.Equal(t, "web", m.machines[0].Name)
+	assert.Equal(t, "dev", m.machines[2].Name)
+
+	view := m.View()
+	assert.Contains(t, view, "web")
+	assert.Contains(t, view, "10.0.0.5")
+	assert.Contains(t, view, "2 running / 3 total")
+}
+
+func TestModel_LoadMachines_BackendErrors(t *testing.T) {
+	m, tg, mp := newTestModel()
+	tg.listErr = errors.New("permission denied")
+	mp.listErr = fmt.Errorf("multipass: %w", deploy.ErrBackendUnavailable)
+
+	load(t, m)
+
+	assert.Empty(t, m.machines)
+	require.Len(t, m.errs, 1, "unavailable backends are not reported")
+	assert.Contains(t, m.View(), "permission denied")
+}
+
+func TestModel_Navigation(t *testing.T) {
+	m, _, _ := newTestModel()
+	load(t, m)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, 2, m.cursor, "cursor stops at last machine")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "db", m.selected().Name)
+}
+
+func TestModel_StartStop(t *testing.T) {
+	m, tg, _ := newTestModel()
+	load(t, m)
+	m.cursor = 1 // db
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	assert.Equal(t, "Starting db...", m.busy)
+	done := runAction(t, cmd)
+	assert.Equal(t, []string{"start db"}, tg.calls)
+
+	m.Update(done)
+	assert.Empty(t, m.busy)
+	assert.Contains(t, m.message, "Start db: done")
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	runAction(t, cmd)
+	assert.Equal(t, []string{"start db", "stop db"}, tg.calls)
+}
+
+func TestModel_ActionRoutedToBackend(t *testing.T) {
+	m, tg, mp := newTestModel()
+	load(t, m)
+	m.cursor = 2 // dev (multipass)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	runAction(t, cmd)
+
+	assert.Empty(t, tg.calls)
+	assert.Equal(t, []string{"stop dev"}, mp.calls)
+}
+
+func TestModel_ActionError(t *testing.T) {
+	m, tg, _ := newTestModel()
+	tg.actErr = errors.New("apply failed")
+	load(t, m)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	m.Update(runAction(t, cmd))
+
+	assert.Contains(t, m.message, "Stop web failed: apply failed")
+}
+
+func TestModel_Destroy_RequiresConfirmation(t *testing.T) {
+	m, tg, _ := newTestModel()
+	load(t, m)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	assert.Nil(t, cmd)
+	assert.True(t, m.confirmDestroy)
+	assert.True(t, m.HasFocusedInput())
+	assert.Contains(t, m.View(), "Destroy web?")
+
+	// Anything but y cancels
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	assert.False(t, m.confirmDestroy)
+	assert.Empty(t, tg.calls)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	runAction(t, cmd)
+	assert.Equal(t, []string{"destroy web"}, tg.calls)
+}
+
+func TestModel_OpenSession(t *testing.T) {
+	m, _, _ := newTestModel()
+	load(t, m)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "$ ssh me@10.0.0.5", m.message)
+
+	// Stopped machine has no SSH command
+	m.cursor = 1
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.message, "No SSH available")
+}
+
+func TestModel_RefreshTick(t *testing.T) {
+	m, _, _ := newTestModel()
+	m.Focus()
+	load(t, m)
+
+	// Stale tick from an earlier focus is ignored
+	_, cmd := m.Update(refreshTickMsg{gen: m.tickGen - 1})
+	assert.Nil(t, cmd)
+
+	_, cmd = m.Update(refreshTickMsg{gen: m.tickGen})
+	assert.NotNil(t, cmd)
+
+	m.Blur()
+	_, cmd = m.Update(refreshTickMsg{gen: m.tickGen})
+	assert.Nil(t, cmd)
+}
+
+func TestModel_EmptyView(t *testing.T) {
+	m := NewWithBackends(&fakeLifecycle{target: deploy.TargetMultipass})
+	m.SetSize(100, 30)
+	load(t, m)
+
+	assert.Contains(t, m.View(), "No VMs found")
+}
+
+func TestModel_KeyBindings(t *testing.T) {
+	m, _, _ := newTestModel()
+
+	assert.Contains(t, m.KeyBindings(), "[d] destroy")
+
+	m.confirmDestroy = true
+	assert.Contains(t, m.KeyBindings(), "[y] destroy")
+}
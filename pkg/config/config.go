@@ -13,7 +13,8 @@ type FullConfig struct {
 
 	// Package configuration
 	EnabledPackages  []string
-	DisabledPackages []string // Packages not selected (for DISABLED_PACKAGE_EXPORTS)
+	DisabledPackages []string        // Packages not selected (for DISABLED_PACKAGE_EXPORTS)
+	PackageScripts   []PackageScript // Installer scripts from overlay directories
 
 	// Git configuration
 	GitDefaultBranch       string
@@ -41,6 +42,18 @@ type FullConfig struct {
 	// Repository configuration
 	RepoURL    string
 	RepoBranch string
+
+	// Bare-metal install configuration (autoinstall ISOs)
+	Install InstallConfig
+}
+
+// PackageScript is an installer script that is not part of the cloned
+// repository and is shipped to the VM in the cloud-init user-data.
+type PackageScript struct {
+	Name     string // Package name
+	File     string // File name under scripts/packages/
+	Content  string
+	Override bool // Replaces a built-in script that install-all.sh runs
 }
 
 // NewFullConfig creates a new FullConfig with sensible defaults.
@@ -66,6 +79,11 @@ func NewFullConfig() *FullConfig {
 
 		// Repository defaults
 		RepoBranch: "main",
+
+		// Install defaults
+		Install: InstallConfig{
+			Storage: StorageConfig{Layout: StorageLVM},
+		},
 	}
 }
 
@@ -0,0 +1,67 @@
+package config
+
+import "fmt"
+
+// Storage layouts supported by the Ubuntu installer.
+const (
+	StorageLVM    = "lvm"    // LVM on the whole disk, optionally LUKS-encrypted
+	StorageDirect = "direct" // Plain partitions on the whole disk
+)
+
+// Disk size selectors for DiskMatch.Size.
+const (
+	DiskLargest  = "largest"
+	DiskSmallest = "smallest"
+)
+
+// InstallConfig holds options for bare-metal installs from an autoinstall
+// ISO. They do not apply to VMs.
+type InstallConfig struct {
+	Storage      StorageConfig
+	PasswordHash string   // crypt(3) hash for the user; password login is locked if empty
+	LateCommands []string // Run by the installer after installing, before reboot
+}
+
+// StorageConfig describes how the install disk is partitioned.
+type StorageConfig struct {
+	Layout     string    // StorageLVM (default) or StorageDirect
+	Match      DiskMatch // Install disk; the largest disk if empty
+	Passphrase string    // LUKS passphrase; encrypts the LVM layout when set
+}
+
+// DiskMatch selects the install disk. Empty fields are not matched on, and
+// Serial and Model accept shell globs such as "Samsung*".
+type DiskMatch struct {
+	Serial string
+	Model  string
+	Vendor string
+	Path   string // e.g. /dev/nvme0n1
+	Size   string // DiskLargest or DiskSmallest
+	SSD    *bool  // Only SSDs (true) or only rotational disks (false)
+}
+
+// IsZero reports whether no match rules are set.
+func (m DiskMatch) IsZero() bool {
+	return m == DiskMatch{}
+}
+
+// Validate checks the storage options.
+func (s StorageConfig) Validate() error {
+	switch s.Layout {
+	case "", StorageLVM:
+	case StorageDirect:
+		if s.Passphrase != "" {
+			return fmt.Errorf("storage encryption requires the lvm layout")
+		}
+	default:
+		return fmt.Errorf("unknown storage layout %q (want lvm or direct)", s.Layout)
+	}
+
+	switch s.Match.Size {
+	case "", DiskLargest, DiskSmallest:
+	default:
+		return fmt.Errorf("unknown disk size match %q (want largest or smallest)", s.Match.Size)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage StorageConfig
+		wantErr string
+	}{
+		{"default", StorageConfig{}, ""},
+		{"encrypted lvm", StorageConfig{Layout: StorageLVM, Passphrase: "secret"}, ""},
+		{"direct", StorageConfig{Layout: StorageDirect, Match: DiskMatch{Size: DiskSmallest}}, ""},
+		{"encrypted direct", StorageConfig{Layout: StorageDirect, Passphrase: "secret"}, "storage encryption requires the lvm layout"},
+		{"unknown layout", StorageConfig{Layout: "zfs"}, `unknown storage layout "zfs"`},
+		{"unknown size", StorageConfig{Match: DiskMatch{Size: "biggest"}}, `unknown disk size match "biggest"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.storage.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiskMatch_IsZero(t *testing.T) {
+	assert.True(t, DiskMatch{}.IsZero())
+	assert.False(t, DiskMatch{Path: "/dev/sda"}.IsZero())
+}
@@ -0,0 +1,115 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNothingToResume is returned by Resume when the checkpoint has no
+// completed stages to continue from.
+var ErrNothingToResume = errors.New("nothing to resume: no stage completed")
+
+// Checkpoint records the stages a deployment completed, the outputs they
+// produced and the options it ran with, so a failed deployment can be
+// resumed. It holds no config; resuming reuses the files already generated.
+type Checkpoint struct {
+	Target      DeploymentTarget  `json:"target"`
+	ProjectRoot string            `json:"project_root"`
+	Completed   []Stage           `json:"completed"`
+	Outputs     map[string]string `json:"outputs,omitempty"`
+
+	Multipass  *MultipassOptions  `json:"multipass,omitempty"`
+	Terragrunt *TerragruntOptions `json:"terragrunt,omitempty"`
+}
+
+// NewCheckpoint creates an empty checkpoint for a deployment to target
+// with opts.
+func NewCheckpoint(target DeploymentTarget, opts *DeployOptions) *Checkpoint {
+	c := &Checkpoint{
+		Target:      target,
+		ProjectRoot: opts.ProjectRoot,
+		Outputs:     make(map[string]string),
+	}
+	switch target {
+	case TargetMultipass:
+		mp := opts.Multipass
+		c.Multipass = &mp
+	case TargetTerragrunt:
+		tg := opts.Terragrunt
+		c.Terragrunt = &tg
+	}
+	return c
+}
+
+// Complete records stage as done along with the outputs it produced.
+func (c *Checkpoint) Complete(stage Stage, outputs map[string]string) {
+	if !c.Done(stage) {
+		c.Completed = append(c.Completed, stage)
+	}
+	for k, v := range outputs {
+		c.Outputs[k] = v
+	}
+}
+
+// Done reports whether stage completed. A nil checkpoint has no stages.
+func (c *Checkpoint) Done(stage Stage) bool {
+	if c == nil {
+		return false
+	}
+	for _, s := range c.Completed {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// Output returns a recorded output, or "" if there is none.
+func (c *Checkpoint) Output(key string) string {
+	if c == nil {
+		return ""
+	}
+	return c.Outputs[key]
+}
+
+// Resumable reports whether any stage completed.
+func (c *Checkpoint) Resumable() bool {
+	return c != nil && len(c.Completed) > 0
+}
+
+// Options returns deploy options that continue from the checkpoint.
+func (c *Checkpoint) Options() *DeployOptions {
+	opts := &DeployOptions{
+		ProjectRoot: c.ProjectRoot,
+		Checkpoint:  c,
+	}
+	if c.Multipass != nil {
+		opts.Multipass = *c.Multipass
+	}
+	if c.Terragrunt != nil {
+		opts.Terragrunt = *c.Terragrunt
+	}
+	return opts
+}
+
+// Resumer is implemented by deployers that can continue a failed
+// deployment from opts.Checkpoint, skipping the stages it completed.
+type Resumer interface {
+	Resume(ctx context.Context, opts *DeployOptions, progress ProgressCallback) (*DeployResult, error)
+}
+
+// Resume continues a failed deployment from opts.Checkpoint.
+func Resume(ctx context.Context, d Deployer, opts *DeployOptions, progress ProgressCallback) (*DeployResult, error) {
+	r, ok := d.(Resumer)
+	if !ok {
+		return nil, fmt.Errorf("%s deployments cannot be resumed", d.Name())
+	}
+	if opts.Checkpoint == nil || opts.Checkpoint.Target != d.Target() {
+		return nil, fmt.Errorf("no %s checkpoint to resume", d.Target())
+	}
+	if !opts.Checkpoint.Resumable() {
+		return nil, ErrNothingToResume
+	}
+	return r.Resume(ctx, opts, progress)
+}
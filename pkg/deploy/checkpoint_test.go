@@ -0,0 +1,101 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resumeDeployer is a Deployer that records Resume calls.
+type resumeDeployer struct {
+	cleanupDeployer
+	resumed *DeployOptions
+}
+
+func (d *resumeDeployer) Resume(_ context.Context, opts *DeployOptions, _ ProgressCallback) (*DeployResult, error) {
+	d.resumed = opts
+	return &DeployResult{Success: true}, nil
+}
+
+func TestCheckpoint(t *testing.T) {
+	opts := &DeployOptions{
+		ProjectRoot: "/project",
+		Multipass:   MultipassOptions{VMName: "dev", CPUs: 2},
+	}
+	c := NewCheckpoint(TargetMultipass, opts)
+	assert.False(t, c.Resumable())
+	assert.Nil(t, c.Terragrunt)
+
+	c.Complete(StageCloudInit, map[string]string{"cloud_init_path": "/tmp/ci.yaml"})
+	c.Complete(StageLaunching, map[string]string{"vm_name": "dev"})
+	c.Complete(StageLaunching, nil)
+	assert.True(t, c.Resumable())
+	assert.Equal(t, []Stage{StageCloudInit, StageLaunching}, c.Completed)
+	assert.True(t, c.Done(StageLaunching))
+	assert.False(t, c.Done(StageWaiting))
+	assert.Equal(t, "/tmp/ci.yaml", c.Output("cloud_init_path"))
+
+	// The options are copied, not shared
+	opts.Multipass.CPUs = 8
+	resumed := c.Options()
+	assert.Equal(t, "/project", resumed.ProjectRoot)
+	assert.Equal(t, 2, resumed.Multipass.CPUs)
+	assert.Same(t, c, resumed.Checkpoint)
+}
+
+func TestCheckpoint_Nil(t *testing.T) {
+	var c *Checkpoint
+	assert.False(t, c.Done(StageLaunching))
+	assert.Empty(t, c.Output("vm_name"))
+	assert.False(t, c.Resumable())
+}
+
+func TestCheckpoint_JSON(t *testing.T) {
+	opts := &DeployOptions{Terragrunt: TerragruntOptions{
+		VMName:  "web",
+		Apply:   true,
+		Approve: func(context.Context, string) (bool, error) { return true, nil },
+	}}
+	c := NewCheckpoint(TargetTerragrunt, opts)
+	c.Complete(StagePreparing, map[string]string{"config_dir": "/project/tf/web"})
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"vm_name":"web"`)
+
+	var got Checkpoint
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.True(t, got.Done(StagePreparing))
+	assert.True(t, got.Terragrunt.Apply)
+	assert.Nil(t, got.Terragrunt.Approve)
+}
+
+func TestResume(t *testing.T) {
+	d := &resumeDeployer{cleanupDeployer: cleanupDeployer{target: TargetMultipass}}
+	c := NewCheckpoint(TargetMultipass, &DeployOptions{})
+
+	_, err := Resume(context.Background(), d, &DeployOptions{}, NoOpProgress)
+	assert.EqualError(t, err, "no multipass checkpoint to resume")
+
+	_, err = Resume(context.Background(), d, c.Options(), NoOpProgress)
+	assert.ErrorIs(t, err, ErrNothingToResume)
+
+	c.Complete(StageLaunching, nil)
+	result, err := Resume(context.Background(), d, c.Options(), NoOpProgress)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Same(t, c, d.resumed.Checkpoint)
+
+	// Checkpoints are per target
+	other := NewCheckpoint(TargetTerragrunt, &DeployOptions{})
+	other.Complete(StagePreparing, nil)
+	_, err = Resume(context.Background(), d, other.Options(), NoOpProgress)
+	assert.Error(t, err)
+
+	// Deployers without Resume cannot continue
+	_, err = Resume(context.Background(), &cleanupDeployer{target: TargetMultipass}, c.Options(), NoOpProgress)
+	assert.EqualError(t, err, "Test deployments cannot be resumed")
+}
@@ -0,0 +1,50 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CleanupTimeout bounds how long RunCleanup waits for a deployer to remove
+// what a failed or cancelled deployment created.
+const CleanupTimeout = 5 * time.Minute
+
+// KeepOnFailure reports whether resources for target should be kept for
+// debugging when a deployment fails.
+func (o *DeployOptions) KeepOnFailure(target DeploymentTarget) bool {
+	switch target {
+	case TargetMultipass:
+		return o.Multipass.KeepOnFailure
+	case TargetTerragrunt:
+		return o.Terragrunt.KeepOnFailure
+	default:
+		return false
+	}
+}
+
+// RunCleanup runs the deployer's Cleanup after a failed or cancelled
+// deployment, reporting it as StageCleanup events. It still runs when ctx
+// has been cancelled, since cancelling is what usually leads here.
+func RunCleanup(ctx context.Context, d Deployer, opts *DeployOptions, progress ProgressCallback) error {
+	if d.Target() == TargetConfigOnly {
+		return nil
+	}
+
+	if opts.KeepOnFailure(d.Target()) {
+		progress(NewProgressEventWithDetail(StageCleanup, "Keeping resources for debugging", "keep on failure is set", -1))
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), CleanupTimeout)
+	defer cancel()
+
+	progress(NewProgressEventWithDetail(StageCleanup, "Cleaning up...", d.Name(), -1))
+	if err := d.Cleanup(ctx, opts); err != nil {
+		progress(NewErrorEventWithDetail("Cleanup failed", err.Error()))
+		return fmt.Errorf("failed to clean up: %w", err)
+	}
+	progress(NewProgressEvent(StageCleanup, "Cleanup complete", -1))
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cleanupDeployer is a Deployer that only records Cleanup calls.
+type cleanupDeployer struct {
+	target DeploymentTarget
+	err    error
+	calls  int
+	ctxErr error
+}
+
+func (d *cleanupDeployer) Name() string                  { return "Test" }
+func (d *cleanupDeployer) Target() DeploymentTarget      { return d.target }
+func (d *cleanupDeployer) Validate(*DeployOptions) error { return nil }
+
+func (d *cleanupDeployer) Deploy(context.Context, *DeployOptions, ProgressCallback) (*DeployResult, error) {
+	return nil, nil
+}
+
+func (d *cleanupDeployer) Cleanup(ctx context.Context, _ *DeployOptions) error {
+	d.calls++
+	d.ctxErr = ctx.Err()
+	return d.err
+}
+
+func TestRunCleanup(t *testing.T) {
+	// Cleanup still runs after the deployment was cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	d := &cleanupDeployer{target: TargetMultipass}
+	tracker := NewProgressTracker()
+	require.NoError(t, RunCleanup(ctx, d, &DeployOptions{}, tracker.Callback()))
+	assert.Equal(t, 1, d.calls)
+	assert.NoError(t, d.ctxErr)
+
+	events := tracker.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, StageCleanup, events[0].Stage)
+	assert.Equal(t, "Cleanup complete", events[1].Message)
+}
+
+func TestRunCleanup_KeepOnFailure(t *testing.T) {
+	d := &cleanupDeployer{target: TargetTerragrunt}
+	opts := &DeployOptions{Terragrunt: TerragruntOptions{KeepOnFailure: true}}
+
+	tracker := NewProgressTracker()
+	require.NoError(t, RunCleanup(context.Background(), d, opts, tracker.Callback()))
+	assert.Zero(t, d.calls)
+	require.Len(t, tracker.Events(), 1)
+	assert.Equal(t, "Keeping resources for debugging", tracker.LastEvent().Message)
+
+	// Config-only deployments have nothing to clean up
+	d = &cleanupDeployer{target: TargetConfigOnly}
+	require.NoError(t, RunCleanup(context.Background(), d, &DeployOptions{}, NoOpProgress))
+	assert.Zero(t, d.calls)
+}
+
+func TestRunCleanup_Error(t *testing.T) {
+	d := &cleanupDeployer{target: TargetMultipass, err: errors.New("delete failed")}
+
+	tracker := NewProgressTracker()
+	err := RunCleanup(context.Background(), d, &DeployOptions{}, tracker.Callback())
+	assert.EqualError(t, err, "failed to clean up: delete failed")
+	assert.True(t, tracker.HasErrors())
+	assert.Equal(t, "delete failed", tracker.LastEvent().Detail)
+}
@@ -0,0 +1,243 @@
+// Package configonly provides a deployer that only writes configuration files.
+package configonly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/generator"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/packages"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/validation"
+)
+
+// Deployer implements deploy.Deployer for config-only generation.
+type Deployer struct {
+	outputDir    string
+	generateYAML bool
+	registry     *packages.Registry
+}
+
+// New creates a new config-only deployer that writes files to outputDir.
+// When generateYAML is true, cloud-init/cloud-init.yaml is written as well.
+func New(outputDir string, generateYAML bool, registry *packages.Registry) *Deployer {
+	return &Deployer{
+		outputDir:    outputDir,
+		generateYAML: generateYAML,
+		registry:     registry,
+	}
+}
+
+// Name returns the deployer name.
+func (d *Deployer) Name() string {
+	return "Config Generator"
+}
+
+// Target returns the deployment target type.
+func (d *Deployer) Target() deploy.DeploymentTarget {
+	return deploy.TargetConfigOnly
+}
+
+// Validate checks if generation can proceed.
+func (d *Deployer) Validate(opts *deploy.DeployOptions) error {
+	if d.registry == nil {
+		return fmt.Errorf("package registry not available - cannot generate summary")
+	}
+	if opts.Config == nil {
+		return fmt.Errorf("configuration is required")
+	}
+	return nil
+}
+
+// Deploy writes config.env, summary.md, secrets.env and optionally cloud-init.yaml.
+func (d *Deployer) Deploy(ctx context.Context, opts *deploy.DeployOptions, progress deploy.ProgressCallback) (*deploy.DeployResult, error) {
+	if err := d.Validate(opts); err != nil {
+		return nil, err
+	}
+
+	cfg := opts.Config
+	outputDir := d.outputDir
+	if outputDir == "" {
+		outputDir = "."
+	}
+
+	// Ensure output directory exists
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Helper to safely call progress callback
+	reportProgress := func(event deploy.ProgressEvent) {
+		if progress != nil {
+			progress(event)
+		}
+	}
+
+	reportProgress(deploy.NewProgressEvent(deploy.StageConfig, "Generating configuration files...", 10))
+
+	// Generate config.env
+	reportProgress(deploy.NewProgressEvent(deploy.StageConfig, "Writing config.env...", 25))
+
+	configEnvPath := filepath.Join(outputDir, "config.env")
+	if err := d.writeConfigEnv(configEnvPath, cfg); err != nil {
+		return nil, fmt.Errorf("failed to write config.env: %w", err)
+	}
+
+	// Generate summary.md
+	reportProgress(deploy.NewProgressEvent(deploy.StageConfig, "Writing summary.md...", 40))
+
+	summaryPath := filepath.Join(outputDir, "summary.md")
+	if err := generator.GenerateSummary(cfg, d.registry, summaryPath); err != nil {
+		return nil, fmt.Errorf("failed to write summary.md: %w", err)
+	}
+
+	// Generate secrets.env
+	reportProgress(deploy.NewProgressEvent(deploy.StageConfig, "Writing cloud-init/secrets.env...", 55))
+
+	secretsDir := filepath.Join(outputDir, "cloud-init")
+	if err := os.MkdirAll(secretsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cloud-init directory: %w", err)
+	}
+
+	secretsEnvPath := filepath.Join(secretsDir, "secrets.env")
+	if err := d.writeSecretsEnv(secretsEnvPath, cfg); err != nil {
+		return nil, fmt.Errorf("failed to write secrets.env: %w", err)
+	}
+
+	// Generate cloud-init.yaml if requested
+	var logs []string
+	if d.generateYAML {
+		reportProgress(deploy.NewProgressEvent(deploy.StageConfig, "Writing cloud-init/cloud-init.yaml...", 75))
+
+		outputPath := filepath.Join(secretsDir, "cloud-init.yaml")
+
+		if err := generator.Generate(cfg, outputPath); err != nil {
+			return nil, fmt.Errorf("failed to generate cloud-init.yaml: %w", err)
+		}
+
+		reportProgress(deploy.NewProgressEventWithDetail(deploy.StageValidating, "Validating cloud-init.yaml...", outputPath, 90))
+
+		var err error
+		if logs, err = validation.CheckCloudInit(outputPath); err != nil {
+			return nil, err
+		}
+	}
+
+	reportProgress(deploy.NewProgressEvent(deploy.StageComplete, "Configuration files generated successfully", 100))
+
+	outputs := map[string]string{
+		"config.env":  configEnvPath,
+		"secrets.env": secretsEnvPath,
+		"summary.md":  summaryPath,
+	}
+	if d.generateYAML {
+		outputs["cloud-init.yaml"] = filepath.Join(secretsDir, "cloud-init.yaml")
+	}
+
+	return &deploy.DeployResult{
+		Success: true,
+		Target:  deploy.TargetConfigOnly,
+		Outputs: outputs,
+		Logs:    logs,
+	}, nil
+}
+
+// writeConfigEnv writes the config.env file
+func (d *Deployer) writeConfigEnv(path string, cfg *config.FullConfig) error {
+	var b strings.Builder
+
+	b.WriteString("# Generated by ucli - Configuration File\n")
+	b.WriteString("# This file contains non-sensitive configuration\n\n")
+
+	// User configuration
+	b.WriteString("# User Configuration\n")
+	b.WriteString(fmt.Sprintf("USERNAME=%q\n", cfg.Username))
+	b.WriteString(fmt.Sprintf("HOSTNAME=%q\n", cfg.Hostname))
+	b.WriteString(fmt.Sprintf("USER_NAME=%q\n", cfg.FullName))
+	b.WriteString(fmt.Sprintf("USER_EMAIL=%q\n", cfg.Email))
+	b.WriteString(fmt.Sprintf("MACHINE_USER_NAME=%q\n", cfg.MachineName))
+	b.WriteString("\n")
+
+	// Git configuration
+	b.WriteString("# Git Configuration\n")
+	b.WriteString(fmt.Sprintf("GIT_DEFAULT_BRANCH=%q\n", cfg.GitDefaultBranch))
+	b.WriteString(fmt.Sprintf("GIT_PUSH_AUTO_SETUP_REMOTE=%t\n", cfg.GitPushAutoSetupRemote))
+	b.WriteString(fmt.Sprintf("GIT_PULL_REBASE=%t\n", cfg.GitPullRebase))
+	b.WriteString(fmt.Sprintf("GIT_PAGER=%q\n", cfg.GitPager))
+	b.WriteString(fmt.Sprintf("GIT_URL_REWRITE_GITHUB=%t\n", cfg.GitURLRewriteGithub))
+	b.WriteString("\n")
+
+	// Tailscale configuration
+	b.WriteString("# Tailscale Configuration\n")
+	b.WriteString(fmt.Sprintf("TAILSCALE_SSH_ENABLED=%t\n", cfg.TailscaleSSHEnabled))
+	b.WriteString(fmt.Sprintf("TAILSCALE_EXIT_NODE_ADVERTISE=%t\n", cfg.TailscaleExitNode))
+	b.WriteString("\n")
+
+	// Docker configuration
+	b.WriteString("# Docker Configuration\n")
+	b.WriteString(fmt.Sprintf("DOCKER_ENABLED=%t\n", cfg.DockerEnabled))
+	b.WriteString(fmt.Sprintf("DOCKER_ADD_TO_GROUP=%t\n", cfg.DockerAddToGroup))
+	b.WriteString(fmt.Sprintf("DOCKER_START_ON_BOOT=%t\n", cfg.DockerStartOnBoot))
+	b.WriteString("\n")
+
+	// Package configuration
+	b.WriteString("# Package Configuration\n")
+	for _, pkg := range cfg.EnabledPackages {
+		envVar := "PACKAGE_" + strings.ToUpper(strings.ReplaceAll(pkg, "-", "_")) + "_ENABLED"
+		b.WriteString(fmt.Sprintf("export %s=true\n", envVar))
+	}
+	for _, pkg := range cfg.DisabledPackages {
+		envVar := "PACKAGE_" + strings.ToUpper(strings.ReplaceAll(pkg, "-", "_")) + "_ENABLED"
+		b.WriteString(fmt.Sprintf("export %s=false\n", envVar))
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// writeSecretsEnv writes the secrets.env file
+func (d *Deployer) writeSecretsEnv(path string, cfg *config.FullConfig) error {
+	var b strings.Builder
+
+	b.WriteString("# Generated by ucli - Secrets File\n")
+	b.WriteString("# This file contains sensitive configuration - DO NOT COMMIT\n\n")
+
+	// SSH keys
+	b.WriteString("# SSH Keys\n")
+	for i, key := range cfg.SSHPublicKeys {
+		b.WriteString(fmt.Sprintf("SSH_PUBLIC_KEY_%d=%q\n", i+1, key))
+	}
+	if len(cfg.SSHPublicKeys) > 0 {
+		b.WriteString(fmt.Sprintf("SSH_PUBLIC_KEY=%q\n", cfg.SSHPublicKeys[0]))
+	}
+	b.WriteString("\n")
+
+	// Tailscale
+	if cfg.TailscaleAuthKey != "" {
+		b.WriteString("# Tailscale\n")
+		b.WriteString(fmt.Sprintf("TAILSCALE_AUTH_KEY=%q\n", cfg.TailscaleAuthKey))
+		b.WriteString("\n")
+	}
+
+	// GitHub
+	if cfg.GithubUser != "" || cfg.GithubPAT != "" {
+		b.WriteString("# GitHub\n")
+		if cfg.GithubUser != "" {
+			b.WriteString(fmt.Sprintf("GITHUB_USER=%q\n", cfg.GithubUser))
+		}
+		if cfg.GithubPAT != "" {
+			b.WriteString(fmt.Sprintf("GITHUB_PAT=%q\n", cfg.GithubPAT))
+		}
+		b.WriteString("\n")
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0600) // More restrictive permissions for secrets
+}
+
+// Cleanup is a no-op; nothing is created outside the output directory.
+func (d *Deployer) Cleanup(_ context.Context, _ *deploy.DeployOptions) error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package configonly
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/packages"
+)
+
+func testConfig() *config.FullConfig {
+	cfg := config.NewFullConfig()
+	cfg.Username = "tester"
+	cfg.Hostname = "testbox"
+	cfg.SSHPublicKeys = []string{"ssh-ed25519 AAAA test@example"}
+	return cfg
+}
+
+func TestDeployer_Validate(t *testing.T) {
+	d := New(t.TempDir(), false, nil)
+	assert.Error(t, d.Validate(&deploy.DeployOptions{Config: testConfig()}))
+
+	d = New(t.TempDir(), false, packages.NewRegistry())
+	assert.Error(t, d.Validate(&deploy.DeployOptions{}))
+	assert.NoError(t, d.Validate(&deploy.DeployOptions{Config: testConfig()}))
+}
+
+func TestDeployer_Deploy(t *testing.T) {
+	registry, err := packages.DiscoverEmbedded()
+	require.NoError(t, err)
+
+	outDir := t.TempDir()
+	d := New(outDir, true, registry)
+	tracker := deploy.NewProgressTracker()
+
+	result, err := d.Deploy(context.Background(), &deploy.DeployOptions{Config: testConfig()}, tracker.Callback())
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	assert.True(t, result.Success)
+	assert.Equal(t, deploy.TargetConfigOnly, result.Target)
+	assert.False(t, tracker.HasErrors())
+
+	for _, name := range []string{"config.env", "summary.md", filepath.Join("cloud-init", "secrets.env"), filepath.Join("cloud-init", "cloud-init.yaml")} {
+		assert.FileExists(t, filepath.Join(outDir, name))
+	}
+
+	info, err := os.Stat(filepath.Join(outDir, "cloud-init", "secrets.env"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestDeployer_WriteConfigEnv(t *testing.T) {
+	cfg := testConfig()
+	cfg.GitDefaultBranch = "trunk"
+	cfg.TailscaleExitNode = false
+	cfg.DockerEnabled = false
+
+	path := filepath.Join(t.TempDir(), "config.env")
+	require.NoError(t, New(t.TempDir(), false, nil).writeConfigEnv(path, cfg))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `GIT_DEFAULT_BRANCH="trunk"`)
+	assert.Contains(t, string(data), "TAILSCALE_EXIT_NODE_ADVERTISE=false\n")
+	assert.Contains(t, string(data), "DOCKER_ENABLED=false\n")
+}
@@ -42,7 +42,7 @@ func (t DeploymentTarget) Description() string {
 	case TargetMultipass:
 		return "Create a local VM using Multipass for testing"
 	case TargetTerragrunt:
-		return "Generate Terragrunt config for libvirt VM (run manually)"
+		return "Generate Terragrunt config for libvirt VM and optionally apply it"
 	case TargetConfigOnly:
 		return "Generate config files only (no deployment)"
 	default:
@@ -71,16 +71,32 @@ type DeployOptions struct {
 
 	// Terragrunt-specific options
 	Terragrunt TerragruntOptions
+
+	// Checkpoint is the failed deployment to continue; see Resume
+	Checkpoint *Checkpoint
+}
+
+// VMName returns the VM name set for target, or "" if the deployer will
+// generate one.
+func (o *DeployOptions) VMName(target DeploymentTarget) string {
+	switch target {
+	case TargetMultipass:
+		return o.Multipass.VMName
+	case TargetTerragrunt:
+		return o.Terragrunt.VMName
+	default:
+		return ""
+	}
 }
 
 // MultipassOptions contains Multipass-specific deployment options.
 type MultipassOptions struct {
-	VMName        string
-	CPUs          int
-	MemoryMB      int
-	DiskGB        int
-	UbuntuVersion string // e.g., "24.04"
-	KeepOnFailure bool   // Keep VM for debugging on failure
+	VMName        string `json:"vm_name"`
+	CPUs          int    `json:"cpus"`
+	MemoryMB      int    `json:"memory_mb"`
+	DiskGB        int    `json:"disk_gb"`
+	UbuntuVersion string `json:"ubuntu_version"`  // e.g., "24.04"
+	KeepOnFailure bool   `json:"keep_on_failure"` // Keep VM for debugging on failure
 }
 
 // DefaultMultipassOptions returns sensible defaults for Multipass.
@@ -97,20 +113,32 @@ func DefaultMultipassOptions() MultipassOptions {
 
 // TerragruntOptions contains Terragrunt-specific deployment options.
 type TerragruntOptions struct {
-	WorkDir       string // Terragrunt working directory (relative to project root)
-	AutoApprove   bool   // Skip interactive approval
-	VMName        string
-	CPUs          int
-	MemoryMB      int
-	DiskGB        int
-	Autostart     bool   // Start VM automatically on host boot
-	LibvirtURI    string // Libvirt connection URI (e.g., "qemu:///system")
-	StoragePool   string // Libvirt storage pool name
-	NetworkName   string // Libvirt network name
-	UbuntuImage   string // Path to Ubuntu cloud image
-	KeepOnFailure bool   // Keep resources for debugging on failure
+	WorkDir       string `json:"work_dir"`     // Terragrunt working directory (relative to project root)
+	AutoApprove   bool   `json:"auto_approve"` // Skip interactive approval
+	VMName        string `json:"vm_name"`
+	CPUs          int    `json:"cpus"`
+	MemoryMB      int    `json:"memory_mb"`
+	DiskGB        int    `json:"disk_gb"`
+	Autostart     bool   `json:"autostart"`       // Start VM automatically on host boot
+	LibvirtURI    string `json:"libvirt_uri"`     // Libvirt connection URI (e.g., "qemu:///system")
+	StoragePool   string `json:"storage_pool"`    // Libvirt storage pool name
+	NetworkName   string `json:"network_name"`    // Libvirt network name
+	UbuntuImage   string `json:"ubuntu_image"`    // Path to Ubuntu cloud image
+	KeepOnFailure bool   `json:"keep_on_failure"` // Keep resources for debugging on failure
+
+	// Apply runs terragrunt init/plan/apply after generating the config.
+	// Without it only the config files are written.
+	Apply bool `json:"apply"`
+
+	// Approve is asked to confirm the plan before apply when AutoApprove
+	// is not set.
+	Approve ApprovalFunc `json:"-"`
 }
 
+// ApprovalFunc asks the user to confirm a plan. summary is a short
+// description of the pending changes (e.g., "Plan: 3 to add, 0 to change, 0 to destroy.").
+type ApprovalFunc func(ctx context.Context, summary string) (bool, error)
+
 // DefaultTerragruntOptions returns sensible defaults for Terragrunt.
 func DefaultTerragruntOptions() TerragruntOptions {
 	return TerragruntOptions{
@@ -135,6 +163,9 @@ type DeployResult struct {
 	Outputs  map[string]string // Target-specific outputs (IP, VM name, etc.)
 	Logs     []string          // Captured log lines
 	Error    error
+
+	// Checkpoint records what completed, for Resume after a failure
+	Checkpoint *Checkpoint
 }
 
 // Deployer executes deployment to a target.
@@ -0,0 +1,35 @@
+// Package deploytest provides a fake deploy.CommandExecutor for testing
+// deployers and lifecycle backends without the real tools.
+package deploytest
+
+import (
+	"context"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+)
+
+// Executor is a fake command executor that records the commands it runs.
+// Every tool is found in /usr/bin and every command succeeds silently
+// unless LookPathFunc or RunFunc say otherwise.
+type Executor struct {
+	LookPathFunc func(file string) (string, error)
+	RunFunc      func(cmd deploy.Command) ([]byte, []byte, error)
+	Calls        []deploy.Command
+}
+
+// LookPath implements deploy.CommandExecutor.
+func (e *Executor) LookPath(file string) (string, error) {
+	if e.LookPathFunc != nil {
+		return e.LookPathFunc(file)
+	}
+	return "/usr/bin/" + file, nil
+}
+
+// Run implements deploy.CommandExecutor.
+func (e *Executor) Run(_ context.Context, cmd deploy.Command) ([]byte, []byte, error) {
+	e.Calls = append(e.Calls, cmd)
+	if e.RunFunc != nil {
+		return e.RunFunc(cmd)
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,107 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Command describes an external command to run.
+type Command struct {
+	Name string
+	Args []string
+	Dir  string   // Working directory (current directory if empty)
+	Env  []string // Extra environment variables (KEY=VALUE)
+
+	// Stream is called with each line of stdout and stderr as it is
+	// produced. Output is still captured and returned by Run.
+	Stream func(line string)
+}
+
+// String returns the command line for display.
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// CommandExecutor is an interface for executing commands, allowing
+// deployers and lifecycle backends to be tested without the real tools.
+type CommandExecutor interface {
+	LookPath(file string) (string, error)
+	Run(ctx context.Context, cmd Command) (stdout, stderr []byte, err error)
+}
+
+// RealExecutor is the default command executor that uses the real system.
+type RealExecutor struct{}
+
+// LookPath finds the path to an executable.
+func (e *RealExecutor) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+// Run executes a command and returns its stdout and stderr.
+func (e *RealExecutor) Run(ctx context.Context, c Command) ([]byte, []byte, error) {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if c.Stream != nil {
+		var mu sync.Mutex
+		outLines := &lineWriter{mu: &mu, fn: c.Stream}
+		errLines := &lineWriter{mu: &mu, fn: c.Stream}
+		defer outLines.Flush()
+		defer errLines.Flush()
+		cmd.Stdout = io.MultiWriter(&stdout, outLines)
+		cmd.Stderr = io.MultiWriter(&stderr, errLines)
+	}
+
+	err := cmd.Run()
+
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// lineWriter calls fn for each complete line written to it.
+// exec.Cmd copies stdout and stderr from separate goroutines, so the
+// writers for one command share mu to serialize calls to fn.
+type lineWriter struct {
+	mu  *sync.Mutex
+	buf []byte
+	fn  func(line string)
+}
+
+// Write implements io.Writer.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.fn(strings.TrimRight(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush emits any trailing partial line.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.fn(strings.TrimRight(string(w.buf), "\r"))
+		w.buf = nil
+	}
+}
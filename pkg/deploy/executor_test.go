@@ -0,0 +1,43 @@
+package deploy
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand_String(t *testing.T) {
+	c := Command{Name: "terragrunt", Args: []string{"apply", "-auto-approve"}}
+	assert.Equal(t, "terragrunt apply -auto-approve", c.String())
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	w := &lineWriter{mu: &sync.Mutex{}, fn: func(line string) { lines = append(lines, line) }}
+
+	_, _ = w.Write([]byte("first\r\nsec"))
+	_, _ = w.Write([]byte("ond\nthird"))
+	assert.Equal(t, []string{"first", "second"}, lines)
+
+	w.Flush()
+	assert.Equal(t, []string{"first", "second", "third"}, lines)
+}
+
+func TestRealExecutor_Stream(t *testing.T) {
+	var lines []string
+	e := &RealExecutor{}
+
+	stdout, _, err := e.Run(context.Background(), Command{
+		Name:   "sh",
+		Args:   []string{"-c", "echo one; echo two >&2; printf three"},
+		Env:    []string{"UCLI_TEST=1"},
+		Stream: func(line string) { lines = append(lines, line) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "one\nthree", string(stdout))
+	assert.ElementsMatch(t, []string{"one", "two", "three"}, lines)
+}
@@ -0,0 +1,68 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrBackendUnavailable is returned by a Lifecycle when its tooling is not
+// installed on this host (e.g., multipass or virsh missing).
+var ErrBackendUnavailable = errors.New("backend not available")
+
+// MachineState represents the runtime state of a deployed machine.
+type MachineState string
+
+const (
+	StateRunning    MachineState = "running"
+	StateStopped    MachineState = "stopped"
+	StateSuspended  MachineState = "suspended"
+	StateStarting   MachineState = "starting"
+	StateNotCreated MachineState = "not created" // Config exists but was never applied
+	StateUnknown    MachineState = "unknown"
+)
+
+// String returns the string representation of the state.
+func (s MachineState) String() string {
+	return string(s)
+}
+
+// Machine describes a machine managed by a Lifecycle backend.
+type Machine struct {
+	Name     string
+	Target   DeploymentTarget
+	State    MachineState
+	IP       string
+	CPUs     int
+	MemoryMB int
+	DiskGB   int
+	Image    string // Ubuntu release or image path
+	Dir      string // Config directory (Terragrunt only)
+
+	// Commands for interactive access; empty if not available
+	ConsoleCommand []string
+	SSHCommand     []string
+}
+
+// Lifecycle manages machines after they have been created by a Deployer.
+type Lifecycle interface {
+	// Target returns the deployment target this backend manages.
+	Target() DeploymentTarget
+
+	// List returns all machines known to the backend.
+	List(ctx context.Context) ([]Machine, error)
+
+	// Status returns the current state of a machine.
+	Status(ctx context.Context, name string) (MachineState, error)
+
+	// Start starts a stopped machine.
+	Start(ctx context.Context, name string) error
+
+	// Stop stops a running machine.
+	Stop(ctx context.Context, name string) error
+
+	// Destroy deletes the machine and its resources.
+	Destroy(ctx context.Context, name string) error
+
+	// Info returns details for a single machine.
+	Info(ctx context.Context, name string) (*Machine, error)
+}
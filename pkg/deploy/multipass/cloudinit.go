@@ -12,9 +12,19 @@ import (
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/generator"
 )
 
-// generateCloudInit generates the cloud-init.yaml file using embedded template.
+// CloudInitPath returns a cloud-init.yaml path of its own for vmName, for
+// deployments that run at the same time.
+func CloudInitPath(projectRoot, vmName string) string {
+	return filepath.Join(projectRoot, "cloud-init", "multipass", vmName, "cloud-init.yaml")
+}
+
+// generateCloudInit generates the cloud-init.yaml file using embedded template,
+// at opts.CloudInitPath or cloud-init/cloud-init.yaml.
 func (d *Deployer) generateCloudInit(opts *deploy.DeployOptions) (string, error) {
-	outputPath := filepath.Join(opts.ProjectRoot, "cloud-init", "cloud-init.yaml")
+	outputPath := opts.CloudInitPath
+	if outputPath == "" {
+		outputPath = filepath.Join(opts.ProjectRoot, "cloud-init", "cloud-init.yaml")
+	}
 
 	if err := generator.Generate(opts.Config, outputPath); err != nil {
 		return "", fmt.Errorf("failed to generate cloud-init.yaml: %w", err)
@@ -32,10 +42,8 @@ func (d *Deployer) waitForCloudInit(ctx context.Context, vmName string, progress
 	progressPct := 50
 
 	for time.Now().Before(deadline) {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
 		// Check cloud-init status
@@ -61,7 +69,9 @@ func (d *Deployer) waitForCloudInit(ctx context.Context, vmName string, progress
 				detail,
 				progressPct,
 			))
-			time.Sleep(pollInterval)
+			if err := sleep(ctx, pollInterval); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -92,12 +102,27 @@ func (d *Deployer) waitForCloudInit(ctx context.Context, vmName string, progress
 			progressPct,
 		))
 
-		time.Sleep(pollInterval)
+		if err := sleep(ctx, pollInterval); err != nil {
+			return err
+		}
 	}
 
 	return fmt.Errorf("timeout waiting for cloud-init to complete")
 }
 
+// sleep waits for d, returning early with the context's error if it is
+// cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // InstallInstructions returns installation instructions for multipass.
 func InstallInstructions() string {
 	return `Multipass is required for VM deployment.
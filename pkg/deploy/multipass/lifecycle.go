@@ -0,0 +1,232 @@
+package multipass
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+)
+
+var _ deploy.Lifecycle = (*Lifecycle)(nil)
+
+// Lifecycle implements deploy.Lifecycle for Multipass instances.
+type Lifecycle struct {
+	binaryPath string
+	exec       deploy.CommandExecutor
+}
+
+// NewLifecycle creates a new Multipass lifecycle backend.
+func NewLifecycle() *Lifecycle {
+	return NewLifecycleWithExecutor(&deploy.RealExecutor{})
+}
+
+// NewLifecycleWithExecutor creates a Multipass lifecycle backend with a custom executor.
+func NewLifecycleWithExecutor(exec deploy.CommandExecutor) *Lifecycle {
+	return &Lifecycle{
+		binaryPath: "multipass",
+		exec:       exec,
+	}
+}
+
+// Target returns the deployment target type.
+func (l *Lifecycle) Target() deploy.DeploymentTarget {
+	return deploy.TargetMultipass
+}
+
+// listOutput is the JSON output of `multipass list --format json`.
+type listOutput struct {
+	List []struct {
+		Name    string   `json:"name"`
+		State   string   `json:"state"`
+		IPv4    []string `json:"ipv4"`
+		Release string   `json:"release"`
+	} `json:"list"`
+}
+
+// infoOutput is the JSON output of `multipass info <name> --format json`.
+type infoOutput struct {
+	Info map[string]struct {
+		State        string   `json:"state"`
+		IPv4         []string `json:"ipv4"`
+		ImageRelease string   `json:"image_release"`
+		CPUCount     flexInt  `json:"cpu_count"`
+		Memory       struct {
+			Total flexInt `json:"total"`
+		} `json:"memory"`
+		Disks map[string]struct {
+			Total flexInt `json:"total"`
+		} `json:"disks"`
+	} `json:"info"`
+}
+
+// flexInt decodes integers that multipass reports either as numbers or strings.
+type flexInt int64
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*f = 0
+		return nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q: %w", s, err)
+	}
+	*f = flexInt(n)
+	return nil
+}
+
+// List returns all Multipass instances.
+func (l *Lifecycle) List(ctx context.Context) ([]deploy.Machine, error) {
+	out, err := l.run(ctx, "list", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed listOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse multipass list output: %w", err)
+	}
+
+	machines := make([]deploy.Machine, 0, len(parsed.List))
+	for _, item := range parsed.List {
+		state := parseState(item.State)
+		if state == "" {
+			continue // Deleted but not purged
+		}
+		m := deploy.Machine{
+			Name:   item.Name,
+			Target: deploy.TargetMultipass,
+			State:  state,
+			IP:     firstIP(item.IPv4),
+			Image:  item.Release,
+		}
+		setCommands(&m)
+		machines = append(machines, m)
+	}
+
+	return machines, nil
+}
+
+// Info returns details for a single instance.
+func (l *Lifecycle) Info(ctx context.Context, name string) (*deploy.Machine, error) {
+	out, err := l.run(ctx, "info", name, "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed infoOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse multipass info output: %w", err)
+	}
+
+	info, ok := parsed.Info[name]
+	if !ok {
+		return nil, fmt.Errorf("instance %q not found", name)
+	}
+
+	m := &deploy.Machine{
+		Name:     name,
+		Target:   deploy.TargetMultipass,
+		State:    parseState(info.State),
+		IP:       firstIP(info.IPv4),
+		CPUs:     int(info.CPUCount),
+		MemoryMB: int(info.Memory.Total / (1024 * 1024)),
+		Image:    info.ImageRelease,
+	}
+	if m.State == "" {
+		m.State = deploy.StateUnknown
+	}
+	var diskBytes int64
+	for _, disk := range info.Disks {
+		diskBytes += int64(disk.Total)
+	}
+	m.DiskGB = int(diskBytes / (1024 * 1024 * 1024))
+	setCommands(m)
+
+	return m, nil
+}
+
+// Status returns the current state of an instance.
+func (l *Lifecycle) Status(ctx context.Context, name string) (deploy.MachineState, error) {
+	m, err := l.Info(ctx, name)
+	if err != nil {
+		return deploy.StateUnknown, err
+	}
+	return m.State, nil
+}
+
+// Start starts an instance.
+func (l *Lifecycle) Start(ctx context.Context, name string) error {
+	_, err := l.run(ctx, "start", name)
+	return err
+}
+
+// Stop stops an instance.
+func (l *Lifecycle) Stop(ctx context.Context, name string) error {
+	_, err := l.run(ctx, "stop", name)
+	return err
+}
+
+// Destroy deletes and purges an instance.
+func (l *Lifecycle) Destroy(ctx context.Context, name string) error {
+	_, err := l.run(ctx, "delete", "--purge", name)
+	return err
+}
+
+// run executes a multipass command and returns stdout.
+func (l *Lifecycle) run(ctx context.Context, args ...string) ([]byte, error) {
+	path, err := l.exec.LookPath(l.binaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("multipass: %w", deploy.ErrBackendUnavailable)
+	}
+
+	stdout, stderr, err := l.exec.Run(ctx, deploy.Command{Name: path, Args: args})
+	if err != nil {
+		if msg := strings.TrimSpace(string(stderr)); msg != "" {
+			return nil, fmt.Errorf("multipass %s failed: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("multipass %s failed: %w", args[0], err)
+	}
+
+	return stdout, nil
+}
+
+// parseState maps a multipass state string to a MachineState.
+// Returns an empty state for deleted instances.
+func parseState(s string) deploy.MachineState {
+	switch strings.ToLower(s) {
+	case "running":
+		return deploy.StateRunning
+	case "stopped":
+		return deploy.StateStopped
+	case "suspended", "suspending":
+		return deploy.StateSuspended
+	case "starting", "restarting":
+		return deploy.StateStarting
+	case "deleted":
+		return ""
+	default:
+		return deploy.StateUnknown
+	}
+}
+
+// firstIP returns the first IPv4 address, if any.
+func firstIP(ips []string) string {
+	if len(ips) > 0 {
+		return ips[0]
+	}
+	return ""
+}
+
+// setCommands fills in console and SSH commands for an instance.
+func setCommands(m *deploy.Machine) {
+	m.ConsoleCommand = []string{"multipass", "shell", m.Name}
+	if m.IP != "" {
+		m.SSHCommand = []string{"ssh", m.IP}
+	}
+}
@@ -0,0 +1,168 @@
+package multipass
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/deploytest"
+)
+
+const sampleList = `{
+  "list": [
+    {"ipv4": ["192.168.64.5"], "name": "dev", "release": "Ubuntu 24.04 LTS", "state": "Running"},
+    {"ipv4": [], "name": "old", "release": "Ubuntu 22.04 LTS", "state": "Stopped"},
+    {"ipv4": [], "name": "gone", "release": "Ubuntu 22.04 LTS", "state": "Deleted"}
+  ]
+}`
+
+const sampleInfo = `{
+  "errors": [],
+  "info": {
+    "dev": {
+      "cpu_count": "2",
+      "disks": {"sda1": {"total": "21474836480", "used": "2147483648"}},
+      "image_release": "24.04 LTS",
+      "ipv4": ["192.168.64.5"],
+      "memory": {"total": 4294967296, "used": 524288000},
+      "state": "Running"
+    }
+  }
+}`
+
+func TestParseState(t *testing.T) {
+	tests := []struct {
+		in   string
+		want deploy.MachineState
+	}{
+		{"Running", deploy.StateRunning},
+		{"Stopped", deploy.StateStopped},
+		{"Suspended", deploy.StateSuspended},
+		{"Starting", deploy.StateStarting},
+		{"Deleted", ""},
+		{"Unknown", deploy.StateUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseState(tt.in))
+		})
+	}
+}
+
+func TestFlexInt(t *testing.T) {
+	var v struct {
+		A flexInt `json:"a"`
+		B flexInt `json:"b"`
+		C flexInt `json:"c"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"a": "2", "b": 2062614528, "c": ""}`), &v))
+
+	assert.Equal(t, flexInt(2), v.A)
+	assert.Equal(t, flexInt(2062614528), v.B)
+	assert.Equal(t, flexInt(0), v.C)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a": "two"}`), &v))
+}
+
+func TestLifecycle_List(t *testing.T) {
+	exec := &deploytest.Executor{
+		RunFunc: func(cmd deploy.Command) ([]byte, []byte, error) {
+			return []byte(sampleList), nil, nil
+		},
+	}
+	l := NewLifecycleWithExecutor(exec)
+
+	machines, err := l.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, machines, 2, "deleted instances are skipped")
+
+	assert.Equal(t, "/usr/bin/multipass", exec.Calls[0].Name)
+	assert.Equal(t, []string{"list", "--format", "json"}, exec.Calls[0].Args)
+
+	assert.Equal(t, "dev", machines[0].Name)
+	assert.Equal(t, deploy.StateRunning, machines[0].State)
+	assert.Equal(t, "192.168.64.5", machines[0].IP)
+	assert.Equal(t, []string{"ssh", "192.168.64.5"}, machines[0].SSHCommand)
+	assert.Equal(t, []string{"multipass", "shell", "dev"}, machines[0].ConsoleCommand)
+
+	assert.Equal(t, deploy.StateStopped, machines[1].State)
+	assert.Empty(t, machines[1].SSHCommand)
+}
+
+func TestLifecycle_Info(t *testing.T) {
+	exec := &deploytest.Executor{
+		RunFunc: func(cmd deploy.Command) ([]byte, []byte, error) {
+			return []byte(sampleInfo), nil, nil
+		},
+	}
+	l := NewLifecycleWithExecutor(exec)
+
+	m, err := l.Info(context.Background(), "dev")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"info", "dev", "--format", "json"}, exec.Calls[0].Args)
+	assert.Equal(t, 2, m.CPUs)
+	assert.Equal(t, 4096, m.MemoryMB)
+	assert.Equal(t, 20, m.DiskGB)
+	assert.Equal(t, "24.04 LTS", m.Image)
+
+	state, err := l.Status(context.Background(), "dev")
+	require.NoError(t, err)
+	assert.Equal(t, deploy.StateRunning, state)
+
+	_, err = l.Info(context.Background(), "other")
+	assert.Error(t, err)
+}
+
+func TestLifecycle_Actions(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(l *Lifecycle) error
+		want []string
+	}{
+		{"start", func(l *Lifecycle) error { return l.Start(context.Background(), "dev") }, []string{"start", "dev"}},
+		{"stop", func(l *Lifecycle) error { return l.Stop(context.Background(), "dev") }, []string{"stop", "dev"}},
+		{"destroy", func(l *Lifecycle) error { return l.Destroy(context.Background(), "dev") }, []string{"delete", "--purge", "dev"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := &deploytest.Executor{}
+			require.NoError(t, tt.run(NewLifecycleWithExecutor(exec)))
+			require.Len(t, exec.Calls, 1)
+			assert.Equal(t, tt.want, exec.Calls[0].Args)
+		})
+	}
+}
+
+func TestLifecycle_CommandError(t *testing.T) {
+	exec := &deploytest.Executor{
+		RunFunc: func(cmd deploy.Command) ([]byte, []byte, error) {
+			return nil, []byte("start failed: instance \"dev\" does not exist\n"), errors.New("exit status 2")
+		},
+	}
+	l := NewLifecycleWithExecutor(exec)
+
+	err := l.Start(context.Background(), "dev")
+	require.Error(t, err)
+	assert.Equal(t, `multipass start failed: start failed: instance "dev" does not exist`, err.Error())
+}
+
+func TestLifecycle_NotInstalled(t *testing.T) {
+	exec := &deploytest.Executor{
+		LookPathFunc: func(file string) (string, error) {
+			return "", errors.New("not found")
+		},
+	}
+	l := NewLifecycleWithExecutor(exec)
+
+	_, err := l.List(context.Background())
+	assert.ErrorIs(t, err, deploy.ErrBackendUnavailable)
+	assert.Empty(t, exec.Calls)
+}
@@ -4,22 +4,31 @@ package multipass
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/validation"
 )
 
 // Deployer implements deploy.Deployer for Multipass VMs.
 type Deployer struct {
 	binaryPath string
+	exec       deploy.CommandExecutor
 	verbose    bool
+	launched   string // VM this deployer launched, for Cleanup
 }
 
 // New creates a new Multipass deployer.
 func New() *Deployer {
+	return NewWithExecutor(&deploy.RealExecutor{})
+}
+
+// NewWithExecutor creates a Multipass deployer with a custom executor.
+func NewWithExecutor(exec deploy.CommandExecutor) *Deployer {
 	return &Deployer{
 		binaryPath: "multipass",
+		exec:       exec,
 	}
 }
 
@@ -60,10 +69,23 @@ func (d *Deployer) Validate(opts *deploy.DeployOptions) error {
 
 // Deploy executes the Multipass deployment.
 func (d *Deployer) Deploy(ctx context.Context, opts *deploy.DeployOptions, progress deploy.ProgressCallback) (*deploy.DeployResult, error) {
+	return d.run(ctx, opts, nil, progress)
+}
+
+// Resume continues a failed deployment from opts.Checkpoint. A VM that was
+// launched is not launched again; waiting for cloud-init and reading the VM
+// info are repeated.
+func (d *Deployer) Resume(ctx context.Context, opts *deploy.DeployOptions, progress deploy.ProgressCallback) (*deploy.DeployResult, error) {
+	return d.run(ctx, opts, opts.Checkpoint, progress)
+}
+
+// run deploys, skipping the stages prev completed.
+func (d *Deployer) run(ctx context.Context, opts *deploy.DeployOptions, prev *deploy.Checkpoint, progress deploy.ProgressCallback) (*deploy.DeployResult, error) {
 	result := &deploy.DeployResult{
-		Target:  deploy.TargetMultipass,
-		Outputs: make(map[string]string),
-		Logs:    make([]string, 0),
+		Target:     deploy.TargetMultipass,
+		Outputs:    make(map[string]string),
+		Logs:       make([]string, 0),
+		Checkpoint: deploy.NewCheckpoint(deploy.TargetMultipass, opts),
 	}
 	start := time.Now()
 
@@ -74,49 +96,117 @@ func (d *Deployer) Deploy(ctx context.Context, opts *deploy.DeployOptions, progr
 		"multipass version",
 		5,
 	))
-	if err := d.Validate(opts); err != nil {
+	var err error
+	if prev != nil {
+		// The config is not needed once cloud-init.yaml exists
+		err = d.checkInstalled()
+	} else {
+		err = d.Validate(opts)
+	}
+	if err != nil {
 		return d.fail(result, err, start), err
 	}
 
+	username := prev.Output("user")
+	if opts.Config != nil && opts.Config.Username != "" {
+		username = opts.Config.Username
+	}
+	if username == "" {
+		username = "ubuntu"
+	}
+
 	// Stage 2: Generate cloud-init.yaml
-	progress(deploy.NewProgressEventWithDetail(
-		deploy.StageCloudInit,
-		"Generating cloud-init.yaml...",
-		fmt.Sprintf("Template: cloud-init/cloud-init.template.yaml"),
-		15,
-	))
-	cloudInitPath, err := d.generateCloudInit(opts)
-	if err != nil {
-		return d.fail(result, err, start), err
+	launched := prev.Done(deploy.StageLaunching)
+	cloudInitPath := prev.Output("cloud_init_path")
+	switch {
+	case launched:
+		// Already passed to the VM
+	case prev.Done(deploy.StageCloudInit) && fileExists(cloudInitPath):
+		progress(deploy.NewProgressEventWithDetail(
+			deploy.StageCloudInit,
+			"Reusing cloud-init.yaml...",
+			cloudInitPath,
+			20,
+		))
+	default:
+		if opts.Config == nil {
+			err := fmt.Errorf("cloud-init.yaml from the failed deployment is missing; start over")
+			return d.fail(result, err, start), err
+		}
+		progress(deploy.NewProgressEventWithDetail(
+			deploy.StageCloudInit,
+			"Generating cloud-init.yaml...",
+			fmt.Sprintf("User: %s, host: %s", opts.Config.Username, opts.Config.Hostname),
+			15,
+		))
+		cloudInitPath, err = d.generateCloudInit(opts)
+		if err != nil {
+			return d.fail(result, err, start), err
+		}
+
+		progress(deploy.NewProgressEventWithDetail(
+			deploy.StageValidating,
+			"Validating cloud-init.yaml...",
+			cloudInitPath,
+			20,
+		))
+		warnings, err := validation.CheckCloudInit(cloudInitPath)
+		if err != nil {
+			return d.fail(result, err, start), err
+		}
+		result.Logs = append(result.Logs, warnings...)
 	}
 	result.Outputs["cloud_init_path"] = cloudInitPath
+	result.Checkpoint.Complete(deploy.StageCloudInit, map[string]string{
+		"cloud_init_path": cloudInitPath,
+		"user":            username,
+	})
 
 	// Stage 3: Determine VM name
 	vmName := opts.Multipass.VMName
+	if vmName == "" {
+		vmName = prev.Output("vm_name")
+	}
 	if vmName == "" {
 		vmName = d.generateVMName()
-		opts.Multipass.VMName = vmName // Store for Cleanup() to use
 	}
+	opts.Multipass.VMName = vmName // Store for Cleanup() to use
+	result.Checkpoint.Multipass.VMName = vmName
 	result.Outputs["vm_name"] = vmName
 
 	// Stage 4: Launch VM
-	mp := opts.Multipass
-	version := mp.UbuntuVersion
-	if version == "" {
-		version = "24.04"
-	}
-	launchCmd := fmt.Sprintf("multipass launch --name %s --cpus %d --memory %dM --disk %dG %s",
-		vmName, mp.CPUs, mp.MemoryMB, mp.DiskGB, version)
+	if launched {
+		progress(deploy.NewProgressEventWithDetail(
+			deploy.StageLaunching,
+			fmt.Sprintf("VM '%s' already launched", vmName),
+			"Skipping multipass launch",
+			35,
+		))
+		d.launched = vmName
+	} else {
+		mp := opts.Multipass
+		version := mp.UbuntuVersion
+		if version == "" {
+			version = "24.04"
+		}
+		launchCmd := fmt.Sprintf("multipass launch --name %s --cpus %d --memory %dM --disk %dG %s",
+			vmName, mp.CPUs, mp.MemoryMB, mp.DiskGB, version)
 
-	progress(deploy.NewProgressEventWithCommand(
-		deploy.StageLaunching,
-		fmt.Sprintf("Launching VM '%s'...", vmName),
-		launchCmd,
-		35,
-	))
-	if err := d.launchVM(ctx, vmName, cloudInitPath, opts); err != nil {
-		return d.fail(result, err, start), err
+		progress(deploy.NewProgressEventWithCommand(
+			deploy.StageLaunching,
+			fmt.Sprintf("Launching VM '%s'...", vmName),
+			launchCmd,
+			35,
+		))
+		d.launched = vmName
+		if err := d.launchVM(ctx, vmName, cloudInitPath, opts); err != nil {
+			if strings.Contains(err.Error(), "already exists") {
+				d.launched = "" // Not ours to clean up
+			}
+			return d.fail(result, err, start), err
+		}
 	}
+	result.Checkpoint.Complete(deploy.StageLaunching, map[string]string{"vm_name": vmName})
 
 	// Stage 5: Wait for cloud-init
 	progress(deploy.NewProgressEventWithCommand(
@@ -126,9 +216,13 @@ func (d *Deployer) Deploy(ctx context.Context, opts *deploy.DeployOptions, progr
 		50,
 	))
 	if err := d.waitForCloudInit(ctx, vmName, progress); err != nil {
+		if ctx.Err() != nil {
+			return d.fail(result, ctx.Err(), start), ctx.Err()
+		}
 		// Don't fail here, just log it
 		result.Logs = append(result.Logs, fmt.Sprintf("Warning: %v", err))
 	}
+	result.Checkpoint.Complete(deploy.StageWaiting, nil)
 
 	// Stage 6: Get VM info
 	progress(deploy.NewProgressEventWithCommand(
@@ -137,7 +231,7 @@ func (d *Deployer) Deploy(ctx context.Context, opts *deploy.DeployOptions, progr
 		fmt.Sprintf("multipass info %s", vmName),
 		90,
 	))
-	info, err := d.getVMInfo(vmName, opts)
+	info, err := d.getVMInfo(vmName, username)
 	if err != nil {
 		return d.fail(result, err, start), err
 	}
@@ -161,28 +255,33 @@ func (d *Deployer) fail(result *deploy.DeployResult, err error, start time.Time)
 	return result
 }
 
-// Cleanup cleans up the VM on failure.
+// Cleanup deletes and purges the VM this deployer launched. A VM with the
+// requested name that already existed is left alone.
 func (d *Deployer) Cleanup(ctx context.Context, opts *deploy.DeployOptions) error {
 	if opts.Multipass.KeepOnFailure {
 		return nil // Don't cleanup, user wants to debug
 	}
 
-	vmName := opts.Multipass.VMName
-	if vmName == "" {
+	vmName := d.launched
+	if vmName == "" || vmName != opts.Multipass.VMName {
 		return nil // No VM was created
 	}
 
-	// Delete VM
-	deleteCmd := exec.CommandContext(ctx, d.binaryPath, "delete", vmName)
-	if err := deleteCmd.Run(); err != nil {
+	// Delete VM; a launch cancelled early may not have created it
+	_, stderr, err := d.exec.Run(ctx, deploy.Command{Name: d.binaryPath, Args: []string{"delete", vmName}})
+	if err != nil {
+		if strings.Contains(string(stderr), "does not exist") {
+			d.launched = ""
+			return nil
+		}
 		return fmt.Errorf("failed to delete VM: %w", err)
 	}
 
 	// Purge
-	purgeCmd := exec.CommandContext(ctx, d.binaryPath, "purge")
-	if err := purgeCmd.Run(); err != nil {
+	if _, _, err := d.exec.Run(ctx, deploy.Command{Name: d.binaryPath, Args: []string{"purge"}}); err != nil {
 		return fmt.Errorf("failed to purge VM: %w", err)
 	}
 
+	d.launched = ""
 	return nil
 }
@@ -0,0 +1,60 @@
+package multipass
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/deploytest"
+)
+
+func TestDeployer_Cleanup(t *testing.T) {
+	exec := &deploytest.Executor{}
+	d := NewWithExecutor(exec)
+	d.launched = "dev"
+	opts := &deploy.DeployOptions{Multipass: deploy.MultipassOptions{VMName: "dev"}}
+
+	require.NoError(t, d.Cleanup(context.Background(), opts))
+	require.Len(t, exec.Calls, 2)
+	assert.Equal(t, "multipass delete dev", exec.Calls[0].String())
+	assert.Equal(t, "multipass purge", exec.Calls[1].String())
+
+	// Only the VM it launched, once
+	require.NoError(t, d.Cleanup(context.Background(), opts))
+	assert.Len(t, exec.Calls, 2)
+}
+
+func TestDeployer_CleanupNotLaunched(t *testing.T) {
+	exec := &deploytest.Executor{
+		RunFunc: func(deploy.Command) ([]byte, []byte, error) {
+			return nil, []byte(`delete failed: The following errors occurred:
+instance "dev" does not exist`), errors.New("exit status 2")
+		},
+	}
+	d := NewWithExecutor(exec)
+	d.launched = "dev"
+	opts := &deploy.DeployOptions{Multipass: deploy.MultipassOptions{VMName: "dev"}}
+
+	require.NoError(t, d.Cleanup(context.Background(), opts))
+	assert.Len(t, exec.Calls, 1, "nothing to purge")
+
+	exec.RunFunc = func(deploy.Command) ([]byte, []byte, error) {
+		return nil, nil, errors.New("exit status 1")
+	}
+	d.launched = "dev"
+	assert.ErrorContains(t, d.Cleanup(context.Background(), opts), "failed to delete VM")
+}
+
+func TestDeployer_CleanupKeepOnFailure(t *testing.T) {
+	exec := &deploytest.Executor{}
+	d := NewWithExecutor(exec)
+	d.launched = "dev"
+	opts := &deploy.DeployOptions{Multipass: deploy.MultipassOptions{VMName: "dev", KeepOnFailure: true}}
+
+	require.NoError(t, d.Cleanup(context.Background(), opts))
+	assert.Empty(t, exec.Calls)
+}
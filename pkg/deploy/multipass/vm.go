@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"regexp"
 	"strings"
@@ -29,6 +30,15 @@ func (d *Deployer) checkInstalled() error {
 	return nil
 }
 
+// fileExists reports whether path is an existing file.
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
 // generateVMName generates a unique VM name.
 func (d *Deployer) generateVMName() string {
 	timestamp := time.Now().Format("20060102-150405")
@@ -75,7 +85,7 @@ func (d *Deployer) launchVM(ctx context.Context, vmName, cloudInitPath string, o
 }
 
 // getVMInfo retrieves information about the VM.
-func (d *Deployer) getVMInfo(vmName string, opts *deploy.DeployOptions) (map[string]string, error) {
+func (d *Deployer) getVMInfo(vmName, username string) (map[string]string, error) {
 	info := make(map[string]string)
 
 	// Get IP address
@@ -91,11 +101,6 @@ func (d *Deployer) getVMInfo(vmName string, opts *deploy.DeployOptions) (map[str
 		info["ip"] = string(matches[1])
 	}
 
-	// Get username from config (falls back to ubuntu if not set)
-	username := "ubuntu"
-	if opts.Config != nil && opts.Config.Username != "" {
-		username = opts.Config.Username
-	}
 	info["user"] = username
 
 	// Generate SSH command
@@ -24,6 +24,9 @@ const (
 	StagePlanning   Stage = "planning"   // Running terraform plan
 	StageConfirming Stage = "confirming" // Waiting for user confirmation
 	StageApplying   Stage = "applying"   // Running terraform apply
+
+	// ISO-specific stages
+	StageBuilding Stage = "building" // Writing the remastered ISO
 )
 
 // String returns the string representation of the stage.
@@ -66,6 +69,8 @@ func (s Stage) DisplayName() string {
 		return "Awaiting Confirmation"
 	case StageApplying:
 		return "Applying"
+	case StageBuilding:
+		return "Building ISO"
 	default:
 		return string(s)
 	}
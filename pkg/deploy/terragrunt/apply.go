@@ -0,0 +1,142 @@
+package terragrunt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+)
+
+// planFile is the saved plan written by plan and consumed by apply,
+// relative to the terragrunt working directory.
+const planFile = "ucli.tfplan"
+
+// ErrApplyDeclined is returned when the plan is rejected at StageConfirming.
+var ErrApplyDeclined = errors.New("apply cancelled: plan was not approved")
+
+// apply runs terragrunt init, plan and apply in machineDir. Once apply has
+// begun the directory holds state, so Cleanup destroys before removing it.
+func (g *Generator) apply(ctx context.Context, opts *deploy.DeployOptions, machineDir string, result *deploy.DeployResult, progress deploy.ProgressCallback) error {
+	tgOpts := opts.Terragrunt
+
+	progress(deploy.NewProgressEventWithCommand(deploy.StagePlanning, "Initializing Terragrunt...", "terragrunt init", 40))
+	if _, err := g.terragrunt(ctx, machineDir, deploy.StagePlanning, "Initializing Terragrunt...", 40, progress,
+		"init", "-input=false", "-no-color"); err != nil {
+		return err
+	}
+
+	progress(deploy.NewProgressEventWithCommand(deploy.StagePlanning, "Planning changes...", "terragrunt plan", 50))
+	planOut, err := g.terragrunt(ctx, machineDir, deploy.StagePlanning, "Planning changes...", 50, progress,
+		"plan", "-input=false", "-no-color", "-out="+planFile)
+	if err != nil {
+		return err
+	}
+	summary := planSummary(planOut)
+	result.Logs = append(result.Logs, summary)
+
+	if !tgOpts.AutoApprove {
+		progress(deploy.NewProgressEventWithDetail(deploy.StageConfirming, "Waiting for approval...", summary, 60))
+		approved, err := tgOpts.Approve(ctx, summary)
+		if err != nil {
+			return fmt.Errorf("failed to get approval: %w", err)
+		}
+		if !approved {
+			return ErrApplyDeclined
+		}
+	}
+
+	g.applied = true
+	progress(deploy.NewProgressEventWithCommand(deploy.StageApplying, "Applying changes...", "terragrunt apply", 70))
+	_, err = g.terragrunt(ctx, machineDir, deploy.StageApplying, "Applying changes...", 70, progress,
+		"apply", "-input=false", "-no-color", planFile)
+	return err
+}
+
+// readOutputs reads the module outputs in machineDir into result.
+func (g *Generator) readOutputs(ctx context.Context, machineDir string, result *deploy.DeployResult, progress deploy.ProgressCallback) error {
+	progress(deploy.NewProgressEventWithCommand(deploy.StageApplying, "Reading outputs...", "terragrunt output -json", 95))
+	out, err := g.terragrunt(ctx, machineDir, deploy.StageApplying, "Reading outputs...", 95, nil,
+		"output", "-json")
+	if err != nil {
+		return err
+	}
+	outputs, err := parseOutputs(out)
+	if err != nil {
+		return err
+	}
+	for k, v := range outputs {
+		result.Outputs[k] = v
+	}
+
+	return nil
+}
+
+// terragrunt runs a terragrunt command in dir. When progress is non-nil each
+// output line is reported as the detail of an event with the given stage,
+// message and percent.
+func (g *Generator) terragrunt(ctx context.Context, dir string, stage deploy.Stage, message string, percent int, progress deploy.ProgressCallback, args ...string) ([]byte, error) {
+	path, err := g.exec.LookPath("terragrunt")
+	if err != nil {
+		return nil, fmt.Errorf("terragrunt not found in PATH\n\n%s", InstallInstructions())
+	}
+
+	cmd := deploy.Command{
+		Name: path,
+		Args: args,
+		Dir:  dir,
+		Env:  []string{"TERRAGRUNT_NON_INTERACTIVE=true", "TG_NON_INTERACTIVE=true"},
+	}
+	if progress != nil {
+		cmd.Stream = func(line string) {
+			if strings.TrimSpace(line) != "" {
+				progress(deploy.NewProgressEventWithDetail(stage, message, line, percent))
+			}
+		}
+	}
+
+	stdout, stderr, err := g.exec.Run(ctx, cmd)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		output := string(stdout) + string(stderr)
+		return nil, fmt.Errorf("terragrunt %s failed: %w\n%s", args[0], err, lastLines(output, 20))
+	}
+	return stdout, nil
+}
+
+// planSummary returns the "Plan: ..." or "No changes." line from plan output.
+func planSummary(out []byte) string {
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Plan:") || strings.HasPrefix(line, "No changes.") {
+			return line
+		}
+	}
+	return "Plan complete"
+}
+
+// parseOutputs converts `terragrunt output -json` into flat string outputs.
+// Strings are used as-is; other values keep their JSON encoding.
+func parseOutputs(data []byte) (map[string]string, error) {
+	var raw map[string]struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse terragrunt outputs: %w", err)
+	}
+
+	outputs := make(map[string]string, len(raw))
+	for k, v := range raw {
+		var s string
+		if err := json.Unmarshal(v.Value, &s); err == nil {
+			outputs[k] = s
+			continue
+		}
+		outputs[k] = string(v.Value)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,381 @@
+package terragrunt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/deploytest"
+)
+
+const samplePlanOutput = `OpenTofu will perform the following actions:
+
+  # libvirt_domain.vm will be created
+  + resource "libvirt_domain" "vm" {
+
+Plan: 3 to add, 0 to change, 0 to destroy.
+`
+
+const sampleOutputJSON = `{
+  "vm_ip": {"sensitive": false, "type": "string", "value": "192.168.122.45"},
+  "ssh_command": {"sensitive": false, "type": "string", "value": "ssh ubuntu@192.168.122.45"},
+  "vm_running": {"sensitive": false, "type": "bool", "value": true}
+}`
+
+// terragruntExecutor returns a mock that answers terragrunt subcommands,
+// streaming a line per command. failOn makes that subcommand fail.
+func terragruntExecutor(failOn string) *deploytest.Executor {
+	return &deploytest.Executor{
+		RunFunc: func(cmd deploy.Command) ([]byte, []byte, error) {
+			if cmd.Stream != nil {
+				cmd.Stream("running " + cmd.Args[0])
+			}
+			if cmd.Args[0] == failOn {
+				return nil, []byte("Error: " + failOn + " exploded"), errors.New("exit status 1")
+			}
+			switch cmd.Args[0] {
+			case "plan":
+				return []byte(samplePlanOutput), nil, nil
+			case "output":
+				return []byte(sampleOutputJSON), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+}
+
+func applyOptions(t *testing.T) *deploy.DeployOptions {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "terragrunt", "modules", "libvirt-vm"), 0755))
+
+	return &deploy.DeployOptions{
+		ProjectRoot: root,
+		Config: &config.FullConfig{
+			Username: "testuser",
+			Hostname: "testhost",
+		},
+		Terragrunt: deploy.TerragruntOptions{
+			VMName:      "web",
+			CPUs:        2,
+			MemoryMB:    2048,
+			DiskGB:      20,
+			UbuntuImage: "/var/lib/libvirt/images/test.img",
+			LibvirtURI:  "qemu:///system",
+			StoragePool: "default",
+			NetworkName: "default",
+			Apply:       true,
+		},
+	}
+}
+
+func TestGenerator_Apply_AutoApprove(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.AutoApprove = true
+
+	exec := terragruntExecutor("")
+	g := NewWithExecutor(opts.ProjectRoot, exec)
+	tracker := deploy.NewProgressTracker()
+
+	result, err := g.Deploy(context.Background(), opts, tracker.Callback())
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+
+	// Commands run in order in the machine directory
+	machineDir := filepath.Join(opts.ProjectRoot, "tf", "web")
+	var subcommands []string
+	for _, c := range exec.Calls {
+		subcommands = append(subcommands, c.Args[0])
+		assert.Equal(t, machineDir, c.Dir)
+		assert.Contains(t, c.Env, "TG_NON_INTERACTIVE=true")
+	}
+	assert.Equal(t, []string{"init", "plan", "apply", "output"}, subcommands)
+	assert.Equal(t, []string{"apply", "-input=false", "-no-color", planFile}, exec.Calls[2].Args)
+
+	// Outputs come from terragrunt output -json
+	assert.Equal(t, "192.168.122.45", result.Outputs["vm_ip"])
+	assert.Equal(t, "ssh ubuntu@192.168.122.45", result.Outputs["ssh_command"])
+	assert.Equal(t, "true", result.Outputs["vm_running"])
+	assert.NotContains(t, result.Outputs, "next_steps")
+
+	// Streamed lines are reported under the running stage
+	stages := make(map[deploy.Stage]bool)
+	for _, e := range tracker.Events() {
+		stages[e.Stage] = true
+		if e.Detail == "running apply" {
+			assert.Equal(t, deploy.StageApplying, e.Stage)
+		}
+	}
+	assert.True(t, stages[deploy.StagePlanning])
+	assert.True(t, stages[deploy.StageApplying])
+	assert.False(t, stages[deploy.StageConfirming], "auto-approve skips confirmation")
+	assert.Equal(t, deploy.StageComplete, tracker.LastEvent().Stage)
+}
+
+func TestGenerator_Apply_Confirm(t *testing.T) {
+	opts := applyOptions(t)
+
+	var gotSummary string
+	opts.Terragrunt.Approve = func(_ context.Context, summary string) (bool, error) {
+		gotSummary = summary
+		return true, nil
+	}
+
+	g := NewWithExecutor(opts.ProjectRoot, terragruntExecutor(""))
+	tracker := deploy.NewProgressTracker()
+
+	result, err := g.Deploy(context.Background(), opts, tracker.Callback())
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "Plan: 3 to add, 0 to change, 0 to destroy.", gotSummary)
+
+	var confirming *deploy.ProgressEvent
+	for i, e := range tracker.Events() {
+		if e.Stage == deploy.StageConfirming {
+			confirming = &tracker.Events()[i]
+		}
+	}
+	require.NotNil(t, confirming)
+	assert.Equal(t, gotSummary, confirming.Detail)
+}
+
+func TestGenerator_Apply_Declined(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.Approve = func(context.Context, string) (bool, error) {
+		return false, nil
+	}
+
+	exec := terragruntExecutor("")
+	g := NewWithExecutor(opts.ProjectRoot, exec)
+
+	result, err := g.Deploy(context.Background(), opts, deploy.NoOpProgress)
+	assert.ErrorIs(t, err, ErrApplyDeclined)
+	assert.False(t, result.Success)
+	assert.Len(t, exec.Calls, 2, "apply is never run")
+
+	// Nothing was applied, so cleanup only removes the generated config
+	machineDir := filepath.Join(opts.ProjectRoot, "tf", "web")
+	assert.DirExists(t, machineDir)
+	require.NoError(t, g.Cleanup(context.Background(), opts))
+	assert.Len(t, exec.Calls, 2, "nothing to destroy")
+	assert.NoDirExists(t, machineDir)
+}
+
+func TestGenerator_Apply_Fails(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.AutoApprove = true
+
+	g := NewWithExecutor(opts.ProjectRoot, terragruntExecutor("apply"))
+
+	result, err := g.Deploy(context.Background(), opts, deploy.NoOpProgress)
+	require.Error(t, err)
+	assert.False(t, result.Success)
+	assert.Contains(t, err.Error(), "terragrunt apply failed")
+	assert.Contains(t, err.Error(), "apply exploded")
+
+	// The directory holds state after apply starts and is kept
+	assert.DirExists(t, filepath.Join(opts.ProjectRoot, "tf", "web"))
+}
+
+func TestGenerator_Cleanup_AfterFailedApply(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.AutoApprove = true
+	machineDir := filepath.Join(opts.ProjectRoot, "tf", "web")
+
+	// Keep on failure leaves the partial VM and its state
+	exec := terragruntExecutor("apply")
+	g := NewWithExecutor(opts.ProjectRoot, exec)
+	_, err := g.Deploy(context.Background(), opts, deploy.NoOpProgress)
+	require.Error(t, err)
+
+	opts.Terragrunt.KeepOnFailure = true
+	require.NoError(t, g.Cleanup(context.Background(), opts))
+	assert.Len(t, exec.Calls, 3)
+	assert.DirExists(t, machineDir)
+
+	// Otherwise it is destroyed and the directory removed
+	opts.Terragrunt.KeepOnFailure = false
+	require.NoError(t, g.Cleanup(context.Background(), opts))
+	require.Len(t, exec.Calls, 4)
+	assert.Equal(t, []string{"destroy", "-auto-approve", "-input=false", "-no-color"}, exec.Calls[3].Args)
+	assert.Equal(t, machineDir, exec.Calls[3].Dir)
+	assert.NoDirExists(t, machineDir)
+
+	// Nothing is left to clean up
+	require.NoError(t, g.Cleanup(context.Background(), opts))
+	assert.Len(t, exec.Calls, 4)
+}
+
+func TestGenerator_Cleanup_DestroyFails(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.AutoApprove = true
+
+	g := NewWithExecutor(opts.ProjectRoot, terragruntExecutor("apply"))
+	_, err := g.Deploy(context.Background(), opts, deploy.NoOpProgress)
+	require.Error(t, err)
+
+	g.exec = terragruntExecutor("destroy")
+	err = g.Cleanup(context.Background(), opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terragrunt destroy failed")
+
+	// The state is needed to retry
+	assert.DirExists(t, filepath.Join(opts.ProjectRoot, "tf", "web"))
+}
+
+func TestGenerator_Cleanup_ExistingConfig(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.AutoApprove = true
+	machineDir := filepath.Join(opts.ProjectRoot, "tf", "web")
+	require.NoError(t, os.MkdirAll(machineDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(machineDir, "terragrunt.hcl"), nil, 0644))
+
+	// A config that was already there is never destroyed
+	exec := terragruntExecutor("")
+	g := NewWithExecutor(opts.ProjectRoot, exec)
+	_, err := g.Deploy(context.Background(), opts, deploy.NoOpProgress)
+	require.ErrorContains(t, err, "already exists")
+
+	require.NoError(t, g.Cleanup(context.Background(), opts))
+	assert.Empty(t, exec.Calls)
+	assert.DirExists(t, machineDir)
+}
+
+func TestGenerator_Apply_PlanFails(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.AutoApprove = true
+
+	g := NewWithExecutor(opts.ProjectRoot, terragruntExecutor("plan"))
+
+	_, err := g.Deploy(context.Background(), opts, deploy.NoOpProgress)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terragrunt plan failed")
+
+	require.NoError(t, g.Cleanup(context.Background(), opts))
+	assert.NoDirExists(t, filepath.Join(opts.ProjectRoot, "tf", "web"))
+}
+
+func TestGenerator_Resume_AfterFailedPlan(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.AutoApprove = true
+	opts.Terragrunt.KeepOnFailure = true
+
+	g := NewWithExecutor(opts.ProjectRoot, terragruntExecutor("plan"))
+	result, err := g.Deploy(context.Background(), opts, deploy.NoOpProgress)
+	require.Error(t, err)
+	require.NoError(t, g.Cleanup(context.Background(), opts))
+
+	cp := result.Checkpoint
+	assert.True(t, cp.Done(deploy.StagePreparing))
+	assert.False(t, cp.Done(deploy.StageApplying))
+
+	// Resuming reuses the config and runs init, plan and apply again
+	exec := terragruntExecutor("")
+	g = NewWithExecutor(opts.ProjectRoot, exec)
+	resumeOpts := cp.Options()
+	result, err = deploy.Resume(context.Background(), g, resumeOpts, deploy.NoOpProgress)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "192.168.122.45", result.Outputs["vm_ip"])
+	assert.Equal(t, "web", result.Outputs["vm_name"])
+
+	var args []string
+	for _, c := range exec.Calls {
+		args = append(args, c.Args[0])
+	}
+	assert.Equal(t, []string{"init", "plan", "apply", "output"}, args)
+}
+
+func TestGenerator_Resume_AfterApply(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.AutoApprove = true
+	opts.Terragrunt.KeepOnFailure = true
+
+	g := NewWithExecutor(opts.ProjectRoot, terragruntExecutor("output"))
+	result, err := g.Deploy(context.Background(), opts, deploy.NoOpProgress)
+	require.Error(t, err)
+	require.True(t, result.Checkpoint.Done(deploy.StageApplying))
+
+	// Only the outputs are read again
+	exec := terragruntExecutor("")
+	g = NewWithExecutor(opts.ProjectRoot, exec)
+	result, err = deploy.Resume(context.Background(), g, result.Checkpoint.Options(), deploy.NoOpProgress)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	require.Len(t, exec.Calls, 1)
+	assert.Equal(t, "output", exec.Calls[0].Args[0])
+
+	// A failed resume still destroys what was applied
+	g = NewWithExecutor(opts.ProjectRoot, terragruntExecutor("output"))
+	cpOpts := result.Checkpoint.Options()
+	_, err = g.Resume(context.Background(), cpOpts, deploy.NoOpProgress)
+	require.Error(t, err)
+	exec = terragruntExecutor("")
+	g.exec = exec
+	cpOpts.Terragrunt.KeepOnFailure = false
+	require.NoError(t, g.Cleanup(context.Background(), cpOpts))
+	require.Len(t, exec.Calls, 1)
+	assert.Equal(t, "destroy", exec.Calls[0].Args[0])
+	assert.NoDirExists(t, filepath.Join(opts.ProjectRoot, "tf", "web"))
+}
+
+func TestGenerator_Resume_ConfigMissing(t *testing.T) {
+	opts := applyOptions(t)
+	opts.Terragrunt.AutoApprove = true
+
+	g := NewWithExecutor(opts.ProjectRoot, terragruntExecutor("plan"))
+	result, err := g.Deploy(context.Background(), opts, deploy.NoOpProgress)
+	require.Error(t, err)
+	require.NoError(t, g.Cleanup(context.Background(), opts))
+
+	_, err = deploy.Resume(context.Background(), g, result.Checkpoint.Options(), deploy.NoOpProgress)
+	assert.ErrorContains(t, err, "config from the failed deployment is missing")
+}
+
+func TestGenerator_Validate_Apply(t *testing.T) {
+	opts := applyOptions(t)
+
+	// No approval prompt and no auto-approve
+	g := NewWithExecutor(opts.ProjectRoot, &deploytest.Executor{})
+	err := g.Validate(opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "apply requires approval")
+
+	// terragrunt missing
+	opts.Terragrunt.AutoApprove = true
+	g = NewWithExecutor(opts.ProjectRoot, &deploytest.Executor{
+		LookPathFunc: func(file string) (string, error) {
+			return "", errors.New("not found")
+		},
+	})
+	err = g.Validate(opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terragrunt not found")
+}
+
+func TestPlanSummary(t *testing.T) {
+	assert.Equal(t, "Plan: 3 to add, 0 to change, 0 to destroy.", planSummary([]byte(samplePlanOutput)))
+	assert.Equal(t, "No changes. Your infrastructure matches the configuration.",
+		planSummary([]byte("\nNo changes. Your infrastructure matches the configuration.\n")))
+	assert.Equal(t, "Plan complete", planSummary(nil))
+}
+
+func TestParseOutputs(t *testing.T) {
+	outputs, err := parseOutputs([]byte(sampleOutputJSON))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"vm_ip":       "192.168.122.45",
+		"ssh_command": "ssh ubuntu@192.168.122.45",
+		"vm_running":  "true",
+	}, outputs)
+
+	_, err = parseOutputs([]byte("not json"))
+	assert.Error(t, err)
+}
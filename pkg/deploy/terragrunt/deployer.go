@@ -13,6 +13,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/validation"
 )
 
 // vmNamePattern defines valid VM name characters: lowercase alphanumeric and hyphens
@@ -21,12 +22,21 @@ var vmNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*[a-z0-9]$|^[a-z0-9]$`
 // Generator implements deploy.Deployer for generating Terragrunt/OpenTofu configs.
 type Generator struct {
 	projectRoot string
+	exec        deploy.CommandExecutor
+	configDir   string // Config dir written by this generator, for Cleanup
+	applied     bool   // Whether apply started in configDir
 }
 
 // New creates a new Terragrunt config generator.
 func New(projectRoot string) *Generator {
+	return NewWithExecutor(projectRoot, &deploy.RealExecutor{})
+}
+
+// NewWithExecutor creates a Terragrunt config generator with a custom executor.
+func NewWithExecutor(projectRoot string, exec deploy.CommandExecutor) *Generator {
 	return &Generator{
 		projectRoot: projectRoot,
+		exec:        exec,
 	}
 }
 
@@ -68,6 +78,21 @@ func (g *Generator) Validate(opts *deploy.DeployOptions) error {
 		}
 	}
 
+	return g.validateApply(opts)
+}
+
+// validateApply checks that apply mode has terragrunt and a way to confirm
+// the plan.
+func (g *Generator) validateApply(opts *deploy.DeployOptions) error {
+	if !opts.Terragrunt.Apply {
+		return nil
+	}
+	if _, err := g.exec.LookPath("terragrunt"); err != nil {
+		return fmt.Errorf("terragrunt not found in PATH\n\n%s", InstallInstructions())
+	}
+	if !opts.Terragrunt.AutoApprove && opts.Terragrunt.Approve == nil {
+		return fmt.Errorf("apply requires approval: enable auto-approve to apply without confirmation")
+	}
 	return nil
 }
 
@@ -111,18 +136,20 @@ func ValidateVMName(name string) error {
 
 // Deploy generates the Terragrunt configuration files.
 // It creates a new directory under tf/<vm-name>/ with terragrunt.hcl and cloud-init.yaml.
+// With TerragruntOptions.Apply set it then runs init, plan and apply.
 func (g *Generator) Deploy(ctx context.Context, opts *deploy.DeployOptions, progress deploy.ProgressCallback) (*deploy.DeployResult, error) {
 	result := &deploy.DeployResult{
-		Target:  deploy.TargetTerragrunt,
-		Outputs: make(map[string]string),
-		Logs:    make([]string, 0),
+		Target:     deploy.TargetTerragrunt,
+		Outputs:    make(map[string]string),
+		Logs:       make([]string, 0),
+		Checkpoint: deploy.NewCheckpoint(deploy.TargetTerragrunt, opts),
 	}
 	start := time.Now()
 
 	tgOpts := opts.Terragrunt
 
 	// Stage 1: Validate (10%)
-	progress(deploy.NewProgressEvent(deploy.StageValidating, "Validating configuration...", 10))
+	progress(deploy.NewProgressEvent(deploy.StageValidating, "Validating configuration...", pct(tgOpts.Apply, 10)))
 	if err := g.Validate(opts); err != nil {
 		return g.fail(result, err, start), err
 	}
@@ -135,7 +162,7 @@ func (g *Generator) Deploy(ctx context.Context, opts *deploy.DeployOptions, prog
 			deploy.StageValidating,
 			"Warning: Ubuntu image issue detected",
 			warning,
-			15,
+			pct(tgOpts.Apply, 15),
 		))
 	}
 
@@ -145,6 +172,7 @@ func (g *Generator) Deploy(ctx context.Context, opts *deploy.DeployOptions, prog
 		vmName = g.generateVMName()
 		opts.Terragrunt.VMName = vmName
 	}
+	result.Checkpoint.Terragrunt.VMName = vmName
 	result.Outputs["vm_name"] = vmName
 
 	// Ensure tf/ directory and root terragrunt.hcl exist
@@ -160,12 +188,11 @@ func (g *Generator) Deploy(ctx context.Context, opts *deploy.DeployOptions, prog
 		deploy.StagePreparing,
 		"Creating config directory...",
 		fmt.Sprintf("tf/%s/", vmName),
-		20,
+		pct(tgOpts.Apply, 20),
 	))
 
 	// Use atomic directory creation to avoid TOCTOU race condition
 	// os.Mkdir fails if directory exists, which is what we want
-	dirCreated := false
 	if err := os.Mkdir(machineDir, 0755); err != nil {
 		if os.IsExist(err) {
 			// Directory exists - check if it has config files
@@ -180,17 +207,11 @@ func (g *Generator) Deploy(ctx context.Context, opts *deploy.DeployOptions, prog
 			wrappedErr := fmt.Errorf("failed to create directory %s: %w", machineDir, err)
 			return g.fail(result, wrappedErr, start), wrappedErr
 		}
-	} else {
-		// We successfully created the directory
-		dirCreated = true
 	}
 
-	// Cleanup on failure only if we created the directory
-	defer func() {
-		if !result.Success && dirCreated {
-			_ = os.RemoveAll(machineDir)
-		}
-	}()
+	// The directory is ours from here on; Cleanup removes it on failure
+	g.configDir = machineDir
+	g.applied = false
 
 	result.Outputs["config_dir"] = machineDir
 
@@ -199,7 +220,7 @@ func (g *Generator) Deploy(ctx context.Context, opts *deploy.DeployOptions, prog
 		deploy.StageCloudInit,
 		"Generating cloud-init.yaml...",
 		fmt.Sprintf("tf/%s/cloud-init.yaml", vmName),
-		50,
+		pct(tgOpts.Apply, 50),
 	))
 	cloudInitPath, err := g.generateCloudInitInDir(opts, machineDir)
 	if err != nil {
@@ -207,29 +228,135 @@ func (g *Generator) Deploy(ctx context.Context, opts *deploy.DeployOptions, prog
 	}
 	result.Outputs["cloud_init_path"] = cloudInitPath
 
+	progress(deploy.NewProgressEventWithDetail(
+		deploy.StageValidating,
+		"Validating cloud-init.yaml...",
+		fmt.Sprintf("tf/%s/cloud-init.yaml", vmName),
+		pct(tgOpts.Apply, 60),
+	))
+	warnings, err := validation.CheckCloudInit(cloudInitPath)
+	if err != nil {
+		return g.fail(result, err, start), err
+	}
+	result.Logs = append(result.Logs, warnings...)
+
 	// Stage 4: Generate terragrunt.hcl (80%)
 	progress(deploy.NewProgressEventWithDetail(
 		deploy.StagePreparing,
 		"Generating terragrunt.hcl...",
 		fmt.Sprintf("tf/%s/terragrunt.hcl", vmName),
-		80,
+		pct(tgOpts.Apply, 80),
 	))
 	if err := g.writeTerragruntHCL(opts, machineDir); err != nil {
 		return g.fail(result, err, start), err
 	}
 	result.Outputs["terragrunt_path"] = filepath.Join(machineDir, "terragrunt.hcl")
+	result.Checkpoint.Complete(deploy.StagePreparing, result.Outputs)
+
+	return g.finish(ctx, opts, nil, result, progress, start)
+}
+
+// Resume continues a failed deployment from opts.Checkpoint, reusing the
+// config in tf/<vm-name>/. Init, plan and apply run again unless apply
+// completed, in which case only the outputs are read.
+func (g *Generator) Resume(ctx context.Context, opts *deploy.DeployOptions, progress deploy.ProgressCallback) (*deploy.DeployResult, error) {
+	prev := opts.Checkpoint
+	result := &deploy.DeployResult{
+		Target:     deploy.TargetTerragrunt,
+		Outputs:    make(map[string]string),
+		Logs:       make([]string, 0),
+		Checkpoint: deploy.NewCheckpoint(deploy.TargetTerragrunt, opts),
+	}
+	start := time.Now()
+
+	progress(deploy.NewProgressEvent(deploy.StageValidating, "Validating configuration...", 5))
+	if err := g.validateApply(opts); err != nil {
+		return g.fail(result, err, start), err
+	}
+
+	machineDir := prev.Output("config_dir")
+	exists := false
+	if prev.Done(deploy.StagePreparing) && machineDir != "" {
+		var err error
+		if exists, err = g.configExists(machineDir); err != nil {
+			return g.fail(result, err, start), err
+		}
+	}
+	if !exists {
+		err := fmt.Errorf("config from the failed deployment is missing; start over")
+		return g.fail(result, err, start), err
+	}
+
+	progress(deploy.NewProgressEventWithDetail(
+		deploy.StagePreparing,
+		"Reusing config directory...",
+		fmt.Sprintf("tf/%s/", prev.Output("vm_name")),
+		pct(opts.Terragrunt.Apply, 80),
+	))
+	g.configDir = machineDir
+	g.applied = prev.Done(deploy.StageApplying)
+
+	for k, v := range prev.Outputs {
+		result.Outputs[k] = v
+	}
+	result.Checkpoint.Terragrunt.VMName = prev.Output("vm_name")
+	result.Checkpoint.Complete(deploy.StagePreparing, prev.Outputs)
 
-	// Stage 5: Complete (100%)
-	progress(deploy.NewProgressEvent(deploy.StageComplete, "Configuration generated!", 100))
+	return g.finish(ctx, opts, prev, result, progress, start)
+}
+
+// finish runs init, plan and apply in the config directory in apply mode,
+// skipping apply if prev completed it.
+func (g *Generator) finish(ctx context.Context, opts *deploy.DeployOptions, prev *deploy.Checkpoint, result *deploy.DeployResult, progress deploy.ProgressCallback, start time.Time) (*deploy.DeployResult, error) {
+	machineDir := result.Outputs["config_dir"]
+	vmName := result.Outputs["vm_name"]
+
+	if !opts.Terragrunt.Apply {
+		// Stage 5: Complete (100%)
+		progress(deploy.NewProgressEvent(deploy.StageComplete, "Configuration generated!", 100))
+		result.Success = true
+		result.Duration = time.Since(start)
+
+		// Add helpful outputs
+		result.Outputs["next_steps"] = fmt.Sprintf("cd tf/%s && terragrunt init && terragrunt apply", vmName)
+
+		return result, nil
+	}
+
+	// Stage 5: Init, plan and apply (40-95%)
+	if prev.Done(deploy.StageApplying) {
+		progress(deploy.NewProgressEventWithDetail(
+			deploy.StageApplying,
+			"Changes already applied",
+			"Skipping init, plan and apply",
+			70,
+		))
+	} else if err := g.apply(ctx, opts, machineDir, result, progress); err != nil {
+		return g.fail(result, err, start), err
+	}
+	result.Checkpoint.Complete(deploy.StageApplying, nil)
+
+	if err := g.readOutputs(ctx, machineDir, result, progress); err != nil {
+		return g.fail(result, err, start), err
+	}
+
+	// Stage 6: Complete (100%)
+	progress(deploy.NewProgressEvent(deploy.StageComplete, "VM created!", 100))
 	result.Success = true
 	result.Duration = time.Since(start)
 
-	// Add helpful outputs
-	result.Outputs["next_steps"] = fmt.Sprintf("cd tf/%s && terragrunt init && terragrunt apply", vmName)
-
 	return result, nil
 }
 
+// pct scales generation progress into the first 40% of the bar in apply
+// mode.
+func pct(apply bool, p int) int {
+	if apply {
+		return p * 4 / 10
+	}
+	return p
+}
+
 // ensureRootConfig ensures the tf/ directory exists with a root terragrunt.hcl.
 func (g *Generator) ensureRootConfig(tfDir string) error {
 	// Create tf/ directory if needed
@@ -282,7 +409,24 @@ generate "versions" {
 }
 `, relModulePath)
 
-	if err := os.WriteFile(rootHCL, []byte(content), 0644); err != nil {
+	// Written through a temp file, since deployments running in parallel
+	// may create it at the same time
+	tmp, err := os.CreateTemp(tfDir, ".terragrunt.hcl-*")
+	if err != nil {
+		return fmt.Errorf("failed to write root terragrunt.hcl: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write root terragrunt.hcl: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write root terragrunt.hcl: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write root terragrunt.hcl: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), rootHCL); err != nil {
 		return fmt.Errorf("failed to write root terragrunt.hcl: %w", err)
 	}
 
@@ -341,8 +485,27 @@ func (g *Generator) fail(result *deploy.DeployResult, err error, start time.Time
 	return result
 }
 
-// Cleanup is a no-op for the generator (cleanup happens in defer).
-func (g *Generator) Cleanup(_ context.Context, _ *deploy.DeployOptions) error {
+// Cleanup removes the config directory of a failed deployment, first
+// destroying whatever apply created. Configs that already existed are
+// never touched.
+func (g *Generator) Cleanup(ctx context.Context, opts *deploy.DeployOptions) error {
+	if g.configDir == "" || opts.Terragrunt.KeepOnFailure {
+		return nil
+	}
+
+	// The directory holds the state, so it is kept if destroy fails
+	if g.applied {
+		if _, err := g.terragrunt(ctx, g.configDir, deploy.StageCleanup, "", 0, nil,
+			"destroy", "-auto-approve", "-input=false", "-no-color"); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(g.configDir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", g.configDir, err)
+	}
+
+	g.configDir = ""
+	g.applied = false
 	return nil
 }
 
@@ -367,7 +530,7 @@ func InstallInstructions() string {
 
 Install OpenTofu:
   macOS:   brew install opentofu
-  Linux:   curl -fsSL https://get.opentofu.org/install-opentofu.sh | sh
+  Linux:   curl -fsSL https://get.opentofu.org/install-opentofu.sh | sh -s -- --install-method standalone
 
 Install Terragrunt:
   macOS:   brew install terragrunt
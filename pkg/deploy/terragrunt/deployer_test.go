@@ -329,6 +329,7 @@ func TestGenerator_WriteTerragruntHCL(t *testing.T) {
 	assert.Contains(t, contentStr, `ubuntu_image_path = "/var/lib/libvirt/images/test.img"`)
 	assert.Contains(t, contentStr, `storage_pool      = "mypool"`)
 	assert.Contains(t, contentStr, `network_name      = "mynet"`)
+	assert.Contains(t, contentStr, `running           = true`)
 	assert.Contains(t, contentStr, "${get_terragrunt_dir()}/cloud-init.yaml")
 }
 
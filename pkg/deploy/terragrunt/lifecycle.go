@@ -0,0 +1,392 @@
+package terragrunt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+)
+
+var (
+	// inputLineRe matches a `key = value` line in terragrunt.hcl.
+	inputLineRe = regexp.MustCompile(`^\s*([a-z_]+)\s*=\s*(.+?)\s*$`)
+
+	// runningLineRe matches the running input in terragrunt.hcl.
+	runningLineRe = regexp.MustCompile(`(?m)^(\s*running\s*=\s*)(true|false)\s*$`)
+
+	// domifaddrIPRe extracts an IPv4 address from `virsh domifaddr` output.
+	domifaddrIPRe = regexp.MustCompile(`ipv4\s+(\d+\.\d+\.\d+\.\d+)`)
+)
+
+var _ deploy.Lifecycle = (*Lifecycle)(nil)
+
+// Lifecycle implements deploy.Lifecycle for VMs under tf/<vm-name>/.
+type Lifecycle struct {
+	projectRoot    string
+	terragruntPath string
+	virshPath      string
+	exec           deploy.CommandExecutor
+}
+
+// NewLifecycle creates a new Terragrunt lifecycle backend.
+func NewLifecycle(projectRoot string) *Lifecycle {
+	return NewLifecycleWithExecutor(projectRoot, &deploy.RealExecutor{})
+}
+
+// NewLifecycleWithExecutor creates a Terragrunt lifecycle backend with a custom executor.
+func NewLifecycleWithExecutor(projectRoot string, exec deploy.CommandExecutor) *Lifecycle {
+	return &Lifecycle{
+		projectRoot:    projectRoot,
+		terragruntPath: "terragrunt",
+		virshPath:      "virsh",
+		exec:           exec,
+	}
+}
+
+// Target returns the deployment target type.
+func (l *Lifecycle) Target() deploy.DeploymentTarget {
+	return deploy.TargetTerragrunt
+}
+
+// vmConfig holds the values read from a VM's terragrunt.hcl.
+type vmConfig struct {
+	VMName     string
+	CPUs       int
+	MemoryMB   int
+	DiskGB     int
+	Image      string
+	LibvirtURI string
+	Running    bool
+}
+
+// parseVMConfig extracts the inputs ucli writes into terragrunt.hcl.
+func parseVMConfig(content string) vmConfig {
+	cfg := vmConfig{Running: true} // Module default
+	for _, line := range strings.Split(content, "\n") {
+		m := inputLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		value := strings.Trim(m[2], `"`)
+		switch m[1] {
+		case "vm_name":
+			cfg.VMName = value
+		case "vcpu_count":
+			cfg.CPUs, _ = strconv.Atoi(value)
+		case "memory_mb":
+			cfg.MemoryMB, _ = strconv.Atoi(value)
+		case "disk_size_gb":
+			cfg.DiskGB, _ = strconv.Atoi(value)
+		case "ubuntu_image_path":
+			cfg.Image = value
+		case "uri":
+			cfg.LibvirtURI = value
+		case "running":
+			cfg.Running = value == "true"
+		}
+	}
+	return cfg
+}
+
+// setRunning returns content with the running input set to running.
+// The input is added to the inputs block if it is missing.
+func setRunning(content string, running bool) (string, error) {
+	value := strconv.FormatBool(running)
+	if runningLineRe.MatchString(content) {
+		return runningLineRe.ReplaceAllString(content, "${1}"+value), nil
+	}
+
+	start := strings.Index(content, "inputs = {")
+	if start == -1 {
+		return "", fmt.Errorf("inputs block not found in terragrunt.hcl")
+	}
+	end := strings.Index(content[start:], "\n}")
+	if end == -1 {
+		return "", fmt.Errorf("unterminated inputs block in terragrunt.hcl")
+	}
+	end += start
+
+	line := fmt.Sprintf("\n  running           = %s", value)
+	return content[:end] + line + content[end:], nil
+}
+
+// List returns all VM configs under tf/ with their live state.
+func (l *Lifecycle) List(ctx context.Context) ([]deploy.Machine, error) {
+	tfDir := filepath.Join(l.projectRoot, "tf")
+	entries, err := os.ReadDir(tfDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read tf directory: %w", err)
+	}
+
+	var machines []deploy.Machine
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(tfDir, entry.Name(), "terragrunt.hcl")); err != nil {
+			continue
+		}
+
+		m, err := l.Info(ctx, entry.Name())
+		if err != nil {
+			continue
+		}
+		machines = append(machines, *m)
+	}
+
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].Name < machines[j].Name
+	})
+	return machines, nil
+}
+
+// Info returns details and live state for a single VM.
+func (l *Lifecycle) Info(ctx context.Context, name string) (*deploy.Machine, error) {
+	dir, cfg, err := l.load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := cfg.VMName
+	if domain == "" {
+		domain = name
+	}
+
+	m := &deploy.Machine{
+		Name:     name,
+		Target:   deploy.TargetTerragrunt,
+		State:    l.domainState(ctx, cfg.LibvirtURI, domain),
+		CPUs:     cfg.CPUs,
+		MemoryMB: cfg.MemoryMB,
+		DiskGB:   cfg.DiskGB,
+		Image:    cfg.Image,
+		Dir:      dir,
+	}
+
+	if m.State == deploy.StateRunning {
+		m.IP = l.domainIP(ctx, cfg.LibvirtURI, domain)
+	}
+
+	if m.State != deploy.StateNotCreated {
+		m.ConsoleCommand = []string{l.virshPath, "console", domain}
+		if cfg.LibvirtURI != "" {
+			m.ConsoleCommand = []string{l.virshPath, "-c", cfg.LibvirtURI, "console", domain}
+		}
+	}
+	if m.IP != "" {
+		m.SSHCommand = []string{"ssh", fmt.Sprintf("%s@%s", cloudInitUser(dir), m.IP)}
+	}
+
+	return m, nil
+}
+
+// Status returns the live libvirt state of a VM.
+func (l *Lifecycle) Status(ctx context.Context, name string) (deploy.MachineState, error) {
+	_, cfg, err := l.load(name)
+	if err != nil {
+		return deploy.StateUnknown, err
+	}
+
+	domain := cfg.VMName
+	if domain == "" {
+		domain = name
+	}
+	return l.domainState(ctx, cfg.LibvirtURI, domain), nil
+}
+
+// Start sets running = true and applies the configuration.
+func (l *Lifecycle) Start(ctx context.Context, name string) error {
+	return l.setRunningAndApply(ctx, name, true)
+}
+
+// Stop sets running = false and applies the configuration.
+func (l *Lifecycle) Stop(ctx context.Context, name string) error {
+	return l.setRunningAndApply(ctx, name, false)
+}
+
+// Destroy runs terragrunt destroy and removes the VM's config directory.
+func (l *Lifecycle) Destroy(ctx context.Context, name string) error {
+	dir, _, err := l.load(name)
+	if err != nil {
+		return err
+	}
+
+	// Configs that were never applied have no state to destroy
+	if _, err := os.Stat(filepath.Join(dir, ".terragrunt-cache")); err == nil {
+		if _, err := l.terragrunt(ctx, dir, "destroy", "-auto-approve"); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+	return nil
+}
+
+// setRunningAndApply rewrites the running input and runs terragrunt apply.
+// The file is restored if apply fails so it keeps matching the real state.
+func (l *Lifecycle) setRunningAndApply(ctx context.Context, name string, running bool) error {
+	dir, _, err := l.load(name)
+	if err != nil {
+		return err
+	}
+
+	hclPath := filepath.Join(dir, "terragrunt.hcl")
+	original, err := os.ReadFile(hclPath)
+	if err != nil {
+		return fmt.Errorf("failed to read terragrunt.hcl: %w", err)
+	}
+
+	updated, err := setRunning(string(original), running)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(hclPath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write terragrunt.hcl: %w", err)
+	}
+
+	if _, err := l.terragrunt(ctx, dir, "apply", "-auto-approve"); err != nil {
+		_ = os.WriteFile(hclPath, original, 0644)
+		return err
+	}
+	return nil
+}
+
+// load resolves a VM's directory and parses its terragrunt.hcl.
+func (l *Lifecycle) load(name string) (string, vmConfig, error) {
+	if err := ValidateVMName(name); err != nil {
+		return "", vmConfig{}, err
+	}
+
+	dir := filepath.Join(l.projectRoot, "tf", name)
+	content, err := os.ReadFile(filepath.Join(dir, "terragrunt.hcl"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", vmConfig{}, fmt.Errorf("VM %q not found in tf/", name)
+		}
+		return "", vmConfig{}, fmt.Errorf("failed to read terragrunt.hcl: %w", err)
+	}
+
+	return dir, parseVMConfig(string(content)), nil
+}
+
+// domainState queries libvirt for the domain state.
+func (l *Lifecycle) domainState(ctx context.Context, uri, domain string) deploy.MachineState {
+	out, err := l.virsh(ctx, uri, "domstate", domain)
+	if err != nil {
+		if strings.Contains(err.Error(), "failed to get domain") {
+			return deploy.StateNotCreated
+		}
+		return deploy.StateUnknown
+	}
+
+	switch strings.TrimSpace(string(out)) {
+	case "running":
+		return deploy.StateRunning
+	case "shut off", "shutdown", "in shutdown", "crashed":
+		return deploy.StateStopped
+	case "paused", "pmsuspended":
+		return deploy.StateSuspended
+	default:
+		return deploy.StateUnknown
+	}
+}
+
+// domainIP returns the domain's first IPv4 address from its DHCP lease.
+func (l *Lifecycle) domainIP(ctx context.Context, uri, domain string) string {
+	out, err := l.virsh(ctx, uri, "domifaddr", domain)
+	if err != nil {
+		return ""
+	}
+	if m := domifaddrIPRe.FindSubmatch(out); len(m) > 1 {
+		return string(m[1])
+	}
+	return ""
+}
+
+// virsh runs a virsh command against the given URI.
+func (l *Lifecycle) virsh(ctx context.Context, uri string, args ...string) ([]byte, error) {
+	path, err := l.exec.LookPath(l.virshPath)
+	if err != nil {
+		return nil, fmt.Errorf("virsh: %w", deploy.ErrBackendUnavailable)
+	}
+	if uri != "" {
+		args = append([]string{"-c", uri}, args...)
+	}
+
+	stdout, stderr, err := l.exec.Run(ctx, deploy.Command{Name: path, Args: args})
+	if err != nil {
+		if msg := strings.TrimSpace(string(stderr)); msg != "" {
+			return nil, fmt.Errorf("virsh failed: %s", msg)
+		}
+		return nil, fmt.Errorf("virsh failed: %w", err)
+	}
+	return stdout, nil
+}
+
+// terragrunt runs a terragrunt command non-interactively in dir.
+func (l *Lifecycle) terragrunt(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	path, err := l.exec.LookPath(l.terragruntPath)
+	if err != nil {
+		return nil, fmt.Errorf("terragrunt: %w", deploy.ErrBackendUnavailable)
+	}
+
+	stdout, stderr, err := l.exec.Run(ctx, deploy.Command{
+		Name: path,
+		Args: args,
+		Dir:  dir,
+		Env:  []string{"TERRAGRUNT_NON_INTERACTIVE=true", "TG_NON_INTERACTIVE=true"},
+	})
+	if err != nil {
+		output := string(stdout) + string(stderr)
+		return stdout, fmt.Errorf("terragrunt %s failed: %w\n%s", args[0], err, lastLines(output, 20))
+	}
+	return stdout, nil
+}
+
+// lastLines returns the last n lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cloudInitUser returns the first user defined in the VM's cloud-init.yaml,
+// falling back to "ubuntu".
+func cloudInitUser(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "cloud-init.yaml"))
+	if err != nil {
+		return "ubuntu"
+	}
+
+	var doc struct {
+		Users []yaml.Node `yaml:"users"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "ubuntu"
+	}
+
+	for _, node := range doc.Users {
+		var user struct {
+			Name string `yaml:"name"`
+		}
+		if node.Kind == yaml.MappingNode && node.Decode(&user) == nil && user.Name != "" {
+			return user.Name
+		}
+	}
+	return "ubuntu"
+}
@@ -0,0 +1,310 @@
+package terragrunt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/deploytest"
+)
+
+// virshExecutor returns a mock that answers virsh domstate/domifaddr.
+func virshExecutor(state string) *deploytest.Executor {
+	return &deploytest.Executor{
+		RunFunc: func(cmd deploy.Command) ([]byte, []byte, error) {
+			if !strings.HasSuffix(cmd.Name, "virsh") {
+				return nil, nil, nil
+			}
+			switch {
+			case strings.Contains(cmd.String(), "domstate"):
+				return []byte(state + "\n\n"), nil, nil
+			case strings.Contains(cmd.String(), "domifaddr"):
+				out := " Name       MAC address          Protocol     Address\n" +
+					"-------------------------------------------------------------------------------\n" +
+					" vnet0      52:54:00:6b:3c:58    ipv4         192.168.122.45/24\n"
+				return []byte(out), nil, nil
+			}
+			return nil, nil, nil
+		},
+	}
+}
+
+const sampleHCL = `include "root" {
+  path = find_in_parent_folders()
+}
+
+generate "provider" {
+  path      = "provider.tf"
+  if_exists = "overwrite_terragrunt"
+  contents  = <<-EOF
+    provider "libvirt" {
+      uri = "qemu:///system"
+    }
+  EOF
+}
+
+inputs = {
+  vm_name           = "web"
+  vcpu_count        = 4
+  memory_mb         = 4096
+  disk_size_gb      = 40
+  autostart         = false
+  ubuntu_image_path = "/var/lib/libvirt/images/noble.img"
+  cloud_init_file   = "${get_terragrunt_dir()}/cloud-init.yaml"
+  storage_pool      = "default"
+  network_name      = "default"
+}
+`
+
+func TestParseVMConfig(t *testing.T) {
+	cfg := parseVMConfig(sampleHCL)
+
+	assert.Equal(t, "web", cfg.VMName)
+	assert.Equal(t, 4, cfg.CPUs)
+	assert.Equal(t, 4096, cfg.MemoryMB)
+	assert.Equal(t, 40, cfg.DiskGB)
+	assert.Equal(t, "/var/lib/libvirt/images/noble.img", cfg.Image)
+	assert.Equal(t, "qemu:///system", cfg.LibvirtURI)
+	assert.True(t, cfg.Running, "running defaults to true when absent")
+}
+
+func TestSetRunning(t *testing.T) {
+	// Missing input is inserted into the inputs block
+	stopped, err := setRunning(sampleHCL, false)
+	require.NoError(t, err)
+	assert.Contains(t, stopped, "  running           = false\n}")
+	assert.False(t, parseVMConfig(stopped).Running)
+
+	// Existing input is replaced in place
+	started, err := setRunning(stopped, true)
+	require.NoError(t, err)
+	assert.True(t, parseVMConfig(started).Running)
+	assert.Equal(t, 1, strings.Count(started, "running "))
+
+	_, err = setRunning("terraform {}\n", true)
+	assert.Error(t, err)
+}
+
+func TestLifecycle_List(t *testing.T) {
+	root := t.TempDir()
+	writeVM(t, root, "web", sampleHCL)
+	writeVM(t, root, "api", sampleHCL)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "tf", "empty"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "tf", "terragrunt.hcl"), []byte("# root"), 0644))
+
+	l := NewLifecycleWithExecutor(root, &deploytest.Executor{
+		LookPathFunc: func(file string) (string, error) {
+			return "", errors.New("not found")
+		},
+	})
+
+	machines, err := l.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, machines, 2)
+
+	assert.Equal(t, "api", machines[0].Name)
+	assert.Equal(t, "web", machines[1].Name)
+	assert.Equal(t, deploy.TargetTerragrunt, machines[1].Target)
+	assert.Equal(t, deploy.StateUnknown, machines[1].State)
+	assert.Equal(t, 4, machines[1].CPUs)
+	assert.Equal(t, filepath.Join(root, "tf", "web"), machines[1].Dir)
+}
+
+func TestLifecycle_List_NoTFDir(t *testing.T) {
+	l := NewLifecycle(t.TempDir())
+
+	machines, err := l.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, machines)
+}
+
+func TestLifecycle_Info_NotFound(t *testing.T) {
+	l := NewLifecycle(t.TempDir())
+
+	_, err := l.Info(context.Background(), "missing")
+	assert.Error(t, err)
+
+	_, err = l.Info(context.Background(), "../etc")
+	assert.Error(t, err)
+}
+
+func TestLifecycle_Destroy_NeverApplied(t *testing.T) {
+	root := t.TempDir()
+	dir := writeVM(t, root, "web", sampleHCL)
+
+	exec := &deploytest.Executor{}
+	l := NewLifecycleWithExecutor(root, exec)
+
+	// No .terragrunt-cache means nothing to destroy, only the config is removed
+	require.NoError(t, l.Destroy(context.Background(), "web"))
+	assert.NoDirExists(t, dir)
+	assert.Empty(t, exec.Calls)
+}
+
+func TestLifecycle_Destroy(t *testing.T) {
+	root := t.TempDir()
+	dir := writeVM(t, root, "web", sampleHCL)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".terragrunt-cache"), 0755))
+
+	exec := &deploytest.Executor{}
+	l := NewLifecycleWithExecutor(root, exec)
+
+	require.NoError(t, l.Destroy(context.Background(), "web"))
+	assert.NoDirExists(t, dir)
+
+	require.Len(t, exec.Calls, 1)
+	assert.Equal(t, "/usr/bin/terragrunt", exec.Calls[0].Name)
+	assert.Equal(t, []string{"destroy", "-auto-approve"}, exec.Calls[0].Args)
+	assert.Equal(t, dir, exec.Calls[0].Dir)
+	assert.Contains(t, exec.Calls[0].Env, "TG_NON_INTERACTIVE=true")
+}
+
+func TestLifecycle_Destroy_Fails(t *testing.T) {
+	root := t.TempDir()
+	dir := writeVM(t, root, "web", sampleHCL)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".terragrunt-cache"), 0755))
+
+	l := NewLifecycleWithExecutor(root, &deploytest.Executor{
+		RunFunc: func(cmd deploy.Command) ([]byte, []byte, error) {
+			return []byte("Planning...\n"), []byte("Error: libvirt connection refused\n"), errors.New("exit status 1")
+		},
+	})
+
+	err := l.Destroy(context.Background(), "web")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terragrunt destroy failed")
+	assert.Contains(t, err.Error(), "libvirt connection refused")
+	assert.DirExists(t, dir, "config is kept when destroy fails")
+}
+
+func TestLifecycle_Info(t *testing.T) {
+	root := t.TempDir()
+	dir := writeVM(t, root, "web", sampleHCL)
+	content := "#cloud-config\nusers:\n  - name: alice\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cloud-init.yaml"), []byte(content), 0644))
+
+	exec := virshExecutor("running")
+	l := NewLifecycleWithExecutor(root, exec)
+
+	m, err := l.Info(context.Background(), "web")
+	require.NoError(t, err)
+
+	assert.Equal(t, deploy.StateRunning, m.State)
+	assert.Equal(t, "192.168.122.45", m.IP)
+	assert.Equal(t, []string{"virsh", "-c", "qemu:///system", "console", "web"}, m.ConsoleCommand)
+	assert.Equal(t, []string{"ssh", "alice@192.168.122.45"}, m.SSHCommand)
+	assert.Equal(t, []string{"-c", "qemu:///system", "domstate", "web"}, exec.Calls[0].Args)
+}
+
+func TestLifecycle_Status(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		stderr string
+		err    error
+		want   deploy.MachineState
+	}{
+		{"running", "running", "", nil, deploy.StateRunning},
+		{"shut off", "shut off", "", nil, deploy.StateStopped},
+		{"paused", "paused", "", nil, deploy.StateSuspended},
+		{"not created", "", "error: failed to get domain 'web'", errors.New("exit status 1"), deploy.StateNotCreated},
+		{"other error", "", "error: failed to connect to the hypervisor", errors.New("exit status 1"), deploy.StateUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeVM(t, root, "web", sampleHCL)
+
+			l := NewLifecycleWithExecutor(root, &deploytest.Executor{
+				RunFunc: func(cmd deploy.Command) ([]byte, []byte, error) {
+					return []byte(tt.stdout), []byte(tt.stderr), tt.err
+				},
+			})
+
+			state, err := l.Status(context.Background(), "web")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, state)
+		})
+	}
+}
+
+func TestLifecycle_StartStop(t *testing.T) {
+	root := t.TempDir()
+	dir := writeVM(t, root, "web", sampleHCL)
+	hclPath := filepath.Join(dir, "terragrunt.hcl")
+
+	exec := &deploytest.Executor{}
+	l := NewLifecycleWithExecutor(root, exec)
+
+	require.NoError(t, l.Stop(context.Background(), "web"))
+	content, err := os.ReadFile(hclPath)
+	require.NoError(t, err)
+	assert.False(t, parseVMConfig(string(content)).Running)
+
+	require.Len(t, exec.Calls, 1)
+	assert.Equal(t, []string{"apply", "-auto-approve"}, exec.Calls[0].Args)
+	assert.Equal(t, dir, exec.Calls[0].Dir)
+
+	require.NoError(t, l.Start(context.Background(), "web"))
+	content, err = os.ReadFile(hclPath)
+	require.NoError(t, err)
+	assert.True(t, parseVMConfig(string(content)).Running)
+}
+
+func TestLifecycle_Stop_RestoresOnFailure(t *testing.T) {
+	root := t.TempDir()
+	dir := writeVM(t, root, "web", sampleHCL)
+
+	l := NewLifecycleWithExecutor(root, &deploytest.Executor{
+		RunFunc: func(cmd deploy.Command) ([]byte, []byte, error) {
+			return nil, []byte("Error: timeout"), errors.New("exit status 1")
+		},
+	})
+
+	err := l.Stop(context.Background(), "web")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "terragrunt apply failed")
+
+	content, err := os.ReadFile(filepath.Join(dir, "terragrunt.hcl"))
+	require.NoError(t, err)
+	assert.Equal(t, sampleHCL, string(content))
+}
+
+func TestLifecycle_NotInstalled(t *testing.T) {
+	root := t.TempDir()
+	writeVM(t, root, "web", sampleHCL)
+
+	l := NewLifecycleWithExecutor(root, &deploytest.Executor{
+		LookPathFunc: func(file string) (string, error) {
+			return "", errors.New("not found")
+		},
+	})
+
+	err := l.Start(context.Background(), "web")
+	assert.ErrorIs(t, err, deploy.ErrBackendUnavailable)
+}
+
+func TestCloudInitUser(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, "ubuntu", cloudInitUser(dir))
+
+	content := "#cloud-config\nusers:\n  - default\n  - name: alice\n    shell: /bin/zsh\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cloud-init.yaml"), []byte(content), 0644))
+	assert.Equal(t, "alice", cloudInitUser(dir))
+}
+
+func writeVM(t *testing.T, root, name, hcl string) string {
+	t.Helper()
+	dir := filepath.Join(root, "tf", name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terragrunt.hcl"), []byte(hcl), 0644))
+	return dir
+}
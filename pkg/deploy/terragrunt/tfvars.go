@@ -65,6 +65,7 @@ func (g *Generator) writeTerragruntHCL(opts *deploy.DeployOptions, machineDir st
 	sb.WriteString(fmt.Sprintf("  memory_mb         = %d\n", tgOpts.MemoryMB))
 	sb.WriteString(fmt.Sprintf("  disk_size_gb      = %d\n", tgOpts.DiskGB))
 	sb.WriteString(fmt.Sprintf("  autostart         = %t\n", tgOpts.Autostart))
+	sb.WriteString("  running           = true\n")
 	sb.WriteString(fmt.Sprintf("  ubuntu_image_path = %q\n", tgOpts.UbuntuImage))
 	sb.WriteString("  cloud_init_file   = \"${get_terragrunt_dir()}/cloud-init.yaml\"\n")
 	sb.WriteString(fmt.Sprintf("  storage_pool      = %q\n", tgOpts.StoragePool))
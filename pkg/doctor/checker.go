@@ -11,6 +11,8 @@ type Checker struct {
 	envGetter EnvGetter
 	platform  string
 	imagePath string // Path to cloud image for Terraform
+	pool      string // Libvirt storage pool for Terraform
+	network   string // Libvirt network for Terraform
 }
 
 // NewChecker creates a new Checker with the real command executor.
@@ -19,6 +21,8 @@ func NewChecker() *Checker {
 		executor:  &RealExecutor{},
 		envGetter: &RealEnvGetter{},
 		platform:  runtime.GOOS,
+		pool:      DefaultStoragePool,
+		network:   DefaultNetwork,
 	}
 }
 
@@ -28,6 +32,8 @@ func NewCheckerWithExecutor(exec CommandExecutor) *Checker {
 		executor:  exec,
 		envGetter: &RealEnvGetter{},
 		platform:  runtime.GOOS,
+		pool:      DefaultStoragePool,
+		network:   DefaultNetwork,
 	}
 }
 
@@ -37,6 +43,8 @@ func NewCheckerWithEnv(exec CommandExecutor, env EnvGetter) *Checker {
 		executor:  exec,
 		envGetter: env,
 		platform:  runtime.GOOS,
+		pool:      DefaultStoragePool,
+		network:   DefaultNetwork,
 	}
 }
 
@@ -45,6 +53,16 @@ func (c *Checker) SetImagePath(path string) {
 	c.imagePath = path
 }
 
+// SetStoragePool sets the libvirt storage pool to check.
+func (c *Checker) SetStoragePool(name string) {
+	c.pool = name
+}
+
+// SetNetwork sets the libvirt network to check.
+func (c *Checker) SetNetwork(name string) {
+	c.network = name
+}
+
 // CheckAll runs all applicable checks and returns groups with results.
 func (c *Checker) CheckAll() []CheckGroup {
 	groups := GetGroups()
@@ -91,6 +109,7 @@ func (c *Checker) CheckGroup(groupID string) CheckGroup {
 		Name:        def.Name,
 		Description: def.Description,
 		Platform:    def.Platform,
+		Required:    def.Required,
 	}
 
 	for _, checkID := range def.CheckIDs {
@@ -106,6 +125,12 @@ func (c *Checker) runCheck(checkID string) Check {
 	switch checkID {
 	case IDTerraform:
 		return CheckTerraform(c.executor)
+	case IDTofu:
+		return CheckTofu(c.executor)
+	case IDTerragrunt:
+		return CheckTerragrunt(c.executor)
+	case IDLibvirtProvider:
+		return CheckLibvirtProvider(c.envGetter)
 	case IDMultipass:
 		return CheckMultipass(c.executor)
 	case IDXorriso:
@@ -116,6 +141,16 @@ func (c *Checker) runCheck(checkID string) Check {
 		return CheckVirsh(c.executor)
 	case IDQemuKVM:
 		return CheckQemuKVM(c.executor)
+	case IDKVMDevice:
+		return CheckKVMDevice(c.executor)
+	case IDLibvirtGroups:
+		return CheckLibvirtGroups(c.executor)
+	case IDLibvirtURI:
+		return CheckLibvirtURI(c.executor)
+	case IDStoragePool:
+		return CheckStoragePool(c.executor, c.pool)
+	case IDNetwork:
+		return CheckNetwork(c.executor, c.network)
 	case IDCloudImage:
 		return CheckCloudImage(c.executor, c.imagePath)
 	case IDGhostty:
@@ -137,11 +172,11 @@ func (c *Checker) GetCheck(checkID string) Check {
 
 // Summary represents an overall health summary.
 type Summary struct {
-	Total    int
-	OK       int
-	Missing  int
-	Warnings int
-	Errors   int
+	Total    int `json:"total"`
+	OK       int `json:"ok"`
+	Missing  int `json:"missing"`
+	Warnings int `json:"warnings"`
+	Errors   int `json:"errors"`
 }
 
 // GetSummary returns a summary of check results.
@@ -1,7 +1,9 @@
 package doctor
 
 import (
+	"encoding/json"
 	"errors"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -270,6 +272,19 @@ func TestCheckStatus_String(t *testing.T) {
 	assert.Equal(t, "warning", StatusWarning.String())
 }
 
+func TestCheckStatus_JSON(t *testing.T) {
+	data, err := json.Marshal(Check{ID: IDXorriso, Status: StatusMissing})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"status":"missing"`)
+
+	var check Check
+	require.NoError(t, json.Unmarshal(data, &check))
+	assert.Equal(t, StatusMissing, check.Status)
+
+	var s CheckStatus
+	assert.Error(t, s.UnmarshalText([]byte("broken")))
+}
+
 func TestExtractVersion(t *testing.T) {
 	tests := []struct {
 		output   string
@@ -485,6 +500,20 @@ func TestGetAllGroupIDs_IncludesTerminal(t *testing.T) {
 	assert.Contains(t, groupIDs, GroupISO)
 }
 
+func TestGetGroups_OrderAndRequired(t *testing.T) {
+	var ids []string
+	for _, g := range GetGroups() {
+		ids = append(ids, g.ID)
+		assert.Equal(t, g.ID != GroupTerminal, g.Required, g.ID)
+	}
+
+	if runtime.GOOS == PlatformLinux {
+		assert.Equal(t, GetAllGroupIDs(), ids)
+	} else {
+		assert.NotContains(t, ids, GroupTerraform)
+	}
+}
+
 func TestGetGroupDefinition_Terminal(t *testing.T) {
 	def, ok := GetGroupDefinition(GroupTerminal)
 
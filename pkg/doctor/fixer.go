@@ -28,6 +28,34 @@ var fixCommands = map[string]map[string]*FixCommand{
 			Platform:    PlatformLinux,
 		},
 	},
+	IDTofu: {
+		PlatformDarwin: {
+			Description: "Install via Homebrew",
+			Command:     "brew install opentofu",
+			Sudo:        false,
+			Platform:    PlatformDarwin,
+		},
+		PlatformLinux: {
+			Description: "Install via the official installer",
+			Command:     "curl -fsSL https://get.opentofu.org/install-opentofu.sh | sh -s -- --install-method standalone",
+			Sudo:        true,
+			Platform:    PlatformLinux,
+		},
+	},
+	IDTerragrunt: {
+		PlatformDarwin: {
+			Description: "Install via Homebrew",
+			Command:     "brew install terragrunt",
+			Sudo:        false,
+			Platform:    PlatformDarwin,
+		},
+		PlatformLinux: {
+			Description: "Install the latest release binary",
+			Command:     "curl -fsSL -o /tmp/terragrunt https://github.com/gruntwork-io/terragrunt/releases/latest/download/terragrunt_linux_$(dpkg --print-architecture) && sudo install -m 0755 /tmp/terragrunt /usr/local/bin/terragrunt && rm /tmp/terragrunt",
+			Sudo:        true,
+			Platform:    PlatformLinux,
+		},
+	},
 	IDMultipass: {
 		PlatformDarwin: {
 			Description: "Install via Homebrew",
@@ -105,6 +105,12 @@ func TestGetFixCommand_AllPlatforms(t *testing.T) {
 		{IDTerraform, PlatformLinux, false, true, "apt"},
 		{IDTerraform, "windows", true, false, ""},
 
+		// OpenTofu and Terragrunt
+		{IDTofu, PlatformDarwin, false, false, "brew install opentofu"},
+		{IDTofu, PlatformLinux, false, true, "install-opentofu.sh"},
+		{IDTerragrunt, PlatformDarwin, false, false, "brew install terragrunt"},
+		{IDTerragrunt, PlatformLinux, false, true, "gruntwork-io/terragrunt/releases"},
+
 		// Multipass
 		{IDMultipass, PlatformDarwin, false, false, "brew install --cask multipass"},
 		{IDMultipass, PlatformLinux, false, true, "snap install multipass"},
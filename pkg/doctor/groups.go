@@ -7,24 +7,33 @@ var groupDefinitions = map[string]struct {
 	Name        string
 	Description string
 	Platform    string
+	Required    bool
 	CheckIDs    []string
 }{
 	GroupTerraform: {
 		Name:        "Terraform/libvirt",
-		Description: "Required for creating VMs via Terraform with libvirt provider",
+		Description: "Required for creating VMs via Terragrunt and OpenTofu with the libvirt provider",
 		Platform:    PlatformLinux, // libvirt is Linux-only
-		CheckIDs:    []string{IDTerraform, IDLibvirt, IDVirsh, IDQemuKVM, IDCloudImage},
+		Required:    true,
+		CheckIDs: []string{
+			IDTofu, IDTerragrunt, IDLibvirtProvider,
+			IDLibvirt, IDVirsh, IDQemuKVM,
+			IDKVMDevice, IDLibvirtGroups, IDLibvirtURI, IDStoragePool, IDNetwork,
+			IDCloudImage,
+		},
 	},
 	GroupMultipass: {
 		Name:        "Multipass",
 		Description: "Required for creating local Ubuntu VMs via Multipass",
 		Platform:    "", // Works on both platforms
+		Required:    true,
 		CheckIDs:    []string{IDMultipass},
 	},
 	GroupISO: {
 		Name:        "ISO/USB",
 		Description: "Required for creating bootable ISOs",
 		Platform:    "", // Works on both platforms
+		Required:    true,
 		CheckIDs:    []string{IDXorriso},
 	},
 	GroupTerminal: {
@@ -35,12 +44,14 @@ var groupDefinitions = map[string]struct {
 	},
 }
 
-// GetGroups returns all check groups applicable to the current platform.
+// GetGroups returns all check groups applicable to the current platform,
+// in GetAllGroupIDs order.
 func GetGroups() []CheckGroup {
 	platform := runtime.GOOS
 	var groups []CheckGroup
 
-	for groupID, def := range groupDefinitions {
+	for _, groupID := range GetAllGroupIDs() {
+		def := groupDefinitions[groupID]
 		// Skip if group is for a different platform
 		if def.Platform != "" && def.Platform != platform {
 			continue
@@ -51,6 +62,7 @@ func GetGroups() []CheckGroup {
 			Name:        def.Name,
 			Description: def.Description,
 			Platform:    def.Platform,
+			Required:    def.Required,
 		}
 		groups = append(groups, group)
 	}
@@ -63,6 +75,7 @@ func GetGroupDefinition(groupID string) (struct {
 	Name        string
 	Description string
 	Platform    string
+	Required    bool
 	CheckIDs    []string
 }, bool) {
 	def, ok := groupDefinitions[groupID]
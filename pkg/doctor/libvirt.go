@@ -0,0 +1,388 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LibvirtURI is the libvirt connection the Terraform deployments use.
+const LibvirtURI = "qemu:///system"
+
+// Default libvirt resources, matching deploy.DefaultTerragruntOptions.
+const (
+	DefaultStoragePool = "default"
+	DefaultNetwork     = "default"
+)
+
+// kvmDevice is the KVM device QEMU needs read/write access to.
+const kvmDevice = "/dev/kvm"
+
+// poolDir is where new storage pools keep their volumes.
+const poolDir = "/var/lib/libvirt/images"
+
+// natNetworkXML defines a NAT network with DHCP on 192.168.N.0/24, like the
+// network libvirt ships as "default" on 192.168.122.0/24.
+const natNetworkXML = `<network><name>%[1]s</name><forward mode="nat"/>` +
+	`<ip address="192.168.%[2]d.1" netmask="255.255.255.0">` +
+	`<dhcp><range start="192.168.%[2]d.2" end="192.168.%[2]d.254"/></dhcp></ip></network>`
+
+// defaultSubnet is the third octet of libvirt's default network range.
+const defaultSubnet = 122
+
+// subnetRegex matches 192.168.N.x addresses in network XML and routes.
+var subnetRegex = regexp.MustCompile(`192\.168\.(\d{1,3})\.\d{1,3}`)
+
+// CheckKVMDevice checks that /dev/kvm exists and the user can open it.
+func CheckKVMDevice(exec CommandExecutor) Check {
+	check := Check{
+		ID:          IDKVMDevice,
+		Name:        kvmDevice,
+		Description: "KVM device access",
+	}
+
+	if !exec.FileExists(kvmDevice) {
+		check.Status = StatusMissing
+		check.Message = "not found (enable virtualization in the firmware)"
+		return check
+	}
+
+	if _, err := exec.Run("test", "-r", kvmDevice, "-a", "-w", kvmDevice); err != nil {
+		check.Status = StatusError
+		check.Message = "no read/write access"
+		check.FixCommand = GroupFixCommand([]string{"kvm"})
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = "read/write"
+	return check
+}
+
+// CheckLibvirtGroups checks that the user is in the libvirt and kvm groups.
+func CheckLibvirtGroups(exec CommandExecutor) Check {
+	check := Check{
+		ID:          IDLibvirtGroups,
+		Name:        "libvirt groups",
+		Description: "Membership of the libvirt and kvm groups",
+	}
+
+	if uid, err := exec.Run("id", "-u"); err == nil && strings.TrimSpace(uid) == "0" {
+		check.Status = StatusOK
+		check.Message = "running as root"
+		return check
+	}
+
+	output, err := exec.Run("id", "-nG")
+	if err != nil {
+		check.Status = StatusError
+		check.Message = "could not read groups"
+		return check
+	}
+
+	groups := strings.Fields(output)
+	var missing []string
+	// Older distributions name the group libvirtd
+	if !slices.Contains(groups, "libvirt") && !slices.Contains(groups, "libvirtd") {
+		missing = append(missing, "libvirt")
+	}
+	if !slices.Contains(groups, "kvm") {
+		missing = append(missing, "kvm")
+	}
+
+	if len(missing) > 0 {
+		// Access may still be granted by polkit or ACLs, which the
+		// connection and device checks cover
+		check.Status = StatusWarning
+		check.Message = "not in " + strings.Join(missing, ", ")
+		check.FixCommand = GroupFixCommand(missing)
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = "member of libvirt, kvm"
+	return check
+}
+
+// CheckLibvirtURI checks that virsh can connect to qemu:///system.
+func CheckLibvirtURI(exec CommandExecutor) Check {
+	check := Check{
+		ID:          IDLibvirtURI,
+		Name:        LibvirtURI,
+		Description: "Libvirt system connection",
+	}
+
+	if _, err := exec.LookPath("virsh"); err != nil {
+		check.Status = StatusMissing
+		check.Message = "virsh not installed"
+		return check
+	}
+
+	output, err := exec.Run("virsh", "-c", LibvirtURI, "uri")
+	if err != nil {
+		check.Status = StatusError
+		check.Message = "cannot connect: " + firstLine(output, err)
+		return check
+	}
+
+	check.Status = StatusOK
+	check.Message = "connected"
+	return check
+}
+
+// CheckStoragePool checks that the storage pool exists and is active.
+func CheckStoragePool(exec CommandExecutor, pool string) Check {
+	check := Check{
+		ID:          IDStoragePool,
+		Name:        "Storage pool",
+		Description: "Libvirt pool for VM disks",
+	}
+
+	info, ok := virshInfo(exec, &check, "pool-info", pool)
+	if !ok {
+		if check.Status == StatusMissing {
+			check.FixCommand = PoolFixCommand(pool, false, false)
+		}
+		return check
+	}
+
+	if info["State"] != "running" {
+		check.Status = StatusError
+		check.Message = fmt.Sprintf("%q is not active", pool)
+		check.FixCommand = PoolFixCommand(pool, true, false)
+		return check
+	}
+
+	autostart(&check, info, pool, PoolFixCommand(pool, true, true))
+	return check
+}
+
+// CheckNetwork checks that the network exists and is active.
+func CheckNetwork(exec CommandExecutor, network string) Check {
+	check := Check{
+		ID:          IDNetwork,
+		Name:        "Network",
+		Description: "Libvirt network for VMs",
+	}
+
+	info, ok := virshInfo(exec, &check, "net-info", network)
+	if !ok {
+		if check.Status != StatusMissing {
+			return check
+		}
+		subnet, free := freeSubnet(exec)
+		if !free {
+			check.Message += ", no free 192.168.N.0/24 subnet"
+			return check
+		}
+		check.FixCommand = NetworkFixCommand(network, subnet, false, false)
+		return check
+	}
+
+	if info["Active"] != "yes" {
+		check.Status = StatusError
+		check.Message = fmt.Sprintf("%q is not active", network)
+		check.FixCommand = NetworkFixCommand(network, 0, true, false)
+		return check
+	}
+
+	autostart(&check, info, network, NetworkFixCommand(network, 0, true, true))
+	return check
+}
+
+// virshInfo runs a virsh pool-info or net-info command and parses its
+// "Key: value" lines. It marks check missing when virsh is not installed
+// or the object is not defined, and as an error when virsh fails for any
+// other reason, such as having no connection.
+func virshInfo(exec CommandExecutor, check *Check, command, name string) (map[string]string, bool) {
+	if _, err := exec.LookPath("virsh"); err != nil {
+		check.Status = StatusMissing
+		check.Message = "virsh not installed"
+		return nil, false
+	}
+
+	output, err := exec.Run("virsh", "-c", LibvirtURI, command, name)
+	if err != nil {
+		// virsh reports "Storage pool not found" or "Network not found"
+		if strings.Contains(output, "not found") {
+			check.Status = StatusMissing
+			check.Message = fmt.Sprintf("%q not defined", name)
+			return nil, false
+		}
+
+		check.Status = StatusError
+		if uri := CheckLibvirtURI(exec); uri.Status != StatusOK {
+			check.Message = uri.Message
+		} else {
+			check.Message = fmt.Sprintf("cannot read %q: %s", name, firstLine(output, err))
+		}
+		return nil, false
+	}
+
+	info := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok {
+			info[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return info, true
+}
+
+// autostart marks an active pool or network OK, or warns with fix when it
+// will not start with the host.
+func autostart(check *Check, info map[string]string, name string, fix *FixCommand) {
+	if info["Autostart"] == "no" {
+		check.Status = StatusWarning
+		check.Message = fmt.Sprintf("%q active, autostart disabled", name)
+		check.FixCommand = fix
+		return
+	}
+
+	check.Status = StatusOK
+	check.Message = fmt.Sprintf("%q active", name)
+}
+
+// freeSubnet returns the third octet of a 192.168.N.0/24 range that no
+// libvirt network or host route uses, preferring libvirt's default range.
+func freeSubnet(exec CommandExecutor) (int, bool) {
+	var outputs []string
+	if names, err := exec.Run("virsh", "-c", LibvirtURI, "net-list", "--all", "--name"); err == nil {
+		for _, name := range strings.Fields(names) {
+			if xml, err := exec.Run("virsh", "-c", LibvirtURI, "net-dumpxml", name); err == nil {
+				outputs = append(outputs, xml)
+			}
+		}
+	}
+	if routes, err := exec.Run("ip", "-4", "route"); err == nil {
+		outputs = append(outputs, routes)
+	}
+
+	used := make(map[int]bool)
+	for _, output := range outputs {
+		for _, m := range subnetRegex.FindAllStringSubmatch(output, -1) {
+			n, _ := strconv.Atoi(m[1])
+			used[n] = true
+		}
+	}
+
+	for n := defaultSubnet; n < 255; n++ {
+		if !used[n] {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// firstLine returns the first line of command output, or err when there
+// is none.
+func firstLine(output string, err error) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	if line == "" {
+		return err.Error()
+	}
+	return line
+}
+
+// GroupFixCommand returns the fix that adds the user to groups.
+func GroupFixCommand(groups []string) *FixCommand {
+	if runtime.GOOS != PlatformLinux {
+		return nil
+	}
+	return &FixCommand{
+		Description: "Add your user to " + strings.Join(groups, ", ") + " (log in again afterwards)",
+		Command:     `sudo usermod -aG ` + strings.Join(groups, ",") + ` "$USER"`,
+		Sudo:        true,
+		Platform:    PlatformLinux,
+	}
+}
+
+// PoolFixCommand returns the fix that defines, starts and autostarts a
+// directory storage pool, skipping the steps already done.
+func PoolFixCommand(pool string, defined, active bool) *FixCommand {
+	if runtime.GOOS != PlatformLinux {
+		return nil
+	}
+
+	dir := poolDir
+	if pool != DefaultStoragePool {
+		dir = filepath.Join(poolDir, pool)
+	}
+
+	var steps []string
+	description := fmt.Sprintf("Enable autostart for storage pool %q", pool)
+	if !defined {
+		steps = append(steps,
+			virsh("pool-define-as", pool, "dir", "--target", dir),
+			virsh("pool-build", pool),
+		)
+		description = fmt.Sprintf("Create storage pool %q in %s", pool, dir)
+	}
+	if !active {
+		steps = append(steps, virsh("pool-start", pool))
+		if defined {
+			description = fmt.Sprintf("Start storage pool %q", pool)
+		}
+	}
+	steps = append(steps, virsh("pool-autostart", pool))
+
+	return &FixCommand{
+		Description: description,
+		Command:     strings.Join(steps, " && "),
+		Platform:    PlatformLinux,
+	}
+}
+
+// NetworkFixCommand returns the fix that defines a NAT network on
+// 192.168.subnet.0/24, starts it and enables autostart, skipping the steps
+// already done. subnet is only used when the network is not defined.
+func NetworkFixCommand(network string, subnet int, defined, active bool) *FixCommand {
+	if runtime.GOOS != PlatformLinux {
+		return nil
+	}
+
+	var steps []string
+	description := fmt.Sprintf("Enable autostart for network %q", network)
+	if !defined {
+		xml := fmt.Sprintf(natNetworkXML, network, subnet)
+		steps = append(steps, "echo "+shellQuote(xml)+" | "+virsh("net-define", "/dev/stdin"))
+		description = fmt.Sprintf("Create NAT network %q (192.168.%d.0/24)", network, subnet)
+	}
+	if !active {
+		steps = append(steps, virsh("net-start", network))
+		if defined {
+			description = fmt.Sprintf("Start network %q", network)
+		}
+	}
+	steps = append(steps, virsh("net-autostart", network))
+
+	return &FixCommand{
+		Description: description,
+		Command:     strings.Join(steps, " && "),
+		Platform:    PlatformLinux,
+	}
+}
+
+// virsh returns a shell command running virsh against LibvirtURI.
+func virsh(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return "virsh -c " + LibvirtURI + " " + strings.Join(quoted, " ")
+}
+
+// shellQuote quotes s for sh unless it only has safe characters.
+func shellQuote(s string) string {
+	safe := s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:", r))
+	}) < 0
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
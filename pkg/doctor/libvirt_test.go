@@ -0,0 +1,240 @@
+package doctor
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// virshHost returns a mock whose commands answer from outputs, keyed by the
+// full command line. Unknown commands fail like virsh for an undefined object.
+func virshHost(outputs map[string]string) *MockExecutor {
+	return &MockExecutor{
+		RunFunc: func(name string, args ...string) (string, error) {
+			out, ok := outputs[strings.Join(append([]string{name}, args...), " ")]
+			if !ok {
+				return "error: failed to get pool 'x'\nerror: Storage pool not found: no storage pool with matching name 'x'\n", errors.New("exit status 1")
+			}
+			return out, nil
+		},
+	}
+}
+
+func TestCheckKVMDevice(t *testing.T) {
+	check := CheckKVMDevice(&MockExecutor{})
+	assert.Equal(t, StatusOK, check.Status)
+	assert.Equal(t, "read/write", check.Message)
+
+	check = CheckKVMDevice(&MockExecutor{FileExistsFunc: func(string) bool { return false }})
+	assert.Equal(t, StatusMissing, check.Status)
+	assert.Nil(t, check.FixCommand)
+
+	check = CheckKVMDevice(&MockExecutor{RunFunc: func(name string, args ...string) (string, error) {
+		assert.Equal(t, "test", name)
+		return "", errors.New("exit status 1")
+	}})
+	assert.Equal(t, StatusError, check.Status)
+	assert.Equal(t, "no read/write access", check.Message)
+}
+
+func TestCheckLibvirtGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs map[string]string
+		status  CheckStatus
+		message string
+	}{
+		{"root", map[string]string{"id -u": "0\n"}, StatusOK, "running as root"},
+		{"member", map[string]string{"id -u": "1000", "id -nG": "me adm kvm libvirt\n"}, StatusOK, "member of libvirt, kvm"},
+		{"libvirtd group", map[string]string{"id -u": "1000", "id -nG": "me libvirtd kvm"}, StatusOK, "member of libvirt, kvm"},
+		{"not a member", map[string]string{"id -u": "1000", "id -nG": "me adm"}, StatusWarning, "not in libvirt, kvm"},
+		{"no groups", map[string]string{"id -u": "1000"}, StatusError, "could not read groups"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := CheckLibvirtGroups(virshHost(tt.outputs))
+			assert.Equal(t, tt.status, check.Status)
+			assert.Equal(t, tt.message, check.Message)
+		})
+	}
+
+	if runtime.GOOS == PlatformLinux {
+		check := CheckLibvirtGroups(virshHost(map[string]string{"id -u": "1000", "id -nG": "me libvirt"}))
+		require.NotNil(t, check.FixCommand)
+		assert.Equal(t, `sudo usermod -aG kvm "$USER"`, check.FixCommand.Command)
+	}
+}
+
+func TestCheckLibvirtURI(t *testing.T) {
+	check := CheckLibvirtURI(virshHost(map[string]string{"virsh -c qemu:///system uri": "qemu:///system\n"}))
+	assert.Equal(t, StatusOK, check.Status)
+
+	check = CheckLibvirtURI(&MockExecutor{RunFunc: func(string, ...string) (string, error) {
+		return "error: failed to connect to the hypervisor\nerror: Permission denied\n", errors.New("exit status 1")
+	}})
+	assert.Equal(t, StatusError, check.Status)
+	assert.Equal(t, "cannot connect: error: failed to connect to the hypervisor", check.Message)
+
+	check = CheckLibvirtURI(&MockExecutor{LookPathFunc: func(string) (string, error) {
+		return "", errors.New("not found")
+	}})
+	assert.Equal(t, StatusMissing, check.Status)
+}
+
+func TestCheckStoragePool(t *testing.T) {
+	const info = "virsh -c qemu:///system pool-info vms"
+	tests := []struct {
+		name    string
+		outputs map[string]string
+		status  CheckStatus
+		message string
+		fix     string
+	}{
+		{
+			name:    "active",
+			outputs: map[string]string{info: "Name:           vms\nState:          running\nAutostart:      yes\n"},
+			status:  StatusOK,
+			message: `"vms" active`,
+		},
+		{
+			name:    "no autostart",
+			outputs: map[string]string{info: "Name:           vms\nState:          running\nAutostart:      no\n"},
+			status:  StatusWarning,
+			message: `"vms" active, autostart disabled`,
+			fix:     "virsh -c qemu:///system pool-autostart vms",
+		},
+		{
+			name:    "inactive",
+			outputs: map[string]string{info: "Name:           vms\nState:          inactive\nAutostart:      no\n"},
+			status:  StatusError,
+			message: `"vms" is not active`,
+			fix:     "virsh -c qemu:///system pool-start vms && virsh -c qemu:///system pool-autostart vms",
+		},
+		{
+			name:    "not defined",
+			outputs: map[string]string{},
+			status:  StatusMissing,
+			message: `"vms" not defined`,
+			fix: "virsh -c qemu:///system pool-define-as vms dir --target /var/lib/libvirt/images/vms && " +
+				"virsh -c qemu:///system pool-build vms && " +
+				"virsh -c qemu:///system pool-start vms && " +
+				"virsh -c qemu:///system pool-autostart vms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := CheckStoragePool(virshHost(tt.outputs), "vms")
+			assert.Equal(t, tt.status, check.Status)
+			assert.Equal(t, tt.message, check.Message)
+			if tt.fix != "" && runtime.GOOS == PlatformLinux {
+				require.NotNil(t, check.FixCommand)
+				assert.Equal(t, tt.fix, check.FixCommand.Command)
+			}
+		})
+	}
+}
+
+func TestCheckStoragePool_Errors(t *testing.T) {
+	// No connection, so the pool may well exist
+	check := CheckStoragePool(&MockExecutor{RunFunc: func(string, ...string) (string, error) {
+		return "error: failed to connect to the hypervisor\n", errors.New("exit status 1")
+	}}, "vms")
+	assert.Equal(t, StatusError, check.Status)
+	assert.Equal(t, "cannot connect: error: failed to connect to the hypervisor", check.Message)
+	assert.Nil(t, check.FixCommand)
+
+	check = CheckStoragePool(&MockExecutor{RunFunc: func(name string, args ...string) (string, error) {
+		if args[len(args)-1] == "uri" {
+			return "qemu:///system\n", nil
+		}
+		return "error: access denied\n", errors.New("exit status 1")
+	}}, "vms")
+	assert.Equal(t, StatusError, check.Status)
+	assert.Equal(t, `cannot read "vms": error: access denied`, check.Message)
+	assert.Nil(t, check.FixCommand)
+}
+
+func TestCheckNetwork(t *testing.T) {
+	const info = "virsh -c qemu:///system net-info default"
+
+	check := CheckNetwork(virshHost(map[string]string{info: "Name: default\nActive: yes\nAutostart: yes\n"}), "default")
+	assert.Equal(t, StatusOK, check.Status)
+	assert.Equal(t, `"default" active`, check.Message)
+
+	check = CheckNetwork(virshHost(map[string]string{info: "Name: default\nActive: no\nAutostart: yes\n"}), "default")
+	assert.Equal(t, StatusError, check.Status)
+
+	check = CheckNetwork(virshHost(nil), "default")
+	assert.Equal(t, StatusMissing, check.Status)
+	if runtime.GOOS == PlatformLinux {
+		require.NotNil(t, check.FixCommand)
+		assert.Contains(t, check.FixCommand.Command, `echo '<network><name>default</name><forward mode="nat"/>`)
+		assert.Contains(t, check.FixCommand.Command, "| virsh -c qemu:///system net-define /dev/stdin && ")
+		assert.True(t, strings.HasSuffix(check.FixCommand.Command, "net-start default && virsh -c qemu:///system net-autostart default"))
+		assert.Contains(t, check.FixCommand.Description, "(192.168.122.0/24)")
+	}
+}
+
+func TestCheckNetwork_FreeSubnet(t *testing.T) {
+	host := virshHost(map[string]string{
+		"virsh -c qemu:///system net-list --all --name": "default\nlab\n\n",
+		"virsh -c qemu:///system net-dumpxml default":   "<network><ip address='192.168.122.1' netmask='255.255.255.0'/></network>",
+		"virsh -c qemu:///system net-dumpxml lab":       "<network><ip address='192.168.123.1' netmask='255.255.255.0'/></network>",
+		"ip -4 route": "default via 192.168.1.1 dev eth0\n192.168.124.0/24 dev docker1 proto kernel scope link src 192.168.124.1\n",
+	})
+
+	check := CheckNetwork(host, "vms")
+	assert.Equal(t, StatusMissing, check.Status)
+	if runtime.GOOS == PlatformLinux {
+		require.NotNil(t, check.FixCommand)
+		assert.Equal(t, `Create NAT network "vms" (192.168.125.0/24)`, check.FixCommand.Description)
+		assert.Contains(t, check.FixCommand.Command, `<ip address="192.168.125.1" netmask="255.255.255.0">`)
+		assert.Contains(t, check.FixCommand.Command, `<range start="192.168.125.2" end="192.168.125.254"/>`)
+	}
+
+	// Every range is taken
+	var routes strings.Builder
+	for n := 0; n < 256; n++ {
+		routes.WriteString("192.168." + strconv.Itoa(n) + ".0/24 dev br0\n")
+	}
+	check = CheckNetwork(virshHost(map[string]string{"ip -4 route": routes.String()}), "vms")
+	assert.Equal(t, StatusMissing, check.Status)
+	assert.Equal(t, `"vms" not defined, no free 192.168.N.0/24 subnet`, check.Message)
+	assert.Nil(t, check.FixCommand)
+}
+
+func TestChecker_StoragePoolAndNetwork(t *testing.T) {
+	var commands []string
+	exec := &MockExecutor{RunFunc: func(name string, args ...string) (string, error) {
+		commands = append(commands, strings.Join(args, " "))
+		return "State: running\nActive: yes\n", nil
+	}}
+
+	checker := NewCheckerWithExecutor(exec)
+	assert.Equal(t, StatusOK, checker.GetCheck(IDStoragePool).Status)
+	checker.SetStoragePool("vms")
+	checker.SetNetwork("lab")
+	checker.GetCheck(IDStoragePool)
+	checker.GetCheck(IDNetwork)
+
+	assert.Equal(t, []string{
+		"-c qemu:///system pool-info default",
+		"-c qemu:///system pool-info vms",
+		"-c qemu:///system net-info lab",
+	}, commands)
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "default", shellQuote("default"))
+	assert.Equal(t, "/var/lib/libvirt/images", shellQuote("/var/lib/libvirt/images"))
+	assert.Equal(t, "'my pool'", shellQuote("my pool"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, "''", shellQuote(""))
+}
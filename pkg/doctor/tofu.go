@@ -0,0 +1,222 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Minimum versions for the configs terragrunt.Generator writes. Every
+// OpenTofu release meets their required_version (">= 1.0"); 1.6.0 was the
+// first stable one. Terragrunt runs OpenTofu from 0.52.0.
+const (
+	MinTofuVersion       = "1.6.0"
+	MinTerragruntVersion = "0.52.0"
+)
+
+// The libvirt provider the generated versions.tf requires.
+const (
+	LibvirtProviderSource  = "dmacvicar/libvirt"
+	LibvirtProviderVersion = "0.9" // ~> 0.9.0
+)
+
+// providerRegistries are the registry hosts a plugin cache may hold
+// providers under.
+var providerRegistries = []string{"registry.opentofu.org", "registry.terraform.io"}
+
+// CheckTofu checks that OpenTofu is installed and new enough.
+func CheckTofu(exec CommandExecutor) Check {
+	check := checkTool(
+		exec,
+		IDTofu,
+		"OpenTofu",
+		"Infrastructure as Code tool",
+		[]string{"version"},
+		regexp.MustCompile(`OpenTofu v(\d+\.\d+\.\d+)`),
+		GetFixCommand(IDTofu, runtime.GOOS),
+	)
+	return checkMinVersion(check, MinTofuVersion)
+}
+
+// CheckTerragrunt checks that Terragrunt is installed and new enough.
+func CheckTerragrunt(exec CommandExecutor) Check {
+	check := checkTool(
+		exec,
+		IDTerragrunt,
+		"Terragrunt",
+		"Runs OpenTofu for each VM config",
+		[]string{"--version"},
+		regexp.MustCompile(`terragrunt version v?(\d+\.\d+\.\d+)`),
+		GetFixCommand(IDTerragrunt, runtime.GOOS),
+	)
+	return checkMinVersion(check, MinTerragruntVersion)
+}
+
+// checkMinVersion marks an installed tool older than min as an error. Tools
+// with an unknown version are left as they are.
+func checkMinVersion(check Check, min string) Check {
+	if check.Status != StatusOK {
+		return check
+	}
+
+	cmp, ok := compareVersions(check.Message, min)
+	if ok && cmp < 0 {
+		check.Status = StatusError
+		check.Message = fmt.Sprintf("%s (need >= %s)", check.Message, min)
+	}
+	return check
+}
+
+// compareVersions compares two dotted numeric versions, returning -1, 0 or
+// 1. ok is false if either is not a version.
+func compareVersions(a, b string) (int, bool) {
+	pa, okA := parseVersion(a)
+	pb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0, false
+	}
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// parseVersion parses "1.2.3", ignoring a leading v and any pre-release
+// suffix.
+func parseVersion(v string) ([]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "-")
+
+	var parts []int
+	for _, field := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// pluginCacheRegex matches plugin_cache_dir in an OpenTofu CLI config file.
+var pluginCacheRegex = regexp.MustCompile(`(?m)^\s*plugin_cache_dir\s*=\s*"([^"]+)"`)
+
+// CheckLibvirtProvider checks that OpenTofu has a plugin cache holding the
+// libvirt provider, so applies do not download it for every VM.
+func CheckLibvirtProvider(env EnvGetter) Check {
+	check := Check{
+		ID:          IDLibvirtProvider,
+		Name:        "libvirt provider",
+		Description: LibvirtProviderSource + " in the plugin cache",
+	}
+
+	home := env.Getenv("HOME")
+	dir := pluginCacheDir(env, home)
+	if dir == "" {
+		check.Status = StatusWarning
+		check.Message = "no plugin cache configured (init downloads it for each VM)"
+		if home != "" {
+			check.FixCommand = ProviderFixCommand(filepath.Join(home, ".terraform.d", "plugin-cache"), filepath.Join(home, ".tofurc"))
+		}
+		return check
+	}
+	check.FixCommand = ProviderFixCommand(dir, "")
+
+	var found []string
+	for _, registry := range providerRegistries {
+		entries, err := os.ReadDir(filepath.Join(dir, registry, LibvirtProviderSource))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			if strings.HasPrefix(e.Name(), LibvirtProviderVersion+".") {
+				check.Status = StatusOK
+				check.Message = e.Name() + " cached"
+				check.FixCommand = nil
+				return check
+			}
+			found = append(found, e.Name())
+		}
+	}
+
+	check.Status = StatusWarning
+	if len(found) > 0 {
+		check.Message = fmt.Sprintf("%s cached, need ~> %s.0", strings.Join(found, ", "), LibvirtProviderVersion)
+	} else {
+		check.Message = "not cached in " + dir
+	}
+	return check
+}
+
+// pluginCacheDir returns TF_PLUGIN_CACHE_DIR, or plugin_cache_dir from
+// ~/.tofurc or ~/.terraformrc, or "" if no cache is configured.
+func pluginCacheDir(env EnvGetter, home string) string {
+	if dir := env.Getenv("TF_PLUGIN_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	if home == "" {
+		return ""
+	}
+
+	for _, name := range []string{".tofurc", ".terraformrc"} {
+		data, err := os.ReadFile(filepath.Join(home, name))
+		if err != nil {
+			continue
+		}
+		if m := pluginCacheRegex.FindSubmatch(data); m != nil {
+			dir := string(m[1])
+			dir = strings.ReplaceAll(dir, "$HOME", home)
+			if strings.HasPrefix(dir, "~/") {
+				dir = filepath.Join(home, dir[2:])
+			}
+			return dir
+		}
+	}
+	return ""
+}
+
+// ProviderFixCommand returns the fix that downloads the libvirt provider
+// into the plugin cache at dir. If rcFile is set, the cache is configured
+// there first.
+func ProviderFixCommand(dir, rcFile string) *FixCommand {
+	versions := fmt.Sprintf(`terraform {\n  required_providers {\n    libvirt = {\n      source  = "%s"\n      version = "~> %s.0"\n    }\n  }\n}\n`,
+		LibvirtProviderSource, LibvirtProviderVersion)
+
+	description := "Download the libvirt provider into " + dir
+	steps := []string{"mkdir -p " + shellQuote(dir)}
+	if rcFile != "" {
+		steps = append(steps, "echo "+shellQuote(fmt.Sprintf(`plugin_cache_dir = "%s"`, dir))+" >> "+shellQuote(rcFile))
+		description = fmt.Sprintf("Configure a plugin cache in %s and download the libvirt provider", rcFile)
+	}
+	steps = append(steps,
+		`d=$(mktemp -d)`,
+		"printf "+shellQuote(versions)+` > "$d/versions.tf"`,
+		"TF_PLUGIN_CACHE_DIR="+shellQuote(dir)+` tofu -chdir="$d" init -input=false -no-color`,
+		`rm -rf "$d"`,
+	)
+
+	return &FixCommand{
+		Description: description,
+		Command:     strings.Join(steps, " && "),
+	}
+}
@@ -0,0 +1,122 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTofu(t *testing.T) {
+	tests := []struct {
+		output  string
+		status  CheckStatus
+		message string
+	}{
+		{"OpenTofu v1.8.2\non linux_amd64\n", StatusOK, "1.8.2"},
+		{"OpenTofu v1.6.0\n", StatusOK, "1.6.0"},
+		{"OpenTofu v1.5.7\n", StatusError, "1.5.7 (need >= 1.6.0)"},
+		{"something else", StatusOK, "installed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			check := CheckTofu(&MockExecutor{RunFunc: func(name string, args ...string) (string, error) {
+				assert.Equal(t, []string{"version"}, args)
+				return tt.output, nil
+			}})
+			assert.Equal(t, IDTofu, check.ID)
+			assert.Equal(t, tt.status, check.Status)
+			assert.Equal(t, tt.message, check.Message)
+		})
+	}
+}
+
+func TestCheckTerragrunt(t *testing.T) {
+	check := CheckTerragrunt(&MockExecutor{RunFunc: func(string, ...string) (string, error) {
+		return "terragrunt version v0.67.16\n", nil
+	}})
+	assert.Equal(t, StatusOK, check.Status)
+	assert.Equal(t, "0.67.16", check.Message)
+
+	check = CheckTerragrunt(&MockExecutor{RunFunc: func(string, ...string) (string, error) {
+		return "terragrunt version v0.48.0\n", nil
+	}})
+	assert.Equal(t, StatusError, check.Status)
+	assert.Equal(t, "0.48.0 (need >= 0.52.0)", check.Message)
+	assert.NotNil(t, check.FixCommand)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+		ok   bool
+	}{
+		{"1.6.0", "1.6.0", 0, true},
+		{"1.10.0", "1.9.9", 1, true},
+		{"0.52", "0.52.0", 0, true},
+		{"v1.5.7", "1.6.0", -1, true},
+		{"1.6.0-rc1", "1.6.0", 0, true},
+		{"installed", "1.6.0", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := compareVersions(tt.a, tt.b)
+		assert.Equal(t, tt.ok, ok, tt.a)
+		assert.Equal(t, tt.want, got, tt.a)
+	}
+}
+
+func TestCheckLibvirtProvider(t *testing.T) {
+	home := t.TempDir()
+	cache := filepath.Join(home, "cache")
+	env := &MockEnvGetter{Vars: map[string]string{"HOME": home}}
+
+	// No cache configured
+	check := CheckLibvirtProvider(env)
+	assert.Equal(t, StatusWarning, check.Status)
+	require.NotNil(t, check.FixCommand)
+	assert.Contains(t, check.FixCommand.Command, `plugin_cache_dir = "`+filepath.Join(home, ".terraform.d", "plugin-cache")+`"`)
+	assert.Contains(t, check.FixCommand.Command, filepath.Join(home, ".tofurc"))
+
+	// Configured in ~/.tofurc but empty
+	rc := "plugin_cache_dir = \"$HOME/cache\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".tofurc"), []byte(rc), 0644))
+	check = CheckLibvirtProvider(env)
+	assert.Equal(t, StatusWarning, check.Status)
+	assert.Equal(t, "not cached in "+cache, check.Message)
+	require.NotNil(t, check.FixCommand)
+	assert.NotContains(t, check.FixCommand.Command, ".tofurc")
+	assert.Contains(t, check.FixCommand.Command, "TF_PLUGIN_CACHE_DIR="+cache+` tofu -chdir="$d" init`)
+
+	// Only an incompatible version
+	provider := filepath.Join(cache, "registry.opentofu.org", "dmacvicar", "libvirt")
+	require.NoError(t, os.MkdirAll(filepath.Join(provider, "0.8.3"), 0755))
+	check = CheckLibvirtProvider(env)
+	assert.Equal(t, StatusWarning, check.Status)
+	assert.Equal(t, "0.8.3 cached, need ~> 0.9.0", check.Message)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(provider, "0.9.1", "linux_amd64"), 0755))
+	check = CheckLibvirtProvider(env)
+	assert.Equal(t, StatusOK, check.Status)
+	assert.Equal(t, "0.9.1 cached", check.Message)
+	assert.Nil(t, check.FixCommand)
+
+	// TF_PLUGIN_CACHE_DIR wins over the CLI config
+	env.Vars["TF_PLUGIN_CACHE_DIR"] = t.TempDir()
+	check = CheckLibvirtProvider(env)
+	assert.Equal(t, StatusWarning, check.Status)
+	assert.Equal(t, "not cached in "+env.Vars["TF_PLUGIN_CACHE_DIR"], check.Message)
+}
+
+func TestGroupTerraform_ChecksWhatTheGeneratorRuns(t *testing.T) {
+	def, ok := GetGroupDefinition(GroupTerraform)
+	require.True(t, ok)
+	assert.Contains(t, def.CheckIDs, IDTofu)
+	assert.Contains(t, def.CheckIDs, IDTerragrunt)
+	assert.Contains(t, def.CheckIDs, IDLibvirtProvider)
+	assert.NotContains(t, def.CheckIDs, IDTerraform)
+}
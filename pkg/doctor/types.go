@@ -1,6 +1,8 @@
 // Package doctor provides dependency checking and fixing for ucli.
 package doctor
 
+import "fmt"
+
 // CheckStatus represents the status of a dependency check.
 type CheckStatus int
 
@@ -31,31 +33,53 @@ func (s CheckStatus) String() string {
 	}
 }
 
+// MarshalText encodes the status as its string form, e.g. in JSON output.
+func (s CheckStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a status written by MarshalText.
+func (s *CheckStatus) UnmarshalText(text []byte) error {
+	for _, status := range []CheckStatus{StatusOK, StatusMissing, StatusError, StatusWarning} {
+		if status.String() == string(text) {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown check status %q", text)
+}
+
 // Check represents a single dependency check result.
 type Check struct {
-	ID          string      // Unique identifier, e.g., "terraform", "multipass"
-	Name        string      // Display name
-	Description string      // What this tool does
-	Status      CheckStatus // Current status
-	Message     string      // Status message (version info, error, etc.)
-	FixCommand  *FixCommand // How to fix if missing (nil if not fixable)
+	ID          string      `json:"id"`            // Unique identifier, e.g., "terraform", "multipass"
+	Name        string      `json:"name"`          // Display name
+	Description string      `json:"description"`   // What this tool does
+	Status      CheckStatus `json:"status"`        // Current status
+	Message     string      `json:"message"`       // Status message (version info, error, etc.)
+	FixCommand  *FixCommand `json:"fix,omitempty"` // How to fix if missing (nil if not fixable)
+}
+
+// HasIssue returns true if the check is missing or errored.
+func (c Check) HasIssue() bool {
+	return c.Status == StatusMissing || c.Status == StatusError
 }
 
 // FixCommand describes how to fix a missing dependency.
 type FixCommand struct {
-	Description string // Human-readable description of what the fix does
-	Command     string // Shell command to run
-	Sudo        bool   // Whether the command requires sudo
-	Platform    string // Target platform: "darwin", "linux", or "" for both
+	Description string `json:"description"` // Human-readable description of what the fix does
+	Command     string `json:"command"`     // Shell command to run
+	Sudo        bool   `json:"sudo"`        // Whether the command requires sudo
+	Platform    string `json:"platform"`    // Target platform: "darwin", "linux", or "" for both
 }
 
 // CheckGroup represents a group of related dependency checks.
 type CheckGroup struct {
-	ID          string  // Unique identifier, e.g., "terraform", "multipass", "iso"
-	Name        string  // Display name
-	Description string  // What this group is for
-	Platform    string  // Target platform: "darwin", "linux", or "" for both
-	Checks      []Check // Individual checks in this group
+	ID          string  `json:"id"`          // Unique identifier, e.g., "terraform", "multipass", "iso"
+	Name        string  `json:"name"`        // Display name
+	Description string  `json:"description"` // What this group is for
+	Platform    string  `json:"platform"`    // Target platform: "darwin", "linux", or "" for both
+	Required    bool    `json:"required"`    // Whether issues in this group fail 'ucli doctor'
+	Checks      []Check `json:"checks"`      // Individual checks in this group
 }
 
 // GroupID constants for check groups.
@@ -68,12 +92,20 @@ const (
 
 // CheckID constants for individual checks.
 const (
-	IDTerraform  = "terraform"
-	IDLibvirt    = "libvirt"
-	IDVirsh      = "virsh"
-	IDQemuKVM    = "qemu-kvm"
-	IDCloudImage = "cloud-image"
-	IDMultipass  = "multipass"
-	IDXorriso    = "xorriso"
-	IDGhostty    = "ghostty"
+	IDTerraform       = "terraform"
+	IDTofu            = "tofu"
+	IDTerragrunt      = "terragrunt"
+	IDLibvirtProvider = "libvirt-provider"
+	IDLibvirt         = "libvirt"
+	IDVirsh           = "virsh"
+	IDQemuKVM         = "qemu-kvm"
+	IDKVMDevice       = "kvm-device"
+	IDLibvirtGroups   = "libvirt-groups"
+	IDLibvirtURI      = "libvirt-uri"
+	IDStoragePool     = "storage-pool"
+	IDNetwork         = "network"
+	IDCloudImage      = "cloud-image"
+	IDMultipass       = "multipass"
+	IDXorriso         = "xorriso"
+	IDGhostty         = "ghostty"
 )
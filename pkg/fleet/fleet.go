@@ -0,0 +1,324 @@
+// Package fleet deploys several machines from the same options in
+// parallel, applying a shared policy when one of them fails.
+package fleet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/journal"
+)
+
+// DefaultParallel is how many machines are deployed at once by default.
+const DefaultParallel = 3
+
+// MaxMachines is the largest fleet that can be deployed in one run.
+const MaxMachines = 50
+
+// Policy decides what happens to the rest of a fleet when a machine fails.
+type Policy string
+
+const (
+	PolicyContinue Policy = "continue" // Keep deploying the other machines
+	PolicyAbort    Policy = "abort"    // Cancel the others; keep those that succeeded
+	PolicyCleanup  Policy = "cleanup"  // Cancel the others and remove those that succeeded
+)
+
+// Policies lists the failure policies in the order they are offered.
+var Policies = []Policy{PolicyContinue, PolicyAbort, PolicyCleanup}
+
+// ParsePolicy parses a failure policy; empty means PolicyContinue.
+func ParsePolicy(s string) (Policy, error) {
+	if s == "" {
+		return PolicyContinue, nil
+	}
+	for _, p := range Policies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown failure policy %q (one of: continue, abort, cleanup)", s)
+}
+
+// Status is the outcome of one machine.
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // Not started yet
+	StatusSucceeded Status = "succeeded" // Deployed
+	StatusFailed    Status = "failed"    // Its deployment failed
+	StatusCancelled Status = "cancelled" // Stopped because the fleet was aborted or interrupted
+	StatusSkipped   Status = "skipped"   // Never started because the fleet was aborted
+	StatusRemoved   Status = "removed"   // Deployed, then removed by PolicyCleanup
+)
+
+// Machine is one deployment of a fleet.
+type Machine struct {
+	Name     string
+	Deployer deploy.Deployer
+	Options  *deploy.DeployOptions
+}
+
+// Event is a progress event from one machine.
+type Event struct {
+	Machine string
+	deploy.ProgressEvent
+}
+
+// ProgressCallback receives the events of every machine, one at a time.
+type ProgressCallback func(Event)
+
+// Options controls how a fleet is deployed.
+type Options struct {
+	Parallel int    // Machines deployed at once (DefaultParallel if zero)
+	Policy   Policy // What to do when a machine fails (PolicyContinue if empty)
+
+	// Journal records each machine's deployment with journal.Start so it
+	// can be replayed with ucli logs and resumed individually.
+	Journal bool
+
+	// SerialFirst deploys the first machine on its own before the others
+	// start, so resources the machines share, such as the Terragrunt
+	// module's base volume, are created once rather than raced for.
+	SerialFirst bool
+}
+
+// Result is the outcome of one machine.
+type Result struct {
+	Name      string
+	Status    Status
+	Result    *deploy.DeployResult // Nil for pending and skipped machines
+	JournalID string
+}
+
+// Address returns the machine's IP address from its outputs, or "".
+func (r Result) Address() string {
+	if r.Result == nil {
+		return ""
+	}
+	for _, key := range []string{"ip", "vm_ip"} {
+		if ip := r.Result.Outputs[key]; ip != "" {
+			return ip
+		}
+	}
+	return ""
+}
+
+// Error returns the first line of the machine's error, or "".
+func (r Result) Error() string {
+	if r.Result == nil || r.Result.Error == nil {
+		return ""
+	}
+	msg, _, _ := strings.Cut(r.Result.Error.Error(), "\n")
+	return msg
+}
+
+// Results are the outcomes of a fleet, in the order the machines were given.
+type Results []Result
+
+// Count returns how many machines have status s.
+func (rs Results) Count(s Status) int {
+	n := 0
+	for _, r := range rs {
+		if r.Status == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Success reports whether every machine was deployed.
+func (rs Results) Success() bool {
+	return rs.Count(StatusSucceeded) == len(rs)
+}
+
+// Summary describes the outcome, e.g. "2 succeeded, 1 failed".
+func (rs Results) Summary() string {
+	var parts []string
+	for _, s := range []Status{StatusSucceeded, StatusFailed, StatusCancelled, StatusSkipped, StatusRemoved, StatusPending} {
+		if n := rs.Count(s); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, s))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Err returns an error describing the machines that were not deployed, or
+// nil if all were.
+func (rs Results) Err() error {
+	if rs.Success() {
+		return nil
+	}
+	return fmt.Errorf("%d of %d machines not deployed (%s)",
+		len(rs)-rs.Count(StatusSucceeded), len(rs), rs.Summary())
+}
+
+// Names returns base-1 to base-count.
+func Names(base string, count int) []string {
+	names := make([]string, count)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s-%d", base, i+1)
+	}
+	return names
+}
+
+// Run deploys machines, at most opts.Parallel at a time, after the first
+// one if opts.SerialFirst is set. A machine that fails is cleaned up like a
+// single deployment (unless it keeps resources on failure), then
+// opts.Policy decides whether the others go on. Run returns once every
+// started machine has finished.
+func Run(ctx context.Context, machines []Machine, opts Options, progress ProgressCallback) Results {
+	parallel := opts.Parallel
+	if parallel <= 0 {
+		parallel = DefaultParallel
+	}
+	parallel = min(parallel, len(machines))
+
+	results := make(Results, len(machines))
+	for i, m := range machines {
+		results[i] = Result{Name: m.Name, Status: StatusPending}
+	}
+
+	// Workers report concurrently; callers see one event at a time
+	var mu sync.Mutex
+	report := func(name string, e deploy.ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress(Event{Machine: name, ProgressEvent: e})
+	}
+
+	fleetCtx, abort := context.WithCancel(ctx)
+	defer abort()
+	onFailure := func() {
+		if opts.Policy == PolicyAbort || opts.Policy == PolicyCleanup {
+			abort()
+		}
+	}
+
+	next := 0
+	if opts.SerialFirst && len(machines) > 1 && fleetCtx.Err() == nil {
+		results[0] = deployMachine(fleetCtx, machines[0], opts.Journal, report, onFailure)
+		next = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if fleetCtx.Err() != nil {
+					continue // Left pending, reported as skipped
+				}
+				results[i] = deployMachine(fleetCtx, machines[i], opts.Journal, report, onFailure)
+			}
+		}()
+	}
+
+dispatch:
+	for i := next; i < len(machines); i++ {
+		select {
+		case jobs <- i:
+		case <-fleetCtx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range results {
+		if results[i].Status == StatusPending {
+			results[i].Status = StatusSkipped
+		}
+	}
+
+	failed := results.Count(StatusFailed)+results.Count(StatusCancelled) > 0
+	if opts.Policy == PolicyCleanup && failed {
+		for i, m := range machines {
+			if results[i].Status == StatusSucceeded {
+				removeMachine(ctx, m, &results[i], report)
+			}
+		}
+	}
+
+	return results
+}
+
+// deployMachine deploys one machine, cleaning up if it fails. onFailure is
+// called as soon as the deployment fails on its own, before the cleanup.
+func deployMachine(ctx context.Context, m Machine, record bool, report func(string, deploy.ProgressEvent), onFailure func()) Result {
+	res := Result{Name: m.Name}
+	target := m.Deployer.Target()
+	progress := func(e deploy.ProgressEvent) { report(m.Name, e) }
+
+	var j *journal.Journal
+	if record {
+		var err error
+		if j, err = journal.Start(target, m.Options.VMName(target)); err == nil {
+			progress = j.Progress(progress)
+			res.JournalID = j.ID
+		}
+	}
+
+	start := time.Now()
+	result, err := m.Deployer.Deploy(ctx, m.Options, progress)
+	if result == nil {
+		result = &deploy.DeployResult{Success: false, Target: target, Error: err}
+	}
+	if result.Duration == 0 {
+		result.Duration = time.Since(start)
+	}
+	res.Result = result
+
+	if result.Success {
+		res.Status = StatusSucceeded
+	} else {
+		if result.Error == nil {
+			result.Error = err
+		}
+		if result.Error == nil {
+			result.Error = errors.New("unknown error")
+		}
+
+		res.Status = StatusFailed
+		if ctx.Err() != nil {
+			res.Status = StatusCancelled
+			progress(deploy.NewErrorEvent("Deployment cancelled"))
+		} else {
+			onFailure()
+		}
+		if err := deploy.RunCleanup(ctx, m.Deployer, m.Options, progress); err != nil {
+			result.Logs = append(result.Logs, fmt.Sprintf("Warning: %v", err))
+		}
+		if !m.Options.KeepOnFailure(target) {
+			result.Checkpoint = nil // Nothing left to resume
+		}
+	}
+
+	if j != nil {
+		_ = j.Finish(result)
+	}
+	return res
+}
+
+// removeMachine removes a deployed machine for PolicyCleanup. Machines that
+// keep resources on failure are left alone.
+func removeMachine(ctx context.Context, m Machine, res *Result, report func(string, deploy.ProgressEvent)) {
+	target := m.Deployer.Target()
+	if m.Options.KeepOnFailure(target) {
+		return
+	}
+
+	progress := func(e deploy.ProgressEvent) { report(m.Name, e) }
+	if err := deploy.RunCleanup(ctx, m.Deployer, m.Options, progress); err != nil {
+		res.Result.Logs = append(res.Result.Logs, fmt.Sprintf("Warning: %v", err))
+		return
+	}
+	res.Status = StatusRemoved
+}
@@ -0,0 +1,251 @@
+package fleet
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/journal"
+)
+
+// fakeDeployer succeeds, fails, or blocks until its context is cancelled.
+type fakeDeployer struct {
+	fail     bool
+	block    bool
+	started  chan struct{} // Closed when Deploy starts, if set
+	wait     chan struct{} // Deploy waits for it to close, if set
+	finished chan struct{} // Closed when Deploy returns, if set
+	after    chan struct{} // Deploy notes whether it is closed yet, if set
+	early    bool          // Deploy started before after was closed
+	running  *atomic.Int32 // Shared by a fleet to measure parallelism
+	peak     *atomic.Int32
+	cleanups int
+}
+
+func (d *fakeDeployer) Name() string                         { return "Fake" }
+func (d *fakeDeployer) Target() deploy.DeploymentTarget      { return deploy.TargetMultipass }
+func (d *fakeDeployer) Validate(*deploy.DeployOptions) error { return nil }
+
+func (d *fakeDeployer) Deploy(ctx context.Context, opts *deploy.DeployOptions, progress deploy.ProgressCallback) (*deploy.DeployResult, error) {
+	if d.finished != nil {
+		defer close(d.finished)
+	}
+	if d.after != nil {
+		select {
+		case <-d.after:
+		default:
+			d.early = true
+		}
+	}
+	if d.running != nil {
+		n := d.running.Add(1)
+		defer d.running.Add(-1)
+		for {
+			peak := d.peak.Load()
+			if n <= peak || d.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if d.started != nil {
+		close(d.started)
+	}
+	if d.wait != nil {
+		<-d.wait
+	}
+
+	progress(deploy.NewProgressEvent(deploy.StageLaunching, "Launching "+opts.Multipass.VMName, 50))
+	switch {
+	case d.block:
+		<-ctx.Done()
+		return &deploy.DeployResult{Success: false, Error: ctx.Err()}, ctx.Err()
+	case d.fail:
+		err := errors.New("launch failed\nstderr follows")
+		return &deploy.DeployResult{Success: false, Error: err}, err
+	}
+	return &deploy.DeployResult{
+		Success: true,
+		Outputs: map[string]string{"ip": "10.0.0.1"},
+	}, nil
+}
+
+func (d *fakeDeployer) Cleanup(context.Context, *deploy.DeployOptions) error {
+	d.cleanups++
+	return nil
+}
+
+func newMachine(name string, d *fakeDeployer) Machine {
+	return Machine{
+		Name:     name,
+		Deployer: d,
+		Options:  &deploy.DeployOptions{Multipass: deploy.MultipassOptions{VMName: name}},
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, PolicyContinue, p)
+
+	p, err = ParsePolicy("cleanup")
+	require.NoError(t, err)
+	assert.Equal(t, PolicyCleanup, p)
+
+	_, err = ParsePolicy("retry")
+	assert.Error(t, err)
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, []string{"dev-1", "dev-2", "dev-3"}, Names("dev", 3))
+}
+
+func TestRun_Parallel(t *testing.T) {
+	var running, peak atomic.Int32
+	var machines []Machine
+	for _, name := range Names("dev", 5) {
+		machines = append(machines, newMachine(name, &fakeDeployer{running: &running, peak: &peak}))
+	}
+
+	seen := make(map[string]int)
+	results := Run(context.Background(), machines, Options{Parallel: 2}, func(e Event) {
+		seen[e.Machine]++
+	})
+
+	require.Len(t, results, 5)
+	assert.True(t, results.Success())
+	assert.NoError(t, results.Err())
+	assert.Equal(t, "dev-3", results[2].Name)
+	assert.Equal(t, "10.0.0.1", results[2].Address())
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+	assert.Len(t, seen, 5)
+}
+
+func TestRun_SerialFirst(t *testing.T) {
+	first := make(chan struct{})
+	others := []*fakeDeployer{{after: first}, {after: first}}
+	machines := []Machine{
+		newMachine("a", &fakeDeployer{finished: first}),
+		newMachine("b", others[0]),
+		newMachine("c", others[1]),
+	}
+
+	results := Run(context.Background(), machines, Options{Parallel: 3, SerialFirst: true}, func(Event) {})
+
+	assert.True(t, results.Success())
+	for _, d := range others {
+		assert.False(t, d.early, "started before the first machine finished")
+	}
+}
+
+func TestRun_SerialFirstAbort(t *testing.T) {
+	machines := []Machine{
+		newMachine("a", &fakeDeployer{fail: true}),
+		newMachine("b", &fakeDeployer{}),
+	}
+
+	results := Run(context.Background(), machines, Options{SerialFirst: true, Policy: PolicyAbort}, func(Event) {})
+
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Equal(t, StatusSkipped, results[1].Status)
+}
+
+func TestRun_Continue(t *testing.T) {
+	failing := &fakeDeployer{fail: true}
+	machines := []Machine{
+		newMachine("a", &fakeDeployer{}),
+		newMachine("b", failing),
+		newMachine("c", &fakeDeployer{}),
+	}
+
+	results := Run(context.Background(), machines, Options{Parallel: 1}, func(Event) {})
+
+	assert.Equal(t, StatusSucceeded, results[0].Status)
+	assert.Equal(t, StatusFailed, results[1].Status)
+	assert.Equal(t, "launch failed", results[1].Error())
+	assert.Equal(t, StatusSucceeded, results[2].Status)
+	assert.Equal(t, 1, failing.cleanups)
+	assert.EqualError(t, results.Err(), "1 of 3 machines not deployed (2 succeeded, 1 failed)")
+}
+
+func TestRun_KeepOnFailure(t *testing.T) {
+	failing := &fakeDeployer{fail: true}
+	m := newMachine("a", failing)
+	m.Options.Multipass.KeepOnFailure = true
+
+	results := Run(context.Background(), []Machine{m}, Options{}, func(Event) {})
+
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Zero(t, failing.cleanups)
+}
+
+func TestRun_Abort(t *testing.T) {
+	// a fails once b is running
+	started := make(chan struct{})
+	machines := []Machine{
+		newMachine("a", &fakeDeployer{fail: true, wait: started}),
+		newMachine("b", &fakeDeployer{block: true, started: started}),
+		newMachine("c", &fakeDeployer{}),
+	}
+
+	results := Run(context.Background(), machines, Options{Parallel: 2, Policy: PolicyAbort}, func(Event) {})
+
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Equal(t, StatusCancelled, results[1].Status)
+	assert.Equal(t, StatusSkipped, results[2].Status)
+	assert.Nil(t, results[2].Result)
+}
+
+func TestRun_Cleanup(t *testing.T) {
+	deployed := &fakeDeployer{}
+	machines := []Machine{
+		newMachine("a", deployed),
+		newMachine("b", &fakeDeployer{fail: true}),
+		newMachine("c", &fakeDeployer{}),
+	}
+
+	results := Run(context.Background(), machines, Options{Parallel: 1, Policy: PolicyCleanup}, func(Event) {})
+
+	assert.Equal(t, StatusRemoved, results[0].Status)
+	assert.Equal(t, 1, deployed.cleanups)
+	assert.Equal(t, StatusFailed, results[1].Status)
+	assert.Equal(t, StatusSkipped, results[2].Status)
+	assert.Equal(t, "1 failed, 1 skipped, 1 removed", results.Summary())
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := Run(ctx, []Machine{newMachine("a", &fakeDeployer{block: true})}, Options{}, func(Event) {})
+
+	assert.Equal(t, StatusSkipped, results[0].Status)
+	assert.False(t, results.Success())
+}
+
+func TestRun_Journal(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	machines := []Machine{
+		newMachine("a", &fakeDeployer{}),
+		newMachine("b", &fakeDeployer{fail: true}),
+	}
+
+	results := Run(context.Background(), machines, Options{Journal: true}, func(Event) {})
+
+	dir, err := journal.Dir()
+	require.NoError(t, err)
+	for _, r := range results {
+		require.NotEmpty(t, r.JournalID)
+		s, err := journal.Find(dir, r.JournalID)
+		require.NoError(t, err)
+		assert.Equal(t, r.Name, s.VM)
+		assert.True(t, s.Finished)
+	}
+}
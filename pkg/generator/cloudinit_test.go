@@ -36,106 +36,9 @@ func TestGenerate(t *testing.T) {
 		assert.Contains(t, content, "name: testuser")
 		assert.Contains(t, content, "hostname: test-host")
 	})
-}
-
-func TestGenerateFromTemplate(t *testing.T) {
-	t.Run("substitutes variables correctly", func(t *testing.T) {
-		tmpDir := t.TempDir()
-
-		templateContent := `#cloud-config
-users:
-  - name: ${USERNAME}
-    ssh_authorized_keys:
-      - ${SSH_PUBLIC_KEY}
-hostname: ${HOSTNAME}
-write_files:
-  - path: /home/${USERNAME}/.config
-    content: |
-      USER_NAME="${USER_NAME}"
-      USER_EMAIL="${USER_EMAIL}"
-`
-		outputPath := filepath.Join(tmpDir, "output.yaml")
-
-		cfg := &config.FullConfig{
-			Username:      "testuser",
-			Hostname:      "test-host",
-			SSHPublicKeys: []string{"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAITest test@example.com"},
-			FullName:      "Test User",
-			Email:         "test@example.com",
-		}
-
-		err := GenerateFromTemplate(templateContent, cfg, outputPath)
-		require.NoError(t, err)
-
-		// Read output
-		output, err := os.ReadFile(outputPath)
-		require.NoError(t, err)
-
-		content := string(output)
-		assert.Contains(t, content, "name: testuser")
-		assert.Contains(t, content, "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAITest")
-		assert.Contains(t, content, "hostname: test-host")
-		assert.Contains(t, content, `USER_NAME="Test User"`)
-		assert.Contains(t, content, `USER_EMAIL="test@example.com"`)
-		assert.NotContains(t, content, "${USERNAME}")
-		assert.NotContains(t, content, "${HOSTNAME}")
-	})
-
-	t.Run("handles missing optional values", func(t *testing.T) {
-		tmpDir := t.TempDir()
-
-		templateContent := `
-TAILSCALE_AUTH_KEY="${TAILSCALE_AUTH_KEY}"
-GITHUB_USER="${GITHUB_USER}"
-`
-		outputPath := filepath.Join(tmpDir, "output.yaml")
-
-		cfg := &config.FullConfig{
-			Username:      "user",
-			Hostname:      "host",
-			SSHPublicKeys: []string{"ssh-ed25519 test"},
-		}
-
-		err := GenerateFromTemplate(templateContent, cfg, outputPath)
-		require.NoError(t, err)
-
-		output, err := os.ReadFile(outputPath)
-		require.NoError(t, err)
-
-		content := string(output)
-		// Empty values should result in empty strings
-		assert.Contains(t, content, `TAILSCALE_AUTH_KEY=""`)
-		assert.Contains(t, content, `GITHUB_USER=""`)
-	})
-
-	t.Run("sets default repo values", func(t *testing.T) {
-		tmpDir := t.TempDir()
-
-		templateContent := `REPO_URL="${REPO_URL}"
-REPO_BRANCH="${REPO_BRANCH}"`
-		outputPath := filepath.Join(tmpDir, "output.yaml")
-
-		cfg := &config.FullConfig{
-			Username:      "user",
-			Hostname:      "host",
-			SSHPublicKeys: []string{"ssh-ed25519 test"},
-			// No REPO_URL or REPO_BRANCH set
-		}
-
-		err := GenerateFromTemplate(templateContent, cfg, outputPath)
-		require.NoError(t, err)
-
-		output, err := os.ReadFile(outputPath)
-		require.NoError(t, err)
-
-		content := string(output)
-		assert.Contains(t, content, "REPO_BRANCH=\"main\"")
-		assert.Contains(t, content, "https://github.com/jaspreet-dot-casa/cloud-init.git")
-	})
 
 	t.Run("creates output directory if missing", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		outputPath := filepath.Join(tmpDir, "nested", "dir", "output.yaml")
+		outputPath := filepath.Join(t.TempDir(), "nested", "dir", "cloud-init.yaml")
 
 		cfg := &config.FullConfig{
 			Username:      "user",
@@ -143,63 +46,13 @@ REPO_BRANCH="${REPO_BRANCH}"`
 			SSHPublicKeys: []string{"ssh-ed25519 test"},
 		}
 
-		err := GenerateFromTemplate("test", cfg, outputPath)
-		require.NoError(t, err)
-
-		_, err = os.Stat(outputPath)
-		assert.NoError(t, err)
+		require.NoError(t, Generate(cfg, outputPath))
+		assert.FileExists(t, outputPath)
 	})
 }
 
-func TestSubstituteVars(t *testing.T) {
-	tests := []struct {
-		name     string
-		template string
-		vars     *TemplateVars
-		expected string
-	}{
-		{
-			name:     "replaces ${VAR} format",
-			template: "Hello ${USERNAME}!",
-			vars:     &TemplateVars{USERNAME: "world"},
-			expected: "Hello world!",
-		},
-		{
-			name:     "replaces $VAR format",
-			template: "Hello $USERNAME!",
-			vars:     &TemplateVars{USERNAME: "world"},
-			expected: "Hello world!",
-		},
-		{
-			name:     "handles multiple occurrences",
-			template: "${USERNAME} and ${USERNAME} again",
-			vars:     &TemplateVars{USERNAME: "test"},
-			expected: "test and test again",
-		},
-		{
-			name:     "handles multiple variables",
-			template: "${USERNAME}@${HOSTNAME}",
-			vars:     &TemplateVars{USERNAME: "user", HOSTNAME: "host"},
-			expected: "user@host",
-		},
-		{
-			name:     "preserves unmatched patterns",
-			template: "Hello ${UNKNOWN}!",
-			vars:     &TemplateVars{USERNAME: "test"},
-			expected: "Hello ${UNKNOWN}!",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := substituteVars(tt.template, tt.vars)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-func TestConfigToVars(t *testing.T) {
-	t.Run("converts config to vars", func(t *testing.T) {
+func TestTemplateValues(t *testing.T) {
+	t.Run("converts config to values", func(t *testing.T) {
 		cfg := &config.FullConfig{
 			Username:         "testuser",
 			Hostname:         "testhost",
@@ -214,21 +67,18 @@ func TestConfigToVars(t *testing.T) {
 			RepoBranch:       "develop",
 		}
 
-		vars := configToVars(cfg)
-
-		assert.Equal(t, "testuser", vars.USERNAME)
-		assert.Equal(t, "testhost", vars.HOSTNAME)
-		assert.Equal(t, "ssh-ed25519 key1", vars.SSH_PUBLIC_KEY)
-		assert.Contains(t, vars.SSH_PUBLIC_KEYS, "- ssh-ed25519 key1")
-		assert.Contains(t, vars.SSH_PUBLIC_KEYS, "- ssh-ed25519 key2")
-		assert.Equal(t, "Test User", vars.USER_NAME)
-		assert.Equal(t, "test@example.com", vars.USER_EMAIL)
-		assert.Equal(t, "My Machine", vars.MACHINE_USER_NAME)
-		assert.Equal(t, "tskey-test", vars.TAILSCALE_AUTH_KEY)
-		assert.Equal(t, "github-user", vars.GITHUB_USER)
-		assert.Equal(t, "ghp-test", vars.GITHUB_PAT)
-		assert.Equal(t, "https://github.com/test/repo.git", vars.REPO_URL)
-		assert.Equal(t, "develop", vars.REPO_BRANCH)
+		values := templateValues(cfg)
+
+		assert.Equal(t, "testuser", values["USERNAME"])
+		assert.Equal(t, "testhost", values["HOSTNAME"])
+		assert.Equal(t, "Test User", values["USER_NAME"])
+		assert.Equal(t, "test@example.com", values["USER_EMAIL"])
+		assert.Equal(t, "My Machine", values["MACHINE_USER_NAME"])
+		assert.Equal(t, "tskey-test", values["TAILSCALE_AUTH_KEY"])
+		assert.Equal(t, "github-user", values["GITHUB_USER"])
+		assert.Equal(t, "ghp-test", values["GITHUB_PAT"])
+		assert.Equal(t, "https://github.com/test/repo.git", values["REPO_URL"])
+		assert.Equal(t, "develop", values["REPO_BRANCH"])
 	})
 
 	t.Run("uses FullName as fallback for MachineName", func(t *testing.T) {
@@ -240,8 +90,8 @@ func TestConfigToVars(t *testing.T) {
 			// MachineName not set
 		}
 
-		vars := configToVars(cfg)
-		assert.Equal(t, "Test User", vars.MACHINE_USER_NAME)
+		values := templateValues(cfg)
+		assert.Equal(t, "Test User", values["MACHINE_USER_NAME"])
 	})
 
 	t.Run("sets default repo values", func(t *testing.T) {
@@ -251,9 +101,19 @@ func TestConfigToVars(t *testing.T) {
 			// REPO_URL and REPO_BRANCH not set
 		}
 
-		vars := configToVars(cfg)
-		assert.Equal(t, "main", vars.REPO_BRANCH)
-		assert.Contains(t, vars.REPO_URL, "github.com/jaspreet-dot-casa/cloud-init")
+		values := templateValues(cfg)
+		assert.Equal(t, "main", values["REPO_BRANCH"])
+		assert.Contains(t, values["REPO_URL"], "github.com/jaspreet-dot-casa/cloud-init")
+	})
+
+	t.Run("covers every template placeholder", func(t *testing.T) {
+		values := templateValues(config.NewFullConfig())
+		for _, m := range placeholderRegex.FindAllStringSubmatch(cloudinit.Template, -1) {
+			if m[1] == "SSH_PUBLIC_KEY" {
+				continue // Replaced by the users' keys
+			}
+			assert.Contains(t, values, m[1])
+		}
 	})
 }
 
@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+)
+
+// safeShellWord matches values that need no quoting in a shell command.
+var safeShellWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote returns s as a single shell word, single-quoting it only when needed.
+func shellQuote(s string) string {
+	if safeShellWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellEscapeDouble escapes s for use inside a double-quoted shell string.
+func shellEscapeDouble(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return r.Replace(s)
+}
+
+// copyPackageScripts returns the bootstrap lines that copy overlay package
+// scripts into the clone, or an empty string if there are none.
+func copyPackageScripts(scripts []config.PackageScript) string {
+	if len(scripts) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n# Add package scripts from overlay directories\n")
+	for _, script := range scripts {
+		fmt.Fprintf(&b, "cp %s \"$CLONE_DIR/scripts/packages/\"\n", shellQuote(PackageScriptsDir+"/"+script.File))
+	}
+	return b.String()
+}
+
+// runPackageScripts returns the bootstrap lines that run overlay package
+// scripts not covered by install-all.sh, or an empty string if there are none.
+func runPackageScripts(scripts []config.PackageScript) string {
+	var b strings.Builder
+	for _, script := range scripts {
+		if script.Override {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("\n# Install packages from overlay directories\n")
+		}
+		fmt.Fprintf(&b, "sudo -u \"$CLONE_USER\" CLOUD_INIT=true bash %s install\n", shellQuote("scripts/packages/"+script.File))
+	}
+	return b.String()
+}
+
+// placeholderRegex matches a ${NAME} template placeholder.
+var placeholderRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandText replaces the known placeholders in s with their values as is.
+// Other ${...} expressions are left alone.
+func expandText(s string, values map[string]string) string {
+	return placeholderRegex.ReplaceAllStringFunc(s, func(match string) string {
+		if value, ok := values[match[2:len(match)-1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// expandShell replaces the known placeholders in the shell script s, quoting
+// each value for where it appears: as a word of its own, inside double quotes,
+// or inside single quotes, which are assumed to hold a command run by a
+// nested shell such as bash -c. Placeholders in code are replaced as is, as
+// they hold generated shell code. Other ${...} expressions are shell syntax
+// and left alone.
+func expandShell(s string, values map[string]string, code map[string]bool) string {
+	var b strings.Builder
+	inSingle, inDouble := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inSingle:
+			if c == '\'' {
+				inSingle = false
+			}
+		case c == '\\' && i+1 < len(s):
+			b.WriteString(s[i : i+2])
+			i++
+			continue
+		case c == '"':
+			inDouble = !inDouble
+		case c == '\'' && !inDouble:
+			inSingle = true
+		case c == '#' && !inDouble && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t' || s[i-1] == '\n'):
+			// Copy comments up to the end of the line
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				end = len(s) - i
+			}
+			b.WriteString(s[i : i+end])
+			i += end - 1
+			continue
+		}
+
+		if c == '$' {
+			if loc := placeholderRegex.FindStringSubmatchIndex(s[i:]); loc != nil && loc[0] == 0 {
+				name := s[i+loc[2] : i+loc[3]]
+				if value, ok := values[name]; ok {
+					switch {
+					case code[name]:
+					case inDouble:
+						value = shellEscapeDouble(value)
+					case inSingle:
+						value = strings.ReplaceAll(shellQuote(value), "'", `'\''`)
+					default:
+						value = shellQuote(value)
+					}
+					b.WriteString(value)
+					i += loc[1] - 1
+					continue
+				}
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -104,14 +104,11 @@ func buildSummaryData(cfg *config.FullConfig, registry *packages.Registry) Summa
 	}
 
 	// Build categories with packages (only non-empty categories)
-	categories := make([]CategorySummary, 0, len(packages.CategoryOrder))
+	// Categories() only returns non-empty categories, in display order
+	categories := make([]CategorySummary, 0, len(registry.ByCategory))
 
-	for _, cat := range packages.CategoryOrder {
-		pkgs, ok := registry.ByCategory[cat]
-		if !ok || len(pkgs) == 0 {
-			// Skip empty categories - don't allocate what we won't render
-			continue
-		}
+	for _, cat := range registry.Categories() {
+		pkgs := registry.ByCategory[cat]
 
 		catSummary := CategorySummary{
 			Name:     string(cat),
@@ -19,6 +19,8 @@ const (
 	StateDirName = "state"
 	// DownloadsFileName is the name of the downloads state file.
 	DownloadsFileName = "downloads.json"
+	// LogFileName is the name of the log file the TUI writes warnings to.
+	LogFileName = "ucli.log"
 )
 
 // GetConfigDir returns the config directory path (~/.config/ucli).
@@ -71,6 +73,15 @@ func GetDownloadsPath() (string, error) {
 	return filepath.Join(stateDir, DownloadsFileName), nil
 }
 
+// GetLogPath returns the path to the TUI's log file.
+func GetLogPath() (string, error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, LogFileName), nil
+}
+
 // EnsureConfigDir creates the config directory if it doesn't exist.
 func EnsureConfigDir() error {
 	configDir, err := GetConfigDir()
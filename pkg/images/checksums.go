@@ -0,0 +1,186 @@
+package images
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultKeyring is the Ubuntu cloud image signing keyring shipped by the
+// ubuntu-keyring package. It is used when no keyring is embedded.
+const DefaultKeyring = "/usr/share/keyrings/ubuntu-cloudimage-keyring.gpg"
+
+// embeddedKeyring is the path of the Ubuntu cloud image signing keyring in
+// keys. See keys/README.md for how it is exported.
+const embeddedKeyring = "keys/ubuntu-cloudimage-keyring.gpg"
+
+//go:embed keys
+var keys embed.FS
+
+// UbuntuKeyring returns the Ubuntu cloud image signing keyring built into
+// ucli, or DefaultKeyring if none was embedded. It returns nil if neither
+// is available.
+func UbuntuKeyring() []byte {
+	if data, err := keys.ReadFile(embeddedKeyring); err == nil && len(data) > 0 {
+		return data
+	}
+	if data, err := os.ReadFile(DefaultKeyring); err == nil && len(data) > 0 {
+		return data
+	}
+	return nil
+}
+
+// ChecksumSource looks up published image checksums from the SHA256SUMS file
+// next to each image, verifying SHA256SUMS.gpg with gpgv when it can.
+type ChecksumSource struct {
+	client  *http.Client
+	keyring []byte
+}
+
+// NewChecksumSource creates a checksum source. Signatures are verified
+// against keyring, a binary OpenPGP keyring, unless it is empty.
+func NewChecksumSource(keyring []byte) *ChecksumSource {
+	return &ChecksumSource{
+		client:  &http.Client{},
+		keyring: keyring,
+	}
+}
+
+// DefaultChecksumSource creates a checksum source that verifies signatures
+// against UbuntuKeyring.
+func DefaultChecksumSource() *ChecksumSource {
+	return NewChecksumSource(UbuntuKeyring())
+}
+
+// Unsigned returns why Lookup cannot verify the SHA256SUMS signature, or ""
+// if it can. Unsigned checksums only catch corrupt downloads, not a
+// tampered mirror.
+func (c *ChecksumSource) Unsigned() string {
+	if len(c.keyring) == 0 {
+		return "no Ubuntu signing key is available"
+	}
+	if _, err := exec.LookPath("gpgv"); err != nil {
+		return "gpgv is not installed"
+	}
+	return ""
+}
+
+// Signed reports whether Lookup verifies the SHA256SUMS signature.
+func (c *ChecksumSource) Signed() bool {
+	return c.Unsigned() == ""
+}
+
+// Lookup returns the published SHA256 checksum for the image at imageURL.
+func (c *ChecksumSource) Lookup(ctx context.Context, imageURL string) (string, error) {
+	dir, filename := path.Split(imageURL)
+	sumsURL := dir + "SHA256SUMS"
+
+	sums, err := c.fetch(ctx, sumsURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch SHA256SUMS: %w", err)
+	}
+
+	if c.Signed() {
+		sig, err := c.fetch(ctx, sumsURL+".gpg")
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch SHA256SUMS.gpg: %w", err)
+		}
+		if err := verifySignature(ctx, c.keyring, sums, sig); err != nil {
+			return "", err
+		}
+	}
+
+	checksums, err := ParseSHA256SUMS(bytes.NewReader(sums))
+	if err != nil {
+		return "", err
+	}
+	sum, ok := checksums[filename]
+	if !ok {
+		return "", fmt.Errorf("no checksum for %s in %s", filename, sumsURL)
+	}
+	return sum, nil
+}
+
+func (c *ChecksumSource) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ParseSHA256SUMS parses a sha256sum file into a map of filename to
+// lowercase hex checksum. Both text ("hash  name") and binary ("hash *name")
+// entries are accepted.
+func ParseSHA256SUMS(r io.Reader) (map[string]string, error) {
+	sums := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, name, ok := strings.Cut(line, " ")
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		if !ok || name == "" || len(hash) != 64 {
+			return nil, fmt.Errorf("SHA256SUMS line %d: expected \"<sha256> <filename>\"", lineNum)
+		}
+		sums[name] = strings.ToLower(hash)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read SHA256SUMS: %w", err)
+	}
+	return sums, nil
+}
+
+// verifySignature checks a detached signature of data with gpgv.
+func verifySignature(ctx context.Context, keyring, data, sig []byte) error {
+	dir, err := os.MkdirTemp("", "ucli-sha256sums-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dataPath := filepath.Join(dir, "SHA256SUMS")
+	sigPath := dataPath + ".gpg"
+	if err := os.WriteFile(dataPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write SHA256SUMS: %w", err)
+	}
+	if err := os.WriteFile(sigPath, sig, 0644); err != nil {
+		return fmt.Errorf("failed to write SHA256SUMS.gpg: %w", err)
+	}
+	keyringPath := filepath.Join(dir, "keyring.gpg")
+	if err := os.WriteFile(keyringPath, keyring, 0644); err != nil {
+		return fmt.Errorf("failed to write keyring: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "gpgv", "--keyring", keyringPath, sigPath, dataPath).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("SHA256SUMS signature verification failed: %w\n%s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package images
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCloudImagesServer serves files by path, standing in for cloud-images.ubuntu.com.
+func newCloudImagesServer(t *testing.T, files map[string][]byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func sha256Hex(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+func TestParseSHA256SUMS(t *testing.T) {
+	hashA := strings.Repeat("a", 64)
+	hashB := strings.Repeat("B", 64)
+	input := hashA + " *noble-server-cloudimg-amd64.img\n" +
+		"\n" +
+		hashB + "  noble-server-cloudimg-arm64.img\n"
+
+	sums, err := ParseSHA256SUMS(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"noble-server-cloudimg-amd64.img": hashA,
+		"noble-server-cloudimg-arm64.img": strings.ToLower(hashB),
+	}, sums)
+
+	_, err = ParseSHA256SUMS(strings.NewReader("abc file.img\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestChecksumSource_Lookup(t *testing.T) {
+	image := []byte("image content")
+	server := newCloudImagesServer(t, map[string][]byte{
+		"/noble/current/SHA256SUMS": []byte(sha256Hex(image) + " *noble-server-cloudimg-amd64.img\n"),
+	})
+	source := NewChecksumSource(nil)
+	assert.False(t, source.Signed())
+
+	sum, err := source.Lookup(context.Background(), server.URL+"/noble/current/noble-server-cloudimg-amd64.img")
+	require.NoError(t, err)
+	assert.Equal(t, sha256Hex(image), sum)
+
+	_, err = source.Lookup(context.Background(), server.URL+"/noble/current/noble-server-cloudimg-arm64.img")
+	assert.ErrorContains(t, err, "no checksum for noble-server-cloudimg-arm64.img")
+
+	_, err = source.Lookup(context.Background(), server.URL+"/jammy/current/jammy-server-cloudimg-amd64.img")
+	assert.ErrorContains(t, err, "HTTP 404")
+}
+
+// newTestSigner creates a throwaway signing key, returning its keyring and
+// a function that makes detached signatures with it. It skips the test
+// without gpg and gpgv.
+func newTestSigner(t *testing.T) ([]byte, func(data []byte) []byte) {
+	t.Helper()
+	gpg, err := exec.LookPath("gpg")
+	if err != nil {
+		t.Skip("gpg not available")
+	}
+	if _, err := exec.LookPath("gpgv"); err != nil {
+		t.Skip("gpgv not available")
+	}
+
+	home := t.TempDir()
+	t.Cleanup(func() { exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run() })
+	runGPG := func(args ...string) []byte {
+		cmd := exec.Command(gpg, append([]string{"--homedir", home, "--batch", "--yes"}, args...)...)
+		out, err := cmd.Output()
+		require.NoError(t, err, "gpg %v", args)
+		return out
+	}
+	runGPG("--passphrase", "", "--quick-gen-key", "Test Signer <test@example.com>", "ed25519", "sign", "never")
+
+	sign := func(data []byte) []byte {
+		path := filepath.Join(t.TempDir(), "data")
+		require.NoError(t, os.WriteFile(path, data, 0644))
+		runGPG("--detach-sign", "--output", path+".gpg", path)
+		sig, err := os.ReadFile(path + ".gpg")
+		require.NoError(t, err)
+		return sig
+	}
+	return runGPG("--export"), sign
+}
+
+func TestChecksumSource_Lookup_Signature(t *testing.T) {
+	keyring, sign := newTestSigner(t)
+
+	image := []byte("image content")
+	sums := []byte(sha256Hex(image) + " *noble-server-cloudimg-amd64.img\n")
+	sig := sign(sums)
+
+	server := newCloudImagesServer(t, map[string][]byte{
+		"/good/SHA256SUMS":     sums,
+		"/good/SHA256SUMS.gpg": sig,
+		"/bad/SHA256SUMS":      []byte(strings.Repeat("0", 64) + " *noble-server-cloudimg-amd64.img\n"),
+		"/bad/SHA256SUMS.gpg":  sig,
+		"/unsigned/SHA256SUMS": sums,
+	})
+	source := NewChecksumSource(keyring)
+	assert.True(t, source.Signed())
+
+	sum, err := source.Lookup(context.Background(), server.URL+"/good/noble-server-cloudimg-amd64.img")
+	require.NoError(t, err)
+	assert.Equal(t, sha256Hex(image), sum)
+
+	_, err = source.Lookup(context.Background(), server.URL+"/bad/noble-server-cloudimg-amd64.img")
+	assert.ErrorContains(t, err, "signature verification failed")
+
+	_, err = source.Lookup(context.Background(), server.URL+"/unsigned/noble-server-cloudimg-amd64.img")
+	assert.ErrorContains(t, err, "failed to fetch SHA256SUMS.gpg")
+}
+
+func TestChecksumSource_Unsigned(t *testing.T) {
+	assert.Equal(t, "no Ubuntu signing key is available", NewChecksumSource(nil).Unsigned())
+
+	// Without gpgv, checksums are still looked up, unsigned
+	t.Setenv("PATH", t.TempDir())
+	source := NewChecksumSource([]byte("keyring"))
+	assert.Equal(t, "gpgv is not installed", source.Unsigned())
+	assert.False(t, source.Signed())
+
+	image := []byte("image content")
+	server := newCloudImagesServer(t, map[string][]byte{
+		"/noble/current/SHA256SUMS": []byte(sha256Hex(image) + " *noble-server-cloudimg-amd64.img\n"),
+	})
+	sum, err := source.Lookup(context.Background(), server.URL+"/noble/current/noble-server-cloudimg-amd64.img")
+	require.NoError(t, err)
+	assert.Equal(t, sha256Hex(image), sum)
+}
@@ -2,11 +2,15 @@ package images
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,25 +23,46 @@ type ProgressCallback func(downloaded, total int64)
 
 // Downloader handles image downloads.
 type Downloader struct {
-	store    *settings.Store
-	client   *http.Client
-	mu       sync.Mutex
-	active   map[string]*downloadTask
+	store     *settings.Store
+	client    *http.Client
+	registry  *Registry
+	checksums *ChecksumSource
+	mu        sync.Mutex
+	active    map[string]*downloadTask
 }
 
 type downloadTask struct {
+	id     string
 	cancel context.CancelFunc
 	done   chan struct{}
+	stop   stopReason // Set under Downloader.mu before cancel
 }
 
+// stopReason records why a background download was stopped.
+type stopReason int
+
+const (
+	stopNone stopReason = iota
+	stopCancel
+	stopPause
+)
+
 // NewDownloader creates a new downloader.
 func NewDownloader(store *settings.Store) *Downloader {
+	return NewDownloaderWithRegistry(store, NewRegistry(), DefaultChecksumSource())
+}
+
+// NewDownloaderWithRegistry creates a downloader with a custom registry and
+// checksum source (for mirrors and testing).
+func NewDownloaderWithRegistry(store *settings.Store, registry *Registry, checksums *ChecksumSource) *Downloader {
 	return &Downloader{
 		store: store,
 		client: &http.Client{
 			Timeout: 0, // No timeout for large downloads
 		},
-		active: make(map[string]*downloadTask),
+		registry:  registry,
+		checksums: checksums,
+		active:    make(map[string]*downloadTask),
 	}
 }
 
@@ -47,6 +72,48 @@ type DownloadOptions struct {
 	DestPath   string
 	SHA256     string // Expected checksum (optional)
 	OnProgress ProgressCallback
+
+	// Resume continues an existing partial download with a Range request and
+	// keeps the partial file when the download is interrupted.
+	Resume bool
+
+	// Validators identify the remote file the partial download came from.
+	// A partial file is only continued if the server still reports them.
+	Validators Validators
+
+	// OnStart is called with the remote file's validators and total size
+	// once the response starts, so callers can persist them for resuming.
+	OnStart func(v Validators, total int64)
+}
+
+// Validators are the HTTP validators of a remote file.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// ifRange returns the If-Range header value, preferring a strong ETag.
+func (v Validators) ifRange() string {
+	if v.ETag != "" && !strings.HasPrefix(v.ETag, "W/") {
+		return v.ETag
+	}
+	return v.LastModified
+}
+
+// matches reports whether the response describes the same remote file.
+func (v Validators) matches(resp *http.Response) bool {
+	if v.ETag != "" {
+		return resp.Header.Get("ETag") == v.ETag
+	}
+	if v.LastModified != "" {
+		return resp.Header.Get("Last-Modified") == v.LastModified
+	}
+	return false
+}
+
+// tempPath returns the path of the partial file for a download.
+func tempPath(destPath string) string {
+	return destPath + ".downloading"
 }
 
 // Download downloads a file with progress tracking.
@@ -57,9 +124,48 @@ func (d *Downloader) Download(ctx context.Context, opts DownloadOptions) error {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
-	// Create temporary file
-	tmpPath := opts.DestPath + ".downloading"
-	out, err := os.Create(tmpPath)
+	tmpPath := tempPath(opts.DestPath)
+
+	// Find how much of a previous attempt can be reused
+	var offset int64
+	if opts.Resume && opts.Validators.ifRange() != "" {
+		if info, err := os.Stat(tmpPath); err == nil {
+			offset = info.Size()
+		}
+	}
+
+	resp, err := d.get(ctx, opts.URL, offset, opts.Validators)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Continue only if the server sent the rest of the same file
+	partial := resp.StatusCode == http.StatusPartialContent
+	if (partial && (!opts.Validators.matches(resp) || !rangeStartsAt(resp, offset))) ||
+		resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		resp.Body.Close()
+		offset = 0
+		if resp, err = d.get(ctx, opts.URL, 0, Validators{}); err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+	}
+
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+	default:
+		return fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	}
+
+	// Open the temporary file, appending when resuming
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	out, err := os.OpenFile(tmpPath, flags, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -68,41 +174,43 @@ func (d *Downloader) Download(ctx context.Context, opts DownloadOptions) error {
 	renamed := false
 	defer func() {
 		out.Close()
-		// Only remove temp file if we didn't successfully rename it
-		if !renamed {
+		// Keep the partial file for resuming, otherwise clean up
+		if !renamed && !opts.Resume {
 			os.Remove(tmpPath)
 		}
 	}()
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", opts.URL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	// Hash the part already on disk so the checksum covers the whole file
+	h := sha256.New()
+	if offset > 0 {
+		if err := hashFile(h, tmpPath); err != nil {
+			return fmt.Errorf("failed to read partial download: %w", err)
+		}
 	}
 
-	// Execute request
-	resp, err := d.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
+	// Get total size
+	total := resp.ContentLength
+	if total >= 0 {
+		total += offset
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	if opts.OnStart != nil {
+		opts.OnStart(Validators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}, total)
 	}
 
-	// Get total size
-	total := resp.ContentLength
-
 	// Create progress reader
 	reader := &progressReader{
 		reader:     resp.Body,
 		total:      total,
+		downloaded: offset,
 		onProgress: opts.OnProgress,
 	}
 
-	// Copy with progress
-	_, err = io.Copy(out, reader)
+	// Copy with progress, hashing as we go
+	_, err = io.Copy(io.MultiWriter(out, h), reader)
 	if err != nil {
 		return fmt.Errorf("download failed: %w", err)
 	}
@@ -112,11 +220,9 @@ func (d *Downloader) Download(ctx context.Context, opts DownloadOptions) error {
 
 	// Verify checksum if provided
 	if opts.SHA256 != "" {
-		hash, err := calculateSHA256(tmpPath)
-		if err != nil {
-			return fmt.Errorf("failed to calculate checksum: %w", err)
-		}
-		if hash != opts.SHA256 {
+		hash := fmt.Sprintf("%x", h.Sum(nil))
+		if hash != strings.ToLower(opts.SHA256) {
+			os.Remove(tmpPath) // A corrupt partial file must not be resumed
 			return fmt.Errorf("checksum mismatch: expected %s, got %s", opts.SHA256, hash)
 		}
 	}
@@ -130,13 +236,75 @@ func (d *Downloader) Download(ctx context.Context, opts DownloadOptions) error {
 	return nil
 }
 
-// DownloadCloudImage downloads a cloud image from the registry.
+// get requests url, asking for the bytes from offset onwards when it is set.
+// If-Range makes the server send the whole file if it no longer matches v.
+func (d *Downloader) get(ctx context.Context, url string, offset int64, v Validators) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", v.ifRange())
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	return resp, nil
+}
+
+// rangeStartsAt reports whether a 206 response's Content-Range starts at offset.
+func rangeStartsAt(resp *http.Response, offset int64) bool {
+	var start int64
+	_, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start)
+	return err == nil && start == offset
+}
+
+// hashFile writes the contents of path to h.
+func hashFile(h io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(h, f)
+	return err
+}
+
+// DownloadCloudImage downloads a cloud image from the registry and verifies
+// it against the release's SHA256SUMS.
 func (d *Downloader) DownloadCloudImage(ctx context.Context, version, arch string, onProgress ProgressCallback) (*settings.CloudImage, error) {
-	registry := NewRegistry()
-	info := registry.GetCloudImageInfo(version, arch)
+	info := d.registry.GetCloudImageInfo(version, arch)
 	if info == nil {
 		return nil, fmt.Errorf("unknown image: %s %s", version, arch)
 	}
+	return d.DownloadCloudImageInfo(ctx, *info, onProgress)
+}
+
+// DownloadCloudImageInfo downloads a specific cloud image build, such as one
+// returned by Registry.GetCloudImageBuild. The checksum is looked up in the
+// release's SHA256SUMS unless info already has one.
+//
+// The download is persisted like a background one: if it is interrupted, the
+// partial file is kept and continued by the next pull of the same image or by
+// ResumePersistedDownloads.
+func (d *Downloader) DownloadCloudImageInfo(ctx context.Context, info CloudImageInfo, onProgress ProgressCallback) (*settings.CloudImage, error) {
+	// Simplestreams checksums are not signed, so check them against the
+	// signed SHA256SUMS when possible
+	signed := d.checksums.Signed()
+	if info.SHA256 == "" || signed {
+		sum, err := d.checksums.Lookup(ctx, info.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up checksum: %w", err)
+		}
+		if info.SHA256 != "" && !strings.EqualFold(info.SHA256, sum) {
+			return nil, fmt.Errorf("checksum mismatch: simplestreams has %s, signed SHA256SUMS has %s", info.SHA256, sum)
+		}
+		info.SHA256 = sum
+	}
 
 	// Determine destination path from global config
 	cfg, err := globalconfig.LoadOrCreate()
@@ -149,26 +317,79 @@ func (d *Downloader) DownloadCloudImage(ctx context.Context, version, arch strin
 	}
 	destPath := filepath.Join(imagesDir, info.Filename)
 
-	// Download
-	err = d.Download(ctx, DownloadOptions{
-		URL:        info.URL,
-		DestPath:   destPath,
-		SHA256:     info.SHA256,
-		OnProgress: onProgress,
-	})
+	// Download, continuing the partial file of an interrupted pull
+	dl := d.cloudImageDownload(info, destPath)
+	dl.SHA256 = info.SHA256
+	dl.Signed = signed
+
+	ctx, task, err := d.startTask(ctx, &dl)
 	if err != nil {
 		return nil, err
 	}
+	err = d.run(ctx, &dl, onProgress)
+	return d.finishTask(task, dl, err)
+}
+
+// PartialDownload returns the interrupted download that pulling info would
+// continue, or nil if it would start from the beginning. Downloaded is the
+// size of the partial file.
+func (d *Downloader) PartialDownload(info CloudImageInfo) *settings.Download {
+	dl := d.savedDownload(GenerateImageID(info.Version, info.Arch), info.URL)
+	if dl == nil {
+		return nil
+	}
+	fi, err := os.Stat(tempPath(dl.DestPath))
+	if err != nil || fi.Size() == 0 {
+		return nil
+	}
+	dl.Downloaded = fi.Size()
+	return dl
+}
+
+// cloudImageDownload returns the download of info to destPath, reusing the
+// validators of a previous attempt at the same URL.
+func (d *Downloader) cloudImageDownload(info CloudImageInfo, destPath string) settings.Download {
+	id := GenerateImageID(info.Version, info.Arch)
+	if dl := d.savedDownload(id, info.URL); dl != nil && dl.DestPath == destPath {
+		return *dl
+	}
+	return settings.Download{
+		ID:        id,
+		URL:       info.URL,
+		DestPath:  destPath,
+		StartedAt: time.Now(),
+		Version:   info.Version,
+		Arch:      info.Arch,
+	}
+}
+
+// savedDownload returns the unfinished download of url persisted under id.
+func (d *Downloader) savedDownload(id, url string) *settings.Download {
+	state, err := d.store.LoadDownloadState()
+	if err != nil {
+		return nil
+	}
+	for _, dl := range state.ActiveDownloads {
+		if dl.ID == id && dl.URL == url && dl.Status != settings.StatusComplete {
+			return &dl
+		}
+	}
+	return nil
+}
 
-	// Add to registry
-	manager := NewManager(d.store)
-	img, err := manager.AddExistingImage(destPath, version, arch)
+// registerImage adds the cloud image a completed download fetched, with the
+// URL and the checksum verified during download.
+func (d *Downloader) registerImage(dl settings.Download) (*settings.CloudImage, error) {
+	manager := NewManagerWithRegistry(d.store, d.registry, d.checksums)
+	img, err := manager.AddExistingImage(dl.DestPath, dl.Version, dl.Arch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to register image: %w", err)
 	}
 
-	// Update with URL
-	img.URL = info.URL
+	img.URL = dl.URL
+	img.SHA256 = dl.SHA256
+	img.Verified = dl.SHA256 != ""
+	img.Signed = dl.Signed
 	s, err := d.store.Load()
 	if err != nil {
 		return img, fmt.Errorf("failed to reload settings: %w", err)
@@ -182,79 +403,153 @@ func (d *Downloader) DownloadCloudImage(ctx context.Context, version, arch strin
 }
 
 // StartBackgroundDownload starts a download in the background.
+// The partial file is kept if the download is paused or ucli exits, so it
+// can be continued with ResumeDownload or ResumePersistedDownloads.
 func (d *Downloader) StartBackgroundDownload(id, url, destPath string) error {
+	return d.startBackground(settings.Download{
+		ID:        id,
+		URL:       url,
+		DestPath:  destPath,
+		StartedAt: time.Now(),
+	})
+}
+
+// startBackground runs dl in a goroutine, continuing any partial file that
+// matches its recorded validators.
+func (d *Downloader) startBackground(dl settings.Download) error {
+	ctx, task, err := d.startTask(context.Background(), &dl)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := d.run(ctx, &dl, nil)
+		_, _ = d.finishTask(task, dl, err)
+	}()
+
+	return nil
+}
+
+// startTask marks dl as active and persists it as downloading. The returned
+// context is cancelled when the download is paused or cancelled.
+func (d *Downloader) startTask(parent context.Context, dl *settings.Download) (context.Context, *downloadTask, error) {
 	d.mu.Lock()
-	if _, exists := d.active[id]; exists {
+	if _, exists := d.active[dl.ID]; exists {
 		d.mu.Unlock()
-		return fmt.Errorf("download already in progress: %s", id)
+		return nil, nil, fmt.Errorf("download already in progress: %s", dl.ID)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(parent)
 	task := &downloadTask{
+		id:     dl.ID,
 		cancel: cancel,
 		done:   make(chan struct{}),
 	}
-	d.active[id] = task
+	d.active[dl.ID] = task
 	d.mu.Unlock()
 
-	// Update state
-	d.updateDownloadState(id, settings.Download{
-		ID:        id,
-		URL:       url,
-		DestPath:  destPath,
-		Status:    settings.StatusDownloading,
-		StartedAt: time.Now(),
+	dl.Status = settings.StatusDownloading
+	dl.Error = ""
+	d.updateDownloadState(dl.ID, *dl)
+
+	return ctx, task, nil
+}
+
+// run downloads dl, continuing any partial file that matches its recorded
+// validators, and persists its progress so it can be resumed.
+func (d *Downloader) run(ctx context.Context, dl *settings.Download, onProgress ProgressCallback) error {
+	var saved time.Time
+	return d.Download(ctx, DownloadOptions{
+		URL:      dl.URL,
+		DestPath: dl.DestPath,
+		SHA256:   dl.SHA256,
+		Resume:   true,
+		Validators: Validators{
+			ETag:         dl.ETag,
+			LastModified: dl.LastModified,
+		},
+		OnStart: func(v Validators, total int64) {
+			dl.ETag, dl.LastModified, dl.TotalBytes = v.ETag, v.LastModified, total
+			d.updateDownloadState(dl.ID, *dl)
+			saved = time.Now()
+		},
+		OnProgress: func(downloaded, total int64) {
+			dl.Downloaded, dl.TotalBytes = downloaded, total
+			// Progress arrives for every read, far too often to save each time
+			if time.Since(saved) >= progressSaveInterval {
+				d.updateDownloadState(dl.ID, *dl)
+				saved = time.Now()
+			}
+			if onProgress != nil {
+				onProgress(downloaded, total)
+			}
+		},
 	})
+}
 
-	// Start download goroutine
-	go func() {
-		defer close(task.done)
-		defer func() {
-			d.mu.Lock()
-			delete(d.active, id)
-			d.mu.Unlock()
-		}()
-
-		err := d.Download(ctx, DownloadOptions{
-			URL:      url,
-			DestPath: destPath,
-			OnProgress: func(downloaded, total int64) {
-				d.updateDownloadState(id, settings.Download{
-					ID:         id,
-					URL:        url,
-					DestPath:   destPath,
-					Downloaded: downloaded,
-					TotalBytes: total,
-					Status:     settings.StatusDownloading,
-				})
-			},
-		})
+// progressSaveInterval is how often download progress is persisted.
+const progressSaveInterval = time.Second
 
-		if err != nil {
-			d.updateDownloadState(id, settings.Download{
-				ID:       id,
-				URL:      url,
-				DestPath: destPath,
-				Status:   settings.StatusError,
-				Error:    err.Error(),
-			})
-		} else {
-			d.updateDownloadState(id, settings.Download{
-				ID:       id,
-				URL:      url,
-				DestPath: destPath,
-				Status:   settings.StatusComplete,
-			})
-		}
+// finishTask records how dl ended, registers its image if it fetched one,
+// and then marks the task as no longer active.
+func (d *Downloader) finishTask(task *downloadTask, dl settings.Download, err error) (*settings.CloudImage, error) {
+	defer close(task.done)
+	defer func() {
+		d.mu.Lock()
+		delete(d.active, task.id)
+		d.mu.Unlock()
+		task.cancel()
 	}()
 
-	return nil
+	d.mu.Lock()
+	stop := task.stop
+	d.mu.Unlock()
+
+	var img *settings.CloudImage
+	switch {
+	case stop == stopCancel:
+		os.Remove(tempPath(dl.DestPath))
+		d.removeDownloadState(dl.ID)
+		return nil, err
+	case stop == stopPause:
+		dl.Status = settings.StatusPaused
+	case errors.Is(err, context.Canceled):
+		// Interrupted: stay downloading so the next launch continues it
+	case err != nil:
+		dl.Status = settings.StatusError
+		dl.Error = err.Error()
+	case dl.Version != "":
+		if img, err = d.registerImage(dl); err != nil {
+			dl.Status = settings.StatusError
+			dl.Error = err.Error()
+			break
+		}
+		dl.Status = settings.StatusComplete
+	default:
+		dl.Status = settings.StatusComplete
+	}
+	d.updateDownloadState(dl.ID, dl)
+
+	return img, err
 }
 
-// CancelDownload cancels an active download.
+// CancelDownload cancels an active download and removes its partial file.
 func (d *Downloader) CancelDownload(id string) error {
+	return d.stopDownload(id, stopCancel)
+}
+
+// PauseDownload stops an active download, keeping the partial file so it
+// can be continued with ResumeDownload.
+func (d *Downloader) PauseDownload(id string) error {
+	return d.stopDownload(id, stopPause)
+}
+
+func (d *Downloader) stopDownload(id string, reason stopReason) error {
 	d.mu.Lock()
 	task, exists := d.active[id]
+	if exists {
+		task.stop = reason
+	}
 	d.mu.Unlock()
 
 	if !exists {
@@ -267,6 +562,47 @@ func (d *Downloader) CancelDownload(id string) error {
 	return nil
 }
 
+// ResumeDownload continues a paused, failed or interrupted download from
+// the persisted download state.
+func (d *Downloader) ResumeDownload(id string) error {
+	state, err := d.store.LoadDownloadState()
+	if err != nil {
+		return fmt.Errorf("failed to load download state: %w", err)
+	}
+
+	for _, dl := range state.ActiveDownloads {
+		if dl.ID != id {
+			continue
+		}
+		if dl.Status == settings.StatusComplete {
+			return fmt.Errorf("download already complete: %s", id)
+		}
+		return d.startBackground(dl)
+	}
+	return fmt.Errorf("download not found: %s", id)
+}
+
+// ResumePersistedDownloads continues downloads that were still running when
+// ucli last exited. Paused downloads stay paused. Returns the resumed IDs.
+func (d *Downloader) ResumePersistedDownloads() ([]string, error) {
+	state, err := d.store.LoadDownloadState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load download state: %w", err)
+	}
+
+	var resumed []string
+	for _, dl := range state.ActiveDownloads {
+		if dl.Status != settings.StatusDownloading || d.IsDownloadActive(dl.ID) {
+			continue
+		}
+		if err := d.startBackground(dl); err != nil {
+			return resumed, err
+		}
+		resumed = append(resumed, dl.ID)
+	}
+	return resumed, nil
+}
+
 // IsDownloadActive checks if a download with the given ID is currently active.
 func (d *Downloader) IsDownloadActive(id string) bool {
 	d.mu.Lock()
@@ -311,6 +647,16 @@ func (d *Downloader) GetActiveDownloads() ([]settings.Download, error) {
 	return active, nil
 }
 
+// Downloads returns all persisted downloads, including paused, failed and
+// recently completed ones.
+func (d *Downloader) Downloads() ([]settings.Download, error) {
+	state, err := d.store.LoadDownloadState()
+	if err != nil {
+		return nil, err
+	}
+	return state.ActiveDownloads, nil
+}
+
 // updateDownloadState updates the download state file.
 func (d *Downloader) updateDownloadState(id string, download settings.Download) {
 	state, err := d.store.LoadDownloadState()
@@ -344,7 +690,27 @@ func (d *Downloader) updateDownloadState(id string, download settings.Download)
 
 	if err := d.store.SaveDownloadState(state); err != nil {
 		// Log but don't fail - download state is not critical
-		fmt.Printf("warning: failed to save download state for %s: %v\n", id, err)
+		log.Printf("Warning: failed to save download state for %s: %v", id, err)
+	}
+}
+
+// removeDownloadState removes a download from the download state file.
+func (d *Downloader) removeDownloadState(id string) {
+	state, err := d.store.LoadDownloadState()
+	if err != nil {
+		return
+	}
+
+	var filtered []settings.Download
+	for _, dl := range state.ActiveDownloads {
+		if dl.ID != id {
+			filtered = append(filtered, dl)
+		}
+	}
+	state.ActiveDownloads = filtered
+
+	if err := d.store.SaveDownloadState(state); err != nil {
+		log.Printf("Warning: failed to save download state for %s: %v", id, err)
 	}
 }
 
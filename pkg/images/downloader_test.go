@@ -1,18 +1,22 @@
 package images
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -397,3 +401,456 @@ func (m *mockReader) Read(p []byte) (int, error) {
 	m.pos += n
 	return n, nil
 }
+
+func TestDownloader_DownloadCloudImage_Verified(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	imagesDir := t.TempDir()
+	cfg := globalconfig.NewConfig()
+	cfg.ProjectPath = t.TempDir()
+	cfg.ImagesDir = imagesDir
+	require.NoError(t, cfg.Save())
+
+	image := []byte("noble image content")
+	server := newCloudImagesServer(t, map[string][]byte{
+		"/noble/current/noble-server-cloudimg-amd64.img": image,
+		"/noble/current/SHA256SUMS":                      []byte(sha256Hex(image) + " *noble-server-cloudimg-amd64.img\n"),
+	})
+
+	store := settings.NewStoreWithDir(t.TempDir())
+	downloader := NewDownloaderWithRegistry(store, NewRegistryWithBaseURL(server.URL), NewChecksumSource(nil))
+
+	img, err := downloader.DownloadCloudImage(context.Background(), "24.04", "amd64", nil)
+	require.NoError(t, err)
+	assert.True(t, img.Verified)
+	assert.False(t, img.Signed, "no keyring, so the checksum is unsigned")
+	assert.Equal(t, sha256Hex(image), img.SHA256)
+	assert.Equal(t, server.URL+"/noble/current/noble-server-cloudimg-amd64.img", img.URL)
+
+	s, err := store.Load()
+	require.NoError(t, err)
+	stored := s.FindCloudImage(img.ID)
+	require.NotNil(t, stored)
+	assert.True(t, stored.Verified)
+	assert.False(t, stored.Signed)
+	assert.FileExists(t, filepath.Join(imagesDir, "noble-server-cloudimg-amd64.img"))
+}
+
+func TestDownloader_DownloadCloudImage_ChecksumMismatch(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	imagesDir := t.TempDir()
+	cfg := globalconfig.NewConfig()
+	cfg.ProjectPath = t.TempDir()
+	cfg.ImagesDir = imagesDir
+	require.NoError(t, cfg.Save())
+
+	server := newCloudImagesServer(t, map[string][]byte{
+		"/noble/current/noble-server-cloudimg-amd64.img": []byte("tampered"),
+		"/noble/current/SHA256SUMS":                      []byte(sha256Hex([]byte("original")) + " *noble-server-cloudimg-amd64.img\n"),
+	})
+
+	store := settings.NewStoreWithDir(t.TempDir())
+	downloader := NewDownloaderWithRegistry(store, NewRegistryWithBaseURL(server.URL), NewChecksumSource(nil))
+
+	_, err := downloader.DownloadCloudImage(context.Background(), "24.04", "amd64", nil)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.NoFileExists(t, filepath.Join(imagesDir, "noble-server-cloudimg-amd64.img"))
+
+	s, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, s.CloudImages)
+}
+
+func TestDownloader_DownloadCloudImageInfo_StreamChecksum(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	imagesDir := t.TempDir()
+	cfg := globalconfig.NewConfig()
+	cfg.ProjectPath = t.TempDir()
+	cfg.ImagesDir = imagesDir
+	require.NoError(t, cfg.Save())
+
+	// No SHA256SUMS is served: the checksum comes from simplestreams
+	image := []byte("pinned noble build")
+	server := newCloudImagesServer(t, map[string][]byte{
+		"/release-20240423/ubuntu-24.04-server-cloudimg-amd64.img": image,
+	})
+
+	store := settings.NewStoreWithDir(t.TempDir())
+	downloader := NewDownloaderWithRegistry(store, NewRegistry(), NewChecksumSource(nil))
+
+	img, err := downloader.DownloadCloudImageInfo(context.Background(), CloudImageInfo{
+		Version:  "24.04",
+		Arch:     "amd64",
+		Serial:   "20240423",
+		URL:      server.URL + "/release-20240423/ubuntu-24.04-server-cloudimg-amd64.img",
+		SHA256:   sha256Hex(image),
+		Filename: "ubuntu-24.04-server-cloudimg-amd64.img",
+	}, nil)
+	require.NoError(t, err)
+	assert.True(t, img.Verified)
+	assert.False(t, img.Signed)
+	assert.Equal(t, sha256Hex(image), img.SHA256)
+	assert.FileExists(t, filepath.Join(imagesDir, "ubuntu-24.04-server-cloudimg-amd64.img"))
+}
+
+func TestDownloader_DownloadCloudImageInfo_SignedStreamChecksum(t *testing.T) {
+	keyring, sign := newTestSigner(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := globalconfig.NewConfig()
+	cfg.ProjectPath = t.TempDir()
+	cfg.ImagesDir = t.TempDir()
+	require.NoError(t, cfg.Save())
+
+	// Simplestreams checksums are checked against the signed SHA256SUMS
+	image := []byte("pinned noble build")
+	sums := []byte(sha256Hex(image) + " *ubuntu-24.04-server-cloudimg-amd64.img\n")
+	server := newCloudImagesServer(t, map[string][]byte{
+		"/release-20240423/ubuntu-24.04-server-cloudimg-amd64.img": image,
+		"/release-20240423/SHA256SUMS":                             sums,
+		"/release-20240423/SHA256SUMS.gpg":                         sign(sums),
+	})
+	info := CloudImageInfo{
+		Version:  "24.04",
+		Arch:     "amd64",
+		URL:      server.URL + "/release-20240423/ubuntu-24.04-server-cloudimg-amd64.img",
+		SHA256:   sha256Hex(image),
+		Filename: "ubuntu-24.04-server-cloudimg-amd64.img",
+	}
+
+	store := settings.NewStoreWithDir(t.TempDir())
+	downloader := NewDownloaderWithRegistry(store, NewRegistry(), NewChecksumSource(keyring))
+
+	img, err := downloader.DownloadCloudImageInfo(context.Background(), info, nil)
+	require.NoError(t, err)
+	assert.True(t, img.Verified)
+	assert.True(t, img.Signed)
+
+	info.SHA256 = sha256Hex([]byte("tampered index"))
+	_, err = downloader.DownloadCloudImageInfo(context.Background(), info, nil)
+	assert.ErrorContains(t, err, "signed SHA256SUMS has")
+}
+
+// newRangeServer serves content with Range and If-Range support under etag.
+func newRangeServer(t *testing.T, content []byte, etag string, ranges *[]string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "image.img", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloader_Download_Resume(t *testing.T) {
+	tmpDir := t.TempDir()
+	downloader := NewDownloader(settings.NewStoreWithDir(tmpDir))
+
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	var ranges []string
+	server := newRangeServer(t, content, `"v1"`, &ranges)
+
+	destPath := filepath.Join(tmpDir, "test.img")
+	require.NoError(t, os.WriteFile(destPath+".downloading", content[:10], 0644))
+
+	var firstProgress int64 = -1
+	err := downloader.Download(context.Background(), DownloadOptions{
+		URL:        server.URL,
+		DestPath:   destPath,
+		SHA256:     sha256Hex(content),
+		Resume:     true,
+		Validators: Validators{ETag: `"v1"`},
+		OnProgress: func(downloaded, total int64) {
+			if firstProgress < 0 {
+				firstProgress = downloaded
+			}
+			assert.Equal(t, int64(len(content)), total)
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"bytes=10-"}, ranges)
+	assert.Greater(t, firstProgress, int64(10))
+	data, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.NoFileExists(t, destPath+".downloading")
+}
+
+func TestDownloader_Download_ResumeChangedFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	downloader := NewDownloader(settings.NewStoreWithDir(tmpDir))
+
+	content := []byte("new upstream image content")
+	var ranges []string
+	server := newRangeServer(t, content, `"v2"`, &ranges)
+
+	destPath := filepath.Join(tmpDir, "test.img")
+	require.NoError(t, os.WriteFile(destPath+".downloading", []byte("old upstream"), 0644))
+
+	var started Validators
+	err := downloader.Download(context.Background(), DownloadOptions{
+		URL:        server.URL,
+		DestPath:   destPath,
+		SHA256:     sha256Hex(content),
+		Resume:     true,
+		Validators: Validators{ETag: `"v1"`},
+		OnStart:    func(v Validators, _ int64) { started = v },
+	})
+	require.NoError(t, err)
+
+	// If-Range fails, so the server sends the whole new file
+	assert.Equal(t, []string{"bytes=12-"}, ranges)
+	assert.Equal(t, `"v2"`, started.ETag)
+	data, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestDownloader_PauseAndResume(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := settings.NewStoreWithDir(tmpDir)
+	downloader := NewDownloader(store)
+
+	content := bytes.Repeat([]byte("cloud-image-"), 100)
+	var requests atomic.Int32
+	var rangeHeader atomic.Value
+	firstChunkSent := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if requests.Add(1) == 1 {
+			// Send part of the file, then stall until the client goes away
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+			w.Write(content[:100])
+			w.(http.Flusher).Flush()
+			close(firstChunkSent)
+			<-r.Context().Done()
+			return
+		}
+		rangeHeader.Store(r.Header.Get("Range"))
+		http.ServeContent(w, r, "image.img", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	destPath := filepath.Join(tmpDir, "paused.img")
+	require.NoError(t, downloader.StartBackgroundDownload("pause-test", server.URL, destPath))
+
+	select {
+	case <-firstChunkSent:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for first chunk")
+	}
+	require.Eventually(t, func() bool {
+		info, err := os.Stat(destPath + ".downloading")
+		return err == nil && info.Size() == 100
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, downloader.PauseDownload("pause-test"))
+
+	state, err := store.LoadDownloadState()
+	require.NoError(t, err)
+	require.Len(t, state.ActiveDownloads, 1)
+	assert.Equal(t, settings.StatusPaused, state.ActiveDownloads[0].Status)
+	assert.Equal(t, `"v1"`, state.ActiveDownloads[0].ETag)
+	assert.FileExists(t, destPath+".downloading")
+
+	require.NoError(t, downloader.ResumeDownload("pause-test"))
+	require.True(t, downloader.WaitForDownload("pause-test", 5*time.Second))
+
+	assert.Equal(t, "bytes=100-", rangeHeader.Load())
+	data, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+
+	state, err = store.LoadDownloadState()
+	require.NoError(t, err)
+	assert.Equal(t, settings.StatusComplete, state.ActiveDownloads[0].Status)
+}
+
+func TestDownloader_CancelDownload_RemovesPartialFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := settings.NewStoreWithDir(tmpDir)
+	downloader := NewDownloader(store)
+
+	requestReceived := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		close(requestReceived)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	destPath := filepath.Join(tmpDir, "test.img")
+	require.NoError(t, downloader.StartBackgroundDownload("cancel-test", server.URL, destPath))
+	<-requestReceived
+
+	require.NoError(t, downloader.CancelDownload("cancel-test"))
+	assert.NoFileExists(t, destPath+".downloading")
+
+	state, err := store.LoadDownloadState()
+	require.NoError(t, err)
+	assert.Empty(t, state.ActiveDownloads)
+}
+
+func TestDownloader_ResumePersistedDownloads(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := settings.NewStoreWithDir(tmpDir)
+	downloader := NewDownloader(store)
+
+	content := []byte("persisted download content")
+	var ranges []string
+	server := newRangeServer(t, content, `"v1"`, &ranges)
+
+	runningPath := filepath.Join(tmpDir, "running.img")
+	pausedPath := filepath.Join(tmpDir, "paused.img")
+	require.NoError(t, os.WriteFile(runningPath+".downloading", content[:5], 0644))
+
+	// State left behind by a previous ucli process
+	state := settings.NewDownloadState()
+	state.ActiveDownloads = []settings.Download{
+		{ID: "running", URL: server.URL, DestPath: runningPath, Status: settings.StatusDownloading, ETag: `"v1"`, StartedAt: time.Now()},
+		{ID: "paused", URL: server.URL, DestPath: pausedPath, Status: settings.StatusPaused, StartedAt: time.Now()},
+	}
+	require.NoError(t, store.SaveDownloadState(state))
+
+	resumed, err := downloader.ResumePersistedDownloads()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"running"}, resumed)
+	require.True(t, downloader.WaitForDownload("running", 5*time.Second))
+
+	assert.Equal(t, []string{"bytes=5-"}, ranges)
+	data, err := os.ReadFile(runningPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.NoFileExists(t, pausedPath)
+}
+
+func TestDownloader_StateWarningsAreLogged(t *testing.T) {
+	// The store's directory is a file, so saving the state fails
+	notDir := filepath.Join(t.TempDir(), "settings")
+	require.NoError(t, os.WriteFile(notDir, nil, 0644))
+	downloader := NewDownloaderWithRegistry(settings.NewStoreWithDir(notDir), NewRegistry(), NewChecksumSource(nil))
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	downloader.updateDownloadState("dl-1", settings.Download{ID: "dl-1", Status: settings.StatusDownloading})
+	assert.Contains(t, buf.String(), "Warning: failed to save download state for dl-1")
+}
+
+func TestDownloader_DownloadCloudImageInfo_ResumesInterruptedPull(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	imagesDir := t.TempDir()
+	cfg := globalconfig.NewConfig()
+	cfg.ProjectPath = t.TempDir()
+	cfg.ImagesDir = imagesDir
+	require.NoError(t, cfg.Save())
+
+	content := bytes.Repeat([]byte("noble-image-"), 100)
+	var rangeHeader atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("Range") == "" {
+			// Send part of the file, then stall until the client goes away
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+			w.Write(content[:100])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		rangeHeader.Store(r.Header.Get("Range"))
+		http.ServeContent(w, r, "image.img", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	info := CloudImageInfo{
+		Version:  "24.04",
+		Arch:     "amd64",
+		URL:      server.URL + "/noble/current/noble-server-cloudimg-amd64.img",
+		SHA256:   sha256Hex(content),
+		Filename: "noble-server-cloudimg-amd64.img",
+	}
+	store := settings.NewStoreWithDir(t.TempDir())
+	downloader := NewDownloaderWithRegistry(store, NewRegistry(), NewChecksumSource(nil))
+
+	// Interrupt the first pull once part of the file has arrived
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := downloader.DownloadCloudImageInfo(ctx, info, func(downloaded, _ int64) {
+		if downloaded == 100 {
+			cancel()
+		}
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	state, err := store.LoadDownloadState()
+	require.NoError(t, err)
+	require.Len(t, state.ActiveDownloads, 1)
+	dl := state.ActiveDownloads[0]
+	assert.Equal(t, "ubuntu-24.04-amd64", dl.ID)
+	assert.Equal(t, settings.StatusDownloading, dl.Status, "left for the next launch to resume")
+	assert.Equal(t, `"v1"`, dl.ETag)
+
+	partial := downloader.PartialDownload(info)
+	require.NotNil(t, partial)
+	assert.Equal(t, int64(100), partial.Downloaded)
+
+	// Pulling again continues from the partial file
+	img, err := downloader.DownloadCloudImageInfo(context.Background(), info, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "bytes=100-", rangeHeader.Load())
+	assert.True(t, img.Verified)
+	data, err := os.ReadFile(filepath.Join(imagesDir, "noble-server-cloudimg-amd64.img"))
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Nil(t, downloader.PartialDownload(info))
+
+	state, err = store.LoadDownloadState()
+	require.NoError(t, err)
+	assert.Equal(t, settings.StatusComplete, state.ActiveDownloads[0].Status)
+}
+
+func TestDownloader_ResumePersistedDownloads_RegistersImage(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := settings.NewStoreWithDir(tmpDir)
+	downloader := NewDownloader(store)
+
+	content := []byte("interrupted pull content")
+	var ranges []string
+	server := newRangeServer(t, content, `"v1"`, &ranges)
+
+	destPath := filepath.Join(tmpDir, "noble-server-cloudimg-amd64.img")
+	require.NoError(t, os.WriteFile(destPath+".downloading", content[:5], 0644))
+
+	// State left behind by an interrupted pull
+	state := settings.NewDownloadState()
+	state.ActiveDownloads = []settings.Download{{
+		ID:        "ubuntu-24.04-amd64",
+		URL:       server.URL,
+		DestPath:  destPath,
+		Status:    settings.StatusDownloading,
+		SHA256:    sha256Hex(content),
+		ETag:      `"v1"`,
+		StartedAt: time.Now(),
+		Version:   "24.04",
+		Arch:      "amd64",
+		Signed:    true,
+	}}
+	require.NoError(t, store.SaveDownloadState(state))
+
+	_, err := downloader.ResumePersistedDownloads()
+	require.NoError(t, err)
+	require.True(t, downloader.WaitForDownload("ubuntu-24.04-amd64", 5*time.Second))
+
+	s, err := store.Load()
+	require.NoError(t, err)
+	img := s.FindCloudImage("ubuntu-24.04-amd64")
+	require.NotNil(t, img)
+	assert.Equal(t, destPath, img.Path)
+	assert.Equal(t, server.URL, img.URL)
+	assert.True(t, img.Verified)
+	assert.True(t, img.Signed)
+}
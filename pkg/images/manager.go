@@ -1,11 +1,13 @@
 package images
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
@@ -13,15 +15,23 @@ import (
 
 // Manager handles image operations.
 type Manager struct {
-	store    *settings.Store
-	registry *Registry
+	store     *settings.Store
+	registry  *Registry
+	checksums *ChecksumSource
 }
 
 // NewManager creates a new image manager.
 func NewManager(store *settings.Store) *Manager {
+	return NewManagerWithRegistry(store, NewRegistry(), DefaultChecksumSource())
+}
+
+// NewManagerWithRegistry creates an image manager with a custom registry and
+// checksum source (for mirrors and testing).
+func NewManagerWithRegistry(store *settings.Store, registry *Registry, checksums *ChecksumSource) *Manager {
 	return &Manager{
-		store:    store,
-		registry: NewRegistry(),
+		store:     store,
+		registry:  registry,
+		checksums: checksums,
 	}
 }
 
@@ -106,6 +116,9 @@ func (m *Manager) RemoveImage(id string, deleteFile bool) error {
 
 	// Remove from settings
 	s.RemoveCloudImage(id)
+	if s.AppSettings.DefaultCloudImage == id {
+		s.AppSettings.DefaultCloudImage = ""
+	}
 
 	// Save settings
 	if err := m.store.Save(s); err != nil {
@@ -115,7 +128,8 @@ func (m *Manager) RemoveImage(id string, deleteFile bool) error {
 	return nil
 }
 
-// VerifyImage verifies an image's checksum.
+// VerifyImage verifies an image's checksum. Images downloaded from a URL
+// without a recorded checksum are checked against the published SHA256SUMS.
 func (m *Manager) VerifyImage(id string) (bool, error) {
 	// Load current settings
 	s, err := m.store.Load()
@@ -129,7 +143,16 @@ func (m *Manager) VerifyImage(id string) (bool, error) {
 		return false, fmt.Errorf("image not found: %s", id)
 	}
 
-	// If no SHA256 is set, we can't verify
+	// Look up the published checksum, otherwise we can't verify
+	signed := img.Signed
+	if img.SHA256 == "" && img.URL != "" {
+		sum, err := m.checksums.Lookup(context.Background(), img.URL)
+		if err != nil {
+			return false, fmt.Errorf("failed to look up checksum: %w", err)
+		}
+		img.SHA256 = sum
+		signed = m.checksums.Signed()
+	}
 	if img.SHA256 == "" {
 		return false, fmt.Errorf("no checksum available for verification")
 	}
@@ -140,19 +163,36 @@ func (m *Manager) VerifyImage(id string) (bool, error) {
 		return false, fmt.Errorf("failed to calculate checksum: %w", err)
 	}
 
-	// Compare
-	verified := hash == img.SHA256
-	if verified != img.Verified {
-		img.Verified = verified
-		s.AddCloudImage(*img) // Update
-		if err := m.store.Save(s); err != nil {
-			return verified, fmt.Errorf("failed to save settings: %w", err)
-		}
+	// Compare and record the result
+	verified := hash == strings.ToLower(img.SHA256)
+	img.Verified = verified
+	img.Signed = verified && signed
+	s.AddCloudImage(*img) // Update
+	if err := m.store.Save(s); err != nil {
+		return verified, fmt.Errorf("failed to save settings: %w", err)
 	}
 
 	return verified, nil
 }
 
+// SetDefaultImage makes id the image preselected for new VMs.
+func (m *Manager) SetDefaultImage(id string) error {
+	s, err := m.store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	if s.FindCloudImage(id) == nil {
+		return fmt.Errorf("image not found: %s", id)
+	}
+	s.AppSettings.DefaultCloudImage = id
+
+	if err := m.store.Save(s); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+	return nil
+}
+
 // GetImages returns all registered images.
 func (m *Manager) GetImages() ([]settings.CloudImage, error) {
 	s, err := m.store.Load()
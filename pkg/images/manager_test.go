@@ -173,6 +173,40 @@ func TestManager_VerifyImage_NoChecksum(t *testing.T) {
 	assert.Contains(t, err.Error(), "no checksum available")
 }
 
+func TestManager_VerifyImage_PublishedChecksum(t *testing.T) {
+	content := []byte("downloaded image")
+	server := newCloudImagesServer(t, map[string][]byte{
+		"/noble/current/SHA256SUMS": []byte(sha256Hex(content) + " *noble-server-cloudimg-amd64.img\n"),
+	})
+
+	tmpDir := t.TempDir()
+	store := settings.NewStoreWithDir(tmpDir)
+	manager := NewManagerWithRegistry(store, NewRegistryWithBaseURL(server.URL), NewChecksumSource(nil))
+
+	imgPath := filepath.Join(tmpDir, "noble-server-cloudimg-amd64.img")
+	require.NoError(t, os.WriteFile(imgPath, content, 0644))
+	img, err := manager.AddExistingImage(imgPath, "24.04", "amd64")
+	require.NoError(t, err)
+
+	// Images with a source URL are checked against the published SHA256SUMS
+	s, err := store.Load()
+	require.NoError(t, err)
+	storedImg := s.FindCloudImage(img.ID)
+	storedImg.URL = server.URL + "/noble/current/noble-server-cloudimg-amd64.img"
+	s.AddCloudImage(*storedImg)
+	require.NoError(t, store.Save(s))
+
+	verified, err := manager.VerifyImage(img.ID)
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	s, err = store.Load()
+	require.NoError(t, err)
+	assert.True(t, s.FindCloudImage(img.ID).Verified)
+	assert.False(t, s.FindCloudImage(img.ID).Signed)
+	assert.Equal(t, sha256Hex(content), s.FindCloudImage(img.ID).SHA256)
+}
+
 func TestManager_VerifyImage_NotFound(t *testing.T) {
 	tmpDir := t.TempDir()
 	store := settings.NewStoreWithDir(tmpDir)
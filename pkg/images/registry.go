@@ -2,8 +2,10 @@
 package images
 
 import (
+	"context"
 	"fmt"
 	"runtime"
+	"strings"
 )
 
 // UbuntuRelease represents an Ubuntu release.
@@ -20,15 +22,18 @@ type CloudImageInfo struct {
 	Version  string // Ubuntu version
 	Codename string // Ubuntu codename
 	Arch     string // Architecture
+	Serial   string // Build serial, e.g. "20240423" (empty for the current build)
 	URL      string // Download URL
 	SHA256   string // SHA256 checksum (may be empty if not known)
+	Size     int64  // Size in bytes (0 if not known)
 	Filename string // Expected filename
 }
 
 // BaseURL is the base URL for Ubuntu cloud images.
 const BaseURL = "https://cloud-images.ubuntu.com"
 
-// KnownReleases contains known Ubuntu releases.
+// KnownReleases contains known Ubuntu releases. It is the offline fallback
+// when simplestreams data is unavailable.
 var KnownReleases = []UbuntuRelease{
 	{
 		Version:  "24.04",
@@ -56,13 +61,42 @@ var KnownReleases = []UbuntuRelease{
 // Registry provides access to known cloud images.
 type Registry struct {
 	releases []UbuntuRelease
+	builds   []CloudImageInfo // From simplestreams, newest serial first
+	baseURL  string
 }
 
 // NewRegistry creates a new image registry.
 func NewRegistry() *Registry {
+	return NewRegistryWithBaseURL(BaseURL)
+}
+
+// NewRegistryWithBaseURL creates an image registry that serves images from
+// baseURL instead of cloud-images.ubuntu.com (for mirrors and testing).
+func NewRegistryWithBaseURL(baseURL string) *Registry {
 	return &Registry{
 		releases: KnownReleases,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// NewRegistryFromCatalog creates an image registry from simplestreams data.
+func NewRegistryFromCatalog(cat *Catalog) *Registry {
+	return &Registry{
+		releases: cat.Releases,
+		builds:   cat.Builds,
+		baseURL:  BaseURL,
+	}
+}
+
+// LoadRegistry creates an image registry from simplestreams. If the stream
+// cannot be read and nothing is cached, it returns the built-in registry
+// along with the error so callers can report the fallback.
+func LoadRegistry(ctx context.Context, client *StreamsClient) (*Registry, error) {
+	cat, err := client.Fetch(ctx)
+	if err != nil {
+		return NewRegistry(), fmt.Errorf("using built-in release list: %w", err)
 	}
+	return NewRegistryFromCatalog(cat), nil
 }
 
 // GetReleases returns all known releases.
@@ -93,12 +127,17 @@ func (r *Registry) FindRelease(versionOrCodename string) *UbuntuRelease {
 }
 
 // GetCloudImageInfo returns information about a cloud image for a specific release and arch.
+// With simplestreams data this is the newest build, including its checksum.
 func (r *Registry) GetCloudImageInfo(version, arch string) *CloudImageInfo {
 	rel := r.FindRelease(version)
 	if rel == nil {
 		return nil
 	}
 
+	if builds := r.GetBuilds(rel.Version, arch); len(builds) > 0 {
+		return &builds[0]
+	}
+
 	// Check if arch is supported
 	archSupported := false
 	for _, a := range rel.Archs {
@@ -114,7 +153,7 @@ func (r *Registry) GetCloudImageInfo(version, arch string) *CloudImageInfo {
 	// Generate URL
 	// Format: https://cloud-images.ubuntu.com/noble/current/noble-server-cloudimg-amd64.img
 	filename := fmt.Sprintf("%s-server-cloudimg-%s.img", rel.Codename, arch)
-	url := fmt.Sprintf("%s/%s/current/%s", BaseURL, rel.Codename, filename)
+	url := fmt.Sprintf("%s/%s/current/%s", r.baseURL, rel.Codename, filename)
 
 	return &CloudImageInfo{
 		Version:  rel.Version,
@@ -125,6 +164,33 @@ func (r *Registry) GetCloudImageInfo(version, arch string) *CloudImageInfo {
 	}
 }
 
+// GetBuilds returns the published builds of a release for arch, newest
+// serial first. It is empty without simplestreams data.
+func (r *Registry) GetBuilds(version, arch string) []CloudImageInfo {
+	rel := r.FindRelease(version)
+	if rel == nil {
+		return nil
+	}
+
+	var builds []CloudImageInfo
+	for _, b := range r.builds {
+		if b.Version == rel.Version && b.Arch == arch {
+			builds = append(builds, b)
+		}
+	}
+	return builds
+}
+
+// GetCloudImageBuild returns a specific build of a release by serial.
+func (r *Registry) GetCloudImageBuild(version, arch, serial string) *CloudImageInfo {
+	for _, b := range r.GetBuilds(version, arch) {
+		if b.Serial == serial {
+			return &b
+		}
+	}
+	return nil
+}
+
 // GetDefaultArch returns the default architecture for the current system.
 func GetDefaultArch() string {
 	switch runtime.GOARCH {
@@ -151,6 +217,21 @@ func (r *Registry) GetAllCloudImages() []CloudImageInfo {
 	return images
 }
 
+// ParseImageFilename returns the release version and architecture of an
+// Ubuntu cloud image file name such as "noble-server-cloudimg-amd64.img" or
+// "ubuntu-24.04-server-cloudimg-amd64.img".
+func (r *Registry) ParseImageFilename(filename string) (version, arch string, ok bool) {
+	for _, rel := range r.releases {
+		for _, a := range rel.Archs {
+			if filename == fmt.Sprintf("%s-server-cloudimg-%s.img", rel.Codename, a) ||
+				filename == fmt.Sprintf("ubuntu-%s-server-cloudimg-%s.img", rel.Version, a) {
+				return rel.Version, a, true
+			}
+		}
+	}
+	return "", "", false
+}
+
 // GenerateImageID generates a unique ID for a cloud image.
 func GenerateImageID(version, arch string) string {
 	return fmt.Sprintf("ubuntu-%s-%s", version, arch)
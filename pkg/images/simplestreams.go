@@ -0,0 +1,250 @@
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+)
+
+// DefaultStreamsURL is the simplestreams mirror for released Ubuntu cloud images.
+const DefaultStreamsURL = BaseURL + "/releases"
+
+// streamsIndexPath is the simplestreams index relative to the mirror.
+const streamsIndexPath = "streams/v1/index.json"
+
+// streamsCacheMaxAge is how long cached stream data is used without refetching.
+const streamsCacheMaxAge = 24 * time.Hour
+
+// StreamsClient reads Ubuntu cloud image metadata from a simplestreams
+// mirror, caching the JSON on disk so it keeps working offline.
+type StreamsClient struct {
+	baseURL  string
+	cacheDir string
+	client   *http.Client
+	maxAge   time.Duration
+}
+
+// NewStreamsClient creates a simplestreams client for the mirror at baseURL.
+// Responses are cached in cacheDir; an empty cacheDir disables caching.
+func NewStreamsClient(baseURL, cacheDir string) *StreamsClient {
+	return &StreamsClient{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		cacheDir: cacheDir,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		maxAge:   streamsCacheMaxAge,
+	}
+}
+
+// DefaultStreamsClient creates a client for cloud-images.ubuntu.com that
+// caches under the ucli state directory.
+func DefaultStreamsClient() *StreamsClient {
+	cacheDir := ""
+	if stateDir, err := globalconfig.GetStateDir(); err == nil {
+		cacheDir = filepath.Join(stateDir, "simplestreams")
+	}
+	return NewStreamsClient(DefaultStreamsURL, cacheDir)
+}
+
+// streamsIndex is the streams/v1/index.json document.
+type streamsIndex struct {
+	Index map[string]struct {
+		DataType string `json:"datatype"`
+		Path     string `json:"path"`
+	} `json:"index"`
+}
+
+// streamsProducts is a products document such as
+// streams/v1/com.ubuntu.cloud:released:download.json.
+type streamsProducts struct {
+	Products map[string]struct {
+		Arch            string `json:"arch"`
+		Release         string `json:"release"`
+		ReleaseTitle    string `json:"release_title"`
+		ReleaseCodename string `json:"release_codename"`
+		Version         string `json:"version"`
+		Supported       bool   `json:"supported"`
+		Versions        map[string]struct {
+			Items map[string]struct {
+				FileType string `json:"ftype"`
+				Path     string `json:"path"`
+				SHA256   string `json:"sha256"`
+				Size     int64  `json:"size"`
+			} `json:"items"`
+		} `json:"versions"`
+	} `json:"products"`
+}
+
+// Catalog is the release and image data read from simplestreams.
+type Catalog struct {
+	Releases []UbuntuRelease
+
+	// Builds lists every published image build, newest serial first
+	// within each release and architecture
+	Builds []CloudImageInfo
+}
+
+// Fetch returns the catalog of supported releases and their disk images.
+// Cached data younger than a day is used as is; older cache is used only
+// when the mirror cannot be reached.
+func (c *StreamsClient) Fetch(ctx context.Context) (*Catalog, error) {
+	var index streamsIndex
+	if err := c.getJSON(ctx, streamsIndexPath, &index); err != nil {
+		return nil, err
+	}
+
+	productsPath := ""
+	for id, entry := range index.Index {
+		if entry.DataType == "image-downloads" && strings.HasSuffix(id, ":download") {
+			productsPath = entry.Path
+			break
+		}
+	}
+	if productsPath == "" {
+		return nil, fmt.Errorf("no image-downloads stream in %s", streamsIndexPath)
+	}
+
+	var products streamsProducts
+	if err := c.getJSON(ctx, productsPath, &products); err != nil {
+		return nil, err
+	}
+	return c.catalog(&products), nil
+}
+
+// catalog converts a products document into a Catalog.
+func (c *StreamsClient) catalog(products *streamsProducts) *Catalog {
+	cat := &Catalog{}
+	releases := make(map[string]*UbuntuRelease)
+
+	for _, p := range products.Products {
+		if !p.Supported {
+			continue
+		}
+
+		rel, ok := releases[p.Version]
+		if !ok {
+			rel = &UbuntuRelease{
+				Version:  p.Version,
+				Codename: p.Release,
+				Name:     fmt.Sprintf("Ubuntu %s (%s)", p.ReleaseTitle, p.ReleaseCodename),
+				LTS:      strings.Contains(p.ReleaseTitle, "LTS"),
+			}
+			releases[p.Version] = rel
+		}
+
+		hasImage := false
+		for serial, v := range p.Versions {
+			for _, item := range v.Items {
+				if item.FileType != "disk1.img" {
+					continue
+				}
+				hasImage = true
+				cat.Builds = append(cat.Builds, CloudImageInfo{
+					Version:  p.Version,
+					Codename: p.Release,
+					Arch:     p.Arch,
+					Serial:   serial,
+					URL:      c.baseURL + "/" + item.Path,
+					SHA256:   item.SHA256,
+					Size:     item.Size,
+					Filename: path.Base(item.Path),
+				})
+			}
+		}
+		if hasImage {
+			rel.Archs = append(rel.Archs, p.Arch)
+		}
+	}
+
+	for _, rel := range releases {
+		if len(rel.Archs) == 0 {
+			continue
+		}
+		sort.Strings(rel.Archs)
+		cat.Releases = append(cat.Releases, *rel)
+	}
+
+	// Newest release first, newest serial first
+	sort.Slice(cat.Releases, func(i, j int) bool { return cat.Releases[i].Version > cat.Releases[j].Version })
+	sort.Slice(cat.Builds, func(i, j int) bool {
+		a, b := cat.Builds[i], cat.Builds[j]
+		if a.Version != b.Version {
+			return a.Version > b.Version
+		}
+		if a.Arch != b.Arch {
+			return a.Arch < b.Arch
+		}
+		return a.Serial > b.Serial
+	})
+	return cat
+}
+
+// getJSON decodes the document at rel (relative to the mirror) into v,
+// going through the on-disk cache.
+func (c *StreamsClient) getJSON(ctx context.Context, rel string, v any) error {
+	data, err := c.get(ctx, rel)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", rel, err)
+	}
+	return nil
+}
+
+func (c *StreamsClient) get(ctx context.Context, rel string) ([]byte, error) {
+	cachePath := ""
+	if c.cacheDir != "" {
+		cachePath = filepath.Join(c.cacheDir, filepath.FromSlash(strings.ReplaceAll(rel, ":", "_")))
+		if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < c.maxAge {
+			if data, err := os.ReadFile(cachePath); err == nil {
+				return data, nil
+			}
+		}
+	}
+
+	data, fetchErr := c.fetch(ctx, c.baseURL+"/"+rel)
+	if fetchErr != nil {
+		// Fall back to stale cache when offline
+		if cachePath != "" {
+			if data, err := os.ReadFile(cachePath); err == nil {
+				return data, nil
+			}
+		}
+		return nil, fmt.Errorf("failed to fetch %s: %w", rel, fetchErr)
+	}
+
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+			_ = os.WriteFile(cachePath, data, 0644) // Caching is best effort
+		}
+	}
+	return data, nil
+}
+
+func (c *StreamsClient) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,252 @@
+package images
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const streamsIndexFixture = `{
+  "format": "index:1.0",
+  "index": {
+    "com.ubuntu.cloud:released:aws": {
+      "datatype": "image-ids",
+      "path": "streams/v1/com.ubuntu.cloud:released:aws.json"
+    },
+    "com.ubuntu.cloud:released:download": {
+      "datatype": "image-downloads",
+      "path": "streams/v1/com.ubuntu.cloud:released:download.json"
+    }
+  }
+}`
+
+const streamsProductsFixture = `{
+  "content_id": "com.ubuntu.cloud:released:download",
+  "format": "products:1.0",
+  "products": {
+    "com.ubuntu.cloud:server:24.04:amd64": {
+      "arch": "amd64",
+      "release": "noble",
+      "release_codename": "Noble Numbat",
+      "release_title": "24.04 LTS",
+      "supported": true,
+      "version": "24.04",
+      "versions": {
+        "20240423": {
+          "items": {
+            "disk1.img": {
+              "ftype": "disk1.img",
+              "path": "server/releases/noble/release-20240423/ubuntu-24.04-server-cloudimg-amd64.img",
+              "sha256": "1111111111111111111111111111111111111111111111111111111111111111",
+              "size": 1000
+            },
+            "manifest": {
+              "ftype": "manifest",
+              "path": "server/releases/noble/release-20240423/ubuntu-24.04-server-cloudimg-amd64.manifest"
+            }
+          }
+        },
+        "20240523": {
+          "items": {
+            "disk1.img": {
+              "ftype": "disk1.img",
+              "path": "server/releases/noble/release-20240523/ubuntu-24.04-server-cloudimg-amd64.img",
+              "sha256": "2222222222222222222222222222222222222222222222222222222222222222",
+              "size": 2000
+            }
+          }
+        }
+      }
+    },
+    "com.ubuntu.cloud:server:24.04:arm64": {
+      "arch": "arm64",
+      "release": "noble",
+      "release_codename": "Noble Numbat",
+      "release_title": "24.04 LTS",
+      "supported": true,
+      "version": "24.04",
+      "versions": {
+        "20240523": {
+          "items": {
+            "disk1.img": {
+              "ftype": "disk1.img",
+              "path": "server/releases/noble/release-20240523/ubuntu-24.04-server-cloudimg-arm64.img",
+              "sha256": "3333333333333333333333333333333333333333333333333333333333333333",
+              "size": 3000
+            }
+          }
+        }
+      }
+    },
+    "com.ubuntu.cloud:server:24.10:amd64": {
+      "arch": "amd64",
+      "release": "oracular",
+      "release_codename": "Oracular Oriole",
+      "release_title": "24.10",
+      "supported": true,
+      "version": "24.10",
+      "versions": {
+        "20241009": {
+          "items": {
+            "disk1.img": {
+              "ftype": "disk1.img",
+              "path": "server/releases/oracular/release-20241009/ubuntu-24.10-server-cloudimg-amd64.img",
+              "sha256": "4444444444444444444444444444444444444444444444444444444444444444",
+              "size": 4000
+            }
+          }
+        }
+      }
+    },
+    "com.ubuntu.cloud:server:18.04:amd64": {
+      "arch": "amd64",
+      "release": "bionic",
+      "release_codename": "Bionic Beaver",
+      "release_title": "18.04 LTS",
+      "supported": false,
+      "version": "18.04",
+      "versions": {}
+    }
+  }
+}`
+
+// newStreamsServer serves the simplestreams fixtures and counts requests.
+func newStreamsServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := newCloudImagesServer(t, map[string][]byte{
+		"/releases/streams/v1/index.json":                              []byte(streamsIndexFixture),
+		"/releases/streams/v1/com.ubuntu.cloud:released:download.json": []byte(streamsProductsFixture),
+	})
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler.ServeHTTP(w, r)
+	})
+	return server, &requests
+}
+
+func TestStreamsClient_Fetch(t *testing.T) {
+	server, _ := newStreamsServer(t)
+	client := NewStreamsClient(server.URL+"/releases", "")
+
+	cat, err := client.Fetch(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, cat.Releases, 2, "unsupported releases are skipped")
+	assert.Equal(t, UbuntuRelease{
+		Version:  "24.10",
+		Codename: "oracular",
+		Name:     "Ubuntu 24.10 (Oracular Oriole)",
+		Archs:    []string{"amd64"},
+	}, cat.Releases[0])
+	assert.Equal(t, UbuntuRelease{
+		Version:  "24.04",
+		Codename: "noble",
+		Name:     "Ubuntu 24.04 LTS (Noble Numbat)",
+		LTS:      true,
+		Archs:    []string{"amd64", "arm64"},
+	}, cat.Releases[1])
+
+	require.Len(t, cat.Builds, 4)
+	assert.Equal(t, CloudImageInfo{
+		Version:  "24.04",
+		Codename: "noble",
+		Arch:     "amd64",
+		Serial:   "20240523",
+		URL:      server.URL + "/releases/server/releases/noble/release-20240523/ubuntu-24.04-server-cloudimg-amd64.img",
+		SHA256:   "2222222222222222222222222222222222222222222222222222222222222222",
+		Size:     2000,
+		Filename: "ubuntu-24.04-server-cloudimg-amd64.img",
+	}, cat.Builds[1])
+}
+
+func TestStreamsClient_Cache(t *testing.T) {
+	server, requests := newStreamsServer(t)
+	cacheDir := t.TempDir()
+	client := NewStreamsClient(server.URL+"/releases", cacheDir)
+
+	_, err := client.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.FileExists(t, filepath.Join(cacheDir, "streams", "v1", "index.json"))
+
+	// A fresh cache is used without touching the network
+	_, err = client.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// A stale cache is refreshed
+	old := time.Now().Add(-2 * streamsCacheMaxAge)
+	require.NoError(t, os.Chtimes(filepath.Join(cacheDir, "streams", "v1", "index.json"), old, old))
+	_, err = client.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestStreamsClient_OfflineFallback(t *testing.T) {
+	server, _ := newStreamsServer(t)
+	cacheDir := t.TempDir()
+	client := NewStreamsClient(server.URL+"/releases", cacheDir)
+
+	_, err := client.Fetch(context.Background())
+	require.NoError(t, err)
+
+	// Stale cache is still used when the mirror is unreachable
+	server.Close()
+	client.maxAge = 0
+	cat, err := client.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, cat.Releases, 2)
+
+	// Without a cache there is nothing to fall back to
+	_, err = NewStreamsClient(server.URL+"/releases", t.TempDir()).Fetch(context.Background())
+	assert.ErrorContains(t, err, "failed to fetch streams/v1/index.json")
+}
+
+func TestLoadRegistry(t *testing.T) {
+	server, _ := newStreamsServer(t)
+
+	reg, err := LoadRegistry(context.Background(), NewStreamsClient(server.URL+"/releases", ""))
+	require.NoError(t, err)
+
+	require.NotNil(t, reg.FindRelease("oracular"))
+
+	info := reg.GetCloudImageInfo("noble", "amd64")
+	require.NotNil(t, info)
+	assert.Equal(t, "20240523", info.Serial)
+	assert.Equal(t, "2222222222222222222222222222222222222222222222222222222222222222", info.SHA256)
+
+	builds := reg.GetBuilds("24.04", "amd64")
+	require.Len(t, builds, 2)
+	assert.Equal(t, "20240423", builds[1].Serial)
+
+	pinned := reg.GetCloudImageBuild("24.04", "amd64", "20240423")
+	require.NotNil(t, pinned)
+	assert.Equal(t, int64(1000), pinned.Size)
+	assert.Nil(t, reg.GetCloudImageBuild("24.04", "amd64", "19990101"))
+
+	version, arch, ok := reg.ParseImageFilename("ubuntu-24.04-server-cloudimg-arm64.img")
+	assert.True(t, ok)
+	assert.Equal(t, "24.04", version)
+	assert.Equal(t, "arm64", arch)
+}
+
+func TestLoadRegistry_Fallback(t *testing.T) {
+	server, _ := newStreamsServer(t)
+	server.Close()
+
+	reg, err := LoadRegistry(context.Background(), NewStreamsClient(server.URL+"/releases", ""))
+	assert.ErrorContains(t, err, "using built-in release list")
+	require.NotNil(t, reg)
+	assert.Len(t, reg.GetReleases(), len(KnownReleases))
+	assert.Empty(t, reg.GetBuilds("24.04", "amd64"))
+}
@@ -0,0 +1,252 @@
+package images
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+)
+
+// imagePathRe matches the ubuntu_image_path input in terragrunt.hcl.
+var imagePathRe = regexp.MustCompile(`(?m)^\s*ubuntu_image_path\s*=\s*"([^"]+)"`)
+
+// ReferenceKind identifies what refers to an image.
+type ReferenceKind string
+
+const (
+	RefMachine  ReferenceKind = "machine"   // tf/<name>/terragrunt.hcl
+	RefVMConfig ReferenceKind = "vm-config" // Saved VM configuration
+	RefDefault  ReferenceKind = "default"   // Default image for new VMs
+)
+
+// Reference is a machine or config that uses an image.
+type Reference struct {
+	Kind ReferenceKind `json:"kind"`
+	Name string        `json:"name"`
+	Path string        `json:"path,omitempty"` // terragrunt.hcl for machines
+}
+
+// ImageUsage describes a registered image and what refers to it.
+type ImageUsage struct {
+	Image      settings.CloudImage `json:"image"`
+	Size       int64               `json:"size"`    // Size on disk, 0 if missing
+	Missing    bool                `json:"missing"` // File no longer exists
+	References []Reference         `json:"references"`
+}
+
+// InUse reports whether anything refers to the image.
+func (u ImageUsage) InUse() bool {
+	return len(u.References) > 0
+}
+
+// TempFile is a partial download left on disk.
+type TempFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// UsageReport maps registered images to their references.
+type UsageReport struct {
+	Images []ImageUsage `json:"images"`
+
+	// Orphans are .downloading files with no paused or active download
+	Orphans []TempFile `json:"orphans"`
+}
+
+// TotalSize returns the disk space used by images and orphaned temp files.
+func (r *UsageReport) TotalSize() int64 {
+	var total int64
+	for _, u := range r.Images {
+		total += u.Size
+	}
+	for _, t := range r.Orphans {
+		total += t.Size
+	}
+	return total
+}
+
+// Usage scans the machines under projectRoot/tf and the saved VM configs
+// for references to registered images. projectRoot may be empty.
+func (m *Manager) Usage(projectRoot string) (*UsageReport, error) {
+	s, err := m.store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	machines, err := scanMachineImages(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{}
+	for _, img := range s.CloudImages {
+		u := ImageUsage{Image: img, References: []Reference{}}
+		if info, err := os.Stat(img.Path); err == nil {
+			u.Size = info.Size()
+		} else {
+			u.Missing = true
+		}
+
+		if s.AppSettings.DefaultCloudImage == img.ID {
+			u.References = append(u.References, Reference{Kind: RefDefault, Name: img.ID})
+		}
+		for _, ref := range machines {
+			if samePath(ref.image, img.Path) {
+				u.References = append(u.References, Reference{Kind: RefMachine, Name: ref.name, Path: ref.hclPath})
+			}
+		}
+		for _, cfg := range s.VMConfigs {
+			opts := cfg.Data.TerragruntOpts
+			if opts != nil && (opts.UbuntuImage == img.ID || samePath(opts.UbuntuImage, img.Path)) {
+				u.References = append(u.References, Reference{Kind: RefVMConfig, Name: cfg.Name})
+			}
+		}
+		report.Images = append(report.Images, u)
+	}
+
+	report.Orphans, err = m.orphanedTempFiles(s)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// machineImage is the image path a machine's terragrunt.hcl refers to.
+type machineImage struct {
+	name    string
+	hclPath string
+	image   string
+}
+
+func scanMachineImages(projectRoot string) ([]machineImage, error) {
+	if projectRoot == "" {
+		return nil, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(projectRoot, "tf", "*", "terragrunt.hcl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan tf directory: %w", err)
+	}
+
+	var result []machineImage
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if m := imagePathRe.FindSubmatch(content); m != nil {
+			result = append(result, machineImage{
+				name:    filepath.Base(filepath.Dir(path)),
+				hclPath: path,
+				image:   string(m[1]),
+			})
+		}
+	}
+	return result, nil
+}
+
+// orphanedTempFiles finds .downloading files in the images directory and
+// next to registered images that no paused or active download will resume.
+func (m *Manager) orphanedTempFiles(s *settings.Settings) ([]TempFile, error) {
+	state, err := m.store.LoadDownloadState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load download state: %w", err)
+	}
+	resumable := make(map[string]bool)
+	for _, dl := range state.ActiveDownloads {
+		if dl.Status == settings.StatusDownloading || dl.Status == settings.StatusPaused {
+			resumable[filepath.Clean(tempPath(dl.DestPath))] = true
+		}
+	}
+
+	dirs := make(map[string]bool)
+	if cfg, err := globalconfig.Load(); err == nil && cfg.ImagesDir != "" {
+		dirs[cfg.ImagesDir] = true
+	}
+	for _, img := range s.CloudImages {
+		dirs[filepath.Dir(img.Path)] = true
+	}
+
+	var orphans []TempFile
+	for dir := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.downloading"))
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || resumable[filepath.Clean(path)] {
+				continue
+			}
+			orphans = append(orphans, TempFile{Path: path, Size: info.Size()})
+		}
+	}
+
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Path < orphans[j].Path })
+	return orphans, nil
+}
+
+// GCResult lists what GC removed, or would remove in a dry run.
+type GCResult struct {
+	Images  []settings.CloudImage `json:"images"`
+	Orphans []TempFile            `json:"orphans"`
+	Freed   int64                 `json:"freed"` // Bytes
+	DryRun  bool                  `json:"dry_run"`
+}
+
+// GC deletes registered images that nothing refers to, along with orphaned
+// .downloading files. With dryRun set it only reports what it would delete.
+func (m *Manager) GC(projectRoot string, dryRun bool) (*GCResult, error) {
+	report, err := m.Usage(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GCResult{
+		Images:  []settings.CloudImage{},
+		Orphans: []TempFile{},
+		DryRun:  dryRun,
+	}
+	for _, u := range report.Images {
+		if u.InUse() {
+			continue
+		}
+		if !dryRun {
+			if err := m.RemoveImage(u.Image.ID, true); err != nil {
+				return result, err
+			}
+		}
+		result.Images = append(result.Images, u.Image)
+		result.Freed += u.Size
+	}
+	for _, t := range report.Orphans {
+		if !dryRun {
+			if err := os.Remove(t.Path); err != nil && !os.IsNotExist(err) {
+				return result, fmt.Errorf("failed to delete %s: %w", t.Path, err)
+			}
+		}
+		result.Orphans = append(result.Orphans, t)
+		result.Freed += t.Size
+	}
+	return result, nil
+}
+
+// samePath reports whether two file paths refer to the same file.
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
@@ -0,0 +1,136 @@
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+)
+
+// setupUsage registers three images and a project with one machine using
+// the first and a saved config using the second.
+func setupUsage(t *testing.T) (*Manager, string, string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	imagesDir := t.TempDir()
+	projectDir := t.TempDir()
+	cfg := globalconfig.NewConfig()
+	cfg.ProjectPath = projectDir
+	cfg.ImagesDir = imagesDir
+	require.NoError(t, cfg.Save())
+
+	store := settings.NewStoreWithDir(t.TempDir())
+	manager := NewManagerWithRegistry(store, NewRegistry(), NewChecksumSource(nil))
+
+	for _, rel := range manager.Registry().GetReleases() {
+		path := filepath.Join(imagesDir, rel.Codename+"-server-cloudimg-amd64.img")
+		require.NoError(t, os.WriteFile(path, []byte(rel.Codename+" image"), 0644))
+		_, err := manager.AddExistingImage(path, rel.Version, "amd64")
+		require.NoError(t, err)
+	}
+
+	vmDir := filepath.Join(projectDir, "tf", "dev")
+	require.NoError(t, os.MkdirAll(vmDir, 0755))
+	hcl := "inputs = {\n  vm_name           = \"dev\"\n  ubuntu_image_path = \"" +
+		filepath.Join(imagesDir, "noble-server-cloudimg-amd64.img") + "\"\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(vmDir, "terragrunt.hcl"), []byte(hcl), 0644))
+
+	require.NoError(t, store.LoadAndSave(func(s *settings.Settings) error {
+		s.VMConfigs = append(s.VMConfigs, settings.VMConfig{
+			ID:   "cfg-1",
+			Name: "jammy box",
+			Data: settings.WizardDataSnapshot{
+				TerragruntOpts: &settings.TerragruntOptsSnapshot{
+					UbuntuImage: filepath.Join(imagesDir, "jammy-server-cloudimg-amd64.img"),
+				},
+			},
+		})
+		return nil
+	}))
+
+	return manager, projectDir, imagesDir
+}
+
+func TestManager_Usage(t *testing.T) {
+	manager, projectDir, imagesDir := setupUsage(t)
+
+	orphan := filepath.Join(imagesDir, "old.img.downloading")
+	require.NoError(t, os.WriteFile(orphan, []byte("partial"), 0644))
+
+	report, err := manager.Usage(projectDir)
+	require.NoError(t, err)
+	require.Len(t, report.Images, 3)
+
+	byID := make(map[string]ImageUsage)
+	for _, u := range report.Images {
+		byID[u.Image.ID] = u
+	}
+
+	noble := byID["ubuntu-24.04-amd64"]
+	require.Len(t, noble.References, 1)
+	assert.Equal(t, RefMachine, noble.References[0].Kind)
+	assert.Equal(t, "dev", noble.References[0].Name)
+	assert.Equal(t, int64(len("noble image")), noble.Size)
+
+	jammy := byID["ubuntu-22.04-amd64"]
+	require.Len(t, jammy.References, 1)
+	assert.Equal(t, Reference{Kind: RefVMConfig, Name: "jammy box"}, jammy.References[0])
+
+	assert.False(t, byID["ubuntu-20.04-amd64"].InUse())
+
+	assert.Equal(t, []TempFile{{Path: orphan, Size: int64(len("partial"))}}, report.Orphans)
+	assert.Equal(t, int64(len("noble image")+len("jammy image")+len("focal image")+len("partial")), report.TotalSize())
+}
+
+func TestManager_Usage_KeepsResumableDownloads(t *testing.T) {
+	manager, projectDir, imagesDir := setupUsage(t)
+
+	dest := filepath.Join(imagesDir, "plucky-server-cloudimg-amd64.img")
+	require.NoError(t, os.WriteFile(tempPath(dest), []byte("partial"), 0644))
+	require.NoError(t, manager.store.SaveDownloadState(&settings.DownloadState{
+		ActiveDownloads: []settings.Download{{ID: "dl-1", DestPath: dest, Status: settings.StatusPaused}},
+	}))
+
+	report, err := manager.Usage(projectDir)
+	require.NoError(t, err)
+	assert.Empty(t, report.Orphans)
+}
+
+func TestManager_GC(t *testing.T) {
+	manager, projectDir, imagesDir := setupUsage(t)
+	require.NoError(t, manager.SetDefaultImage("ubuntu-20.04-amd64"))
+	require.NoError(t, manager.store.LoadAndSave(func(s *settings.Settings) error {
+		s.VMConfigs = nil
+		return nil
+	}))
+
+	orphan := filepath.Join(imagesDir, "old.img.downloading")
+	require.NoError(t, os.WriteFile(orphan, []byte("partial"), 0644))
+	jammyPath := filepath.Join(imagesDir, "jammy-server-cloudimg-amd64.img")
+
+	// Dry run reports without deleting
+	result, err := manager.GC(projectDir, true)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	require.Len(t, result.Images, 1)
+	assert.Equal(t, "ubuntu-22.04-amd64", result.Images[0].ID)
+	assert.Len(t, result.Orphans, 1)
+	assert.Equal(t, int64(len("jammy image")+len("partial")), result.Freed)
+	assert.FileExists(t, jammyPath)
+	assert.FileExists(t, orphan)
+
+	result, err = manager.GC(projectDir, false)
+	require.NoError(t, err)
+	assert.Len(t, result.Images, 1)
+	assert.NoFileExists(t, jammyPath)
+	assert.NoFileExists(t, orphan)
+
+	images, err := manager.GetImages()
+	require.NoError(t, err)
+	assert.Len(t, images, 2, "images used by a machine or set as default are kept")
+}
@@ -0,0 +1,299 @@
+// Package iso builds Ubuntu autoinstall ISOs by remastering a live-server
+// ISO with xorriso.
+package iso
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/generator"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/seed"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/validation"
+)
+
+// NoCloudDir is where the autoinstall user-data and meta-data are placed
+// on the ISO. The installer sees the ISO mounted at /cdrom.
+const NoCloudDir = "/nocloud"
+
+// KernelArgs are added to the installer boot entries so Subiquity runs
+// unattended from the NoCloud seed on the ISO. GRUB needs the ; escaped.
+const KernelArgs = `autoinstall ds=nocloud\;s=/cdrom` + NoCloudDir + `/`
+
+// grubConfigs are the GRUB configs patched on the ISO. grub.cfg is used
+// for BIOS and UEFI boot, loopback.cfg when the ISO is booted from a file.
+var grubConfigs = []string{"/boot/grub/grub.cfg", "/boot/grub/loopback.cfg"}
+
+var (
+	// casperKernelRegex matches the installer kernel lines in grub.cfg.
+	casperKernelRegex = regexp.MustCompile(`^\s*linux\s+\S*/casper/\S*vmlinuz`)
+
+	// xorrisoProgressRegex matches xorriso's "UPDATE : 42.17% done" lines.
+	xorrisoProgressRegex = regexp.MustCompile(`UPDATE\s*:\s*([\d.]+)% done`)
+)
+
+// Options configures an ISO build.
+type Options struct {
+	SourceISO  string             // Ubuntu live-server ISO
+	OutputPath string             // Where to write the autoinstall ISO
+	Config     *config.FullConfig // Configuration for the installed system
+}
+
+// Result is the outcome of an ISO build.
+type Result struct {
+	OutputPath string
+	Size       int64
+	Duration   time.Duration
+	Logs       []string // Validation warnings
+}
+
+// Builder remasters Ubuntu live-server ISOs into autoinstall ISOs.
+type Builder struct {
+	exec deploy.CommandExecutor
+}
+
+// New creates a new ISO builder.
+func New() *Builder {
+	return NewWithExecutor(&deploy.RealExecutor{})
+}
+
+// NewWithExecutor creates an ISO builder with a custom executor.
+func NewWithExecutor(exec deploy.CommandExecutor) *Builder {
+	return &Builder{exec: exec}
+}
+
+// Validate checks if the build can proceed.
+func (b *Builder) Validate(opts *Options) error {
+	if opts.Config == nil {
+		return fmt.Errorf("configuration is required")
+	}
+	if err := opts.Config.Install.Storage.Validate(); err != nil {
+		return err
+	}
+	if opts.SourceISO == "" {
+		return fmt.Errorf("source ISO is required")
+	}
+	if _, err := os.Stat(opts.SourceISO); err != nil {
+		return fmt.Errorf("source ISO not found: %w", err)
+	}
+	if opts.OutputPath == "" {
+		return fmt.Errorf("output path is required")
+	}
+
+	src, _ := filepath.Abs(opts.SourceISO)
+	out, _ := filepath.Abs(opts.OutputPath)
+	if src == out {
+		return fmt.Errorf("output path must differ from the source ISO")
+	}
+
+	if _, err := b.exec.LookPath("xorriso"); err != nil {
+		return fmt.Errorf("xorriso not found: install it with 'sudo apt install xorriso' or 'brew install xorriso'")
+	}
+
+	return nil
+}
+
+// Build writes an autoinstall ISO for opts.Config to opts.OutputPath,
+// reporting progress through the callback.
+func (b *Builder) Build(ctx context.Context, opts *Options, progress deploy.ProgressCallback) (*Result, error) {
+	start := time.Now()
+	result := &Result{OutputPath: opts.OutputPath}
+
+	// Stage 1: Validate
+	progress(deploy.NewProgressEventWithCommand(deploy.StageValidating, "Checking xorriso and source ISO...", "xorriso -version", 5))
+	if err := b.Validate(opts); err != nil {
+		return b.fail(result, err, start, progress)
+	}
+
+	// Stage 2: Generate autoinstall user-data
+	progress(deploy.NewProgressEventWithDetail(
+		deploy.StageCloudInit,
+		"Generating autoinstall user-data...",
+		fmt.Sprintf("User: %s, host: %s", opts.Config.Username, opts.Config.Hostname),
+		10,
+	))
+	userData, warnings, err := generateUserData(opts.Config)
+	if err != nil {
+		return b.fail(result, err, start, progress)
+	}
+	result.Logs = append(result.Logs, warnings...)
+
+	workDir, err := os.MkdirTemp("", "ucli-iso-*")
+	if err != nil {
+		return b.fail(result, fmt.Errorf("failed to create work directory: %w", err), start, progress)
+	}
+	defer os.RemoveAll(workDir)
+
+	nocloud := filepath.Join(workDir, "nocloud")
+	if err := os.MkdirAll(nocloud, 0755); err != nil {
+		return b.fail(result, fmt.Errorf("failed to create work directory: %w", err), start, progress)
+	}
+	files := map[string][]byte{
+		"user-data": userData,
+		"meta-data": seed.MetaData("iid-ucli-"+start.Format("20060102150405"), opts.Config.Hostname),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(nocloud, name), data, 0644); err != nil {
+			return b.fail(result, fmt.Errorf("failed to write %s: %w", name, err), start, progress)
+		}
+	}
+
+	// Stage 3: Extract and patch the GRUB configs
+	maps := []string{"-map", nocloud, NoCloudDir}
+	for i, cfg := range grubConfigs {
+		local := filepath.Join(workDir, filepath.Base(cfg))
+		extract := deploy.Command{
+			Name: "xorriso",
+			Args: []string{"-osirrox", "on", "-indev", opts.SourceISO, "-extract", cfg, local},
+		}
+		progress(deploy.NewProgressEventWithCommand(deploy.StagePreparing, "Patching GRUB boot entries...", extract.String(), 15+i*5))
+
+		if _, stderr, err := b.exec.Run(ctx, extract); err != nil {
+			if i == 0 {
+				return b.fail(result, fmt.Errorf("failed to extract %s: %w: %s", cfg, err, strings.TrimSpace(string(stderr))), start, progress)
+			}
+			continue // loopback.cfg is optional
+		}
+
+		data, err := os.ReadFile(local)
+		if err != nil {
+			return b.fail(result, fmt.Errorf("failed to read %s: %w", cfg, err), start, progress)
+		}
+		patched, n := PatchGrubConfig(data)
+		if n == 0 {
+			if i == 0 {
+				return b.fail(result, fmt.Errorf("no installer boot entries found in %s: is this an Ubuntu live-server ISO?", cfg), start, progress)
+			}
+			continue
+		}
+		// Extracted files are read-only copies of the ISO's
+		if err := os.Chmod(local, 0644); err != nil {
+			return b.fail(result, fmt.Errorf("failed to write %s: %w", cfg, err), start, progress)
+		}
+		if err := os.WriteFile(local, patched, 0644); err != nil {
+			return b.fail(result, fmt.Errorf("failed to write %s: %w", cfg, err), start, progress)
+		}
+		maps = append(maps, "-map", local, cfg)
+	}
+
+	// Stage 4: Write the ISO, replaying the source boot setup so the
+	// result stays a hybrid BIOS/UEFI image that can be dd'ed to USB.
+	if err := os.MkdirAll(filepath.Dir(opts.OutputPath), 0755); err != nil {
+		return b.fail(result, fmt.Errorf("failed to create output directory: %w", err), start, progress)
+	}
+	if err := os.Remove(opts.OutputPath); err != nil && !os.IsNotExist(err) {
+		return b.fail(result, fmt.Errorf("failed to replace %s: %w", opts.OutputPath, err), start, progress)
+	}
+
+	args := []string{"-indev", opts.SourceISO, "-outdev", opts.OutputPath}
+	args = append(args, maps...)
+	args = append(args, "-boot_image", "any", "replay")
+	build := deploy.Command{
+		Name: "xorriso",
+		Args: args,
+		Stream: func(line string) {
+			if pct, ok := parseProgress(line); ok {
+				progress(deploy.NewProgressEventWithDetail(deploy.StageBuilding, "Writing ISO...", strings.TrimSpace(line), 30+pct*65/100))
+			}
+		},
+	}
+	progress(deploy.NewProgressEventWithCommand(deploy.StageBuilding, "Writing ISO...", build.String(), 30))
+
+	if _, stderr, err := b.exec.Run(ctx, build); err != nil {
+		os.Remove(opts.OutputPath)
+		return b.fail(result, fmt.Errorf("xorriso failed: %w: %s", err, lastLine(stderr)), start, progress)
+	}
+
+	info, err := os.Stat(opts.OutputPath)
+	if err != nil {
+		return b.fail(result, fmt.Errorf("xorriso did not write %s: %w", opts.OutputPath, err), start, progress)
+	}
+	result.Size = info.Size()
+	result.Duration = time.Since(start)
+
+	progress(deploy.NewProgressEventWithDetail(deploy.StageComplete, "ISO ready", opts.OutputPath, 100))
+	return result, nil
+}
+
+// fail reports err as an error event and returns it.
+func (b *Builder) fail(result *Result, err error, start time.Time, progress deploy.ProgressCallback) (*Result, error) {
+	result.Duration = time.Since(start)
+	progress(deploy.NewErrorEvent(err.Error()))
+	return result, err
+}
+
+// generateUserData renders the autoinstall document and validates the
+// cloud-config nested in it. It returns validation warnings as strings.
+func generateUserData(cfg *config.FullConfig) ([]byte, []string, error) {
+	ai := generator.NewAutoinstall(cfg)
+
+	cloudConfig, err := ai.UserData.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	var warnings, errs []string
+	for _, issue := range validation.ValidateCloudInitData("user-data", cloudConfig) {
+		if issue.Severity == validation.SeverityError {
+			errs = append(errs, issue.String())
+		} else {
+			warnings = append(warnings, "Warning: "+issue.String())
+		}
+	}
+	if len(errs) > 0 {
+		return nil, warnings, fmt.Errorf("invalid cloud-init user-data:\n  %s", strings.Join(errs, "\n  "))
+	}
+
+	data, err := ai.Marshal()
+	if err != nil {
+		return nil, warnings, err
+	}
+	return data, warnings, nil
+}
+
+// PatchGrubConfig adds KernelArgs to every installer kernel line in a
+// grub.cfg, before the "---" separator when there is one. It returns the
+// patched config and the number of lines changed. Lines that already ask
+// for autoinstall are left alone.
+func PatchGrubConfig(data []byte) ([]byte, int) {
+	lines := strings.Split(string(data), "\n")
+	n := 0
+	for i, line := range lines {
+		if !casperKernelRegex.MatchString(line) || strings.Contains(line, "autoinstall") {
+			continue
+		}
+		if idx := strings.Index(line, " ---"); idx != -1 {
+			lines[i] = line[:idx] + " " + KernelArgs + line[idx:]
+		} else {
+			lines[i] = strings.TrimRight(line, " ") + " " + KernelArgs
+		}
+		n++
+	}
+	return []byte(strings.Join(lines, "\n")), n
+}
+
+// parseProgress extracts the percentage from an xorriso progress line.
+func parseProgress(line string) (int, bool) {
+	m := xorrisoProgressRegex.FindStringSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+	pct, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return min(int(pct), 100), true
+}
+
+// lastLine returns the last non-empty line of command output, which for
+// xorriso holds the reason for a failure.
+func lastLine(out []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,229 @@
+package iso
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy/deploytest"
+)
+
+const sampleGrub = `set timeout=30
+
+loadfont unicode
+
+menuentry "Try or Install Ubuntu Server" {
+	set gfxpayload=keep
+	linux	/casper/vmlinuz  ---
+	initrd	/casper/initrd
+}
+menuentry "Ubuntu Server with the HWE kernel" {
+	set gfxpayload=keep
+	linux	/casper/hwe-vmlinuz  ---
+	initrd	/casper/hwe-initrd
+}
+menuentry 'Boot from next volume' {
+	exit 1
+}
+`
+
+func testConfig() *config.FullConfig {
+	return &config.FullConfig{
+		Username:      "tester",
+		Hostname:      "metal",
+		SSHPublicKeys: []string{"ssh-ed25519 AAAAC3 test@example"},
+	}
+}
+
+// fakeXorriso answers extract commands with grub and writes the output ISO
+// for build commands, recording the mapped files before they are removed.
+func fakeXorriso(t *testing.T, grub string, mapped map[string]string) *deploytest.Executor {
+	return &deploytest.Executor{
+		RunFunc: func(cmd deploy.Command) ([]byte, []byte, error) {
+			args := cmd.Args
+			if slices.Contains(args, "-extract") {
+				if args[len(args)-2] != "/boot/grub/grub.cfg" {
+					return nil, []byte("xorriso : FAILURE : Cannot determine attributes of source file"), errors.New("exit status 5")
+				}
+				require.NoError(t, os.WriteFile(args[len(args)-1], []byte(grub), 0444))
+				return nil, nil, nil
+			}
+
+			for i, arg := range args {
+				if arg != "-map" {
+					continue
+				}
+				local, target := args[i+1], args[i+2]
+				if target == NoCloudDir {
+					for _, name := range []string{"user-data", "meta-data"} {
+						data, err := os.ReadFile(filepath.Join(local, name))
+						require.NoError(t, err)
+						mapped[target+"/"+name] = string(data)
+					}
+					continue
+				}
+				data, err := os.ReadFile(local)
+				require.NoError(t, err)
+				mapped[target] = string(data)
+			}
+
+			cmd.Stream("xorriso : UPDATE :  50.00% done")
+			cmd.Stream("xorriso : UPDATE : 100.00% done")
+			out := args[slices.Index(args, "-outdev")+1]
+			require.NoError(t, os.WriteFile(out, []byte("iso"), 0644))
+			return nil, nil, nil
+		},
+	}
+}
+
+func TestPatchGrubConfig(t *testing.T) {
+	patched, n := PatchGrubConfig([]byte(sampleGrub))
+	assert.Equal(t, 2, n)
+	assert.Contains(t, string(patched), "linux\t/casper/vmlinuz  autoinstall ds=nocloud\\;s=/cdrom/nocloud/ ---\n")
+	assert.Contains(t, string(patched), "linux\t/casper/hwe-vmlinuz  autoinstall ds=nocloud\\;s=/cdrom/nocloud/ ---\n")
+	assert.Contains(t, string(patched), "exit 1")
+
+	// Patching twice is a no-op
+	again, n := PatchGrubConfig(patched)
+	assert.Zero(t, n)
+	assert.Equal(t, string(patched), string(again))
+
+	// Kernel lines without a separator get the arguments appended
+	patched, n = PatchGrubConfig([]byte("  linux /casper/vmlinuz quiet \n"))
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "  linux /casper/vmlinuz quiet "+KernelArgs+"\n", string(patched))
+}
+
+func TestParseProgress(t *testing.T) {
+	pct, ok := parseProgress("xorriso : UPDATE :  42.17% done, estimate finish Thu Oct 16 10:00:00 2026")
+	assert.True(t, ok)
+	assert.Equal(t, 42, pct)
+
+	_, ok = parseProgress("xorriso : NOTE : Copying to System Area")
+	assert.False(t, ok)
+}
+
+func TestBuilder_Build(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "ubuntu-24.04-live-server-amd64.iso")
+	require.NoError(t, os.WriteFile(src, []byte("source"), 0644))
+	out := filepath.Join(dir, "out", "autoinstall.iso")
+
+	mapped := make(map[string]string)
+	exec := fakeXorriso(t, sampleGrub, mapped)
+
+	var events []deploy.ProgressEvent
+	result, err := NewWithExecutor(exec).Build(context.Background(), &Options{
+		SourceISO:  src,
+		OutputPath: out,
+		Config:     testConfig(),
+	}, func(e deploy.ProgressEvent) { events = append(events, e) })
+	require.NoError(t, err)
+
+	assert.Equal(t, out, result.OutputPath)
+	assert.Equal(t, int64(3), result.Size)
+	assert.FileExists(t, out)
+
+	// grub.cfg, then the missing loopback.cfg, then the build
+	require.Len(t, exec.Calls, 3)
+	build := exec.Calls[2]
+	assert.Equal(t, []string{"-indev", src, "-outdev", out}, build.Args[:4])
+	assert.Equal(t, []string{"-boot_image", "any", "replay"}, build.Args[len(build.Args)-3:])
+
+	assert.Contains(t, mapped["/nocloud/user-data"], "#cloud-config\nautoinstall:\n  version: 1\n")
+	assert.Contains(t, mapped["/nocloud/user-data"], "  user-data:\n    users:\n      - name: tester\n")
+	assert.Contains(t, mapped["/nocloud/meta-data"], "local-hostname: metal\n")
+	assert.Contains(t, mapped["/boot/grub/grub.cfg"], KernelArgs+" ---")
+	assert.NotContains(t, mapped, "/boot/grub/loopback.cfg")
+
+	var percents []int
+	for _, e := range events {
+		assert.False(t, e.IsError, e.Message)
+		if e.Stage == deploy.StageBuilding {
+			percents = append(percents, e.Percent)
+		}
+	}
+	assert.Equal(t, []int{30, 62, 95}, percents)
+	assert.Equal(t, deploy.StageComplete, events[len(events)-1].Stage)
+}
+
+func TestBuilder_Build_Errors(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source.iso")
+	require.NoError(t, os.WriteFile(src, []byte("source"), 0644))
+	out := filepath.Join(dir, "out.iso")
+
+	tests := []struct {
+		name    string
+		opts    *Options
+		exec    *deploytest.Executor
+		wantErr string
+	}{
+		{
+			name: "no xorriso",
+			opts: &Options{SourceISO: src, OutputPath: out, Config: testConfig()},
+			exec: &deploytest.Executor{LookPathFunc: func(string) (string, error) {
+				return "", errors.New("not found")
+			}},
+			wantErr: "xorriso not found",
+		},
+		{
+			name: "encrypted direct layout",
+			opts: &Options{SourceISO: src, OutputPath: out, Config: &config.FullConfig{
+				Username: "tester",
+				Hostname: "metal",
+				Install: config.InstallConfig{Storage: config.StorageConfig{
+					Layout:     config.StorageDirect,
+					Passphrase: "secret",
+				}},
+			}},
+			exec:    &deploytest.Executor{},
+			wantErr: "storage encryption requires the lvm layout",
+		},
+		{
+			name:    "missing source",
+			opts:    &Options{SourceISO: filepath.Join(dir, "missing.iso"), OutputPath: out, Config: testConfig()},
+			exec:    &deploytest.Executor{},
+			wantErr: "source ISO not found",
+		},
+		{
+			name:    "overwrite source",
+			opts:    &Options{SourceISO: src, OutputPath: src, Config: testConfig()},
+			exec:    &deploytest.Executor{},
+			wantErr: "output path must differ from the source ISO",
+		},
+		{
+			name:    "not a live-server ISO",
+			opts:    &Options{SourceISO: src, OutputPath: out, Config: testConfig()},
+			exec:    fakeXorriso(t, "menuentry 'Windows' {\n}\n", map[string]string{}),
+			wantErr: "no installer boot entries found in /boot/grub/grub.cfg",
+		},
+		{
+			name: "xorriso fails",
+			opts: &Options{SourceISO: src, OutputPath: out, Config: testConfig()},
+			exec: &deploytest.Executor{RunFunc: func(deploy.Command) ([]byte, []byte, error) {
+				return nil, []byte("xorriso : FAILURE : Not a known drive\n"), errors.New("exit status 5")
+			}},
+			wantErr: "failed to extract /boot/grub/grub.cfg: exit status 5: xorriso : FAILURE : Not a known drive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var last deploy.ProgressEvent
+			_, err := NewWithExecutor(tt.exec).Build(context.Background(), tt.opts, func(e deploy.ProgressEvent) { last = e })
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.True(t, last.IsError)
+			assert.Equal(t, deploy.StageError, last.Stage)
+			assert.NoFileExists(t, out)
+		})
+	}
+}
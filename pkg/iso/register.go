@@ -0,0 +1,55 @@
+package iso
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+)
+
+// liveServerRegex matches Ubuntu live-server ISO names such as
+// ubuntu-24.04.1-live-server-amd64.iso.
+var liveServerRegex = regexp.MustCompile(`^ubuntu-(\d+\.\d+(?:\.\d+)?)-live-server-(\w+)\.iso$`)
+
+// ParseISOFilename extracts the Ubuntu version and architecture from a
+// live-server ISO file name.
+func ParseISOFilename(name string) (version, arch string, ok bool) {
+	m := liveServerRegex.FindStringSubmatch(name)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// NewISO describes the ISO file at path for registration in the global
+// config. The ID is the file name without its extension.
+func NewISO(path string) (globalconfig.ISO, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return globalconfig.ISO{}, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return globalconfig.ISO{}, fmt.Errorf("ISO not found: %w", err)
+	}
+	if info.IsDir() {
+		return globalconfig.ISO{}, fmt.Errorf("%s is a directory", absPath)
+	}
+
+	base := filepath.Base(absPath)
+	iso := globalconfig.ISO{
+		ID:      strings.TrimSuffix(base, filepath.Ext(base)),
+		Name:    base,
+		Path:    absPath,
+		AddedAt: time.Now(),
+	}
+	if version, arch, ok := ParseISOFilename(base); ok {
+		iso.Version = version
+		iso.Name = fmt.Sprintf("Ubuntu %s Live Server (%s)", version, arch)
+	}
+	return iso, nil
+}
@@ -0,0 +1,486 @@
+// Package journal records deployments as JSONL files under the ucli state
+// directory, so their progress and outcome can be read after ucli exits.
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+)
+
+// DirName is the journal directory under the state directory.
+const DirName = "deployments"
+
+// fileExt is the extension of journal files.
+const fileExt = ".jsonl"
+
+// pollInterval is how often Follow checks a journal for new records.
+var pollInterval = 500 * time.Millisecond
+
+// ErrInterrupted is returned by Follow when the process writing a journal
+// exits without recording a result.
+var ErrInterrupted = errors.New("deployment was interrupted")
+
+// RecordKind identifies what a journal record holds.
+type RecordKind string
+
+const (
+	KindStart  RecordKind = "start"  // First record: target and VM
+	KindEvent  RecordKind = "event"  // A progress event
+	KindResult RecordKind = "result" // Last record: the deploy result
+)
+
+// Record is one line of a journal.
+type Record struct {
+	Time time.Time  `json:"time"`
+	Kind RecordKind `json:"kind"`
+
+	// Start
+	Target deploy.DeploymentTarget `json:"target,omitempty"`
+	VM     string                  `json:"vm,omitempty"`
+	PID    int                     `json:"pid,omitempty"` // Process writing the journal
+
+	// Event
+	Stage   deploy.Stage `json:"stage,omitempty"`
+	Message string       `json:"message,omitempty"`
+	Command string       `json:"command,omitempty"`
+	Detail  string       `json:"detail,omitempty"`
+	Percent int          `json:"percent,omitempty"`
+	IsError bool         `json:"is_error,omitempty"`
+
+	// Result
+	Success    bool              `json:"success,omitempty"`
+	DurationMS int64             `json:"duration_ms,omitempty"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	Logs       []string          `json:"logs,omitempty"`
+	Error      string            `json:"error,omitempty"`
+
+	// Checkpoint is what a failed deployment completed, for ucli create --resume
+	Checkpoint *deploy.Checkpoint `json:"checkpoint,omitempty"`
+}
+
+// ProgressEvent returns the progress event an event record was made from.
+func (r Record) ProgressEvent() deploy.ProgressEvent {
+	return deploy.ProgressEvent{
+		Stage:     r.Stage,
+		Message:   r.Message,
+		Command:   r.Command,
+		Detail:    r.Detail,
+		Percent:   r.Percent,
+		IsError:   r.IsError,
+		Timestamp: r.Time,
+	}
+}
+
+// DeployResult returns the result a result record was made from.
+func (r Record) DeployResult() *deploy.DeployResult {
+	result := &deploy.DeployResult{
+		Success:  r.Success,
+		Target:   r.Target,
+		Duration: time.Duration(r.DurationMS) * time.Millisecond,
+		Outputs:  r.Outputs,
+		Logs:     r.Logs,
+
+		Checkpoint: r.Checkpoint,
+	}
+	if r.Error != "" {
+		result.Error = errors.New(r.Error)
+	}
+	return result
+}
+
+// Journal is an open journal for a running deployment.
+type Journal struct {
+	ID   string
+	Path string
+
+	mu     sync.Mutex
+	file   *os.File
+	target deploy.DeploymentTarget
+}
+
+// Dir returns the journal directory under the ucli state directory.
+func Dir() (string, error) {
+	stateDir, err := globalconfig.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, DirName), nil
+}
+
+// Start creates a journal in the default directory and prunes old ones
+// with DefaultRetention.
+func Start(target deploy.DeploymentTarget, vm string) (*Journal, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal directory: %w", err)
+	}
+
+	j, err := Create(dir, target, vm)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = Prune(dir, DefaultRetention, j.ID)
+	return j, nil
+}
+
+// Create creates a journal in dir and writes its start record. The ID is
+// the start time, followed by the VM name if there is one.
+func Create(dir string, target deploy.DeploymentTarget, vm string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	now := time.Now()
+	base := now.Format("20060102-150405")
+	if vm != "" {
+		base += "-" + vm
+	}
+
+	// Deployments started in the same second get a numbered ID
+	id := base
+	for i := 2; ; i++ {
+		path := filepath.Join(dir, id+fileExt)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			id = fmt.Sprintf("%s-%d", base, i)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create journal: %w", err)
+		}
+
+		j := &Journal{ID: id, Path: path, file: f, target: target}
+		if err := j.write(Record{Time: now, Kind: KindStart, Target: target, VM: vm, PID: os.Getpid()}); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return j, nil
+	}
+}
+
+// Progress returns a callback that records each event and passes it on to
+// next.
+func (j *Journal) Progress(next deploy.ProgressCallback) deploy.ProgressCallback {
+	return func(e deploy.ProgressEvent) {
+		_ = j.Event(e)
+		next(e)
+	}
+}
+
+// Event records a progress event.
+func (j *Journal) Event(e deploy.ProgressEvent) error {
+	t := e.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return j.write(Record{
+		Time:    t,
+		Kind:    KindEvent,
+		Stage:   e.Stage,
+		Message: e.Message,
+		Command: e.Command,
+		Detail:  e.Detail,
+		Percent: e.Percent,
+		IsError: e.IsError,
+	})
+}
+
+// Finish records the deployment result and closes the journal.
+func (j *Journal) Finish(result *deploy.DeployResult) error {
+	r := Record{Time: time.Now(), Kind: KindResult, Target: j.target}
+	if result != nil {
+		r.Success = result.Success
+		r.DurationMS = result.Duration.Milliseconds()
+		r.Outputs = result.Outputs
+		r.Logs = result.Logs
+		if result.Error != nil {
+			r.Error = result.Error.Error()
+		}
+		if vm := result.Outputs["vm_name"]; vm != "" {
+			r.VM = vm
+		}
+		if !result.Success && result.Checkpoint.Resumable() {
+			r.Checkpoint = result.Checkpoint
+		}
+	}
+
+	err := j.write(r)
+	if closeErr := j.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Close closes the journal without recording a result.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// write appends a record as one line. Progress can be reported from several
+// goroutines, so writes are serialized.
+func (j *Journal) write(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal record: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return fmt.Errorf("journal %s is closed", j.ID)
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Summary describes a journal from its first and last records.
+type Summary struct {
+	ID       string                  `json:"id"`
+	Path     string                  `json:"path"`
+	Target   deploy.DeploymentTarget `json:"target"`
+	VM       string                  `json:"vm,omitempty"`
+	Started  time.Time               `json:"started"`
+	Finished bool                    `json:"finished"`
+	Success  bool                    `json:"success"`
+	Error    string                  `json:"error,omitempty"`
+
+	// Resumable is set for failed deployments with a checkpoint
+	Resumable bool `json:"resumable,omitempty"`
+}
+
+// Status returns "succeeded", "failed", or "unfinished" for a deployment
+// that is still running or whose ucli was killed.
+func (s Summary) Status() string {
+	switch {
+	case !s.Finished:
+		return "unfinished"
+	case s.Success:
+		return "succeeded"
+	default:
+		return "failed"
+	}
+}
+
+// List returns the journals in dir, newest first. Files that cannot be
+// read are skipped.
+func List(dir string) ([]Summary, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal directory: %w", err)
+	}
+
+	var list []Summary
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileExt) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		records, err := Read(path)
+		if err != nil || len(records) == 0 {
+			continue
+		}
+		list = append(list, summarize(strings.TrimSuffix(e.Name(), fileExt), path, records))
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Started.After(list[j].Started)
+	})
+	return list, nil
+}
+
+// summarize builds the summary of a journal's records.
+func summarize(id, path string, records []Record) Summary {
+	s := Summary{ID: id, Path: path}
+
+	first := records[0]
+	s.Started = first.Time
+	s.Target = first.Target
+	s.VM = first.VM
+
+	if last := records[len(records)-1]; last.Kind == KindResult {
+		s.Finished = true
+		s.Success = last.Success
+		s.Error = last.Error
+		s.Resumable = last.Checkpoint.Resumable()
+		if s.VM == "" {
+			s.VM = last.VM
+		}
+	}
+	return s
+}
+
+// Find returns the newest journal in dir whose ID or VM is name, or the
+// newest journal if name is empty.
+func Find(dir, name string) (Summary, error) {
+	list, err := List(dir)
+	if err != nil {
+		return Summary{}, err
+	}
+	for _, s := range list {
+		if name == "" || s.ID == name || s.VM == name {
+			return s, nil
+		}
+	}
+	if name == "" {
+		return Summary{}, fmt.Errorf("no deployments recorded in %s", dir)
+	}
+	return Summary{}, fmt.Errorf("no deployment found for %q", name)
+}
+
+// Checkpoint returns the checkpoint of the failed deployment in a journal.
+func Checkpoint(s Summary) (*deploy.Checkpoint, error) {
+	if !s.Finished {
+		return nil, fmt.Errorf("deployment %s has not finished", s.ID)
+	}
+	if s.Success {
+		return nil, fmt.Errorf("deployment %s succeeded; nothing to resume", s.ID)
+	}
+
+	records, err := Read(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	cp := records[len(records)-1].Checkpoint
+	if !cp.Resumable() {
+		return nil, fmt.Errorf("deployment %s cannot be resumed: its resources were cleaned up or no stage completed", s.ID)
+	}
+	return cp, nil
+}
+
+// Read returns the records in a journal. A last line without a newline is
+// still being written and is skipped.
+func Read(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal: %w", err)
+		}
+
+		rec, err := parseRecord(line)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+}
+
+// Follow calls fn for each record in a journal, then waits for new ones
+// until the result record is written or ctx is done. It returns
+// ErrInterrupted if the process writing the journal exits first.
+func Follow(ctx context.Context, path string, fn func(Record)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var line string
+	pid, exited := 0, false
+	for {
+		chunk, err := reader.ReadString('\n')
+		line += chunk
+		if err == io.EOF {
+			// Read once more after the writer exits, as it may have
+			// written the result just before
+			if exited {
+				return fmt.Errorf("%w: ucli process %d exited without recording a result", ErrInterrupted, pid)
+			}
+			if !processAlive(pid) {
+				exited = true
+				continue
+			}
+
+			// Wait for the rest of the line or the next record
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read journal: %w", err)
+		}
+
+		rec, err := parseRecord(line)
+		if err != nil {
+			return err
+		}
+		line = ""
+		if rec.Kind == KindStart {
+			pid = rec.PID
+		}
+
+		fn(rec)
+		if rec.Kind == KindResult {
+			return nil
+		}
+	}
+}
+
+// processAlive reports whether the process with pid is running. Journals
+// written before PIDs were recorded have none and are assumed to be live.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return true
+	}
+	// On Windows FindProcess fails for processes that have exited
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// On POSIX systems signal 0 only checks that the process exists. Any
+	// other error, such as EPERM for another user's process or Windows not
+	// supporting the signal, leaves it counted as alive.
+	err = p.Signal(syscall.Signal(0))
+	return !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
+
+// parseRecord decodes one journal line.
+func parseRecord(line string) (Record, error) {
+	var rec Record
+	if err := json.Unmarshal([]byte(line), &rec); err != nil {
+		return Record{}, fmt.Errorf("failed to parse journal record: %w", err)
+	}
+	return rec, nil
+}
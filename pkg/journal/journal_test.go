@@ -0,0 +1,254 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+)
+
+func TestJournal_RecordsDeployment(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Create(dir, deploy.TargetMultipass, "dev")
+	require.NoError(t, err)
+	assert.Regexp(t, `^\d{8}-\d{6}-dev$`, j.ID)
+
+	var forwarded []deploy.ProgressEvent
+	progress := j.Progress(func(e deploy.ProgressEvent) { forwarded = append(forwarded, e) })
+	progress(deploy.NewProgressEventWithCommand(deploy.StageLaunching, "Launching VM 'dev'...", "multipass launch --name dev", 35))
+	progress(deploy.NewErrorEventWithDetail("Launch failed", "launch timed out"))
+	require.Len(t, forwarded, 2)
+
+	require.NoError(t, j.Finish(&deploy.DeployResult{
+		Target:   deploy.TargetMultipass,
+		Duration: 1500 * time.Millisecond,
+		Outputs:  map[string]string{"vm_name": "dev"},
+		Logs:     []string{"Warning: slow"},
+		Error:    errors.New("failed to launch VM: timed out"),
+	}))
+	assert.Error(t, j.Event(deploy.NewProgressEvent(deploy.StageComplete, "late", 100)), "closed")
+
+	records, err := Read(j.Path)
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, KindStart, records[0].Kind)
+	assert.Equal(t, "dev", records[0].VM)
+
+	event := records[1].ProgressEvent()
+	assert.Equal(t, deploy.StageLaunching, event.Stage)
+	assert.Equal(t, "multipass launch --name dev", event.Command)
+	assert.Equal(t, 35, event.Percent)
+	assert.True(t, records[2].IsError)
+
+	assert.Equal(t, KindResult, records[3].Kind)
+	result := records[3].DeployResult()
+	assert.False(t, result.Success)
+	assert.Equal(t, deploy.TargetMultipass, result.Target)
+	assert.Equal(t, 1500*time.Millisecond, result.Duration)
+	assert.Equal(t, []string{"Warning: slow"}, result.Logs)
+	assert.EqualError(t, result.Error, "failed to launch VM: timed out")
+
+	info, err := os.Stat(j.Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestCreate_UniqueIDs(t *testing.T) {
+	dir := t.TempDir()
+
+	a, err := Create(dir, deploy.TargetTerragrunt, "web")
+	require.NoError(t, err)
+	defer a.Close()
+	b, err := Create(dir, deploy.TargetTerragrunt, "web")
+	require.NoError(t, err)
+	defer b.Close()
+
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.NotEqual(t, a.Path, b.Path)
+}
+
+func TestList_AndFind(t *testing.T) {
+	dir := t.TempDir()
+
+	// A finished deployment whose VM name was generated while deploying
+	old, err := Create(dir, deploy.TargetMultipass, "")
+	require.NoError(t, err)
+	require.NoError(t, old.Finish(&deploy.DeployResult{Success: true, Outputs: map[string]string{"vm_name": "cloud-init-test-1"}}))
+
+	// One still running
+	running, err := Create(dir, deploy.TargetTerragrunt, "web")
+	require.NoError(t, err)
+	defer running.Close()
+
+	// Files that are not journals are ignored
+	require.NoError(t, os.WriteFile(dir+"/notes.txt", []byte("x"), 0644))
+
+	list, err := List(dir)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "web", list[0].VM)
+	assert.Equal(t, "unfinished", list[0].Status())
+	assert.Equal(t, "cloud-init-test-1", list[1].VM)
+	assert.Equal(t, "succeeded", list[1].Status())
+
+	s, err := Find(dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, running.ID, s.ID)
+
+	s, err = Find(dir, "cloud-init-test-1")
+	require.NoError(t, err)
+	assert.Equal(t, old.ID, s.ID)
+
+	s, err = Find(dir, old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, old.ID, s.ID)
+
+	_, err = Find(dir, "missing")
+	assert.EqualError(t, err, `no deployment found for "missing"`)
+
+	list, err = List(t.TempDir() + "/none")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+
+	cp := deploy.NewCheckpoint(deploy.TargetMultipass, &deploy.DeployOptions{ProjectRoot: "/project"})
+	cp.Complete(deploy.StageLaunching, map[string]string{"vm_name": "dev"})
+
+	j, err := Create(dir, deploy.TargetMultipass, "dev")
+	require.NoError(t, err)
+	require.NoError(t, j.Finish(&deploy.DeployResult{Error: errors.New("timed out"), Checkpoint: cp}))
+
+	s, err := Find(dir, "dev")
+	require.NoError(t, err)
+	assert.True(t, s.Resumable)
+
+	got, err := Checkpoint(s)
+	require.NoError(t, err)
+	assert.True(t, got.Done(deploy.StageLaunching))
+	assert.Equal(t, "dev", got.Output("vm_name"))
+	assert.Equal(t, "/project", got.ProjectRoot)
+
+	// Successful deployments do not record their checkpoint
+	ok, err := Create(dir, deploy.TargetMultipass, "ok")
+	require.NoError(t, err)
+	require.NoError(t, ok.Finish(&deploy.DeployResult{Success: true, Checkpoint: cp}))
+	s, err = Find(dir, "ok")
+	require.NoError(t, err)
+	assert.False(t, s.Resumable)
+	_, err = Checkpoint(s)
+	assert.ErrorContains(t, err, "nothing to resume")
+
+	// Nor do cleaned up ones
+	gone, err := Create(dir, deploy.TargetMultipass, "gone")
+	require.NoError(t, err)
+	require.NoError(t, gone.Finish(&deploy.DeployResult{Error: errors.New("timed out")}))
+	s, err = Find(dir, "gone")
+	require.NoError(t, err)
+	_, err = Checkpoint(s)
+	assert.ErrorContains(t, err, "cannot be resumed")
+}
+
+func TestFollow(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+
+	dir := t.TempDir()
+	j, err := Create(dir, deploy.TargetMultipass, "dev")
+	require.NoError(t, err)
+
+	var messages []string
+	done := make(chan error, 1)
+	go func() {
+		done <- Follow(context.Background(), j.Path, func(r Record) {
+			messages = append(messages, string(r.Kind)+":"+r.Message)
+		})
+	}()
+
+	require.NoError(t, j.Event(deploy.NewProgressEvent(deploy.StageLaunching, "Launching...", 35)))
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, j.Event(deploy.NewProgressEvent(deploy.StageComplete, "Deployment complete!", 100)))
+	require.NoError(t, j.Finish(&deploy.DeployResult{Success: true}))
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Follow did not stop at the result record")
+	}
+	assert.Equal(t, []string{"start:", "event:Launching...", "event:Deployment complete!", "result:"}, messages)
+}
+
+func TestFollow_Cancelled(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+
+	j, err := Create(t.TempDir(), deploy.TargetMultipass, "dev")
+	require.NoError(t, err)
+	defer j.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	count := 0
+	err = Follow(ctx, j.Path, func(Record) { count++ })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, count)
+}
+
+func TestFollow_WriterExited(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+
+	// A finished process stands in for a killed writer
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	pid := cmd.Process.Pid
+
+	var lines []byte
+	for _, rec := range []Record{
+		{Kind: KindStart, Target: deploy.TargetMultipass, VM: "dev", PID: pid},
+		{Kind: KindEvent, Message: "Launching..."},
+	} {
+		data, err := json.Marshal(rec)
+		require.NoError(t, err)
+		lines = append(append(lines, data...), '\n')
+	}
+	path := filepath.Join(t.TempDir(), "dev.jsonl")
+	require.NoError(t, os.WriteFile(path, lines, 0600))
+
+	done := make(chan error, 1)
+	count := 0
+	go func() {
+		done <- Follow(context.Background(), path, func(Record) { count++ })
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrInterrupted)
+		assert.ErrorContains(t, err, strconv.Itoa(pid))
+		assert.Equal(t, 2, count)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Follow kept polling after the writer exited")
+	}
+}
+
+func TestProcessAlive(t *testing.T) {
+	assert.True(t, processAlive(os.Getpid()))
+	// Journals written before PIDs were recorded
+	assert.True(t, processAlive(0))
+
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	assert.False(t, processAlive(cmd.Process.Pid))
+}
@@ -0,0 +1,79 @@
+package journal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Retention limits how many journals are kept.
+type Retention struct {
+	MaxCount int           // Newest journals to keep, 0 for no limit
+	MaxAge   time.Duration // Remove journals older than this, 0 for no limit
+}
+
+// DefaultRetention keeps the last 100 deployments from the past 30 days.
+var DefaultRetention = Retention{
+	MaxCount: 100,
+	MaxAge:   30 * 24 * time.Hour,
+}
+
+// Prune removes the journals in dir that fall outside r, judged by when
+// they were last written. Journals named in keep are never removed. It
+// returns how many were removed.
+func Prune(dir string, r Retention, keep ...string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read journal directory: %w", err)
+	}
+
+	type journalFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []journalFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), fileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, journalFile{path: filepath.Join(dir, e.Name()), modTime: info.ModTime()})
+	}
+
+	// Newest first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+
+	kept := make(map[string]bool, len(keep))
+	for _, id := range keep {
+		kept[filepath.Join(dir, id+fileExt)] = true
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-r.MaxAge)
+	for i, f := range files {
+		if kept[f.path] {
+			continue
+		}
+		tooMany := r.MaxCount > 0 && i >= r.MaxCount
+		tooOld := r.MaxAge > 0 && f.modTime.Before(cutoff)
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove journal: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,44 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+
+	// Journals written 1, 2, ... 5 days ago
+	now := time.Now()
+	for i := 1; i <= 5; i++ {
+		path := filepath.Join(dir, "j"+string(rune('0'+i))+fileExt)
+		require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0600))
+		mtime := now.Add(-time.Duration(i) * 24 * time.Hour)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), nil, 0600))
+
+	// Too old, but kept by name
+	removed, err := Prune(dir, Retention{MaxAge: 60 * time.Hour}, "j4")
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.FileExists(t, filepath.Join(dir, "j4"+fileExt))
+	assert.NoFileExists(t, filepath.Join(dir, "j3"+fileExt))
+	assert.NoFileExists(t, filepath.Join(dir, "j5"+fileExt))
+
+	// Only the newest are kept
+	removed, err = Prune(dir, Retention{MaxCount: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.FileExists(t, filepath.Join(dir, "j1"+fileExt))
+	assert.FileExists(t, filepath.Join(dir, "other.txt"))
+
+	removed, err = Prune(filepath.Join(dir, "missing"), DefaultRetention)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
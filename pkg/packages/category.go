@@ -0,0 +1,80 @@
+package packages
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	scriptspackages "github.com/jaspreet-dot-casa/cloud-init/scripts/packages"
+)
+
+// CategoryDef maps a CATEGORY= id used in installer scripts to its display name.
+type CategoryDef struct {
+	ID   string
+	Name Category
+}
+
+// defaultCategories is parsed from the embedded categories.conf.
+var defaultCategories = mustParseCategories(scriptspackages.Categories)
+
+// DefaultCategories returns the known categories in display order.
+func DefaultCategories() []CategoryDef {
+	result := make([]CategoryDef, len(defaultCategories))
+	copy(result, defaultCategories)
+	return result
+}
+
+// LookupCategory returns the category for a CATEGORY= id.
+func LookupCategory(id string) (Category, bool) {
+	for _, def := range defaultCategories {
+		if def.ID == id {
+			return def.Name, true
+		}
+	}
+	return "", false
+}
+
+// ParseCategories parses a categories.conf file.
+// Each non-comment line is "<id> <display name>"; order is display order.
+func ParseCategories(r io.Reader) ([]CategoryDef, error) {
+	var defs []CategoryDef
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		id, name, ok := strings.Cut(line, " ")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: expected \"<id> <display name>\"", lineNum)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("line %d: duplicate category %q", lineNum, id)
+		}
+		seen[id] = true
+
+		defs = append(defs, CategoryDef{ID: id, Name: Category(name)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading categories: %w", err)
+	}
+
+	return defs, nil
+}
+
+func mustParseCategories(data []byte) []CategoryDef {
+	defs, err := ParseCategories(bytes.NewReader(data))
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded categories.conf: %v", err))
+	}
+	return defs
+}
@@ -16,16 +16,20 @@ import (
 // Pre-compiled regex patterns for parsing shell scripts
 var (
 	packageNameRe = regexp.MustCompile(`^PACKAGE_NAME="([^"]+)"`)
+	displayNameRe = regexp.MustCompile(`^DISPLAY_NAME="([^"]*)"`)
+	categoryRe    = regexp.MustCompile(`^CATEGORY="([^"]*)"`)
+	defaultRe     = regexp.MustCompile(`^DEFAULT="([^"]*)"`)
 	githubRepoRe  = regexp.MustCompile(`GITHUB_REPO="([^"]+)"`)
-	headerRe      = regexp.MustCompile(`^#\s*(\w+)\s+[Ii]nstaller`)
+	dependsRe     = regexp.MustCompile(`^DEPENDS="([^"]*)"`)
+	conflictsRe   = regexp.MustCompile(`^CONFLICTS="([^"]*)"`)
+	headerRe      = regexp.MustCompile(`^#\s*(.+?)\s+[Ii]nstaller\s*$`)
 	descRe        = regexp.MustCompile(`^#\s+([A-Z].+)$`)
 	separatorRe   = regexp.MustCompile(`^#[=\-]*$|^#\s*$`)
 )
 
 // Discover scans a directory for package installer scripts and returns a registry.
+// Scripts that cannot be parsed or lack metadata are recorded in Registry.Issues.
 func Discover(scriptsDir string) (*Registry, error) {
-	registry := NewRegistry()
-
 	packagesDir := filepath.Join(scriptsDir, "packages")
 
 	// Validate directory exists
@@ -37,36 +41,7 @@ func Discover(scriptsDir string) (*Registry, error) {
 		return nil, fmt.Errorf("packages path is not a directory: %s", packagesDir)
 	}
 
-	entries, err := os.ReadDir(packagesDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read packages directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-		// Skip non-shell files and template
-		if !strings.HasSuffix(name, ".sh") || name == "_template.sh" {
-			continue
-		}
-
-		scriptPath := filepath.Join(packagesDir, name)
-		pkg, err := ParseScript(scriptPath)
-		if err != nil {
-			// Log error but continue processing other scripts
-			// In production, consider collecting these as warnings
-			continue
-		}
-
-		if pkg != nil {
-			registry.Add(*pkg)
-		}
-	}
-
-	return registry, nil
+	return discover(os.DirFS(packagesDir), packagesDir)
 }
 
 // ParseScript parses a package installer script and extracts metadata.
@@ -80,115 +55,7 @@ func ParseScript(path string) (*Package, error) {
 	}
 	defer file.Close()
 
-	pkg := &Package{
-		ScriptPath: path,
-		Default:    true, // All packages enabled by default
-	}
-
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-	lookingForDesc := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		lineNum++
-
-		// Look for PACKAGE_NAME
-		if matches := packageNameRe.FindStringSubmatch(line); len(matches) > 1 {
-			pkg.Name = strings.TrimSpace(matches[1])
-		}
-
-		// Look for GITHUB_REPO
-		if matches := githubRepoRe.FindStringSubmatch(line); len(matches) > 1 {
-			pkg.GithubRepo = strings.TrimSpace(matches[1])
-		}
-
-		// Look for header comment (e.g., "# lazygit Installer")
-		if matches := headerRe.FindStringSubmatch(line); len(matches) > 1 {
-			pkg.DisplayName = matches[1]
-			lookingForDesc = true
-			continue
-		}
-
-		// After finding header, look for description
-		if lookingForDesc {
-			// Skip separator and empty comment lines
-			if separatorRe.MatchString(line) {
-				continue
-			}
-
-			// Found description line
-			if matches := descRe.FindStringSubmatch(line); len(matches) > 1 {
-				desc := strings.TrimSpace(matches[1])
-				// Skip URL lines
-				if !strings.HasPrefix(desc, "http") {
-					pkg.Description = desc
-					lookingForDesc = false
-				}
-			} else {
-				// Not a description line, stop looking
-				lookingForDesc = false
-			}
-		}
-
-		// Stop after finding all we need (first 50 lines should be enough)
-		if lineNum > 50 {
-			break
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading script: %w", err)
-	}
-
-	// Skip if no package name found
-	if pkg.Name == "" {
-		return nil, nil
-	}
-
-	// Use name as display name if not found
-	if pkg.DisplayName == "" {
-		pkg.DisplayName = pkg.Name
-	}
-
-	// Determine category based on package name or other hints
-	pkg.Category = categorize(pkg)
-
-	return pkg, nil
-}
-
-// categorize determines the category for a package based on its name and other metadata.
-func categorize(pkg *Package) Category {
-	name := strings.ToLower(pkg.Name)
-
-	// Docker-related
-	if strings.Contains(name, "docker") || strings.Contains(name, "lazydocker") {
-		return CategoryDocker
-	}
-
-	// Git-related
-	if strings.Contains(name, "git") || strings.Contains(name, "delta") {
-		if name == "lazygit" {
-			return CategoryCLI // lazygit is a CLI tool
-		}
-		return CategoryGit
-	}
-
-	// Shell/terminal tools
-	shellTools := []string{"starship", "zoxide", "zsh", "oh-my-zsh", "zellij"}
-	for _, tool := range shellTools {
-		if strings.Contains(name, tool) {
-			return CategoryShell
-		}
-	}
-
-	// System packages (apt-based)
-	if name == "apt" {
-		return CategorySystem
-	}
-
-	// Default to CLI tools
-	return CategoryCLI
+	return ParseScriptFromReader(path, file)
 }
 
 // DiscoverFromProjectRoot discovers packages from the project root directory.
@@ -201,17 +68,28 @@ func DiscoverFromProjectRoot(projectRoot string) (*Registry, error) {
 // DiscoverEmbedded discovers packages from embedded scripts.
 // This uses the scripts embedded at compile time, making the binary portable.
 func DiscoverEmbedded() (*Registry, error) {
-	return DiscoverFromFS(scriptspackages.Scripts)
+	registry, err := DiscoverFromFS(scriptspackages.Scripts)
+	if err != nil {
+		return nil, err
+	}
+	registry.setSource(SourceEmbedded)
+	return registry, nil
 }
 
 // DiscoverFromFS discovers packages from any fs.FS implementation.
 // This is useful for both embedded filesystems and testing.
 func DiscoverFromFS(fsys fs.FS) (*Registry, error) {
+	return discover(fsys, "")
+}
+
+// discover parses every installer script at the root of fsys. Script paths
+// are joined to dir when it is set.
+func discover(fsys fs.FS, dir string) (*Registry, error) {
 	registry := NewRegistry()
 
 	entries, err := fs.ReadDir(fsys, ".")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read embedded scripts: %w", err)
+		return nil, fmt.Errorf("failed to read packages directory: %w", err)
 	}
 
 	for _, entry := range entries {
@@ -225,36 +103,90 @@ func DiscoverFromFS(fsys fs.FS) (*Registry, error) {
 			continue
 		}
 
-		file, err := fsys.Open(name)
-		if err != nil {
-			continue
+		scriptPath := name
+		if dir != "" {
+			scriptPath = filepath.Join(dir, name)
 		}
 
-		pkg, err := ParseScriptFromReader(name, file)
-		file.Close()
+		pkg, meta, err := parseScriptFile(fsys, name, scriptPath)
 		if err != nil {
+			registry.Issues = append(registry.Issues, ScriptIssue{Script: scriptPath, Message: err.Error()})
+			continue
+		}
+		if pkg == nil {
+			registry.Issues = append(registry.Issues, ScriptIssue{Script: scriptPath, Message: "no PACKAGE_NAME, skipped"})
 			continue
 		}
 
-		if pkg != nil {
-			registry.Add(*pkg)
+		for _, msg := range meta.problems(pkg) {
+			registry.Issues = append(registry.Issues, ScriptIssue{Script: scriptPath, Message: msg})
 		}
+		registry.Add(*pkg)
 	}
 
 	return registry, nil
 }
 
+func parseScriptFile(fsys fs.FS, name, scriptPath string) (*Package, *scriptMeta, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open script: %w", err)
+	}
+	defer file.Close()
+
+	return parseScript(scriptPath, file)
+}
+
 // ParseScriptFromReader parses a package installer script from an io.Reader.
-// This is used for embedded scripts where we don't have a filesystem path.
+// name is stored as the script path; for embedded scripts it is the filename.
 func ParseScriptFromReader(name string, r io.Reader) (*Package, error) {
+	pkg, _, err := parseScript(name, r)
+	return pkg, err
+}
+
+// scriptMeta records the raw metadata lines found in a script.
+type scriptMeta struct {
+	displayName string
+	category    string
+	defaultVal  string
+	hasDisplay  bool
+	hasCategory bool
+	hasDefault  bool
+}
+
+// problems returns messages for missing or invalid metadata.
+func (m *scriptMeta) problems(pkg *Package) []string {
+	var result []string
+	if !m.hasDisplay || m.displayName == "" {
+		result = append(result, "missing DISPLAY_NAME")
+	}
+	if !m.hasCategory || m.category == "" {
+		result = append(result, "missing CATEGORY")
+	} else if _, ok := LookupCategory(m.category); !ok {
+		result = append(result, fmt.Sprintf("unknown CATEGORY %q", m.category))
+	}
+	if !m.hasDefault {
+		result = append(result, "missing DEFAULT")
+	} else if m.defaultVal != "true" && m.defaultVal != "false" {
+		result = append(result, fmt.Sprintf("DEFAULT must be \"true\" or \"false\", got %q", m.defaultVal))
+	}
+	if pkg.Description == "" {
+		result = append(result, "missing description comment after the Installer header")
+	}
+	return result
+}
+
+func parseScript(name string, r io.Reader) (*Package, *scriptMeta, error) {
 	pkg := &Package{
-		ScriptPath: name, // Use filename as path for embedded scripts
-		Default:    true,
+		ScriptPath: name,
+		Default:    true, // Packages are enabled unless DEFAULT="false"
 	}
+	meta := &scriptMeta{}
 
 	scanner := bufio.NewScanner(r)
 	lineNum := 0
 	lookingForDesc := false
+	headerName := ""
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -265,14 +197,36 @@ func ParseScriptFromReader(name string, r io.Reader) (*Package, error) {
 			pkg.Name = strings.TrimSpace(matches[1])
 		}
 
+		// Look for DISPLAY_NAME, CATEGORY and DEFAULT
+		if matches := displayNameRe.FindStringSubmatch(line); len(matches) > 1 {
+			meta.displayName = strings.TrimSpace(matches[1])
+			meta.hasDisplay = true
+		}
+		if matches := categoryRe.FindStringSubmatch(line); len(matches) > 1 {
+			meta.category = strings.TrimSpace(matches[1])
+			meta.hasCategory = true
+		}
+		if matches := defaultRe.FindStringSubmatch(line); len(matches) > 1 {
+			meta.defaultVal = strings.TrimSpace(matches[1])
+			meta.hasDefault = true
+		}
+
 		// Look for GITHUB_REPO
 		if matches := githubRepoRe.FindStringSubmatch(line); len(matches) > 1 {
 			pkg.GithubRepo = strings.TrimSpace(matches[1])
 		}
 
+		// Look for DEPENDS and CONFLICTS (space or comma separated names)
+		if matches := dependsRe.FindStringSubmatch(line); len(matches) > 1 {
+			pkg.Depends = splitNames(matches[1])
+		}
+		if matches := conflictsRe.FindStringSubmatch(line); len(matches) > 1 {
+			pkg.Conflicts = splitNames(matches[1])
+		}
+
 		// Look for header comment (e.g., "# lazygit Installer")
-		if matches := headerRe.FindStringSubmatch(line); len(matches) > 1 {
-			pkg.DisplayName = matches[1]
+		if matches := headerRe.FindStringSubmatch(line); len(matches) > 1 && headerName == "" {
+			headerName = matches[1]
 			lookingForDesc = true
 			continue
 		}
@@ -305,21 +259,42 @@ func ParseScriptFromReader(name string, r io.Reader) (*Package, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading script: %w", err)
+		return nil, nil, fmt.Errorf("error reading script: %w", err)
 	}
 
 	// Skip if no package name found
 	if pkg.Name == "" {
-		return nil, nil
+		return nil, meta, nil
 	}
 
-	// Use name as display name if not found
-	if pkg.DisplayName == "" {
+	// Prefer DISPLAY_NAME, then the header, then the package name
+	switch {
+	case meta.displayName != "":
+		pkg.DisplayName = meta.displayName
+	case headerName != "":
+		pkg.DisplayName = headerName
+	default:
 		pkg.DisplayName = pkg.Name
 	}
 
-	// Determine category based on package name or other hints
-	pkg.Category = categorize(pkg)
+	// Unknown ids are kept as-is so the package still shows up
+	pkg.Category = CategoryOther
+	if meta.category != "" {
+		if cat, ok := LookupCategory(meta.category); ok {
+			pkg.Category = cat
+		} else {
+			pkg.Category = Category(meta.category)
+		}
+	}
+
+	if meta.defaultVal == "false" {
+		pkg.Default = false
+	}
+
+	return pkg, meta, nil
+}
 
-	return pkg, nil
+// splitNames splits a space or comma separated list of package names.
+func splitNames(s string) []string {
+	return strings.Fields(strings.ReplaceAll(s, ",", " "))
 }
@@ -3,7 +3,9 @@ package packages
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -53,6 +55,7 @@ func TestParseScript_WithDescription(t *testing.T) {
 #==============================================================================
 
 PACKAGE_NAME="lazygit"
+CATEGORY="cli"
 GITHUB_REPO="jesseduffield/lazygit"
 `
 	scriptPath := filepath.Join(tmpDir, "lazygit.sh")
@@ -132,77 +135,93 @@ func TestRegistry_Categories(t *testing.T) {
 	assert.Len(t, registry.ByCategory[CategoryDocker], 1)
 }
 
-func TestCategorize(t *testing.T) {
+func TestParseScript_Metadata(t *testing.T) {
 	tests := []struct {
-		name     string
-		pkg      *Package
-		expected Category
+		name        string
+		metadata    string
+		category    Category
+		isDefault   bool
+		displayName string
+		problems    []string
 	}{
 		{
-			name:     "lazygit is CLI",
-			pkg:      &Package{Name: "lazygit"},
-			expected: CategoryCLI,
+			name:        "all metadata",
+			metadata:    "DISPLAY_NAME=\"GitUI\"\nCATEGORY=\"git\"\nDEFAULT=\"false\"\n",
+			category:    CategoryGit,
+			isDefault:   false,
+			displayName: "GitUI",
 		},
 		{
-			name:     "lazydocker is Docker",
-			pkg:      &Package{Name: "lazydocker"},
-			expected: CategoryDocker,
+			name:        "missing metadata",
+			metadata:    "",
+			category:    CategoryOther,
+			isDefault:   true,
+			displayName: "gitui",
+			problems:    []string{"missing DISPLAY_NAME", "missing CATEGORY", "missing DEFAULT"},
 		},
 		{
-			name:     "docker is Docker",
-			pkg:      &Package{Name: "docker"},
-			expected: CategoryDocker,
-		},
-		{
-			name:     "starship is Shell",
-			pkg:      &Package{Name: "starship"},
-			expected: CategoryShell,
-		},
-		{
-			name:     "zoxide is Shell",
-			pkg:      &Package{Name: "zoxide"},
-			expected: CategoryShell,
-		},
-		{
-			name:     "zellij is Shell",
-			pkg:      &Package{Name: "zellij"},
-			expected: CategoryShell,
-		},
-		{
-			name:     "github-cli is Git",
-			pkg:      &Package{Name: "github-cli"},
-			expected: CategoryGit,
-		},
-		{
-			name:     "delta is Git",
-			pkg:      &Package{Name: "delta"},
-			expected: CategoryGit,
-		},
-		{
-			name:     "apt is System",
-			pkg:      &Package{Name: "apt"},
-			expected: CategorySystem,
-		},
-		{
-			name:     "btop is CLI (default)",
-			pkg:      &Package{Name: "btop"},
-			expected: CategoryCLI,
-		},
-		{
-			name:     "yq is CLI (default)",
-			pkg:      &Package{Name: "yq"},
-			expected: CategoryCLI,
+			name:        "unknown category and bad default",
+			metadata:    "DISPLAY_NAME=\"GitUI\"\nCATEGORY=\"vcs\"\nDEFAULT=\"yes\"\n",
+			category:    Category("vcs"),
+			isDefault:   true,
+			displayName: "GitUI",
+			problems:    []string{`unknown CATEGORY "vcs"`, `DEFAULT must be "true" or "false", got "yes"`},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := categorize(tt.pkg)
-			assert.Equal(t, tt.expected, result)
+			script := "#!/bin/bash\n# gitui Installer\n#\n# Blazing fast terminal UI for git\n\nPACKAGE_NAME=\"gitui\"\n" + tt.metadata
+
+			pkg, meta, err := parseScript("gitui.sh", strings.NewReader(script))
+			require.NoError(t, err)
+			require.NotNil(t, pkg)
+
+			assert.Equal(t, tt.category, pkg.Category)
+			assert.Equal(t, tt.isDefault, pkg.Default)
+			assert.Equal(t, tt.displayName, pkg.DisplayName)
+			assert.Equal(t, "Blazing fast terminal UI for git", pkg.Description)
+			assert.Equal(t, tt.problems, meta.problems(pkg))
 		})
 	}
 }
 
+func TestParseCategories(t *testing.T) {
+	defs, err := ParseCategories(strings.NewReader("# comment\n\ncli  CLI Tools\nnet Networking & VPN\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []CategoryDef{
+		{ID: "cli", Name: "CLI Tools"},
+		{ID: "net", Name: "Networking & VPN"},
+	}, defs)
+
+	_, err = ParseCategories(strings.NewReader("cli\n"))
+	assert.ErrorContains(t, err, "line 1")
+
+	_, err = ParseCategories(strings.NewReader("cli A\ncli B\n"))
+	assert.ErrorContains(t, err, `duplicate category "cli"`)
+
+	cat, ok := LookupCategory("docker")
+	assert.True(t, ok)
+	assert.Equal(t, CategoryDocker, cat)
+}
+
+func TestRegistry_Categories_Unknown(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add(Package{Name: "custom", Category: "Zeta"})
+	registry.Add(Package{Name: "other", Category: "Alpha"})
+	registry.Add(Package{Name: "btop", Category: CategoryCLI})
+
+	assert.Equal(t, []Category{CategoryCLI, "Alpha", "Zeta"}, registry.Categories())
+}
+
+func TestRegistry_Defaults(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add(Package{Name: "btop", Default: true})
+	registry.Add(Package{Name: "glances"})
+
+	assert.Equal(t, []string{"btop"}, registry.Defaults())
+}
+
 func TestDiscover(t *testing.T) {
 	// Create a temporary directory structure
 	tmpDir := t.TempDir()
@@ -246,6 +265,30 @@ PACKAGE_NAME="template"
 	assert.NotNil(t, registry.Get("lazygit"))
 	assert.NotNil(t, registry.Get("starship"))
 	assert.Nil(t, registry.Get("template"))
+
+	// Test scripts have no DISPLAY_NAME/CATEGORY/DEFAULT lines
+	assert.NotEmpty(t, registry.Issues)
+	assert.Equal(t, filepath.Join(packagesDir, "lazygit.sh")+": missing DISPLAY_NAME", registry.Issues[0].String())
+}
+
+func TestDiscoverFromFS_Issues(t *testing.T) {
+	fsys := fstest.MapFS{
+		"empty.sh": {Data: []byte("#!/bin/bash\necho hi\n")},
+		"ok.sh": {Data: []byte(`#!/bin/bash
+# ok Installer
+# Does things
+PACKAGE_NAME="ok"
+DISPLAY_NAME="OK"
+CATEGORY="cli"
+DEFAULT="true"
+`)},
+	}
+
+	registry, err := DiscoverFromFS(fsys)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ok"}, registry.Names())
+	assert.Equal(t, []ScriptIssue{{Script: "empty.sh", Message: "no PACKAGE_NAME, skipped"}}, registry.Issues)
 }
 
 func TestDiscoverFromProjectRoot(t *testing.T) {
@@ -302,6 +345,27 @@ func TestDiscoverEmbedded(t *testing.T) {
 	assert.Nil(t, registry.Get("template"), "_template.sh should be skipped")
 }
 
+func TestDiscoverEmbedded_Metadata(t *testing.T) {
+	registry, err := DiscoverEmbedded()
+	require.NoError(t, err)
+
+	// Every shipped script declares its metadata
+	assert.Empty(t, registry.Issues)
+
+	categories := map[string]Category{
+		"lazygit":    CategoryCLI,
+		"delta":      CategoryGit,
+		"starship":   CategoryShell,
+		"lazydocker": CategoryDocker,
+		"apt":        CategorySystem,
+	}
+	for name, want := range categories {
+		assert.Equal(t, want, registry.Get(name).Category, name)
+	}
+	assert.Equal(t, "GitHub CLI", registry.Get("gh").DisplayName)
+	assert.NotEmpty(t, registry.Get("apt").Description)
+}
+
 func TestDiscoverEmbedded_MatchesFilesystemDiscovery(t *testing.T) {
 	// Get the actual project root
 	cwd, err := os.Getwd()
@@ -0,0 +1,153 @@
+package packages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/globalconfig"
+)
+
+// Source identifies where a package installer script was loaded from.
+type Source string
+
+// Package sources, from lowest to highest precedence
+const (
+	SourceEmbedded Source = "embedded" // Compiled into the binary
+	SourceUser     Source = "user"     // ~/.config/ucli/packages/
+	SourceProject  Source = "project"  // <project>/packages.d/
+)
+
+// ProjectOverlayDirName is the overlay directory inside a project.
+const ProjectOverlayDirName = "packages.d"
+
+// Overlay is a directory of extra installer scripts.
+type Overlay struct {
+	Dir    string
+	Source Source
+}
+
+// OverlayDirs returns the overlay directories that exist, lowest precedence
+// first: the user directory, then the project directory if projectRoot is set.
+func OverlayDirs(projectRoot string) []Overlay {
+	var candidates []Overlay
+	if configDir, err := globalconfig.GetConfigDir(); err == nil {
+		candidates = append(candidates, Overlay{Dir: filepath.Join(configDir, "packages"), Source: SourceUser})
+	}
+	if projectRoot != "" {
+		candidates = append(candidates, Overlay{Dir: filepath.Join(projectRoot, ProjectOverlayDirName), Source: SourceProject})
+	}
+
+	var result []Overlay
+	for _, o := range candidates {
+		if info, err := os.Stat(o.Dir); err == nil && info.IsDir() {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// DiscoverWithOverlays discovers the embedded packages and merges the user
+// and project overlay directories on top. A script in a later overlay
+// replaces a package with the same name from an earlier one.
+func DiscoverWithOverlays(projectRoot string) (*Registry, error) {
+	registry, err := DiscoverEmbedded()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range OverlayDirs(projectRoot) {
+		if err := registry.MergeDir(o.Dir, o.Source); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// MergeDir discovers the installer scripts in dir and merges them into the
+// registry, tagging each with source.
+func (r *Registry) MergeDir(dir string, source Source) error {
+	overlay, err := discover(os.DirFS(dir), dir)
+	if err != nil {
+		return fmt.Errorf("failed to load %s packages from %s: %w", source, dir, err)
+	}
+
+	r.Issues = append(r.Issues, overlay.Issues...)
+	for _, pkg := range overlay.Packages {
+		pkg.Source = source
+		if existing, ok := r.ByName[pkg.Name]; ok {
+			pkg.Overrides = &existing
+		}
+		r.Put(pkg)
+	}
+	return nil
+}
+
+// setSource tags every package in the registry with source.
+func (r *Registry) setSource(source Source) {
+	for i := range r.Packages {
+		r.Packages[i].Source = source
+		r.Put(r.Packages[i])
+	}
+}
+
+// Origin returns the package at the bottom of the override chain, which is
+// pkg itself when it does not replace another package.
+func (p Package) Origin() Package {
+	for p.Overrides != nil {
+		p = *p.Overrides
+	}
+	return p
+}
+
+// Put adds pkg to the registry, replacing any package with the same name
+// in place.
+func (r *Registry) Put(pkg Package) {
+	if _, ok := r.ByName[pkg.Name]; !ok {
+		r.Add(pkg)
+		return
+	}
+
+	r.ByName[pkg.Name] = pkg
+	r.ByCategory = make(map[Category][]Package)
+	for i := range r.Packages {
+		if r.Packages[i].Name == pkg.Name {
+			r.Packages[i] = pkg
+		}
+		cat := r.Packages[i].Category
+		r.ByCategory[cat] = append(r.ByCategory[cat], r.Packages[i])
+	}
+}
+
+// ExtraScripts returns the installer scripts from overlay directories for
+// the enabled packages, in dependency order, so they can be shipped to the VM.
+func (r *Registry) ExtraScripts(enabled []string) ([]config.PackageScript, error) {
+	res, err := r.Resolve(enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	var scripts []config.PackageScript
+	for _, name := range res.Packages {
+		pkg := r.ByName[name]
+		if pkg.Source == SourceEmbedded {
+			continue
+		}
+
+		content, err := os.ReadFile(pkg.ScriptPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s package script: %w", pkg.Name, err)
+		}
+
+		// An override of a built-in script takes its file name so that
+		// install-all.sh runs it in place of the original.
+		script := config.PackageScript{Name: pkg.Name, File: filepath.Base(pkg.ScriptPath), Content: string(content)}
+		if base := pkg.Origin(); base.Source == SourceEmbedded {
+			script.File = filepath.Base(base.ScriptPath)
+			script.Override = true
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
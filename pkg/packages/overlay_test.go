@@ -0,0 +1,119 @@
+package packages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeOverlayScript(t *testing.T, dir, file, name, depends string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	content := `#!/bin/bash
+# ` + name + ` Installer
+# Overlay package
+PACKAGE_NAME="` + name + `"
+DISPLAY_NAME="` + name + `"
+CATEGORY="cli"
+DEFAULT="false"
+DEPENDS="` + depends + `"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+}
+
+func TestOverlayDirs(t *testing.T) {
+	configHome := t.TempDir()
+	projectRoot := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	// Missing directories are skipped
+	assert.Empty(t, OverlayDirs(projectRoot))
+
+	userDir := filepath.Join(configHome, "ucli", "packages")
+	projectDir := filepath.Join(projectRoot, ProjectOverlayDirName)
+	require.NoError(t, os.MkdirAll(userDir, 0755))
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+
+	assert.Equal(t, []Overlay{
+		{Dir: userDir, Source: SourceUser},
+		{Dir: projectDir, Source: SourceProject},
+	}, OverlayDirs(projectRoot))
+	assert.Equal(t, []Overlay{{Dir: userDir, Source: SourceUser}}, OverlayDirs(""))
+}
+
+func TestDiscoverWithOverlays(t *testing.T) {
+	configHome := t.TempDir()
+	projectRoot := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	userDir := filepath.Join(configHome, "ucli", "packages")
+	projectDir := filepath.Join(projectRoot, ProjectOverlayDirName)
+	writeOverlayScript(t, userDir, "internal-tool.sh", "internal-tool", "homebrew")
+	writeOverlayScript(t, userDir, "my-lazygit.sh", "lazygit", "")
+	writeOverlayScript(t, projectDir, "internal-tool.sh", "internal-tool", "")
+
+	embedded, err := DiscoverEmbedded()
+	require.NoError(t, err)
+
+	registry, err := DiscoverWithOverlays(projectRoot)
+	require.NoError(t, err)
+	assert.Len(t, registry.Packages, len(embedded.Packages)+1)
+
+	bat := registry.Get("bat")
+	require.NotNil(t, bat)
+	assert.Equal(t, SourceEmbedded, bat.Source)
+	assert.Nil(t, bat.Overrides)
+
+	// User script replaces the embedded package in place
+	lazygit := registry.Get("lazygit")
+	require.NotNil(t, lazygit)
+	assert.Equal(t, SourceUser, lazygit.Source)
+	require.NotNil(t, lazygit.Overrides)
+	assert.Equal(t, SourceEmbedded, lazygit.Overrides.Source)
+	assert.Equal(t, filepath.Join(userDir, "my-lazygit.sh"), lazygit.ScriptPath)
+	assert.Len(t, registry.ByCategory[CategoryCLI], len(embedded.ByCategory[CategoryCLI])+1) // plus internal-tool
+
+	// Project script wins over the user script
+	tool := registry.Get("internal-tool")
+	require.NotNil(t, tool)
+	assert.Equal(t, SourceProject, tool.Source)
+	require.NotNil(t, tool.Overrides)
+	assert.Equal(t, SourceUser, tool.Overrides.Source)
+	assert.Equal(t, SourceUser, tool.Origin().Source)
+	assert.Empty(t, tool.Depends)
+}
+
+func TestRegistry_ExtraScripts(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	userDir := filepath.Join(configHome, "ucli", "packages")
+	writeOverlayScript(t, userDir, "internal-tool.sh", "internal-tool", "custom-base")
+	writeOverlayScript(t, userDir, "custom-base.sh", "custom-base", "")
+	writeOverlayScript(t, userDir, "my-gh.sh", "gh", "")
+
+	registry, err := DiscoverWithOverlays("")
+	require.NoError(t, err)
+
+	scripts, err := registry.ExtraScripts([]string{"bat", "gh", "internal-tool"})
+	require.NoError(t, err)
+	require.Len(t, scripts, 3)
+
+	// Overrides take the file name of the embedded script
+	assert.Equal(t, "gh", scripts[0].Name)
+	assert.Equal(t, "github-cli.sh", scripts[0].File)
+	assert.True(t, scripts[0].Override)
+
+	// Dependencies come first
+	assert.Equal(t, "custom-base", scripts[1].Name)
+	assert.Equal(t, "internal-tool", scripts[2].Name)
+	assert.Equal(t, "internal-tool.sh", scripts[2].File)
+	assert.False(t, scripts[2].Override)
+	assert.Contains(t, scripts[2].Content, `PACKAGE_NAME="internal-tool"`)
+
+	_, err = registry.ExtraScripts([]string{"missing"})
+	assert.Error(t, err)
+}
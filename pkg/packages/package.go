@@ -2,15 +2,21 @@
 // installable packages from shell scripts.
 package packages
 
+import "sort"
+
 // Category represents a grouping of related packages.
+// The known categories and their order come from categories.conf;
+// see DefaultCategories.
 type Category string
 
+// Well-known categories from categories.conf
 const (
 	CategoryCLI    Category = "CLI Tools"
 	CategoryShell  Category = "Shell & Terminal"
 	CategoryGit    Category = "Git & Version Control"
 	CategoryDocker Category = "Docker & Containers"
 	CategorySystem Category = "System"
+	CategoryOther  Category = "Other" // Scripts without a CATEGORY line
 )
 
 // Well-known package names - use these instead of magic strings
@@ -19,10 +25,6 @@ const (
 	PackageTailscale = "tailscale"
 )
 
-// CategoryOrder defines the canonical ordering for displaying categories.
-// This is the single source of truth - use this everywhere instead of duplicating.
-var CategoryOrder = []Category{CategoryCLI, CategoryShell, CategoryGit, CategoryDocker, CategorySystem}
-
 // Package represents a discoverable package from scripts/packages/.
 type Package struct {
 	// Name is the package identifier (e.g., "lazygit")
@@ -40,11 +42,23 @@ type Package struct {
 	// GithubRepo is the GitHub repository if applicable (e.g., "jesseduffield/lazygit")
 	GithubRepo string
 
-	// Category is the package category for grouping in TUI
+	// Category is the package category for grouping in TUI (CATEGORY="...")
 	Category Category
 
-	// Default indicates whether the package is enabled by default
+	// Default indicates whether the package is preselected (DEFAULT="...", true if unset)
 	Default bool
+
+	// Depends lists packages that must be installed with this one (DEPENDS="...")
+	Depends []string
+
+	// Conflicts lists packages that cannot be installed with this one (CONFLICTS="...")
+	Conflicts []string
+
+	// Source is where the script was loaded from
+	Source Source
+
+	// Overrides is the package this one replaced from a lower-precedence source, if any
+	Overrides *Package
 }
 
 // Registry holds all discovered packages.
@@ -58,6 +72,21 @@ type Registry struct {
 
 	// ByCategory groups packages by their category
 	ByCategory map[Category][]Package
+
+	// Issues lists scripts that were skipped or are missing metadata
+	Issues []ScriptIssue
+}
+
+// ScriptIssue describes an installer script that could not be parsed or is
+// missing metadata.
+type ScriptIssue struct {
+	Script  string
+	Message string
+}
+
+// String returns the issue as "script: message".
+func (i ScriptIssue) String() string {
+	return i.Script + ": " + i.Message
 }
 
 // NewRegistry creates an empty package registry.
@@ -97,13 +126,36 @@ func (r *Registry) Names() []string {
 	return names
 }
 
-// Categories returns all categories that have packages.
+// Defaults returns the names of packages that are preselected by default.
+func (r *Registry) Defaults() []string {
+	names := make([]string, 0, len(r.Packages))
+	for _, pkg := range r.Packages {
+		if pkg.Default {
+			names = append(names, pkg.Name)
+		}
+	}
+	return names
+}
+
+// Categories returns all categories that have packages, in categories.conf
+// order followed by any unknown categories sorted by name.
 func (r *Registry) Categories() []Category {
-	result := make([]Category, 0, len(CategoryOrder))
-	for _, cat := range CategoryOrder {
-		if pkgs, ok := r.ByCategory[cat]; ok && len(pkgs) > 0 {
-			result = append(result, cat)
+	result := make([]Category, 0, len(r.ByCategory))
+	known := make(map[Category]bool)
+	for _, def := range defaultCategories {
+		known[def.Name] = true
+		if pkgs, ok := r.ByCategory[def.Name]; ok && len(pkgs) > 0 {
+			result = append(result, def.Name)
 		}
 	}
-	return result
+
+	var unknown []Category
+	for cat, pkgs := range r.ByCategory {
+		if !known[cat] && len(pkgs) > 0 {
+			unknown = append(unknown, cat)
+		}
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+
+	return append(result, unknown...)
 }
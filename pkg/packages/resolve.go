@@ -0,0 +1,157 @@
+package packages
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Conflict is a pair of packages that cannot be installed together.
+type Conflict struct {
+	Package string
+	With    string
+}
+
+// String returns a human-readable description of the conflict.
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s conflicts with %s", c.Package, c.With)
+}
+
+// Resolution is the result of resolving a package selection.
+type Resolution struct {
+	// Packages is the selection plus all dependencies, dependencies first
+	Packages []string
+
+	// Added lists dependencies that were not part of the selection
+	Added []string
+
+	// Conflicts lists conflicting pairs within Packages
+	Conflicts []Conflict
+}
+
+// Resolve expands selected with the transitive dependencies of each package
+// and reports conflicts between the resulting packages.
+// Returns an error for unknown packages and dependency cycles.
+func (r *Registry) Resolve(selected []string) (*Resolution, error) {
+	res := &Resolution{}
+	inSelection := make(map[string]bool, len(selected))
+	for _, name := range selected {
+		inSelection[name] = true
+	}
+
+	// 0 = unvisited, 1 = visiting, 2 = done
+	state := make(map[string]int)
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+
+		pkg, ok := r.ByName[name]
+		if !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("package %q depends on unknown package %q", path[len(path)-1], name)
+			}
+			return fmt.Errorf("unknown package %q", name)
+		}
+
+		state[name] = 1
+		for _, dep := range pkg.Depends {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+
+		res.Packages = append(res.Packages, name)
+		if !inSelection[name] {
+			res.Added = append(res.Added, name)
+		}
+		return nil
+	}
+
+	for _, name := range selected {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	res.Conflicts = r.Conflicts(res.Packages)
+	return res, nil
+}
+
+// Conflicts returns the conflicting pairs among names.
+func (r *Registry) Conflicts(names []string) []Conflict {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+
+	var result []Conflict
+	seen := make(map[[2]string]bool)
+	for _, name := range names {
+		for _, other := range r.ByName[name].Conflicts {
+			if !set[other] {
+				continue
+			}
+			key := [2]string{name, other}
+			if other < name {
+				key = [2]string{other, name}
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, Conflict{Package: name, With: other})
+		}
+	}
+	return result
+}
+
+// Dependents returns the packages that depend on name directly or
+// transitively, sorted by name.
+func (r *Registry) Dependents(name string) []string {
+	found := make(map[string]bool)
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, pkg := range r.Packages {
+			if found[pkg.Name] || pkg.Name == name {
+				continue
+			}
+			for _, dep := range pkg.Depends {
+				if dep == current {
+					found[pkg.Name] = true
+					queue = append(queue, pkg.Name)
+					break
+				}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(found))
+	for n := range found {
+		result = append(result, n)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// CheckDependencies verifies that every DEPENDS and CONFLICTS entry names a
+// known package and that there are no dependency cycles.
+func (r *Registry) CheckDependencies() error {
+	for _, pkg := range r.Packages {
+		for _, other := range pkg.Conflicts {
+			if _, ok := r.ByName[other]; !ok {
+				return fmt.Errorf("package %q conflicts with unknown package %q", pkg.Name, other)
+			}
+		}
+	}
+
+	_, err := r.Resolve(r.Names())
+	return err
+}
@@ -0,0 +1,107 @@
+package packages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDependencyRegistry() *Registry {
+	r := NewRegistry()
+	r.Add(Package{Name: "homebrew"})
+	r.Add(Package{Name: "docker", Conflicts: []string{"podman"}})
+	r.Add(Package{Name: "podman"})
+	r.Add(Package{Name: "lazygit", Depends: []string{"homebrew"}})
+	r.Add(Package{Name: "lazydocker", Depends: []string{"homebrew", "docker"}})
+	r.Add(Package{Name: "delta", Depends: []string{"lazygit"}})
+	return r
+}
+
+func TestParseScriptFromReader_DependsAndConflicts(t *testing.T) {
+	script := `#!/bin/bash
+PACKAGE_NAME="lazydocker"
+DEPENDS="homebrew docker"
+CONFLICTS="podman, nerdctl"
+`
+	pkg, err := ParseScriptFromReader("lazydocker.sh", strings.NewReader(script))
+	require.NoError(t, err)
+	require.NotNil(t, pkg)
+
+	assert.Equal(t, []string{"homebrew", "docker"}, pkg.Depends)
+	assert.Equal(t, []string{"podman", "nerdctl"}, pkg.Conflicts)
+
+	pkg, err = ParseScriptFromReader("plain.sh", strings.NewReader("PACKAGE_NAME=\"plain\"\nDEPENDS=\"\"\n"))
+	require.NoError(t, err)
+	assert.Empty(t, pkg.Depends)
+	assert.Empty(t, pkg.Conflicts)
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	r := newDependencyRegistry()
+
+	res, err := r.Resolve([]string{"delta", "lazydocker"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"homebrew", "lazygit", "delta", "docker", "lazydocker"}, res.Packages)
+	assert.Equal(t, []string{"homebrew", "lazygit", "docker"}, res.Added)
+	assert.Empty(t, res.Conflicts)
+
+	// Selected dependencies are not reported as added
+	res, err = r.Resolve([]string{"homebrew", "lazygit"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"homebrew", "lazygit"}, res.Packages)
+	assert.Empty(t, res.Added)
+}
+
+func TestRegistry_Resolve_Conflicts(t *testing.T) {
+	r := newDependencyRegistry()
+
+	res, err := r.Resolve([]string{"podman", "lazydocker"})
+	require.NoError(t, err)
+	require.Len(t, res.Conflicts, 1)
+	assert.Equal(t, "docker conflicts with podman", res.Conflicts[0].String())
+}
+
+func TestRegistry_Resolve_Errors(t *testing.T) {
+	r := newDependencyRegistry()
+
+	_, err := r.Resolve([]string{"missing"})
+	assert.EqualError(t, err, `unknown package "missing"`)
+
+	r.Add(Package{Name: "broken", Depends: []string{"nothing"}})
+	_, err = r.Resolve([]string{"broken"})
+	assert.EqualError(t, err, `package "broken" depends on unknown package "nothing"`)
+
+	r.Add(Package{Name: "a", Depends: []string{"b"}})
+	r.Add(Package{Name: "b", Depends: []string{"a"}})
+	_, err = r.Resolve([]string{"a"})
+	assert.EqualError(t, err, "dependency cycle: a -> b -> a")
+}
+
+func TestRegistry_Dependents(t *testing.T) {
+	r := newDependencyRegistry()
+
+	assert.Equal(t, []string{"delta", "lazydocker", "lazygit"}, r.Dependents("homebrew"))
+	assert.Equal(t, []string{"lazydocker"}, r.Dependents("docker"))
+	assert.Empty(t, r.Dependents("delta"))
+}
+
+func TestRegistry_CheckDependencies(t *testing.T) {
+	r := newDependencyRegistry()
+	assert.NoError(t, r.CheckDependencies())
+
+	r.Add(Package{Name: "x", Conflicts: []string{"unknown"}})
+	assert.EqualError(t, r.CheckDependencies(), `package "x" conflicts with unknown package "unknown"`)
+}
+
+func TestDiscoverEmbedded_Dependencies(t *testing.T) {
+	registry, err := DiscoverEmbedded()
+	require.NoError(t, err)
+
+	require.NoError(t, registry.CheckDependencies())
+	assert.Equal(t, []string{"homebrew"}, registry.Get("lazygit").Depends)
+	assert.ElementsMatch(t, []string{"homebrew", "docker"}, registry.Get("lazydocker").Depends)
+	assert.Empty(t, registry.Get("homebrew").Depends)
+}
@@ -0,0 +1,214 @@
+package seed
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// FAT12 layout: a boot sector, two FATs, a fixed root directory and the
+// data clusters. The cluster size grows with the content to stay within
+// the FAT12 cluster limit.
+const (
+	fatSectorSize   = 512
+	fatRootEntries  = 512
+	fatDirEntrySize = 32
+	fatMaxClusters  = 4084 // FAT12 limit
+	fatSlack        = 32   // Spare clusters so the volume isn't exactly full
+
+	attrReadOnly  = 0x01
+	attrHidden    = 0x02
+	attrSystem    = 0x04
+	attrVolumeID  = 0x08
+	attrArchive   = 0x20
+	attrLongName  = attrReadOnly | attrHidden | attrSystem | attrVolumeID
+	lfnCharsEntry = 13
+)
+
+// buildFAT writes files into a FAT12 image with VFAT long file names.
+func buildFAT(label string, files []File, modTime time.Time) ([]byte, error) {
+	spc := 1 // Sectors per cluster
+	var clusters int
+	for {
+		clusterSize := spc * fatSectorSize
+		clusters = fatSlack
+		for _, f := range files {
+			clusters += (len(f.Data) + clusterSize - 1) / clusterSize
+		}
+		if clusters <= fatMaxClusters {
+			break
+		}
+		if spc == 128 {
+			return nil, fmt.Errorf("seed content too large for a FAT12 image")
+		}
+		spc *= 2
+	}
+
+	fatSectors := int(sectors((clusters+2)*3/2+1, fatSectorSize))
+	rootSectors := fatRootEntries * fatDirEntrySize / fatSectorSize
+	dataStart := 1 + 2*fatSectors + rootSectors
+	totalSectors := dataStart + clusters*spc
+
+	img := make([]byte, totalSectors*fatSectorSize)
+	writeBootSector(img[:fatSectorSize], label, spc, fatSectors, totalSectors, modTime)
+
+	fat := make([]byte, fatSectors*fatSectorSize)
+	setFAT12(fat, 0, 0xFF8) // Media descriptor
+	setFAT12(fat, 1, 0xFFF)
+
+	root := img[(1+2*fatSectors)*fatSectorSize : dataStart*fatSectorSize]
+	off := copy(root, shortEntry(padRight(strings.ToUpper(label), 11), attrVolumeID, 0, 0, modTime))
+
+	next := 2 // First data cluster
+	for i, f := range files {
+		first := 0
+		if len(f.Data) > 0 {
+			first = next
+			n := (len(f.Data) + spc*fatSectorSize - 1) / (spc * fatSectorSize)
+			for c := 0; c < n; c++ {
+				value := next + 1
+				if c == n-1 {
+					value = 0xFFF // End of chain
+				}
+				setFAT12(fat, next, value)
+				next++
+			}
+			copy(img[(dataStart+(first-2)*spc)*fatSectorSize:], f.Data)
+		}
+
+		short := shortName(f.Name, i+1)
+		for _, e := range longNameEntries(f.Name, short) {
+			off += copy(root[off:], e)
+		}
+		off += copy(root[off:], shortEntry(short, attrArchive, first, len(f.Data), modTime))
+	}
+
+	copy(img[fatSectorSize:], fat)
+	copy(img[(1+fatSectors)*fatSectorSize:], fat)
+	return img, nil
+}
+
+// writeBootSector writes the boot sector and BIOS parameter block.
+func writeBootSector(b []byte, label string, spc, fatSectors, totalSectors int, modTime time.Time) {
+	copy(b[0:3], []byte{0xEB, 0x3C, 0x90}) // Jump over the BPB
+	copy(b[3:11], "UCLI    ")
+	binary.LittleEndian.PutUint16(b[11:], fatSectorSize)
+	b[13] = byte(spc)
+	binary.LittleEndian.PutUint16(b[14:], 1) // Reserved sectors
+	b[16] = 2                                // Number of FATs
+	binary.LittleEndian.PutUint16(b[17:], fatRootEntries)
+	if totalSectors < 0x10000 {
+		binary.LittleEndian.PutUint16(b[19:], uint16(totalSectors))
+	} else {
+		binary.LittleEndian.PutUint32(b[32:], uint32(totalSectors))
+	}
+	b[21] = 0xF8 // Fixed disk
+	binary.LittleEndian.PutUint16(b[22:], uint16(fatSectors))
+	binary.LittleEndian.PutUint16(b[24:], 32) // Sectors per track
+	binary.LittleEndian.PutUint16(b[26:], 64) // Heads
+	b[36] = 0x80                              // Drive number
+	b[38] = 0x29                              // Extended boot signature
+	binary.LittleEndian.PutUint32(b[39:], uint32(modTime.Unix()))
+	copy(b[43:54], padRight(strings.ToUpper(label), 11))
+	copy(b[54:62], "FAT12   ")
+	b[510], b[511] = 0x55, 0xAA
+}
+
+// setFAT12 sets the 12-bit FAT entry for cluster n.
+func setFAT12(fat []byte, n, value int) {
+	off := n * 3 / 2
+	if n%2 == 0 {
+		fat[off] = byte(value)
+		fat[off+1] = fat[off+1]&0xF0 | byte(value>>8)&0x0F
+	} else {
+		fat[off] = fat[off]&0x0F | byte(value<<4)
+		fat[off+1] = byte(value >> 4)
+	}
+}
+
+// shortName returns an 8.3 alias such as "USER-D~1" for a long name. The
+// seq number keeps aliases unique within the directory.
+func shortName(name string, seq int) []byte {
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("!#$%&'()-@^_`{}~", r):
+			return r
+		default:
+			return -1
+		}
+	}, name)
+
+	tail := fmt.Sprintf("~%d", seq)
+	if len(base) > 8-len(tail) {
+		base = base[:8-len(tail)]
+	}
+	return padRight(base+tail, 11)
+}
+
+// shortEntry returns an 8.3 directory entry.
+func shortEntry(name []byte, attr byte, cluster, size int, modTime time.Time) []byte {
+	e := make([]byte, fatDirEntrySize)
+	copy(e[0:11], name)
+	e[11] = attr
+
+	t := modTime.Local()
+	date := uint16((t.Year()-1980)<<9 | int(t.Month())<<5 | t.Day())
+	clock := uint16(t.Hour()<<11 | t.Minute()<<5 | t.Second()/2)
+	binary.LittleEndian.PutUint16(e[14:], clock) // Created
+	binary.LittleEndian.PutUint16(e[16:], date)
+	binary.LittleEndian.PutUint16(e[18:], date)  // Accessed
+	binary.LittleEndian.PutUint16(e[22:], clock) // Modified
+	binary.LittleEndian.PutUint16(e[24:], date)
+	binary.LittleEndian.PutUint16(e[26:], uint16(cluster))
+	binary.LittleEndian.PutUint32(e[28:], uint32(size))
+	return e
+}
+
+// longNameEntries returns the VFAT entries for name, in the order they are
+// stored: last part first, immediately before the short entry.
+func longNameEntries(name string, short []byte) [][]byte {
+	var sum byte
+	for _, c := range short {
+		sum = (sum&1)<<7 + sum>>1 + c
+	}
+
+	units := utf16.Encode([]rune(name))
+	count := (len(units) + lfnCharsEntry - 1) / lfnCharsEntry
+	if len(units)%lfnCharsEntry != 0 {
+		units = append(units, 0) // Terminator, then 0xFFFF padding
+	}
+	for len(units) < count*lfnCharsEntry {
+		units = append(units, 0xFFFF)
+	}
+
+	entries := make([][]byte, count)
+	for i := 0; i < count; i++ {
+		e := make([]byte, fatDirEntrySize)
+		e[0] = byte(i + 1)
+		if i == count-1 {
+			e[0] |= 0x40 // Last logical entry
+		}
+		e[11] = attrLongName
+		e[13] = sum
+
+		chars := units[i*lfnCharsEntry : (i+1)*lfnCharsEntry]
+		for j, u := range chars {
+			var off int
+			switch {
+			case j < 5:
+				off = 1 + 2*j
+			case j < 11:
+				off = 14 + 2*(j-5)
+			default:
+				off = 28 + 2*(j-11)
+			}
+			binary.LittleEndian.PutUint16(e[off:], u)
+		}
+		entries[count-1-i] = e
+	}
+	return entries
+}
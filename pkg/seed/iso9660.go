@@ -0,0 +1,212 @@
+package seed
+
+import (
+	"encoding/binary"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// ISO9660 layout: 16 system sectors, the primary and Joliet volume
+// descriptors, a terminator, four path tables, two root directories and
+// then the file data. Everything fits in a single flat root directory.
+const (
+	isoSectorSize = 2048
+
+	isoPrimarySector    = 16
+	isoJolietSector     = 17
+	isoTerminatorSector = 18
+	isoPathTableSector  = 19 // L and M tables for primary, then for Joliet
+	isoPrimaryRoot      = 23
+	isoJolietRoot       = 24
+	isoDataSector       = 25
+
+	isoPathTableSize = 10 // A single root entry
+)
+
+// buildISO writes files into an ISO9660 image with Joliet extensions so
+// the lowercase names cloud-init expects survive.
+func buildISO(label string, files []File, modTime time.Time) []byte {
+	extents := make([]uint32, len(files))
+	next := uint32(isoDataSector)
+	for i, f := range files {
+		if len(f.Data) == 0 {
+			continue // Empty files have no extent
+		}
+		extents[i] = next
+		next += sectors(len(f.Data), isoSectorSize)
+	}
+	total := next
+
+	img := make([]byte, int(total)*isoSectorSize)
+	sector := func(n uint32) []byte { return img[n*isoSectorSize : (n+1)*isoSectorSize] }
+
+	writeVolumeDescriptor(sector(isoPrimarySector), 1, label, total, isoPathTableSector, isoPrimaryRoot, modTime)
+	writeVolumeDescriptor(sector(isoJolietSector), 2, label, total, isoPathTableSector+2, isoJolietRoot, modTime)
+
+	term := sector(isoTerminatorSector)
+	term[0] = 255
+	copy(term[1:6], "CD001")
+	term[6] = 1
+
+	writePathTables(sector(isoPathTableSector), sector(isoPathTableSector+1), isoPrimaryRoot)
+	writePathTables(sector(isoPathTableSector+2), sector(isoPathTableSector+3), isoJolietRoot)
+
+	writeRootDirectory(sector(isoPrimaryRoot), isoPrimaryRoot, files, extents, modTime, isoPrimaryName)
+	writeRootDirectory(sector(isoJolietRoot), isoJolietRoot, files, extents, modTime, jolietName)
+
+	for i, f := range files {
+		copy(img[int(extents[i])*isoSectorSize:], f.Data)
+	}
+	return img
+}
+
+// writeVolumeDescriptor writes a primary (type 1) or Joliet supplementary
+// (type 2) volume descriptor.
+func writeVolumeDescriptor(b []byte, typ byte, label string, total, pathTable, root uint32, modTime time.Time) {
+	joliet := typ == 2
+	text := func(off, size int, s string) {
+		if joliet {
+			copy(b[off:off+size], ucs2Padded(s, size))
+		} else {
+			copy(b[off:off+size], padRight(s, size))
+		}
+	}
+
+	b[0] = typ
+	copy(b[1:6], "CD001")
+	b[6] = 1
+	text(8, 32, "")     // System identifier
+	text(40, 32, label) // Volume identifier
+	bothEndian32(b[80:], total)
+	if joliet {
+		copy(b[88:91], "%/E") // UCS-2 level 3
+	}
+	bothEndian16(b[120:], 1)             // Volume set size
+	bothEndian16(b[124:], 1)             // Volume sequence number
+	bothEndian16(b[128:], isoSectorSize) // Logical block size
+	bothEndian32(b[132:], isoPathTableSize)
+	binary.LittleEndian.PutUint32(b[140:], pathTable)
+	binary.BigEndian.PutUint32(b[148:], pathTable+1)
+	writeDirRecord(b[156:190], root, isoSectorSize, true, []byte{0}, modTime)
+	text(190, 128, "")     // Volume set identifier
+	text(318, 128, "")     // Publisher
+	text(446, 128, "")     // Data preparer
+	text(574, 128, "UCLI") // Application
+	text(702, 37, "")      // Copyright file
+	text(739, 37, "")      // Abstract file
+	text(776, 37, "")      // Bibliographic file
+
+	stamp := []byte(modTime.UTC().Format("20060102150405") + "00")
+	copy(b[813:], stamp)                   // Creation
+	copy(b[830:], stamp)                   // Modification
+	copy(b[847:], strings.Repeat("0", 16)) // Expiration
+	copy(b[864:], stamp)                   // Effective
+	b[881] = 1                             // File structure version
+}
+
+// writePathTables writes little- and big-endian path tables holding only
+// the root directory.
+func writePathTables(l, m []byte, root uint32) {
+	for _, t := range [][]byte{l, m} {
+		t[0] = 1 // Identifier length
+	}
+	binary.LittleEndian.PutUint32(l[2:], root)
+	binary.LittleEndian.PutUint16(l[6:], 1)
+	binary.BigEndian.PutUint32(m[2:], root)
+	binary.BigEndian.PutUint16(m[6:], 1)
+}
+
+// writeRootDirectory writes the ".", ".." and file records of the root.
+func writeRootDirectory(b []byte, root uint32, files []File, extents []uint32, modTime time.Time, name func(string) []byte) {
+	off := writeDirRecord(b, root, isoSectorSize, true, []byte{0}, modTime)
+	off += writeDirRecord(b[off:], root, isoSectorSize, true, []byte{1}, modTime)
+	for i, f := range files {
+		off += writeDirRecord(b[off:], extents[i], uint32(len(f.Data)), false, name(f.Name), modTime)
+	}
+}
+
+// writeDirRecord writes a directory record and returns its length.
+func writeDirRecord(b []byte, extent, size uint32, dir bool, name []byte, modTime time.Time) int {
+	length := 33 + len(name)
+	if length%2 == 1 {
+		length++
+	}
+
+	b[0] = byte(length)
+	bothEndian32(b[2:], extent)
+	bothEndian32(b[10:], size)
+
+	t := modTime.UTC()
+	b[18] = byte(t.Year() - 1900)
+	b[19] = byte(t.Month())
+	b[20] = byte(t.Day())
+	b[21] = byte(t.Hour())
+	b[22] = byte(t.Minute())
+	b[23] = byte(t.Second())
+
+	if dir {
+		b[25] = 2
+	}
+	bothEndian16(b[28:], 1) // Volume sequence number
+	b[32] = byte(len(name))
+	copy(b[33:], name)
+	return length
+}
+
+// isoPrimaryName maps a file name to ISO9660 d-characters, e.g.
+// "user-data" becomes "USER_DATA.;1".
+func isoPrimaryName(name string) []byte {
+	mapped := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if !strings.Contains(mapped, ".") {
+		mapped += "."
+	}
+	return []byte(mapped + ";1")
+}
+
+// jolietName encodes a file name as UCS-2 big-endian.
+func jolietName(name string) []byte {
+	return ucs2(name)
+}
+
+func ucs2(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.BigEndian.PutUint16(b[2*i:], u)
+	}
+	return b
+}
+
+// ucs2Padded encodes s as UCS-2 padded with spaces to size bytes.
+func ucs2Padded(s string, size int) []byte {
+	return ucs2(s + strings.Repeat(" ", (size+1)/2-len([]rune(s))))[:size]
+}
+
+func padRight(s string, size int) []byte {
+	return []byte(s + strings.Repeat(" ", size-len(s)))[:size]
+}
+
+func bothEndian16(b []byte, v uint16) {
+	binary.LittleEndian.PutUint16(b, v)
+	binary.BigEndian.PutUint16(b[2:], v)
+}
+
+func bothEndian32(b []byte, v uint32) {
+	binary.LittleEndian.PutUint32(b, v)
+	binary.BigEndian.PutUint32(b[4:], v)
+}
+
+// sectors returns the number of sectors needed for n bytes.
+func sectors(n, size int) uint32 {
+	return uint32((n + size - 1) / size)
+}
@@ -0,0 +1,104 @@
+// Package seed builds NoCloud seed images (CIDATA drives) for cloud-init.
+package seed
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Label is the volume label cloud-init's NoCloud datasource looks for.
+const Label = "cidata"
+
+// Format is the file system of a seed image.
+type Format string
+
+const (
+	FormatISO Format = "iso" // ISO9660 with Joliet names
+	FormatFAT Format = "fat" // FAT12 with long file names
+)
+
+// DefaultNetworkConfig configures DHCP on every ethernet interface.
+const DefaultNetworkConfig = `version: 2
+ethernets:
+  id0:
+    match:
+      driver: "*"
+    dhcp4: true
+`
+
+// Seed is the content of a NoCloud seed.
+type Seed struct {
+	UserData      []byte
+	MetaData      []byte
+	NetworkConfig []byte // Optional
+
+	// ModTime is recorded as the file times; zero means now
+	ModTime time.Time
+}
+
+// File is a file in a seed image.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// MetaData returns NoCloud meta-data for an instance.
+func MetaData(instanceID, hostname string) []byte {
+	return []byte(fmt.Sprintf("instance-id: %s\nlocal-hostname: %s\n", instanceID, hostname))
+}
+
+// Files returns the seed's files in name order.
+func (s *Seed) Files() []File {
+	files := []File{
+		{Name: "meta-data", Data: s.MetaData},
+	}
+	if s.NetworkConfig != nil {
+		files = append(files, File{Name: "network-config", Data: s.NetworkConfig})
+	}
+	return append(files, File{Name: "user-data", Data: s.UserData})
+}
+
+// Build returns the seed as an image in format.
+func (s *Seed) Build(format Format) ([]byte, error) {
+	modTime := s.ModTime
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+
+	switch format {
+	case FormatISO:
+		return buildISO(Label, s.Files(), modTime), nil
+	case FormatFAT:
+		return buildFAT(Label, s.Files(), modTime)
+	default:
+		return nil, fmt.Errorf("unsupported seed format %q (want iso or fat)", format)
+	}
+}
+
+// WriteFile builds the seed image and writes it to path.
+func (s *Seed) WriteFile(path string, format Format) error {
+	data, err := s.Build(format)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write seed image: %w", err)
+	}
+	return nil
+}
+
+// FormatForPath picks the format from an output file name: .iso is ISO9660,
+// anything else (.img, .vfat) is FAT.
+func FormatForPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".iso") {
+		return FormatISO
+	}
+	return FormatFAT
+}
@@ -0,0 +1,361 @@
+package seed
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSeed() *Seed {
+	return &Seed{
+		UserData:      []byte("#cloud-config\nhostname: box\n"),
+		MetaData:      MetaData("iid-box", "box"),
+		NetworkConfig: []byte(DefaultNetworkConfig),
+		ModTime:       time.Date(2024, 4, 23, 10, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestMetaData(t *testing.T) {
+	assert.Equal(t, "instance-id: iid-box\nlocal-hostname: box\n", string(MetaData("iid-box", "box")))
+}
+
+func TestFormatForPath(t *testing.T) {
+	assert.Equal(t, FormatISO, FormatForPath("seed.iso"))
+	assert.Equal(t, FormatISO, FormatForPath("/tmp/SEED.ISO"))
+	assert.Equal(t, FormatFAT, FormatForPath("seed.img"))
+}
+
+func TestSeed_BuildISO(t *testing.T) {
+	s := testSeed()
+	img, err := s.Build(FormatISO)
+	require.NoError(t, err)
+
+	label, files := readISO(t, img)
+	assert.Equal(t, Label, label)
+	assert.Equal(t, map[string]string{
+		"user-data":      string(s.UserData),
+		"meta-data":      string(s.MetaData),
+		"network-config": DefaultNetworkConfig,
+	}, files)
+}
+
+func TestSeed_BuildFAT(t *testing.T) {
+	s := testSeed()
+	img, err := s.Build(FormatFAT)
+	require.NoError(t, err)
+
+	label, files := readFAT(t, img)
+	assert.Equal(t, "CIDATA", label)
+	assert.Equal(t, map[string]string{
+		"user-data":      string(s.UserData),
+		"meta-data":      string(s.MetaData),
+		"network-config": DefaultNetworkConfig,
+	}, files)
+}
+
+func TestSeed_LargeUserData(t *testing.T) {
+	// Large enough to span many ISO sectors and need bigger FAT clusters
+	s := testSeed()
+	s.UserData = bytes.Repeat([]byte("#cloud-config padding line\n"), 200000)
+	s.NetworkConfig = nil
+
+	for _, format := range []Format{FormatISO, FormatFAT} {
+		t.Run(string(format), func(t *testing.T) {
+			img, err := s.Build(format)
+			require.NoError(t, err)
+
+			var files map[string]string
+			if format == FormatISO {
+				_, files = readISO(t, img)
+			} else {
+				_, files = readFAT(t, img)
+			}
+			assert.Len(t, files, 2)
+			assert.Equal(t, string(s.UserData), files["user-data"])
+		})
+	}
+}
+
+func TestSeed_EmptyMetaData(t *testing.T) {
+	s := testSeed()
+	s.MetaData = []byte{}
+
+	for _, format := range []Format{FormatISO, FormatFAT} {
+		img, err := s.Build(format)
+		require.NoError(t, err)
+
+		var files map[string]string
+		if format == FormatISO {
+			_, files = readISO(t, img)
+		} else {
+			_, files = readFAT(t, img)
+		}
+		assert.Equal(t, "", files["meta-data"])
+		assert.Equal(t, string(s.UserData), files["user-data"])
+	}
+}
+
+func TestSeed_WriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "seed.iso")
+	require.NoError(t, testSeed().WriteFile(path, FormatISO))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Zero(t, len(data)%isoSectorSize)
+
+	err = testSeed().WriteFile(path, Format("qcow2"))
+	assert.ErrorContains(t, err, `unsupported seed format "qcow2"`)
+}
+
+// The readers below share this package's reading of the formats, so the
+// images are also checked with the usual tools, where they are installed.
+
+func TestSeed_ISOTools(t *testing.T) {
+	for name, s := range toolSeeds() {
+		t.Run(name, func(t *testing.T) {
+			img := writeImage(t, s, FormatISO)
+			want := seedFiles(s)
+
+			t.Run("isoinfo", func(t *testing.T) {
+				isoinfo := lookTool(t, "isoinfo")
+				out := runTool(t, isoinfo, "-d", "-i", img)
+				assert.Contains(t, out, "Volume id: "+Label)
+				assert.Contains(t, out, "Joliet with UCS level 3 found")
+				for file, content := range want {
+					assert.Equal(t, content, runTool(t, isoinfo, "-i", img, "-J", "-x", "/"+file), file)
+				}
+			})
+
+			t.Run("xorriso", func(t *testing.T) {
+				xorriso := lookTool(t, "xorriso")
+				dir := extractDir(t)
+				out := runTool(t, xorriso, "-indev", img, "-pvd_info", "-osirrox", "on", "-extract", "/", dir)
+				assert.Regexp(t, `Volume Id\s*: `+Label, out)
+				assert.Equal(t, want, readDir(t, dir))
+			})
+
+			t.Run("bsdtar", func(t *testing.T) {
+				bsdtar := lookTool(t, "bsdtar")
+				dir := extractDir(t)
+				runTool(t, bsdtar, "-xf", img, "-C", dir)
+				assert.Equal(t, want, readDir(t, dir))
+			})
+		})
+	}
+}
+
+func TestSeed_FATTools(t *testing.T) {
+	for name, s := range toolSeeds() {
+		t.Run(name, func(t *testing.T) {
+			img := writeImage(t, s, FormatFAT)
+
+			t.Run("mtools", func(t *testing.T) {
+				mdir := lookTool(t, "mdir")
+				mtype := lookTool(t, "mtype")
+				out := runTool(t, mdir, "-i", img, "::")
+				assert.Contains(t, out, "Volume in drive : is CIDATA")
+				for file, content := range seedFiles(s) {
+					assert.Contains(t, out, file)
+					assert.Equal(t, content, runTool(t, mtype, "-i", img, "::"+file), file)
+				}
+			})
+
+			t.Run("fsck.fat", func(t *testing.T) {
+				fsck := lookTool(t, "fsck.fat")
+				runTool(t, fsck, "-n", img)
+			})
+		})
+	}
+}
+
+// toolSeeds returns the seeds the external tools check: a typical one and
+// one large enough to need bigger FAT clusters.
+func toolSeeds() map[string]*Seed {
+	large := testSeed()
+	large.UserData = bytes.Repeat([]byte("#cloud-config padding line\n"), 200000)
+	return map[string]*Seed{"small": testSeed(), "large": large}
+}
+
+// seedFiles returns the seed's files by name.
+func seedFiles(s *Seed) map[string]string {
+	files := make(map[string]string)
+	for _, f := range s.Files() {
+		files[f.Name] = string(f.Data)
+	}
+	return files
+}
+
+// writeImage builds the seed in format and returns the image's path.
+func writeImage(t *testing.T, s *Seed, format Format) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "seed."+string(format))
+	require.NoError(t, s.WriteFile(path, format))
+	return path
+}
+
+// lookTool returns the path of an external tool, skipping the test when it
+// is not installed.
+func lookTool(t *testing.T, name string) string {
+	t.Helper()
+	path, err := exec.LookPath(name)
+	if err != nil {
+		t.Skipf("%s not installed", name)
+	}
+	return path
+}
+
+// runTool runs an external tool and returns its output.
+func runTool(t *testing.T, name string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command(name, args...).CombinedOutput()
+	require.NoError(t, err, "%s: %s", filepath.Base(name), out)
+	return string(out)
+}
+
+// extractDir returns a directory to extract an image into. Extracting can
+// make it read-only, so write access is restored before it is removed.
+func extractDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chmod(dir, 0755) })
+	return dir
+}
+
+// readDir reads the regular files in dir by name.
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		files[e.Name()] = string(data)
+	}
+	return files
+}
+
+// readISO reads the volume label and root files of an ISO image through
+// its Joliet volume descriptor.
+func readISO(t *testing.T, img []byte) (string, map[string]string) {
+	t.Helper()
+	require.Zero(t, len(img)%isoSectorSize)
+
+	var svd []byte
+	for n := 16; ; n++ {
+		vd := img[n*isoSectorSize : (n+1)*isoSectorSize]
+		require.Equal(t, "CD001", string(vd[1:6]))
+		if vd[0] == 255 {
+			break
+		}
+		if vd[0] == 2 && string(vd[88:91]) == "%/E" {
+			svd = vd
+		}
+	}
+	require.NotNil(t, svd, "no Joliet volume descriptor")
+	assert.Equal(t, uint32(len(img)/isoSectorSize), binary.LittleEndian.Uint32(svd[80:]))
+
+	label := strings.TrimRight(decodeUCS2BE(svd[40:72]), " ")
+	root := binary.LittleEndian.Uint32(svd[156+2:])
+	rootSize := binary.LittleEndian.Uint32(svd[156+10:])
+	dir := img[int(root)*isoSectorSize : int(root)*isoSectorSize+int(rootSize)]
+
+	files := make(map[string]string)
+	for off := 0; off < len(dir) && dir[off] != 0; off += int(dir[off]) {
+		rec := dir[off:]
+		nameLen := int(rec[32])
+		if rec[25]&2 != 0 {
+			continue // "." and ".."
+		}
+		extent := binary.LittleEndian.Uint32(rec[2:])
+		size := binary.LittleEndian.Uint32(rec[10:])
+		name := decodeUCS2BE(rec[33 : 33+nameLen])
+		files[name] = string(img[int(extent)*isoSectorSize : int(extent)*isoSectorSize+int(size)])
+	}
+	return label, files
+}
+
+// readFAT reads the volume label and root files of a FAT12 image,
+// joining VFAT long names.
+func readFAT(t *testing.T, img []byte) (string, map[string]string) {
+	t.Helper()
+	require.Equal(t, []byte{0x55, 0xAA}, img[510:512])
+
+	sectorSize := int(binary.LittleEndian.Uint16(img[11:]))
+	spc := int(img[13])
+	reserved := int(binary.LittleEndian.Uint16(img[14:]))
+	numFATs := int(img[16])
+	rootEntries := int(binary.LittleEndian.Uint16(img[17:]))
+	fatSize := int(binary.LittleEndian.Uint16(img[22:]))
+	assert.Equal(t, "FAT12   ", string(img[54:62]))
+
+	fat := img[reserved*sectorSize:]
+	rootStart := (reserved + numFATs*fatSize) * sectorSize
+	dataStart := rootStart + rootEntries*32
+	next := func(c int) int {
+		v := int(binary.LittleEndian.Uint16(fat[c*3/2:]))
+		if c%2 == 0 {
+			return v & 0xFFF
+		}
+		return v >> 4
+	}
+
+	var label string
+	files := make(map[string]string)
+	var long []uint16
+	for off := rootStart; off < dataStart; off += 32 {
+		e := img[off : off+32]
+		if e[0] == 0 {
+			break
+		}
+		switch {
+		case e[11] == attrLongName:
+			var part []uint16
+			for _, r := range [][2]int{{1, 11}, {14, 26}, {28, 32}} {
+				for i := r[0]; i < r[1]; i += 2 {
+					part = append(part, binary.LittleEndian.Uint16(e[i:]))
+				}
+			}
+			long = append(part, long...) // Stored last part first
+		case e[11]&attrVolumeID != 0:
+			label = strings.TrimRight(string(e[0:11]), " ")
+		default:
+			name := strings.TrimRight(string(e[0:11]), " ")
+			if long != nil {
+				end := 0
+				for end < len(long) && long[end] != 0 && long[end] != 0xFFFF {
+					end++
+				}
+				name = string(utf16.Decode(long[:end]))
+				long = nil
+			}
+
+			size := int(binary.LittleEndian.Uint32(e[28:]))
+			var data []byte
+			for c := int(binary.LittleEndian.Uint16(e[26:])); c >= 2 && c < 0xFF8; c = next(c) {
+				start := dataStart + (c-2)*spc*sectorSize
+				data = append(data, img[start:start+spc*sectorSize]...)
+			}
+			files[name] = string(data[:size])
+		}
+	}
+	return label, files
+}
+
+func decodeUCS2BE(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
@@ -90,6 +90,27 @@ func TestSettings_FindCloudImage_NotFound(t *testing.T) {
 	assert.Nil(t, found)
 }
 
+func TestSettings_DefaultCloudImage(t *testing.T) {
+	s := NewSettings()
+	assert.Nil(t, s.DefaultCloudImage())
+	assert.Empty(t, s.CloudImagesDefaultFirst())
+
+	s.AddCloudImage(CloudImage{ID: "a"})
+	s.AddCloudImage(CloudImage{ID: "b"})
+	s.AddCloudImage(CloudImage{ID: "c"})
+
+	// Falls back to the first image
+	assert.Equal(t, "a", s.DefaultCloudImage().ID)
+
+	s.AppSettings.DefaultCloudImage = "b"
+	assert.Equal(t, "b", s.DefaultCloudImage().ID)
+	ids := []string{}
+	for _, img := range s.CloudImagesDefaultFirst() {
+		ids = append(ids, img.ID)
+	}
+	assert.Equal(t, []string{"b", "a", "c"}, ids)
+}
+
 func TestSettings_VMConfig(t *testing.T) {
 	settings := NewSettings()
 
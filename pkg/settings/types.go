@@ -29,6 +29,7 @@ type CloudImage struct {
 	Size     int64     `json:"size"`               // File size in bytes
 	AddedAt  time.Time `json:"added_at"`           // When added
 	Verified bool      `json:"verified,omitempty"` // Checksum verified
+	Signed   bool      `json:"signed,omitempty"`   // Checksum came from a signed SHA256SUMS
 }
 
 // VMConfig represents a saved VM configuration.
@@ -86,9 +87,10 @@ type PackagePreset struct {
 
 // AppSettings represents ucli application preferences.
 type AppSettings struct {
-	TerraformDir  string `json:"terraform_dir,omitempty"`
-	DefaultTarget string `json:"default_target,omitempty"` // "terraform" or "multipass"
-	AutoApprove   bool   `json:"auto_approve"`
+	TerraformDir      string `json:"terraform_dir,omitempty"`
+	DefaultTarget     string `json:"default_target,omitempty"` // "terraform" or "multipass"
+	AutoApprove       bool   `json:"auto_approve"`
+	DefaultCloudImage string `json:"default_cloud_image,omitempty"` // Cloud image ID preselected for new VMs
 }
 
 // DownloadState represents active downloads state.
@@ -108,14 +110,22 @@ const (
 
 // Download represents an active or completed download.
 type Download struct {
-	ID         string         `json:"id"`
-	URL        string         `json:"url"`
-	DestPath   string         `json:"dest_path"`
-	TotalBytes int64          `json:"total_bytes"`
-	Downloaded int64          `json:"downloaded"`
-	StartedAt  time.Time      `json:"started_at"`
-	Status     DownloadStatus `json:"status"`
-	Error      string         `json:"error,omitempty"`
+	ID           string         `json:"id"`
+	URL          string         `json:"url"`
+	DestPath     string         `json:"dest_path"`
+	TotalBytes   int64          `json:"total_bytes"`
+	Downloaded   int64          `json:"downloaded"`
+	StartedAt    time.Time      `json:"started_at"`
+	Status       DownloadStatus `json:"status"`
+	Error        string         `json:"error,omitempty"`
+	SHA256       string         `json:"sha256,omitempty"`        // Expected checksum
+	ETag         string         `json:"etag,omitempty"`          // Validators of the remote file,
+	LastModified string         `json:"last_modified,omitempty"` // checked before resuming
+
+	// Cloud image registered once the download completes (empty for plain files)
+	Version string `json:"version,omitempty"`
+	Arch    string `json:"arch,omitempty"`
+	Signed  bool   `json:"signed,omitempty"` // SHA256 came from a signed SHA256SUMS
 }
 
 // NewSettings creates a new Settings with defaults.
@@ -146,6 +156,33 @@ func (s *Settings) FindCloudImage(id string) *CloudImage {
 	return nil
 }
 
+// DefaultCloudImage returns the default cloud image, falling back to the
+// first registered image. Returns nil if there are no images.
+func (s *Settings) DefaultCloudImage() *CloudImage {
+	if img := s.FindCloudImage(s.AppSettings.DefaultCloudImage); img != nil {
+		return img
+	}
+	if len(s.CloudImages) > 0 {
+		return &s.CloudImages[0]
+	}
+	return nil
+}
+
+// CloudImagesDefaultFirst returns the cloud images with the default first.
+func (s *Settings) CloudImagesDefaultFirst() []CloudImage {
+	result := make([]CloudImage, 0, len(s.CloudImages))
+	def := s.DefaultCloudImage()
+	if def != nil {
+		result = append(result, *def)
+	}
+	for _, img := range s.CloudImages {
+		if def == nil || img.ID != def.ID {
+			result = append(result, img)
+		}
+	}
+	return result
+}
+
 // AddCloudImage adds a cloud image to the settings.
 // If an image with the same ID exists, it is replaced.
 func (s *Settings) AddCloudImage(img CloudImage) {
@@ -0,0 +1,207 @@
+package spec
+
+import (
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+)
+
+// ToFullConfig converts the spec into a config.FullConfig.
+// Unset values keep the defaults from config.NewFullConfig. allPackages is
+// used to calculate the disabled package list; when the spec lists no
+// packages, every package in allPackages is enabled.
+func (s *Spec) ToFullConfig(allPackages []string) *config.FullConfig {
+	cfg := config.NewFullConfig()
+	cfg.Username = s.User.Username
+	cfg.Hostname = s.User.Hostname
+	cfg.MachineName = s.User.DisplayName
+	cfg.SSHPublicKeys = s.SSHKeys
+
+	// Git
+	cfg.FullName = s.Git.Name
+	cfg.Email = s.Git.Email
+	setString(&cfg.GitDefaultBranch, s.Git.DefaultBranch)
+	setBool(&cfg.GitPushAutoSetupRemote, s.Git.PushAutoSetupRemote)
+	setBool(&cfg.GitPullRebase, s.Git.PullRebase)
+	setString(&cfg.GitPager, s.Git.Pager)
+	setBool(&cfg.GitURLRewriteGithub, s.Git.URLRewriteGithub)
+
+	// GitHub
+	cfg.GithubUser = s.GitHub.User
+	cfg.GithubPAT = s.GitHub.PAT
+
+	// Tailscale
+	cfg.TailscaleAuthKey = s.Tailscale.AuthKey
+	setBool(&cfg.TailscaleSSHEnabled, s.Tailscale.SSH)
+	setBool(&cfg.TailscaleExitNode, s.Tailscale.ExitNode)
+
+	// Docker
+	setBool(&cfg.DockerEnabled, s.Docker.Enabled)
+	setBool(&cfg.DockerAddToGroup, s.Docker.AddToGroup)
+	setBool(&cfg.DockerStartOnBoot, s.Docker.StartOnBoot)
+
+	// Repository
+	cfg.RepoURL = s.Repo.URL
+	setString(&cfg.RepoBranch, s.Repo.Branch)
+
+	cfg.EnabledPackages = s.Packages
+	if len(cfg.EnabledPackages) == 0 {
+		cfg.EnabledPackages = allPackages
+	}
+	cfg.DisabledPackages = config.CalculateDisabledPackages(allPackages, cfg.EnabledPackages)
+
+	// Bare-metal install
+	if s.Install != nil {
+		cfg.Install.PasswordHash = s.Install.PasswordHash
+		cfg.Install.LateCommands = s.Install.LateCommands
+		storage := &cfg.Install.Storage
+		setString(&storage.Layout, s.Install.Storage.Layout)
+		storage.Passphrase = s.Install.Storage.Passphrase
+		storage.Match = config.DiskMatch(s.Install.Storage.Match)
+	}
+
+	return cfg
+}
+
+func setString(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+func setBool(dst *bool, v *bool) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// MultipassOptions returns deploy options for the Multipass target,
+// filling unset values from deploy.DefaultMultipassOptions.
+func (s *Spec) MultipassOptions() deploy.MultipassOptions {
+	opts := deploy.DefaultMultipassOptions()
+	opts.VMName = s.Name
+	if mp := s.Multipass; mp != nil {
+		if mp.CPUs > 0 {
+			opts.CPUs = mp.CPUs
+		}
+		if mp.MemoryMB > 0 {
+			opts.MemoryMB = mp.MemoryMB
+		}
+		if mp.DiskGB > 0 {
+			opts.DiskGB = mp.DiskGB
+		}
+		setString(&opts.UbuntuVersion, mp.UbuntuVersion)
+		opts.KeepOnFailure = mp.KeepOnFailure
+	}
+	return opts
+}
+
+// TerragruntOptions returns deploy options for the Terragrunt target,
+// filling unset values from deploy.DefaultTerragruntOptions.
+func (s *Spec) TerragruntOptions() deploy.TerragruntOptions {
+	opts := deploy.DefaultTerragruntOptions()
+	opts.VMName = s.Name
+	if tg := s.Terragrunt; tg != nil {
+		if tg.CPUs > 0 {
+			opts.CPUs = tg.CPUs
+		}
+		if tg.MemoryMB > 0 {
+			opts.MemoryMB = tg.MemoryMB
+		}
+		if tg.DiskGB > 0 {
+			opts.DiskGB = tg.DiskGB
+		}
+		setString(&opts.LibvirtURI, tg.LibvirtURI)
+		setString(&opts.StoragePool, tg.StoragePool)
+		setString(&opts.NetworkName, tg.NetworkName)
+		setString(&opts.UbuntuImage, tg.UbuntuImage)
+		opts.Autostart = tg.Autostart
+		opts.Apply = tg.Apply
+		opts.AutoApprove = tg.AutoApprove
+		opts.KeepOnFailure = tg.KeepOnFailure
+	}
+	return opts
+}
+
+// FromSnapshot creates a spec from saved wizard data.
+func FromSnapshot(target deploy.DeploymentTarget, name string, snapshot *settings.WizardDataSnapshot) *Spec {
+	s := New(target)
+	s.Name = name
+	s.User = UserSpec{
+		Username:    snapshot.Username,
+		Hostname:    snapshot.Hostname,
+		DisplayName: snapshot.DisplayName,
+	}
+	s.SSHKeys = snapshot.SSHKeys
+	s.Packages = snapshot.Packages
+	s.Git.Name = snapshot.GitName
+	s.Git.Email = snapshot.GitEmail
+	s.GitHub.User = snapshot.GitHubUser
+
+	if opts := snapshot.TerragruntOpts; opts != nil {
+		s.Terragrunt = &TerragruntSpec{
+			CPUs:        opts.CPUs,
+			MemoryMB:    opts.MemoryMB,
+			DiskGB:      opts.DiskGB,
+			Autostart:   opts.Autostart,
+			UbuntuImage: opts.UbuntuImage,
+		}
+	}
+	if opts := snapshot.MultipassOpts; opts != nil {
+		s.Multipass = &MultipassSpec{
+			CPUs:          opts.CPUs,
+			MemoryMB:      opts.MemoryMB,
+			DiskGB:        opts.DiskGB,
+			UbuntuVersion: opts.UbuntuVersion,
+		}
+	}
+
+	return s
+}
+
+// FromVMConfig creates a spec from a saved VM configuration.
+// The config name becomes the VM name.
+func FromVMConfig(cfg *settings.VMConfig) *Spec {
+	target := deploy.DeploymentTarget(cfg.Target)
+	if !settings.IsValidTarget(cfg.Target) {
+		// Same fallback as the wizard uses for old configs
+		target = deploy.TargetTerragrunt
+	}
+	return FromSnapshot(target, cfg.Name, &cfg.Data)
+}
+
+// ToSnapshot converts the spec into wizard data that can be saved as a VMConfig.
+// Fields the wizard does not persist (secrets, git/tailscale/docker
+// preferences, libvirt settings) are dropped.
+func (s *Spec) ToSnapshot() settings.WizardDataSnapshot {
+	snapshot := settings.WizardDataSnapshot{
+		Username:    s.User.Username,
+		Hostname:    s.User.Hostname,
+		DisplayName: s.User.DisplayName,
+		GitName:     s.Git.Name,
+		GitEmail:    s.Git.Email,
+		GitHubUser:  s.GitHub.User,
+		SSHKeys:     s.SSHKeys,
+		Packages:    s.Packages,
+	}
+
+	if tg := s.Terragrunt; tg != nil {
+		snapshot.TerragruntOpts = &settings.TerragruntOptsSnapshot{
+			CPUs:        tg.CPUs,
+			MemoryMB:    tg.MemoryMB,
+			DiskGB:      tg.DiskGB,
+			Autostart:   tg.Autostart,
+			UbuntuImage: tg.UbuntuImage,
+		}
+	}
+	if mp := s.Multipass; mp != nil {
+		snapshot.MultipassOpts = &settings.MultipassOptsSnapshot{
+			CPUs:          mp.CPUs,
+			MemoryMB:      mp.MemoryMB,
+			DiskGB:        mp.DiskGB,
+			UbuntuVersion: mp.UbuntuVersion,
+		}
+	}
+
+	return snapshot
+}
@@ -0,0 +1,95 @@
+package spec
+
+import (
+	"fmt"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
+)
+
+// Expand returns one spec per machine of the fleet, each with its own name
+// and hostname and no fleet section. A spec without a fleet section is
+// returned as is.
+func (s *Spec) Expand() ([]*Spec, error) {
+	if s.Fleet == nil {
+		return []*Spec{s}, nil
+	}
+	f := s.Fleet
+
+	if deploy.DeploymentTarget(s.Target) == deploy.TargetConfigOnly {
+		return nil, fmt.Errorf("fleet: not supported for the config target")
+	}
+	if f.Parallel < 0 {
+		return nil, fmt.Errorf("fleet: parallel must be positive")
+	}
+	if _, err := fleet.ParsePolicy(f.OnFailure); err != nil {
+		return nil, fmt.Errorf("fleet: %w", err)
+	}
+
+	var machines []MachineSpec
+	switch {
+	case f.Count != 0 && len(f.Machines) > 0:
+		return nil, fmt.Errorf("fleet: set count or machines, not both")
+	case f.Count < 0:
+		return nil, fmt.Errorf("fleet: count must be positive")
+	case f.Count > 0:
+		if s.Name == "" {
+			return nil, fmt.Errorf("fleet: name is required with count")
+		}
+		hostname := s.User.Hostname
+		if hostname == "" {
+			hostname = s.Name
+		}
+		hostnames := fleet.Names(hostname, f.Count)
+		for i, name := range fleet.Names(s.Name, f.Count) {
+			machines = append(machines, MachineSpec{Name: name, Hostname: hostnames[i]})
+		}
+	case len(f.Machines) > 0:
+		machines = f.Machines
+	default:
+		return nil, fmt.Errorf("fleet: count or machines is required")
+	}
+
+	if len(machines) > fleet.MaxMachines {
+		return nil, fmt.Errorf("fleet: at most %d machines (got %d)", fleet.MaxMachines, len(machines))
+	}
+
+	seen := make(map[string]bool)
+	specs := make([]*Spec, 0, len(machines))
+	for i, m := range machines {
+		if m.Name == "" {
+			return nil, fmt.Errorf("fleet: machines[%d]: name is required", i)
+		}
+		if seen[m.Name] {
+			return nil, fmt.Errorf("fleet: duplicate machine name %q", m.Name)
+		}
+		seen[m.Name] = true
+
+		c := *s
+		c.Fleet = nil
+		c.Name = m.Name
+		c.User.Hostname = m.Hostname
+		if c.User.Hostname == "" {
+			c.User.Hostname = m.Name
+		}
+		specs = append(specs, &c)
+	}
+	return specs, nil
+}
+
+// FleetOptions returns how the fleet's machines are deployed.
+func (s *Spec) FleetOptions() (fleet.Options, error) {
+	opts := fleet.Options{Parallel: fleet.DefaultParallel, Policy: fleet.PolicyContinue}
+	if s.Fleet == nil {
+		return opts, nil
+	}
+	if s.Fleet.Parallel > 0 {
+		opts.Parallel = s.Fleet.Parallel
+	}
+	policy, err := fleet.ParsePolicy(s.Fleet.OnFailure)
+	if err != nil {
+		return opts, fmt.Errorf("fleet: %w", err)
+	}
+	opts.Policy = policy
+	return opts, nil
+}
@@ -0,0 +1,260 @@
+// Package spec defines the declarative machine spec file format (ucli.yaml)
+// used to create machines without the interactive wizard.
+package spec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+)
+
+// Version is the current spec schema version.
+const Version = 1
+
+// DefaultFileName is the conventional spec file name.
+const DefaultFileName = "ucli.yaml"
+
+// Spec describes a single machine to create.
+//
+// Optional booleans are pointers so that an unset value falls back to the
+// default from config.NewFullConfig instead of false.
+type Spec struct {
+	Version    int             `yaml:"version"`              // Schema version (must be Version)
+	Target     string          `yaml:"target"`               // "terragrunt", "multipass" or "config"
+	Name       string          `yaml:"name,omitempty"`       // VM name (auto-generated if empty)
+	User       UserSpec        `yaml:"user"`                 // User account on the machine
+	SSHKeys    []string        `yaml:"ssh_keys,omitempty"`   // Authorized SSH public keys
+	Packages   []string        `yaml:"packages,omitempty"`   // Enabled packages (all if empty)
+	Git        GitSpec         `yaml:"git,omitempty"`        // Git identity and preferences
+	GitHub     GitHubSpec      `yaml:"github,omitempty"`     // GitHub integration
+	Tailscale  TailscaleSpec   `yaml:"tailscale,omitempty"`  // Tailscale settings
+	Docker     DockerSpec      `yaml:"docker,omitempty"`     // Docker settings
+	Repo       RepoSpec        `yaml:"repo,omitempty"`       // Repository cloned on the machine
+	Multipass  *MultipassSpec  `yaml:"multipass,omitempty"`  // Multipass target options
+	Terragrunt *TerragruntSpec `yaml:"terragrunt,omitempty"` // Terragrunt target options
+	Output     *OutputSpec     `yaml:"output,omitempty"`     // Config-only target options
+	Install    *InstallSpec    `yaml:"install,omitempty"`    // Bare-metal install options (ucli iso build)
+	Fleet      *FleetSpec      `yaml:"fleet,omitempty"`      // Create several machines from this spec
+}
+
+// UserSpec describes the machine user.
+type UserSpec struct {
+	Username    string `yaml:"username"`
+	Hostname    string `yaml:"hostname"`
+	DisplayName string `yaml:"display_name,omitempty"`
+}
+
+// GitSpec describes the git identity and preferences.
+type GitSpec struct {
+	Name                string `yaml:"name,omitempty"`
+	Email               string `yaml:"email,omitempty"`
+	DefaultBranch       string `yaml:"default_branch,omitempty"`
+	PushAutoSetupRemote *bool  `yaml:"push_auto_setup_remote,omitempty"`
+	PullRebase          *bool  `yaml:"pull_rebase,omitempty"`
+	Pager               string `yaml:"pager,omitempty"`
+	URLRewriteGithub    *bool  `yaml:"url_rewrite_github,omitempty"`
+}
+
+// GitHubSpec describes the GitHub integration.
+type GitHubSpec struct {
+	User string `yaml:"user,omitempty"`
+	PAT  string `yaml:"pat,omitempty"`
+}
+
+// TailscaleSpec describes Tailscale settings.
+type TailscaleSpec struct {
+	AuthKey  string `yaml:"auth_key,omitempty"`
+	SSH      *bool  `yaml:"ssh,omitempty"`
+	ExitNode *bool  `yaml:"exit_node,omitempty"`
+}
+
+// DockerSpec describes Docker settings.
+type DockerSpec struct {
+	Enabled     *bool `yaml:"enabled,omitempty"`
+	AddToGroup  *bool `yaml:"add_to_group,omitempty"`
+	StartOnBoot *bool `yaml:"start_on_boot,omitempty"`
+}
+
+// RepoSpec describes the repository cloned on the machine.
+type RepoSpec struct {
+	URL    string `yaml:"url,omitempty"`
+	Branch string `yaml:"branch,omitempty"`
+}
+
+// MultipassSpec captures Multipass target options.
+type MultipassSpec struct {
+	CPUs          int    `yaml:"cpus,omitempty"`
+	MemoryMB      int    `yaml:"memory_mb,omitempty"`
+	DiskGB        int    `yaml:"disk_gb,omitempty"`
+	UbuntuVersion string `yaml:"ubuntu_version,omitempty"`
+	KeepOnFailure bool   `yaml:"keep_on_failure,omitempty"`
+}
+
+// TerragruntSpec captures Terragrunt target options.
+type TerragruntSpec struct {
+	CPUs          int    `yaml:"cpus,omitempty"`
+	MemoryMB      int    `yaml:"memory_mb,omitempty"`
+	DiskGB        int    `yaml:"disk_gb,omitempty"`
+	Autostart     bool   `yaml:"autostart,omitempty"`
+	LibvirtURI    string `yaml:"libvirt_uri,omitempty"`
+	StoragePool   string `yaml:"storage_pool,omitempty"`
+	NetworkName   string `yaml:"network_name,omitempty"`
+	UbuntuImage   string `yaml:"ubuntu_image,omitempty"`
+	Apply         bool   `yaml:"apply,omitempty"` // Run init/plan/apply after generating
+	AutoApprove   bool   `yaml:"auto_approve,omitempty"`
+	KeepOnFailure bool   `yaml:"keep_on_failure,omitempty"`
+}
+
+// OutputSpec captures config-only target options.
+type OutputSpec struct {
+	Dir       string `yaml:"dir,omitempty"`
+	CloudInit bool   `yaml:"cloud_init,omitempty"` // Also write cloud-init/cloud-init.yaml
+}
+
+// FleetSpec turns a spec into several identical machines, either count
+// machines named <name>-1 to <name>-<count> or the listed machines.
+type FleetSpec struct {
+	Count     int           `yaml:"count,omitempty"`
+	Machines  []MachineSpec `yaml:"machines,omitempty"`
+	Parallel  int           `yaml:"parallel,omitempty"`   // Machines deployed at once (default 3)
+	OnFailure string        `yaml:"on_failure,omitempty"` // "continue" (default), "abort" or "cleanup"
+}
+
+// MachineSpec names one machine of a fleet. The hostname defaults to the name.
+type MachineSpec struct {
+	Name     string `yaml:"name"`
+	Hostname string `yaml:"hostname,omitempty"`
+}
+
+// InstallSpec captures bare-metal install options for autoinstall ISOs.
+type InstallSpec struct {
+	PasswordHash string      `yaml:"password_hash,omitempty"` // crypt(3) hash, e.g. from mkpasswd -m sha-512
+	Storage      StorageSpec `yaml:"storage,omitempty"`
+	LateCommands []string    `yaml:"late_commands,omitempty"`
+}
+
+// StorageSpec describes the install disk layout.
+type StorageSpec struct {
+	Layout     string        `yaml:"layout,omitempty"`     // "lvm" (default) or "direct"
+	Passphrase string        `yaml:"passphrase,omitempty"` // Encrypts the LVM layout
+	Match      DiskMatchSpec `yaml:"match,omitempty"`      // Install disk (largest if empty)
+}
+
+// DiskMatchSpec selects the install disk. Serial and model accept globs.
+type DiskMatchSpec struct {
+	Serial string `yaml:"serial,omitempty"`
+	Model  string `yaml:"model,omitempty"`
+	Vendor string `yaml:"vendor,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+	Size   string `yaml:"size,omitempty"` // "largest" or "smallest"
+	SSD    *bool  `yaml:"ssd,omitempty"`
+}
+
+// New creates an empty spec at the current schema version.
+func New(target deploy.DeploymentTarget) *Spec {
+	return &Spec{
+		Version: Version,
+		Target:  string(target),
+	}
+}
+
+// Load reads a spec from a YAML file.
+func Load(path string) (*Spec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spec file: %w", err)
+	}
+	defer f.Close()
+
+	s, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse decodes a spec from YAML.
+// Unknown fields are rejected so typos do not silently fall back to defaults.
+func Parse(r io.Reader) (*Spec, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("spec is empty")
+		}
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	// Only a single document is allowed
+	var extra yaml.Node
+	if err := dec.Decode(&extra); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("spec must contain a single YAML document")
+	}
+
+	if err := s.checkVersion(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// checkVersion verifies the spec's schema version is supported.
+func (s *Spec) checkVersion() error {
+	switch {
+	case s.Version == 0:
+		return fmt.Errorf("version is required (current version: %d)", Version)
+	case s.Version > Version:
+		return fmt.Errorf("unsupported spec version %d (this ucli supports up to %d)", s.Version, Version)
+	case s.Version < 0:
+		return fmt.Errorf("invalid spec version %d", s.Version)
+	}
+	return nil
+}
+
+// Marshal encodes the spec as YAML.
+func (s *Spec) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(s); err != nil {
+		return nil, fmt.Errorf("failed to marshal spec: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal spec: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Save writes the spec to path.
+// The file may contain secrets (GitHub PAT, Tailscale key, disk
+// passphrase), so it is only readable by the owner.
+func (s *Spec) Save(path string) error {
+	data, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write spec file: %w", err)
+	}
+	return nil
+}
+
+// DeploymentTarget returns the spec's target as a deploy.DeploymentTarget.
+func (s *Spec) DeploymentTarget() (deploy.DeploymentTarget, error) {
+	switch t := deploy.DeploymentTarget(s.Target); t {
+	case deploy.TargetTerragrunt, deploy.TargetMultipass, deploy.TargetConfigOnly:
+		return t, nil
+	case "":
+		return "", fmt.Errorf("target is required (one of: terragrunt, multipass, config)")
+	default:
+		return "", fmt.Errorf("unknown target %q (one of: terragrunt, multipass, config)", s.Target)
+	}
+}
@@ -0,0 +1,430 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/deploy"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/fleet"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/settings"
+)
+
+const sampleSpec = `version: 1
+target: multipass
+name: dev-vm
+user:
+  username: alice
+  hostname: devbox
+  display_name: Dev Box
+ssh_keys:
+  - ssh-ed25519 AAAA alice@laptop
+git:
+  name: Alice
+  email: alice@example.com
+packages:
+  - lazygit
+multipass:
+  cpus: 4
+  memory_mb: 8192
+`
+
+func TestParse(t *testing.T) {
+	s, err := Parse(strings.NewReader(sampleSpec))
+	require.NoError(t, err)
+
+	assert.Equal(t, "multipass", s.Target)
+	assert.Equal(t, "dev-vm", s.Name)
+	assert.Equal(t, "alice", s.User.Username)
+	assert.Equal(t, "devbox", s.User.Hostname)
+	assert.Equal(t, []string{"ssh-ed25519 AAAA alice@laptop"}, s.SSHKeys)
+	require.NotNil(t, s.Multipass)
+	assert.Equal(t, 4, s.Multipass.CPUs)
+	assert.Nil(t, s.Terragrunt)
+}
+
+func TestParse_Empty(t *testing.T) {
+	_, err := Parse(strings.NewReader(""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty")
+}
+
+func TestParse_Strict(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name:    "unknown top-level field",
+			yaml:    "version: 1\ntarget: config\nusername: alice\n",
+			wantErr: "field username not found",
+		},
+		{
+			name:    "unknown nested field",
+			yaml:    "version: 1\ntarget: config\ngit:\n  emial: a@b.c\n",
+			wantErr: "field emial not found",
+		},
+		{
+			name:    "missing version",
+			yaml:    "target: config\n",
+			wantErr: "version is required",
+		},
+		{
+			name:    "future version",
+			yaml:    "version: 99\ntarget: config\n",
+			wantErr: "unsupported spec version 99",
+		},
+		{
+			name:    "wrong type",
+			yaml:    "version: 1\ntarget: config\nmultipass:\n  cpus: many\n",
+			wantErr: "cannot unmarshal",
+		},
+		{
+			name:    "multiple documents",
+			yaml:    "version: 1\ntarget: config\n---\nversion: 1\n",
+			wantErr: "single YAML document",
+		},
+		{
+			name:    "unsupported tailscale field",
+			yaml:    "version: 1\ntarget: config\ntailscale:\n  ssh_check_mode: false\n",
+			wantErr: "field ssh_check_mode not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.yaml))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ucli.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(sampleSpec), 0644))
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", s.User.Username)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestDeploymentTarget(t *testing.T) {
+	tests := []struct {
+		target  string
+		want    deploy.DeploymentTarget
+		wantErr bool
+	}{
+		{"terragrunt", deploy.TargetTerragrunt, false},
+		{"multipass", deploy.TargetMultipass, false},
+		{"config", deploy.TargetConfigOnly, false},
+		{"", "", true},
+		{"aws", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			s := &Spec{Target: tt.target}
+			got, err := s.DeploymentTarget()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToFullConfig(t *testing.T) {
+	s, err := Parse(strings.NewReader(sampleSpec))
+	require.NoError(t, err)
+
+	cfg := s.ToFullConfig([]string{"lazygit", "fzf", "bat"})
+
+	assert.Equal(t, "alice", cfg.Username)
+	assert.Equal(t, "devbox", cfg.Hostname)
+	assert.Equal(t, "Dev Box", cfg.MachineName)
+	assert.Equal(t, "Alice", cfg.FullName)
+	assert.Equal(t, "alice@example.com", cfg.Email)
+	assert.Equal(t, []string{"lazygit"}, cfg.EnabledPackages)
+	assert.ElementsMatch(t, []string{"fzf", "bat"}, cfg.DisabledPackages)
+}
+
+func TestToFullConfig_Defaults(t *testing.T) {
+	s := &Spec{Version: Version, User: UserSpec{Username: "u", Hostname: "h"}}
+
+	cfg := s.ToFullConfig(nil)
+	defaults := config.NewFullConfig()
+	defaults.Username = "u"
+	defaults.Hostname = "h"
+
+	assert.Equal(t, defaults, cfg)
+}
+
+func TestToFullConfig_Overrides(t *testing.T) {
+	yamlSpec := `version: 1
+target: config
+user:
+  username: u
+  hostname: h
+git:
+  default_branch: trunk
+  pull_rebase: false
+  pager: less
+tailscale:
+  auth_key: tskey-123
+  exit_node: false
+docker:
+  enabled: false
+repo:
+  url: https://github.com/example/dotfiles
+  branch: dev
+`
+	s, err := Parse(strings.NewReader(yamlSpec))
+	require.NoError(t, err)
+
+	cfg := s.ToFullConfig(nil)
+
+	assert.Equal(t, "trunk", cfg.GitDefaultBranch)
+	assert.False(t, cfg.GitPullRebase)
+	assert.True(t, cfg.GitPushAutoSetupRemote, "unset bool keeps default")
+	assert.Equal(t, "less", cfg.GitPager)
+	assert.Equal(t, "tskey-123", cfg.TailscaleAuthKey)
+	assert.False(t, cfg.TailscaleExitNode)
+	assert.True(t, cfg.TailscaleSSHEnabled)
+	assert.False(t, cfg.DockerEnabled)
+	assert.True(t, cfg.DockerAddToGroup)
+	assert.Equal(t, "https://github.com/example/dotfiles", cfg.RepoURL)
+	assert.Equal(t, "dev", cfg.RepoBranch)
+}
+
+func TestToFullConfig_Install(t *testing.T) {
+	yamlSpec := `version: 1
+target: config
+user:
+  username: u
+  hostname: h
+install:
+  password_hash: $6$salt$hash
+  storage:
+    passphrase: secret
+    match:
+      model: Samsung*
+      size: largest
+      ssd: true
+  late_commands:
+    - echo done
+`
+	s, err := Parse(strings.NewReader(yamlSpec))
+	require.NoError(t, err)
+
+	cfg := s.ToFullConfig(nil)
+
+	assert.Equal(t, "$6$salt$hash", cfg.Install.PasswordHash)
+	assert.Equal(t, []string{"echo done"}, cfg.Install.LateCommands)
+	assert.Equal(t, config.StorageLVM, cfg.Install.Storage.Layout, "unset layout keeps default")
+	assert.Equal(t, "secret", cfg.Install.Storage.Passphrase)
+	assert.Equal(t, "Samsung*", cfg.Install.Storage.Match.Model)
+	assert.Equal(t, config.DiskLargest, cfg.Install.Storage.Match.Size)
+	require.NotNil(t, cfg.Install.Storage.Match.SSD)
+	assert.True(t, *cfg.Install.Storage.Match.SSD)
+}
+
+func TestToFullConfig_AllPackagesByDefault(t *testing.T) {
+	s := &Spec{User: UserSpec{Username: "u", Hostname: "h"}}
+
+	cfg := s.ToFullConfig([]string{"lazygit", "fzf"})
+
+	assert.Equal(t, []string{"lazygit", "fzf"}, cfg.EnabledPackages)
+	assert.Empty(t, cfg.DisabledPackages)
+}
+
+func TestMultipassOptions(t *testing.T) {
+	s, err := Parse(strings.NewReader(sampleSpec))
+	require.NoError(t, err)
+
+	opts := s.MultipassOptions()
+	defaults := deploy.DefaultMultipassOptions()
+
+	assert.Equal(t, "dev-vm", opts.VMName)
+	assert.Equal(t, 4, opts.CPUs)
+	assert.Equal(t, 8192, opts.MemoryMB)
+	assert.Equal(t, defaults.DiskGB, opts.DiskGB)
+	assert.Equal(t, defaults.UbuntuVersion, opts.UbuntuVersion)
+}
+
+func TestTerragruntOptions_Defaults(t *testing.T) {
+	s := &Spec{Target: "terragrunt", Name: "tg-vm"}
+
+	opts := s.TerragruntOptions()
+	defaults := deploy.DefaultTerragruntOptions()
+
+	assert.Equal(t, "tg-vm", opts.VMName)
+	assert.Equal(t, defaults.CPUs, opts.CPUs)
+	assert.Equal(t, defaults.StoragePool, opts.StoragePool)
+	assert.Equal(t, defaults.NetworkName, opts.NetworkName)
+	assert.False(t, opts.Apply)
+}
+
+func TestTerragruntOptions_Apply(t *testing.T) {
+	s, err := Parse(strings.NewReader("version: 1\ntarget: terragrunt\nterragrunt:\n  apply: true\n  auto_approve: true\n"))
+	require.NoError(t, err)
+
+	opts := s.TerragruntOptions()
+	assert.True(t, opts.Apply)
+	assert.True(t, opts.AutoApprove)
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snapshot := &settings.WizardDataSnapshot{
+		Username:    "alice",
+		Hostname:    "devbox",
+		DisplayName: "Dev Box",
+		GitName:     "Alice",
+		GitEmail:    "alice@example.com",
+		GitHubUser:  "alice",
+		SSHKeys:     []string{"ssh-ed25519 AAAA alice@laptop"},
+		Packages:    []string{"lazygit", "fzf"},
+		TerragruntOpts: &settings.TerragruntOptsSnapshot{
+			CPUs:        4,
+			MemoryMB:    8192,
+			DiskGB:      40,
+			Autostart:   true,
+			UbuntuImage: "/var/lib/libvirt/images/noble.img",
+		},
+	}
+
+	s := FromSnapshot(deploy.TargetTerragrunt, "dev-vm", snapshot)
+	data, err := s.Marshal()
+	require.NoError(t, err)
+
+	parsed, err := Parse(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, Version, parsed.Version)
+	assert.Equal(t, "terragrunt", parsed.Target)
+	assert.Equal(t, "dev-vm", parsed.Name)
+	assert.Equal(t, *snapshot, parsed.ToSnapshot())
+}
+
+func TestFromVMConfig(t *testing.T) {
+	cfg := &settings.VMConfig{
+		Name:   "saved",
+		Target: "usb",
+		Data: settings.WizardDataSnapshot{
+			Username:      "u",
+			Hostname:      "h",
+			MultipassOpts: &settings.MultipassOptsSnapshot{CPUs: 2, UbuntuVersion: "22.04"},
+		},
+	}
+
+	s := FromVMConfig(cfg)
+
+	assert.Equal(t, "terragrunt", s.Target, "unknown targets fall back to terragrunt")
+	assert.Equal(t, "saved", s.Name)
+	require.NotNil(t, s.Multipass)
+	assert.Equal(t, "22.04", s.Multipass.UbuntuVersion)
+}
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFileName)
+	s := New(deploy.TargetConfigOnly)
+	s.User = UserSpec{Username: "u", Hostname: "h"}
+	s.GitHub.PAT = "secret"
+
+	require.NoError(t, s.Save(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, s, loaded)
+}
+
+func TestExpand_Count(t *testing.T) {
+	s, err := Parse(strings.NewReader(sampleSpec + "fleet:\n  count: 3\n  parallel: 2\n  on_failure: abort\n"))
+	require.NoError(t, err)
+
+	specs, err := s.Expand()
+	require.NoError(t, err)
+	require.Len(t, specs, 3)
+	for i, c := range specs {
+		assert.Equal(t, fmt.Sprintf("dev-vm-%d", i+1), c.Name)
+		assert.Equal(t, fmt.Sprintf("devbox-%d", i+1), c.User.Hostname)
+		assert.Nil(t, c.Fleet)
+		assert.Equal(t, 4, c.Multipass.CPUs)
+	}
+	assert.Equal(t, "dev-vm", s.Name, "the original spec is not changed")
+
+	opts, err := s.FleetOptions()
+	require.NoError(t, err)
+	assert.Equal(t, 2, opts.Parallel)
+	assert.Equal(t, fleet.PolicyAbort, opts.Policy)
+}
+
+func TestExpand_Machines(t *testing.T) {
+	s := &Spec{Target: "terragrunt", Fleet: &FleetSpec{Machines: []MachineSpec{
+		{Name: "web", Hostname: "web.lan"},
+		{Name: "db"},
+	}}}
+
+	specs, err := s.Expand()
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	assert.Equal(t, "web.lan", specs[0].User.Hostname)
+	assert.Equal(t, "db", specs[1].Name)
+	assert.Equal(t, "db", specs[1].User.Hostname)
+
+	opts, err := s.FleetOptions()
+	require.NoError(t, err)
+	assert.Equal(t, fleet.DefaultParallel, opts.Parallel)
+	assert.Equal(t, fleet.PolicyContinue, opts.Policy)
+}
+
+func TestExpand_NoFleet(t *testing.T) {
+	s := &Spec{Target: "multipass", Name: "dev"}
+
+	specs, err := s.Expand()
+	require.NoError(t, err)
+	assert.Equal(t, []*Spec{s}, specs)
+}
+
+func TestExpand_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  Spec
+		error string
+	}{
+		{"config target", Spec{Target: "config", Name: "a", Fleet: &FleetSpec{Count: 2}}, "config target"},
+		{"both", Spec{Target: "multipass", Name: "a", Fleet: &FleetSpec{Count: 2, Machines: []MachineSpec{{Name: "b"}}}}, "not both"},
+		{"neither", Spec{Target: "multipass", Fleet: &FleetSpec{Parallel: 2}}, "count or machines is required"},
+		{"count without name", Spec{Target: "multipass", Fleet: &FleetSpec{Count: 2}}, "name is required"},
+		{"negative count", Spec{Target: "multipass", Name: "a", Fleet: &FleetSpec{Count: -1}}, "count must be positive"},
+		{"too many", Spec{Target: "multipass", Name: "a", Fleet: &FleetSpec{Count: fleet.MaxMachines + 1}}, "at most"},
+		{"missing name", Spec{Target: "multipass", Fleet: &FleetSpec{Machines: []MachineSpec{{Hostname: "h"}}}}, "machines[0]: name is required"},
+		{"duplicate", Spec{Target: "multipass", Fleet: &FleetSpec{Machines: []MachineSpec{{Name: "a"}, {Name: "a"}}}}, `duplicate machine name "a"`},
+		{"policy", Spec{Target: "multipass", Name: "a", Fleet: &FleetSpec{Count: 2, OnFailure: "retry"}}, "unknown failure policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.spec.Expand()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.error)
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/generator"
+)
+
+// cloudConfigKeys are the top-level keys handled by cloud-init's cloud-config modules.
+var cloudConfigKeys = map[string]bool{
+	"ansible": true, "apk_repos": true, "apt": true, "apt_pipelining": true,
+	"autoinstall": true, "bootcmd": true, "byobu_by_default": true,
+	"ca_certs": true, "chef": true, "chpasswd": true, "cloud_config_modules": true,
+	"cloud_final_modules": true, "cloud_init_modules": true, "create_hostname_file": true,
+	"datasource": true, "device_aliases": true, "disable_ec2_metadata": true,
+	"disable_root": true, "disable_root_opts": true, "disk_setup": true,
+	"drivers": true, "fan": true, "final_message": true, "fqdn": true,
+	"fs_setup": true, "groups": true, "growpart": true, "hostname": true,
+	"keyboard": true, "landscape": true, "locale": true, "locale_configfile": true,
+	"lxd": true, "manage_etc_hosts": true, "manage_resolv_conf": true,
+	"mcollective": true, "merge_how": true, "merge_type": true, "mounts": true,
+	"mount_default_fields": true, "network": true, "no_ssh_fingerprints": true,
+	"ntp": true, "output": true, "package_reboot_if_required": true,
+	"package_update": true, "package_upgrade": true, "packages": true,
+	"password": true, "phone_home": true, "power_state": true,
+	"prefer_fqdn_over_hostname": true, "preserve_hostname": true, "puppet": true,
+	"random_seed": true, "reporting": true, "resize_rootfs": true,
+	"resolv_conf": true, "rh_subscription": true, "rsyslog": true, "runcmd": true,
+	"salt_minion": true, "seed_random": true, "snap": true, "spacewalk": true,
+	"ssh": true, "ssh_authorized_keys": true, "ssh_deletekeys": true,
+	"ssh_fp_console_blacklist": true, "ssh_genkeytypes": true, "ssh_import_id": true,
+	"ssh_key_console_blacklist": true, "ssh_keys": true, "ssh_publish_hostkeys": true,
+	"ssh_pwauth": true, "ssh_quiet_keygen": true, "swap": true, "timezone": true,
+	"ubuntu_advantage": true, "ubuntu_pro": true, "updates": true, "user": true,
+	"users": true, "vendor_data": true, "wireguard": true, "write_files": true,
+	"yum_repo_dir": true, "yum_repos": true, "zypper": true,
+}
+
+// templateVars are the placeholders used by cloud-init.template.yaml.
+var templateVars = map[string]bool{
+	"USERNAME": true, "HOSTNAME": true, "SSH_PUBLIC_KEY": true, "SSH_PUBLIC_KEYS": true,
+	"USER_NAME": true, "USER_EMAIL": true, "MACHINE_USER_NAME": true,
+	"TAILSCALE_AUTH_KEY": true, "GITHUB_USER": true, "GITHUB_PAT": true,
+	"REPO_URL": true, "REPO_BRANCH": true, "DISABLED_PACKAGE_EXPORTS": true,
+	"PACKAGE_SCRIPTS_COPY": true, "PACKAGE_SCRIPTS_INSTALL": true, "USER_GROUPS": true,
+	"GIT_DEFAULT_BRANCH": true, "GIT_PUSH_AUTO_SETUP_REMOTE": true, "GIT_PULL_REBASE": true,
+	"GIT_PAGER": true, "GIT_URL_REWRITE_GITHUB": true,
+	"TAILSCALE_SSH_ENABLED": true, "TAILSCALE_EXIT_NODE_ADVERTISE": true,
+	"DOCKER_ENABLED": true, "DOCKER_ADD_TO_GROUP": true, "DOCKER_START_ON_BOOT": true,
+}
+
+var (
+	placeholderRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	permissionsRegex = regexp.MustCompile(`^0?[0-7]{3,4}$`)
+)
+
+// ValidateCloudInit validates a cloud-init user-data file such as the
+// cloud-init.yaml written by generator.Generate.
+func ValidateCloudInit(path string) []Issue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []Issue{{
+			File:     path,
+			Message:  fmt.Sprintf("failed to read file: %v", err),
+			Severity: SeverityError,
+		}}
+	}
+	return ValidateCloudInitData(path, data)
+}
+
+// ValidateCloudInitData validates cloud-init user-data. file is only used
+// to label the returned issues.
+//
+// It checks the #cloud-config header, that top-level keys are known to
+// cloud-init, the shape of users, write_files and runcmd, and that no ${...}
+// placeholders were left unsubstituted. Inside shell content (write_files
+// content, runcmd, bootcmd) only template variable names are reported, since
+// ${VAR} is ordinary shell syntax there. Overlay package scripts under
+// generator.PackageScriptsDir are shipped verbatim and not checked.
+func ValidateCloudInitData(file string, data []byte) []Issue {
+	c := &cloudInitChecker{file: file, issues: []Issue{}}
+	c.check(data)
+	return c.issues
+}
+
+// CheckCloudInit validates a cloud-init file for a deployment. It returns
+// warnings as printable strings and an error listing all error-level issues.
+func CheckCloudInit(path string) ([]string, error) {
+	var warnings, errs []string
+	for _, issue := range ValidateCloudInit(path) {
+		if issue.Severity == SeverityError {
+			errs = append(errs, issue.String())
+		} else {
+			warnings = append(warnings, "Warning: "+issue.String())
+		}
+	}
+
+	if len(errs) > 0 {
+		return warnings, fmt.Errorf("invalid cloud-init user-data:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return warnings, nil
+}
+
+// cloudInitChecker collects issues while walking a cloud-config document.
+type cloudInitChecker struct {
+	file   string
+	issues []Issue
+}
+
+func (c *cloudInitChecker) add(severity Severity, field string, node *yaml.Node, format string, args ...any) {
+	issue := Issue{
+		File:     c.file,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+		Severity: severity,
+	}
+	if node != nil {
+		issue.Line = node.Line
+	}
+	c.issues = append(c.issues, issue)
+}
+
+func (c *cloudInitChecker) check(data []byte) {
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	if strings.TrimRight(firstLine, " \t\r") != "#cloud-config" {
+		c.issues = append(c.issues, Issue{
+			File:     c.file,
+			Line:     1,
+			Message:  "first line must be #cloud-config",
+			Severity: SeverityError,
+		})
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		c.add(SeverityError, "", nil, "invalid YAML: %v", err)
+		return
+	}
+	if len(doc.Content) == 0 {
+		c.add(SeverityError, "", nil, "document is empty")
+		return
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		c.add(SeverityError, "", root, "top level must be a mapping")
+		return
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "users":
+			c.checkUsers(value)
+		case "write_files":
+			c.checkWriteFiles(value)
+		case "runcmd", "bootcmd":
+			c.checkCommands(key.Value, value)
+		default:
+			if !cloudConfigKeys[key.Value] {
+				c.add(SeverityWarning, key.Value, key, "unknown top-level key %q", key.Value)
+			}
+		}
+		c.checkPlaceholders(key.Value, value, key.Value == "runcmd" || key.Value == "bootcmd")
+	}
+}
+
+// checkUsers checks the users list. Entries are either a string such as
+// "default" or a mapping with at least a name.
+func (c *cloudInitChecker) checkUsers(node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		c.add(SeverityError, "users", node, "must be a list")
+		return
+	}
+
+	for i, item := range node.Content {
+		field := fmt.Sprintf("users[%d]", i)
+		if isString(item) {
+			continue
+		}
+		if item.Kind != yaml.MappingNode {
+			c.add(SeverityError, field, item, "must be a string or a mapping")
+			continue
+		}
+
+		if name := mappingValue(item, "name"); name == nil {
+			c.add(SeverityError, field, item, "name is required")
+		} else if !isString(name) || name.Value == "" {
+			c.add(SeverityError, field+".name", name, "must be a non-empty string")
+		}
+		if groups := mappingValue(item, "groups"); groups != nil && !isString(groups) && !isStringList(groups) {
+			c.add(SeverityError, field+".groups", groups, "must be a string or a list of strings")
+		}
+		if keys := mappingValue(item, "ssh_authorized_keys"); keys != nil && !isStringList(keys) {
+			c.add(SeverityError, field+".ssh_authorized_keys", keys, "must be a list of strings")
+		}
+		if lock := mappingValue(item, "lock_passwd"); lock != nil && !isBool(lock) {
+			c.add(SeverityError, field+".lock_passwd", lock, "must be a boolean")
+		}
+	}
+}
+
+// checkWriteFiles checks the write_files list.
+func (c *cloudInitChecker) checkWriteFiles(node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		c.add(SeverityError, "write_files", node, "must be a list")
+		return
+	}
+
+	for i, item := range node.Content {
+		field := fmt.Sprintf("write_files[%d]", i)
+		if item.Kind != yaml.MappingNode {
+			c.add(SeverityError, field, item, "must be a mapping")
+			continue
+		}
+
+		if path := mappingValue(item, "path"); path == nil {
+			c.add(SeverityError, field, item, "path is required")
+		} else if !isString(path) || path.Value == "" {
+			c.add(SeverityError, field+".path", path, "must be a non-empty string")
+		}
+		if content := mappingValue(item, "content"); content != nil && !isString(content) {
+			c.add(SeverityError, field+".content", content, "must be a string")
+		}
+		if perms := mappingValue(item, "permissions"); perms != nil {
+			if !isString(perms) {
+				c.add(SeverityError, field+".permissions", perms, "must be a quoted octal string such as '0644'")
+			} else if !permissionsRegex.MatchString(perms.Value) {
+				c.add(SeverityError, field+".permissions", perms, "invalid permissions %q", perms.Value)
+			}
+		}
+		if deferred := mappingValue(item, "defer"); deferred != nil && !isBool(deferred) {
+			c.add(SeverityError, field+".defer", deferred, "must be a boolean")
+		}
+	}
+}
+
+// checkCommands checks runcmd or bootcmd. Entries are either a shell string
+// or an argv list.
+func (c *cloudInitChecker) checkCommands(key string, node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		c.add(SeverityError, key, node, "must be a list")
+		return
+	}
+
+	for i, item := range node.Content {
+		if !isString(item) && !isStringList(item) {
+			c.add(SeverityError, fmt.Sprintf("%s[%d]", key, i), item, "must be a string or a list of strings")
+		}
+	}
+}
+
+// checkPlaceholders reports ${...} placeholders left in string values.
+func (c *cloudInitChecker) checkPlaceholders(field string, node *yaml.Node, shell bool) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		for _, match := range placeholderRegex.FindAllStringSubmatch(node.Value, -1) {
+			if shell && !templateVars[match[1]] {
+				continue
+			}
+			c.add(SeverityError, field, node, "unsubstituted placeholder %s", match[0])
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			c.checkPlaceholders(fmt.Sprintf("%s[%d]", field, i), item, shell)
+		}
+	case yaml.MappingNode:
+		if isPackageScript(field, node) {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			isContent := strings.HasPrefix(field, "write_files[") && key == "content"
+			c.checkPlaceholders(field+"."+key, node.Content[i+1], shell || isContent)
+		}
+	}
+}
+
+// isPackageScript reports whether node is a write_files entry for an
+// overlay package script.
+func isPackageScript(field string, node *yaml.Node) bool {
+	if !strings.HasPrefix(field, "write_files[") {
+		return false
+	}
+	path := mappingValue(node, "path")
+	return path != nil && strings.HasPrefix(path.Value, generator.PackageScriptsDir+"/")
+}
+
+// mappingValue returns the value for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func isString(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!str"
+}
+
+func isBool(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+}
+
+func isStringList(node *yaml.Node) bool {
+	if node.Kind != yaml.SequenceNode {
+		return false
+	}
+	for _, item := range node.Content {
+		if !isString(item) {
+			return false
+		}
+	}
+	return true
+}
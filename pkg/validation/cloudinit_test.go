@@ -0,0 +1,214 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/config"
+	"github.com/jaspreet-dot-casa/cloud-init/pkg/generator"
+)
+
+func TestValidateCloudInit_GeneratedOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cloud-init.yaml")
+	cfg := &config.FullConfig{
+		Username:         "o'brien",
+		Hostname:         "test-host",
+		SSHPublicKeys:    []string{"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAITest test@example.com"},
+		FullName:         "Test User",
+		Email:            "test@example.com",
+		GithubUser:       "octocat",
+		DisabledPackages: []string{"lazygit", "fzf"},
+	}
+	require.NoError(t, generator.Generate(cfg, path))
+
+	assert.Empty(t, ValidateCloudInit(path))
+}
+
+func TestValidateCloudInitData(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		severity Severity
+		field    string
+		message  string
+		line     int
+	}{
+		{
+			name:     "missing header",
+			content:  "hostname: test\n",
+			severity: SeverityError,
+			message:  "first line must be #cloud-config",
+			line:     1,
+		},
+		{
+			name:     "invalid yaml",
+			content:  "#cloud-config\nusers: [\n",
+			severity: SeverityError,
+			message:  "invalid YAML",
+		},
+		{
+			name:     "not a mapping",
+			content:  "#cloud-config\n- a\n",
+			severity: SeverityError,
+			message:  "top level must be a mapping",
+		},
+		{
+			name:     "unknown key",
+			content:  "#cloud-config\nhostname: test\nhost_name: test\n",
+			severity: SeverityWarning,
+			field:    "host_name",
+			message:  `unknown top-level key "host_name"`,
+			line:     3,
+		},
+		{
+			name:     "users not a list",
+			content:  "#cloud-config\nusers: alice\n",
+			severity: SeverityError,
+			field:    "users",
+			message:  "must be a list",
+		},
+		{
+			name:     "user without name",
+			content:  "#cloud-config\nusers:\n  - default\n  - shell: /bin/bash\n",
+			severity: SeverityError,
+			field:    "users[1]",
+			message:  "name is required",
+			line:     4,
+		},
+		{
+			name:     "ssh keys not a list",
+			content:  "#cloud-config\nusers:\n  - name: alice\n    ssh_authorized_keys: ssh-ed25519 AAAA\n",
+			severity: SeverityError,
+			field:    "users[0].ssh_authorized_keys",
+			message:  "must be a list of strings",
+		},
+		{
+			name:     "write_files without path",
+			content:  "#cloud-config\nwrite_files:\n  - content: hi\n",
+			severity: SeverityError,
+			field:    "write_files[0]",
+			message:  "path is required",
+		},
+		{
+			name:     "unquoted permissions",
+			content:  "#cloud-config\nwrite_files:\n  - path: /etc/x\n    permissions: 0644\n",
+			severity: SeverityError,
+			field:    "write_files[0].permissions",
+			message:  "quoted octal string",
+			line:     4,
+		},
+		{
+			name:     "bad permissions",
+			content:  "#cloud-config\nwrite_files:\n  - path: /etc/x\n    permissions: 'rwx'\n",
+			severity: SeverityError,
+			field:    "write_files[0].permissions",
+			message:  `invalid permissions "rwx"`,
+		},
+		{
+			name:     "runcmd entry is a mapping",
+			content:  "#cloud-config\nruncmd:\n  - echo ok\n  - [ls, -la]\n  - run: x\n",
+			severity: SeverityError,
+			field:    "runcmd[2]",
+			message:  "must be a string or a list of strings",
+		},
+		{
+			name:     "placeholder in value",
+			content:  "#cloud-config\nhostname: ${HOSTNAME}\n",
+			severity: SeverityError,
+			field:    "hostname",
+			message:  "unsubstituted placeholder ${HOSTNAME}",
+			line:     2,
+		},
+		{
+			name:     "template placeholder in runcmd",
+			content:  "#cloud-config\nruncmd:\n  - [su, -c, 'echo ${USERNAME}']\n",
+			severity: SeverityError,
+			field:    "runcmd[0][2]",
+			message:  "unsubstituted placeholder ${USERNAME}",
+		},
+		{
+			name:     "template placeholder in file content",
+			content:  "#cloud-config\nwrite_files:\n  - path: /x\n    content: |\n      KEY=${TAILSCALE_AUTH_KEY}\n",
+			severity: SeverityError,
+			field:    "write_files[0].content",
+			message:  "unsubstituted placeholder ${TAILSCALE_AUTH_KEY}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := ValidateCloudInitData("user-data", []byte(tt.content))
+			require.NotEmpty(t, issues)
+
+			issue := issues[0]
+			assert.Equal(t, tt.severity, issue.Severity)
+			assert.Equal(t, tt.field, issue.Field)
+			assert.Contains(t, issue.Message, tt.message)
+			if tt.line > 0 {
+				assert.Equal(t, tt.line, issue.Line)
+			}
+		})
+	}
+}
+
+func TestValidateCloudInitData_ShellVariablesAllowed(t *testing.T) {
+	content := `#cloud-config
+users:
+  - default
+  - name: alice
+    groups: [sudo, docker]
+    lock_passwd: false
+write_files:
+  - path: /opt/run.sh
+    permissions: '0755'
+    content: |
+      echo "${HOME}" "${1:-}" "${items[@]}"
+runcmd:
+  - echo "$HOME ${PATH}"
+final_message: "up after $UPTIME seconds"
+`
+	assert.Empty(t, ValidateCloudInitData("user-data", []byte(content)))
+}
+
+func TestValidateCloudInitData_PackageScriptsVerbatim(t *testing.T) {
+	content := `#cloud-config
+write_files:
+  - path: /opt/ucli/packages/internal-tool.sh
+    permissions: '755'
+    content: |
+      git config --global user.name "${USER_NAME}"
+  - path: /opt/other.sh
+    content: |
+      echo "${USER_NAME}"
+`
+	issues := ValidateCloudInitData("user-data", []byte(content))
+	require.Len(t, issues, 1)
+	assert.Equal(t, "write_files[1].content", issues[0].Field)
+}
+
+func TestCheckCloudInit(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("#cloud-config\nhostname: test\nextra: 1\n"), 0644))
+
+	warnings, err := CheckCloudInit(valid)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, `Warning: `+valid+`:3: extra: unknown top-level key "extra"`, warnings[0])
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("hostname: ${HOSTNAME}\n"), 0644))
+
+	_, err = CheckCloudInit(invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "first line must be #cloud-config")
+	assert.Contains(t, err.Error(), invalid+":1: hostname: unsubstituted placeholder ${HOSTNAME}")
+
+	_, err = CheckCloudInit(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read file")
+}
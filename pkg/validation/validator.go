@@ -24,10 +24,23 @@ const (
 type Issue struct {
 	File     string   `json:"file"`
 	Field    string   `json:"field,omitempty"`
+	Line     int      `json:"line,omitempty"`
 	Message  string   `json:"message"`
 	Severity Severity `json:"severity"`
 }
 
+// String formats the issue as file[:line]: [field: ]message.
+func (i Issue) String() string {
+	loc := i.File
+	if i.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, i.Line)
+	}
+	if i.Field != "" {
+		return fmt.Sprintf("%s: %s: %s", loc, i.Field, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", loc, i.Message)
+}
+
 // Result holds all validation results.
 type Result struct {
 	Issues []Issue `json:"issues"`
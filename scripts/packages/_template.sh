@@ -32,7 +32,16 @@ source "${SCRIPT_DIR}/../lib/dryrun.sh"
 #==============================================================================
 
 PACKAGE_NAME="example"
+DISPLAY_NAME="Example"
+# Category id from categories.conf (cli, shell, git, docker, system, ...)
+CATEGORY="cli"
+# Whether the package is preselected in the wizard
+DEFAULT="true"
 GITHUB_REPO="owner/repo"
+# Optional: packages this one needs, and packages it cannot be installed with
+# (space separated package names, e.g. DEPENDS="homebrew docker")
+DEPENDS=""
+CONFLICTS=""
 INSTALL_PATH="/usr/local/bin/${PACKAGE_NAME}"
 
 #==============================================================================
@@ -27,6 +27,9 @@ source "${SCRIPT_DIR}/../lib/health.sh"
 #==============================================================================
 
 PACKAGE_NAME="apt"
+DISPLAY_NAME="APT Packages"
+CATEGORY="system"
+DEFAULT="true"
 
 # Default packages if not specified in config.env
 DEFAULT_APT_PACKAGES="curl wget git zsh tree jq htop unzip build-essential procps file"
@@ -25,6 +25,10 @@ source "${SCRIPT_DIR}/../lib/health.sh"
 source "${SCRIPT_DIR}/../lib/dryrun.sh"
 
 PACKAGE_NAME="btop"
+DISPLAY_NAME="btop"
+CATEGORY="cli"
+DEFAULT="true"
+DEPENDS="homebrew"
 
 # shellcheck source=scripts/lib/brew.sh
 source "${SCRIPT_DIR}/../lib/brew.sh"
@@ -25,6 +25,10 @@ source "${SCRIPT_DIR}/../lib/health.sh"
 source "${SCRIPT_DIR}/../lib/dryrun.sh"
 
 PACKAGE_NAME="delta"
+DISPLAY_NAME="Delta"
+CATEGORY="git"
+DEFAULT="true"
+DEPENDS="homebrew"
 
 # shellcheck source=scripts/lib/brew.sh
 source "${SCRIPT_DIR}/../lib/brew.sh"
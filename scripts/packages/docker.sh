@@ -29,6 +29,9 @@ source "${SCRIPT_DIR}/../lib/lock.sh"
 #==============================================================================
 
 PACKAGE_NAME="docker"
+DISPLAY_NAME="Docker"
+CATEGORY="docker"
+DEFAULT="true"
 
 #==============================================================================
 # Functions
@@ -10,3 +10,9 @@ import "embed"
 //
 //go:embed *.sh
 var Scripts embed.FS
+
+// Categories is categories.conf, the ordered list of package categories
+// that scripts reference with CATEGORY="<id>".
+//
+//go:embed categories.conf
+var Categories []byte
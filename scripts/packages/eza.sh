@@ -25,6 +25,10 @@ source "${SCRIPT_DIR}/../lib/health.sh"
 source "${SCRIPT_DIR}/../lib/dryrun.sh"
 
 PACKAGE_NAME="eza"
+DISPLAY_NAME="eza"
+CATEGORY="cli"
+DEFAULT="true"
+DEPENDS="homebrew"
 
 # shellcheck source=scripts/lib/brew.sh
 source "${SCRIPT_DIR}/../lib/brew.sh"
@@ -25,6 +25,10 @@ source "${SCRIPT_DIR}/../lib/health.sh"
 source "${SCRIPT_DIR}/../lib/dryrun.sh"
 
 PACKAGE_NAME="lazydocker"
+DISPLAY_NAME="lazydocker"
+CATEGORY="docker"
+DEFAULT="true"
+DEPENDS="homebrew docker"
 
 # shellcheck source=scripts/lib/brew.sh
 source "${SCRIPT_DIR}/../lib/brew.sh"
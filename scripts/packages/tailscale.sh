@@ -25,6 +25,9 @@ source "${SCRIPT_DIR}/../lib/health.sh"
 source "${SCRIPT_DIR}/../lib/dryrun.sh"
 
 PACKAGE_NAME="tailscale"
+DISPLAY_NAME="Tailscale"
+CATEGORY="system"
+DEFAULT="true"
 
 is_installed() { command_exists tailscale; }
 